@@ -0,0 +1,180 @@
+// Package capture implements the capture of the plaintext flows between the
+// dispatcher and the outbounds, for debugging why a destination fails
+// through an outbound.
+package capture
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/pcapng"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/features/capture"
+	"github.com/xtls/xray-core/transport"
+)
+
+const (
+	defaultMaxDuration = time.Hour
+	defaultMaxBytes    = 1 << 30
+)
+
+// Capturer is an implementation of capture.Capturer.
+type Capturer struct {
+	access      sync.RWMutex
+	sessions    []*Session
+	active      atomic.Int32
+	maxDuration time.Duration
+	maxBytes    int64
+}
+
+// New creates a new Capturer.
+func New(config *Config) *Capturer {
+	c := &Capturer{
+		maxDuration: time.Duration(config.MaxDuration) * time.Second,
+		maxBytes:    int64(config.MaxBytes),
+	}
+	if c.maxDuration == 0 {
+		c.maxDuration = defaultMaxDuration
+	}
+	if c.maxBytes == 0 {
+		c.maxBytes = defaultMaxBytes
+	}
+	return c
+}
+
+// Type implements common.HasType.
+func (*Capturer) Type() interface{} {
+	return capture.CapturerType()
+}
+
+// Start implements common.Runnable.
+func (*Capturer) Start() error {
+	return nil
+}
+
+// Close implements common.Closable.
+func (c *Capturer) Close() error {
+	c.access.RLock()
+	sessions := slices.Clone(c.sessions)
+	c.access.RUnlock()
+	for _, s := range sessions {
+		s.Close()
+	}
+	return nil
+}
+
+// Open implements capture.Capturer.
+func (c *Capturer) Open(filter *capture.Filter, duration time.Duration, maxBytes int64) (capture.Session, error) {
+	if duration < 0 || duration > c.maxDuration {
+		return nil, errors.New("capture duration ", duration, " exceeds the limit ", c.maxDuration)
+	}
+	if maxBytes < 0 || maxBytes > c.maxBytes {
+		return nil, errors.New("capture size ", maxBytes, " exceeds the limit ", c.maxBytes)
+	}
+	if duration == 0 {
+		duration = c.maxDuration
+	}
+	if maxBytes == 0 {
+		maxBytes = c.maxBytes
+	}
+	s, err := newSession(c, filter, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	c.access.Lock()
+	c.sessions = append(c.sessions, s)
+	c.active.Store(int32(len(c.sessions)))
+	c.access.Unlock()
+	go func() {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			s.Close()
+		case <-s.done:
+		}
+	}()
+	return s, nil
+}
+
+func (c *Capturer) remove(s *Session) {
+	c.access.Lock()
+	defer c.access.Unlock()
+	if i := slices.Index(c.sessions, s); i >= 0 {
+		c.sessions = slices.Delete(c.sessions, i, i+1)
+		c.active.Store(int32(len(c.sessions)))
+	}
+}
+
+// Capture implements capture.Capturer.
+func (c *Capturer) Capture(ctx context.Context, ruleTag string, link *transport.Link) *transport.Link {
+	if c.active.Load() == 0 {
+		return link
+	}
+
+	var source net.Destination
+	var user string
+	inbound := session.InboundFromContext(ctx)
+	if inbound != nil {
+		source = inbound.Source
+		if inbound.User != nil {
+			user = inbound.User.Email
+		}
+	}
+	var destination net.Destination
+	if outbounds := session.OutboundsFromContext(ctx); len(outbounds) > 0 {
+		destination = outbounds[len(outbounds)-1].Target
+	}
+
+	var sessions []*Session
+	c.access.RLock()
+	for _, s := range c.sessions {
+		if s.matches(ruleTag, user) {
+			sessions = append(sessions, s)
+		}
+	}
+	c.access.RUnlock()
+
+	// A session may be closed by its writes, which must not hold the lock.
+	f := new(flow)
+	now := time.Now()
+	for _, s := range sessions {
+		if p, err := pcapng.NewFlow(s.writer, source, destination, now); err == nil {
+			f.flows = append(f.flows, p)
+		}
+	}
+	if len(f.flows) == 0 {
+		return link
+	}
+
+	// Spliced data would bypass the capture.
+	if inbound != nil {
+		inbound.CanSpliceCopy = 3
+	}
+	errors.LogDebug(ctx, "capturing flow to ", destination)
+	return &transport.Link{
+		Reader: &buf.TeeReader{
+			Reader: link.Reader,
+			Tee:    func(mb buf.MultiBuffer) { f.write(pcapng.Uplink, mb) },
+			End:    func() { f.end(pcapng.Uplink) },
+		},
+		Writer: &buf.TeeWriter{
+			Writer: link.Writer,
+			Tee:    func(mb buf.MultiBuffer) { f.write(pcapng.Downlink, mb) },
+			End:    func() { f.end(pcapng.Downlink) },
+		},
+	}
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return New(config.(*Config)), nil
+	}))
+}
@@ -0,0 +1,135 @@
+package capture_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/xtls/xray-core/app/capture"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/features/capture"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/pipe"
+)
+
+func flowContext(email string) context.Context {
+	ctx := session.ContextWithInbound(context.Background(), &session.Inbound{
+		Source: net.TCPDestination(net.ParseAddress("10.0.0.1"), 40000),
+		User:   &protocol.MemoryUser{Email: email},
+	})
+	return session.ContextWithOutbounds(ctx, []*session.Outbound{{
+		Target: net.TCPDestination(net.DomainAddress("www.example.com"), 443),
+	}})
+}
+
+func readAll(t *testing.T, s capture.Session) []byte {
+	var b []byte
+	for {
+		chunk, err := s.ReadChunk(context.Background())
+		if err == io.EOF {
+			return b
+		}
+		common.Must(err)
+		b = append(b, chunk...)
+	}
+}
+
+// payloads returns the TCP payloads of the packets in a pcapng stream.
+func payloads(b []byte) []string {
+	var list []string
+	for b = b[48:]; len(b) >= 12; {
+		size := binary.LittleEndian.Uint32(b[4:])
+		n := binary.LittleEndian.Uint32(b[20:])
+		if packet := b[28 : 28+n]; len(packet) > 40 {
+			list = append(list, string(packet[40:]))
+		}
+		b = b[size:]
+	}
+	return list
+}
+
+func transfer(c *Capturer, ctx context.Context, ruleTag string) {
+	uplinkReader, uplinkWriter := pipe.New()
+	downlinkReader, downlinkWriter := pipe.New()
+	link := c.Capture(ctx, ruleTag, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter})
+
+	b := buf.New()
+	b.WriteString("request")
+	common.Must(uplinkWriter.WriteMultiBuffer(buf.MultiBuffer{b}))
+	common.Must(uplinkWriter.Close())
+	mb, err := link.Reader.ReadMultiBuffer()
+	common.Must(err)
+	buf.ReleaseMulti(mb)
+	if _, err := link.Reader.ReadMultiBuffer(); err != io.EOF {
+		panic(err)
+	}
+
+	b = buf.New()
+	b.WriteString("response")
+	common.Must(link.Writer.WriteMultiBuffer(buf.MultiBuffer{b}))
+	common.Must(common.Close(link.Writer))
+	mb, err = downlinkReader.ReadMultiBuffer()
+	common.Must(err)
+	buf.ReleaseMulti(mb)
+}
+
+func TestCapture(t *testing.T) {
+	c := New(&Config{})
+	s, err := c.Open(&capture.Filter{RuleTags: []string{"debug"}, Users: []string{"alice"}}, time.Second, 0)
+	common.Must(err)
+
+	transfer(c, flowContext("bob"), "debug")
+	transfer(c, flowContext("bob"), "other")
+	transfer(c, flowContext("alice"), "")
+	common.Must(s.Close())
+
+	b := readAll(t, s)
+	if !bytes.HasPrefix(b, []byte{0x0A, 0x0D, 0x0D, 0x0A}) {
+		t.Fatal("not pcapng")
+	}
+	if p := payloads(b); len(p) != 4 || p[0] != "request" || p[1] != "response" || p[2] != "request" {
+		t.Error("unexpected payloads ", p)
+	}
+	if s.Dropped() != 0 {
+		t.Error("dropped ", s.Dropped())
+	}
+
+	// The flows are no longer captured.
+	link := &transport.Link{}
+	if c.Capture(flowContext("alice"), "debug", link) != link {
+		t.Error("captured after the capture is closed")
+	}
+}
+
+func TestCaptureLimits(t *testing.T) {
+	c := New(&Config{MaxDuration: 10, MaxBytes: 1000})
+	if _, err := c.Open(nil, time.Minute, 0); err == nil {
+		t.Error("expected error of duration")
+	}
+	if _, err := c.Open(nil, 0, 2000); err == nil {
+		t.Error("expected error of size")
+	}
+
+	s, err := c.Open(nil, 0, 300)
+	common.Must(err)
+	transfer(c, flowContext(""), "")
+	// The header and the handshake fit, but not the data.
+	if b := readAll(t, s); len(b) > 300 || len(payloads(b)) != 0 {
+		t.Error("unexpected capture of ", len(b), " bytes")
+	}
+
+	s, err = c.Open(nil, 100*time.Millisecond, 0)
+	common.Must(err)
+	start := time.Now()
+	readAll(t, s)
+	if d := time.Since(start); d < 100*time.Millisecond || d > 5*time.Second {
+		t.Error("unexpected duration ", d)
+	}
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/capture"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+type captureServer struct {
+	capturer capture.Capturer
+}
+
+// NewCaptureServer creates a CaptureServiceServer of capturer.
+func NewCaptureServer(capturer capture.Capturer) CaptureServiceServer {
+	return &captureServer{capturer: capturer}
+}
+
+// Capture implements CaptureServiceServer.
+func (s *captureServer) Capture(request *CaptureRequest, stream CaptureService_CaptureServer) error {
+	if s.capturer == nil {
+		return status.Error(codes.Unavailable, "capture not enabled")
+	}
+	session, err := s.capturer.Open(&capture.Filter{
+		RuleTags: request.RuleTags,
+		Users:    request.Users,
+	}, time.Duration(request.Duration)*time.Second, int64(request.MaxBytes))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer session.Close()
+
+	for {
+		data, err := session.ReadChunk(stream.Context())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&CaptureResponse{
+			Data:    data,
+			Dropped: session.Dropped(),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *captureServer) mustEmbedUnimplementedCaptureServiceServer() {}
+
+type service struct {
+	capturer capture.Capturer
+}
+
+func (s *service) Register(server *grpc.Server) {
+	RegisterCaptureServiceServer(server, NewCaptureServer(s.capturer))
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
+		s := new(service)
+
+		core.OptionalFeatures(ctx, func(c capture.Capturer) {
+			s.capturer = c
+		})
+
+		return s, nil
+	}))
+}
@@ -0,0 +1,254 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: app/capture/command/config.proto
+
+package command
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Config struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_app_capture_command_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_app_capture_command_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_app_capture_command_config_proto_rawDescGZIP(), []int{0}
+}
+
+type CaptureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Captures the flows routed by these rules.
+	RuleTags []string `protobuf:"bytes,1,rep,name=rule_tags,json=ruleTags,proto3" json:"rule_tags,omitempty"`
+	// Captures the flows of these users, by email.
+	Users []string `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty"`
+	// The duration of the capture in seconds. 0 means the longest allowed.
+	Duration uint32 `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	// The size limit of the capture in bytes. 0 means the largest allowed.
+	MaxBytes      uint64 `protobuf:"varint,4,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureRequest) Reset() {
+	*x = CaptureRequest{}
+	mi := &file_app_capture_command_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureRequest) ProtoMessage() {}
+
+func (x *CaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_capture_command_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureRequest.ProtoReflect.Descriptor instead.
+func (*CaptureRequest) Descriptor() ([]byte, []int) {
+	return file_app_capture_command_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CaptureRequest) GetRuleTags() []string {
+	if x != nil {
+		return x.RuleTags
+	}
+	return nil
+}
+
+func (x *CaptureRequest) GetUsers() []string {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *CaptureRequest) GetDuration() uint32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *CaptureRequest) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+type CaptureResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A chunk of the pcapng stream.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// The number of packets dropped so far.
+	Dropped       uint64 `protobuf:"varint,2,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureResponse) Reset() {
+	*x = CaptureResponse{}
+	mi := &file_app_capture_command_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureResponse) ProtoMessage() {}
+
+func (x *CaptureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_capture_command_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureResponse.ProtoReflect.Descriptor instead.
+func (*CaptureResponse) Descriptor() ([]byte, []int) {
+	return file_app_capture_command_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CaptureResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CaptureResponse) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+var File_app_capture_command_config_proto protoreflect.FileDescriptor
+
+const file_app_capture_command_config_proto_rawDesc = "" +
+	"\n" +
+	" app/capture/command/config.proto\x12\x18xray.app.capture.command\"\b\n" +
+	"\x06Config\"|\n" +
+	"\x0eCaptureRequest\x12\x1b\n" +
+	"\trule_tags\x18\x01 \x03(\tR\bruleTags\x12\x14\n" +
+	"\x05users\x18\x02 \x03(\tR\x05users\x12\x1a\n" +
+	"\bduration\x18\x03 \x01(\rR\bduration\x12\x1b\n" +
+	"\tmax_bytes\x18\x04 \x01(\x04R\bmaxBytes\"?\n" +
+	"\x0fCaptureResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x18\n" +
+	"\adropped\x18\x02 \x01(\x04R\adropped2t\n" +
+	"\x0eCaptureService\x12b\n" +
+	"\aCapture\x12(.xray.app.capture.command.CaptureRequest\x1a).xray.app.capture.command.CaptureResponse\"\x000\x01Bj\n" +
+	"\x1ccom.xray.app.capture.commandP\x01Z-github.com/xtls/xray-core/app/capture/command\xaa\x02\x18Xray.App.Capture.Commandb\x06proto3"
+
+var (
+	file_app_capture_command_config_proto_rawDescOnce sync.Once
+	file_app_capture_command_config_proto_rawDescData []byte
+)
+
+func file_app_capture_command_config_proto_rawDescGZIP() []byte {
+	file_app_capture_command_config_proto_rawDescOnce.Do(func() {
+		file_app_capture_command_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_app_capture_command_config_proto_rawDesc), len(file_app_capture_command_config_proto_rawDesc)))
+	})
+	return file_app_capture_command_config_proto_rawDescData
+}
+
+var file_app_capture_command_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_app_capture_command_config_proto_goTypes = []any{
+	(*Config)(nil),          // 0: xray.app.capture.command.Config
+	(*CaptureRequest)(nil),  // 1: xray.app.capture.command.CaptureRequest
+	(*CaptureResponse)(nil), // 2: xray.app.capture.command.CaptureResponse
+}
+var file_app_capture_command_config_proto_depIdxs = []int32{
+	1, // 0: xray.app.capture.command.CaptureService.Capture:input_type -> xray.app.capture.command.CaptureRequest
+	2, // 1: xray.app.capture.command.CaptureService.Capture:output_type -> xray.app.capture.command.CaptureResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_app_capture_command_config_proto_init() }
+func file_app_capture_command_config_proto_init() {
+	if File_app_capture_command_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_capture_command_config_proto_rawDesc), len(file_app_capture_command_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_app_capture_command_config_proto_goTypes,
+		DependencyIndexes: file_app_capture_command_config_proto_depIdxs,
+		MessageInfos:      file_app_capture_command_config_proto_msgTypes,
+	}.Build()
+	File_app_capture_command_config_proto = out.File
+	file_app_capture_command_config_proto_goTypes = nil
+	file_app_capture_command_config_proto_depIdxs = nil
+}
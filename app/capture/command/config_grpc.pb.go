@@ -0,0 +1,124 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v6.33.5
+// source: app/capture/command/config.proto
+
+package command
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CaptureService_Capture_FullMethodName = "/xray.app.capture.command.CaptureService/Capture"
+)
+
+// CaptureServiceClient is the client API for CaptureService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CaptureServiceClient interface {
+	Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CaptureResponse], error)
+}
+
+type captureServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCaptureServiceClient(cc grpc.ClientConnInterface) CaptureServiceClient {
+	return &captureServiceClient{cc}
+}
+
+func (c *captureServiceClient) Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CaptureResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CaptureService_ServiceDesc.Streams[0], CaptureService_Capture_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CaptureRequest, CaptureResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CaptureService_CaptureClient = grpc.ServerStreamingClient[CaptureResponse]
+
+// CaptureServiceServer is the server API for CaptureService service.
+// All implementations must embed UnimplementedCaptureServiceServer
+// for forward compatibility.
+type CaptureServiceServer interface {
+	Capture(*CaptureRequest, grpc.ServerStreamingServer[CaptureResponse]) error
+	mustEmbedUnimplementedCaptureServiceServer()
+}
+
+// UnimplementedCaptureServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCaptureServiceServer struct{}
+
+func (UnimplementedCaptureServiceServer) Capture(*CaptureRequest, grpc.ServerStreamingServer[CaptureResponse]) error {
+	return status.Error(codes.Unimplemented, "method Capture not implemented")
+}
+func (UnimplementedCaptureServiceServer) mustEmbedUnimplementedCaptureServiceServer() {}
+func (UnimplementedCaptureServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeCaptureServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CaptureServiceServer will
+// result in compilation errors.
+type UnsafeCaptureServiceServer interface {
+	mustEmbedUnimplementedCaptureServiceServer()
+}
+
+func RegisterCaptureServiceServer(s grpc.ServiceRegistrar, srv CaptureServiceServer) {
+	// If the following call panics, it indicates UnimplementedCaptureServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CaptureService_ServiceDesc, srv)
+}
+
+func _CaptureService_Capture_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CaptureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CaptureServiceServer).Capture(m, &grpc.GenericServerStream[CaptureRequest, CaptureResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CaptureService_CaptureServer = grpc.ServerStreamingServer[CaptureResponse]
+
+// CaptureService_ServiceDesc is the grpc.ServiceDesc for CaptureService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CaptureService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "xray.app.capture.command.CaptureService",
+	HandlerType: (*CaptureServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Capture",
+			Handler:       _CaptureService_Capture_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "app/capture/command/config.proto",
+}
@@ -0,0 +1,47 @@
+package capture
+
+import (
+	"sync"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/pcapng"
+)
+
+// flow is a captured flow, written to every session that matched it. It ends
+// when both its directions end, as the outbound may keep the link after
+// returning from dispatching it, such as with mux.
+type flow struct {
+	sync.Mutex
+	flows []*pcapng.Flow
+	ended [2]bool
+}
+
+func (f *flow) write(dir pcapng.Direction, mb buf.MultiBuffer) {
+	if mb.IsEmpty() {
+		return
+	}
+	f.Lock()
+	defer f.Unlock()
+	for i, p := range f.flows {
+		// The session has ended.
+		if p != nil && p.Write(dir, mb) != nil {
+			f.flows[i] = nil
+		}
+	}
+}
+
+// end ends a direction of the flow, and closes the flow once both have ended.
+func (f *flow) end(dir pcapng.Direction) {
+	f.Lock()
+	defer f.Unlock()
+	f.ended[dir-1] = true
+	if !f.ended[0] || !f.ended[1] {
+		return
+	}
+	for i, p := range f.flows {
+		if p != nil {
+			p.Close()
+			f.flows[i] = nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package capture
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/pcapng"
+	"github.com/xtls/xray-core/features/capture"
+)
+
+// sessionQueueSize is how many packets a session queues for its reader,
+// beyond which they are dropped rather than slowing down the flows.
+const sessionQueueSize = 1024
+
+var errSessionClosed = errors.New("capture closed")
+
+// Session is an implementation of capture.Session. It is the io.Writer of
+// its pcapng stream.
+type Session struct {
+	capturer *Capturer
+	ruleTags []string
+	users    []string
+	writer   *pcapng.Writer
+	chunks   chan []byte
+	done     chan struct{}
+	once     sync.Once
+	left     int64
+	dropped  atomic.Uint64
+}
+
+func newSession(c *Capturer, filter *capture.Filter, maxBytes int64) (*Session, error) {
+	s := &Session{
+		capturer: c,
+		chunks:   make(chan []byte, sessionQueueSize),
+		done:     make(chan struct{}),
+		left:     maxBytes,
+	}
+	if filter != nil {
+		s.ruleTags = filter.RuleTags
+		s.users = filter.Users
+	}
+	w, err := pcapng.NewWriter(s)
+	if err != nil {
+		return nil, err
+	}
+	s.writer = w
+	return s, nil
+}
+
+func (s *Session) matches(ruleTag, user string) bool {
+	if len(s.ruleTags) == 0 && len(s.users) == 0 {
+		return true
+	}
+	return (ruleTag != "" && slices.Contains(s.ruleTags, ruleTag)) ||
+		(user != "" && slices.Contains(s.users, user))
+}
+
+// Write implements io.Writer. It is called with the lock of the pcapng
+// writer held, and writes a whole block at a time.
+func (s *Session) Write(p []byte) (int, error) {
+	select {
+	case <-s.done:
+		return 0, errSessionClosed
+	default:
+	}
+	if int64(len(p)) > s.left {
+		s.Close()
+		return 0, errSessionClosed
+	}
+	select {
+	case s.chunks <- slices.Clone(p):
+		s.left -= int64(len(p))
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// ReadChunk implements capture.Session.
+func (s *Session) ReadChunk(ctx context.Context) ([]byte, error) {
+	select {
+	case b := <-s.chunks:
+		return b, nil
+	case <-s.done:
+		select {
+		case b := <-s.chunks:
+			return b, nil
+		default:
+			return nil, io.EOF
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Dropped implements capture.Session.
+func (s *Session) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close implements common.Closable.
+func (s *Session) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.capturer.remove(s)
+	})
+	return nil
+}
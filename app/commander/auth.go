@@ -0,0 +1,134 @@
+package commander
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/features/events"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// buildTLSConfig returns the TLS config of the API server.
+func buildTLSConfig(config *TLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(config.CertificateFile, config.KeyFile)
+	if err != nil {
+		return nil, errors.New("failed to load API server certificate").Base(err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if config.ClientCaFile != "" {
+		pem, err := os.ReadFile(config.ClientCaFile)
+		if err != nil {
+			return nil, errors.New("failed to read API client CA ", config.ClientCaFile).Base(err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate in API client CA ", config.ClientCaFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// authenticator checks the bearer token of every call against the methods
+// allowed for the token.
+type authenticator struct {
+	tokens []*Token
+}
+
+// allows returns whether the pattern allows the full method name.
+func allows(pattern, method string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if service, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(method, service+"/")
+	}
+	return pattern == method
+}
+
+// match returns the token of the secret, or nil if there isn't one.
+func (a *authenticator) match(secret string) *Token {
+	var matched *Token
+	for _, token := range a.tokens {
+		// Every token is compared, so that the time doesn't tell which one matches.
+		if subtle.ConstantTimeCompare([]byte(token.Secret), []byte(secret)) == 1 {
+			matched = token
+		}
+	}
+	return matched
+}
+
+func (a *authenticator) authorize(ctx context.Context, method string) error {
+	var secret string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if s, ok := strings.CutPrefix(v, "Bearer "); ok {
+				secret = s
+			}
+		}
+	}
+	from := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		from = p.Addr.String()
+	}
+
+	token := a.match(secret)
+	if token == nil {
+		errors.LogWarning(ctx, "API call ", method, " from ", from, " denied: invalid token")
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	for _, pattern := range token.Method {
+		if allows(pattern, method) {
+			return nil
+		}
+	}
+	errors.LogWarning(ctx, "API call ", method, " from ", from, " denied for ", token.Name)
+	return status.Error(codes.PermissionDenied, "method not allowed for "+token.Name)
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// changes are the prefixes of the methods that change the config.
+var changes = []string{"Add", "Alter", "Override", "Remove", "Restart"}
+
+// publishChange publishes the successful calls that change the config. It
+// runs after the authentication.
+func (c *Commander) publishChange(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil || c.events == nil {
+		return resp, err
+	}
+	name := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	for _, prefix := range changes {
+		if strings.HasPrefix(name, prefix) {
+			c.events.Publish(&events.Event{Type: events.ConfigChanged, Detail: info.FullMethod})
+			break
+		}
+	}
+	return resp, err
+}
@@ -2,7 +2,9 @@ package commander
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
 
@@ -10,9 +12,11 @@ import (
 	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/common/signal/done"
 	core "github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/events"
 	"github.com/xtls/xray-core/features/outbound"
 	"github.com/xtls/xray-core/transport/internet"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Commander is a Xray feature that provides gRPC methods to external clients.
@@ -21,8 +25,15 @@ type Commander struct {
 	server   *grpc.Server
 	services []Service
 	ohm      outbound.Manager
+	events   events.Bus
 	tag      string
 	listen   string
+	options  []grpc.ServerOption
+	// TLS of the listener, when the HTTP server terminates it.
+	tlsConfig  *tls.Config
+	rest       bool
+	gateway    *gateway
+	httpServer *http.Server
 }
 
 // NewCommander creates a new Commander based on the given config.
@@ -35,6 +46,30 @@ func NewCommander(ctx context.Context, config *Config) (*Commander, error) {
 	common.Must(core.RequireFeatures(ctx, func(om outbound.Manager) {
 		c.ohm = om
 	}))
+	core.OptionalFeatures(ctx, func(bus events.Bus) {
+		c.events = bus
+	})
+
+	if config.Tls != nil {
+		tlsConfig, err := buildTLSConfig(config.Tls)
+		if err != nil {
+			return nil, err
+		}
+		if config.Rest {
+			c.tlsConfig = tlsConfig
+		} else {
+			c.options = append(c.options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+	}
+	if len(config.Token) > 0 {
+		auth := &authenticator{tokens: config.Token}
+		c.options = append(c.options, grpc.ChainUnaryInterceptor(auth.unary), grpc.ChainStreamInterceptor(auth.stream))
+	}
+	c.options = append(c.options, grpc.ChainUnaryInterceptor(c.publishChange))
+	if config.Rest {
+		c.rest = true
+		c.options = append(c.options, grpc.ChainStreamInterceptor(sendHeader))
+	}
 
 	for _, rawConfig := range config.Service {
 		config, err := rawConfig.GetInstance()
@@ -63,14 +98,48 @@ func (c *Commander) Type() interface{} {
 // Start implements common.Runnable.
 func (c *Commander) Start() error {
 	c.Lock()
-	c.server = grpc.NewServer()
+	c.server = grpc.NewServer(c.options...)
 	for _, service := range c.services {
 		service.Register(c.server)
 	}
+	if c.rest {
+		gateway, err := newGateway(c.server)
+		if err != nil {
+			c.Unlock()
+			return errors.New("failed to start REST gateway").Base(err)
+		}
+		c.gateway = gateway
+		// gRPC and HTTP/JSON are told apart by the content type of requests.
+		server := c.server
+		c.httpServer = &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+					server.ServeHTTP(w, r)
+				} else {
+					gateway.ServeHTTP(w, r)
+				}
+			}),
+			TLSConfig: c.tlsConfig,
+			Protocols: new(http.Protocols),
+		}
+		c.httpServer.Protocols.SetHTTP1(true)
+		c.httpServer.Protocols.SetHTTP2(true)
+		c.httpServer.Protocols.SetUnencryptedHTTP2(true)
+	}
+	httpServer := c.httpServer
 	c.Unlock()
 
 	listen := func(listener net.Listener) {
-		if err := c.server.Serve(listener); err != nil {
+		var err error
+		switch {
+		case httpServer == nil:
+			err = c.server.Serve(listener)
+		case httpServer.TLSConfig != nil:
+			err = httpServer.ServeTLS(listener, "", "")
+		default:
+			err = httpServer.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errors.LogErrorInner(context.Background(), err, "failed to start grpc server")
 		}
 	}
@@ -120,6 +189,14 @@ func (c *Commander) Close() error {
 	c.Lock()
 	defer c.Unlock()
 
+	if c.httpServer != nil {
+		c.httpServer.Close()
+		c.httpServer = nil
+	}
+	if c.gateway != nil {
+		c.gateway.Close()
+		c.gateway = nil
+	}
 	if c.server != nil {
 		c.server.Stop()
 		c.server = nil
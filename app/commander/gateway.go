@@ -0,0 +1,255 @@
+package commander
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/signal/done"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// gateway serves the gRPC services in HTTP/JSON. The calls are made to the
+// gRPC server over an in-memory connection, so that they go through the same
+// services and authentication.
+//
+// A method is called by POST to the path of its gRPC method, such as
+// /xray.app.stats.command.StatsService/QueryStats, or in short
+// /StatsService/QueryStats, with the request in JSON. The responses of
+// streaming methods are sent as server-sent events.
+type gateway struct {
+	server   *grpc.Server
+	listener *OutboundListener
+	conn     *grpc.ClientConn
+}
+
+func newGateway(server *grpc.Server) (*gateway, error) {
+	g := &gateway{
+		server: server,
+		listener: &OutboundListener{
+			buffer: make(chan net.Conn, 4),
+			done:   done.New(),
+		},
+	}
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			client, server := net.Pipe()
+			g.listener.add(server)
+			return client, nil
+		}))
+	if err != nil {
+		return nil, err
+	}
+	g.conn = conn
+	go server.Serve(g.listener)
+	return g, nil
+}
+
+// Close implements common.Closable.
+func (g *gateway) Close() error {
+	g.conn.Close()
+	return g.listener.Close()
+}
+
+// method resolves the full name of the method of the path, and returns its
+// descriptor.
+func (g *gateway) method(path string) (string, protoreflect.MethodDescriptor) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !ok {
+		return "", nil
+	}
+	for full, info := range g.server.GetServiceInfo() {
+		if full != service && !strings.HasSuffix(full, "."+service) {
+			continue
+		}
+		for _, m := range info.Methods {
+			if m.Name != name || m.IsClientStream {
+				continue
+			}
+			// Services registered under aliases have no descriptors of their own.
+			d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(full))
+			if err != nil {
+				continue
+			}
+			if sd, ok := d.(protoreflect.ServiceDescriptor); ok {
+				if md := sd.Methods().ByName(protoreflect.Name(name)); md != nil {
+					return "/" + full + "/" + name, md
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+func newMessage(d protoreflect.MessageDescriptor) proto.Message {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(d.FullName())
+	if err != nil {
+		return nil
+	}
+	return mt.New().Interface()
+}
+
+// httpStatus returns the HTTP status of a gRPC code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, s *status.Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(s.Code()))
+	b, _ := protojson.Marshal(s.Proto())
+	w.Write(b)
+}
+
+// ServeHTTP implements http.Handler.
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, status.New(codes.Unimplemented, "method not allowed"))
+		return
+	}
+	name, md := g.method(r.URL.Path)
+	if md == nil {
+		writeError(w, status.New(codes.NotFound, "unknown method "+r.URL.Path))
+		return
+	}
+	req, resp := newMessage(md.Input()), newMessage(md.Output())
+	if req == nil || resp == nil {
+		writeError(w, status.New(codes.Unimplemented, "unknown message of "+name))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 16<<20))
+	if err != nil {
+		writeError(w, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+	if len(body) > 0 {
+		if err := protojson.Unmarshal(body, req); err != nil {
+			writeError(w, status.New(codes.InvalidArgument, err.Error()))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", auth)
+	}
+	deny := func(err error) {
+		if code := status.Code(err); code == codes.Unauthenticated || code == codes.PermissionDenied {
+			errors.LogWarning(ctx, "REST API call ", name, " from ", r.RemoteAddr, " denied")
+		}
+	}
+
+	if !md.IsStreamingServer() {
+		if err := g.conn.Invoke(ctx, name, req, resp); err != nil {
+			deny(err)
+			writeError(w, status.Convert(err))
+			return
+		}
+		b, err := protojson.Marshal(resp)
+		if err != nil {
+			writeError(w, status.New(codes.Internal, err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+		return
+	}
+
+	stream, err := g.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, name)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err == nil {
+		// The stream is terminated without headers on errors, such as denials.
+		var header metadata.MD
+		if header, err = stream.Header(); err == nil && header == nil {
+			err = stream.RecvMsg(resp)
+		}
+	}
+	if err != nil && err != io.EOF {
+		deny(err)
+		writeError(w, status.Convert(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for {
+		if err := stream.RecvMsg(resp); err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				b, _ := protojson.Marshal(status.Convert(err).Proto())
+				w.Write([]byte("event: error\ndata: "))
+				w.Write(b)
+				w.Write([]byte("\n\n"))
+			}
+			return
+		}
+		b, err := protojson.Marshal(resp)
+		if err != nil {
+			return
+		}
+		w.Write([]byte("data: "))
+		w.Write(b)
+		w.Write([]byte("\n\n"))
+		if flusher != nil {
+			flusher.Flush()
+		}
+		resp = newMessage(md.Output())
+	}
+}
+
+// sendHeader sends the headers of server streams ahead of their messages, so
+// that the gateway starts the event streams without waiting for them.
+func sendHeader(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if info.IsServerStream {
+		ss.SendHeader(nil)
+	}
+	return handler(srv, ss)
+}
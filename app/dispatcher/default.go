@@ -14,7 +14,9 @@ import (
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/capture"
 	"github.com/xtls/xray-core/features/dns"
+	"github.com/xtls/xray-core/features/events"
 	"github.com/xtls/xray-core/features/outbound"
 	"github.com/xtls/xray-core/features/policy"
 	"github.com/xtls/xray-core/features/routing"
@@ -93,11 +95,13 @@ func (r *cachedReader) Interrupt() {
 
 // DefaultDispatcher is a default implementation of Dispatcher.
 type DefaultDispatcher struct {
-	ohm    outbound.Manager
-	router routing.Router
-	policy policy.Manager
-	stats  stats.Manager
-	fdns   dns.FakeDNSEngine
+	ohm      outbound.Manager
+	router   routing.Router
+	policy   policy.Manager
+	stats    stats.Manager
+	fdns     dns.FakeDNSEngine
+	capturer capture.Capturer
+	events   events.Bus
 }
 
 func init() {
@@ -107,6 +111,12 @@ func init() {
 			core.OptionalFeatures(ctx, func(fdns dns.FakeDNSEngine) {
 				d.fdns = fdns
 			})
+			core.OptionalFeatures(ctx, func(c capture.Capturer) {
+				d.capturer = c
+			})
+			core.OptionalFeatures(ctx, func(bus events.Bus) {
+				d.events = bus
+			})
 			return d.Init(config.(*Config), om, router, pm, sm)
 		}); err != nil {
 			return nil, err
@@ -295,6 +305,7 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 			result, err := sniffer(ctx, cReader, sniffingRequest.MetadataOnly, destination.Network)
 			if err == nil {
 				content.Protocol = result.Protocol()
+				setFingerprintAttributes(content, result)
 			}
 			if err == nil && d.shouldOverride(ctx, result, sniffingRequest, destination) {
 				domain := result.Domain()
@@ -350,6 +361,7 @@ func (d *DefaultDispatcher) DispatchLink(ctx context.Context, destination net.De
 		result, err := sniffer(ctx, cReader, sniffingRequest.MetadataOnly, destination.Network)
 		if err == nil {
 			content.Protocol = result.Protocol()
+			setFingerprintAttributes(content, result)
 		}
 		if err == nil && d.shouldOverride(ctx, result, sniffingRequest, destination) {
 			domain := result.Domain()
@@ -440,6 +452,7 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 	routingLink := routing_session.AsRoutingContext(ctx)
 	inTag := routingLink.GetInboundTag()
 	isPickRoute := 0
+	var ruleTag string
 	if forcedOutboundTag := session.GetForcedOutboundTagFromContext(ctx); forcedOutboundTag != "" {
 		ctx = session.SetForcedOutboundTagToContext(ctx, "")
 		if h := d.ohm.GetHandler(forcedOutboundTag); h != nil {
@@ -457,10 +470,15 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 			outTag := route.GetOutboundTag()
 			if h := d.ohm.GetHandler(outTag); h != nil {
 				isPickRoute = 2
-				if route.GetRuleTag() == "" {
+				ruleTag = route.GetRuleTag()
+				if chain := route.GetChain(); len(chain) > 0 {
+					errors.LogInfo(ctx, "dialing [", outTag, "] through chain ", strings.Join(chain, " -> "))
+					ctx = session.ContextWithChain(ctx, chain)
+				}
+				if ruleTag == "" {
 					errors.LogInfo(ctx, "taking detour [", outTag, "] for [", destination, "]")
 				} else {
-					errors.LogInfo(ctx, "Hit route rule: [", route.GetRuleTag(), "] so taking detour [", outTag, "] for [", destination, "]")
+					errors.LogInfo(ctx, "Hit route rule: [", ruleTag, "] so taking detour [", outTag, "] for [", destination, "]")
 				}
 				handler = h
 			} else {
@@ -487,6 +505,10 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 
 	ob.Tag = handler.Tag()
 	if accessMessage := log.AccessMessageFromContext(ctx); accessMessage != nil {
+		if content := session.ContentFromContext(ctx); content != nil {
+			accessMessage.JA3 = content.Attribute(":ja3")
+			accessMessage.JA4 = content.Attribute(":ja4")
+		}
 		if tag := handler.Tag(); tag != "" {
 			if inTag == "" {
 				accessMessage.Detour = tag
@@ -498,8 +520,19 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 				accessMessage.Detour = inTag + " >> " + tag
 			}
 		}
+		if picker, ok := handler.(outbound.GatewayPicker); ok {
+			if gateway := picker.PickGateway(ctx); gateway != nil {
+				accessMessage.Via = gateway
+			}
+		}
 		log.Record(accessMessage)
 	}
 
+	if d.capturer != nil {
+		link = d.capturer.Capture(ctx, ruleTag, link)
+	}
+	if d.events != nil {
+		link = trackConnection(ctx, d.events, destination, link)
+	}
 	handler.Dispatch(ctx, link)
 }
@@ -0,0 +1,140 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/features/events"
+	"github.com/xtls/xray-core/transport"
+)
+
+// connection publishes the events of a dispatched connection. It closes when
+// both its directions end, as the outbound may keep the link after returning
+// from dispatching it, such as with mux.
+type connection struct {
+	bus      events.Bus
+	event    events.Event
+	uplink   atomic.Int64
+	downlink atomic.Int64
+
+	access sync.Mutex
+	ended  [2]bool
+}
+
+func (c *connection) end(dir int) {
+	c.access.Lock()
+	if c.ended[dir] {
+		c.access.Unlock()
+		return
+	}
+	c.ended[dir] = true
+	closed := c.ended[0] && c.ended[1]
+	c.access.Unlock()
+	if !closed {
+		return
+	}
+	e := c.event
+	e.Type = events.ConnectionClosed
+	e.Time = time.Now()
+	e.Uplink = c.uplink.Load()
+	e.Downlink = c.downlink.Load()
+	e.Duration = e.Time.Sub(c.event.Time)
+	c.bus.Publish(&e)
+}
+
+// trackConnection publishes the opening of the connection of the link, and
+// returns the link that publishes its closing with its traffic. Spliced
+// traffic bypasses the link, and isn't counted.
+func trackConnection(ctx context.Context, bus events.Bus, destination net.Destination, link *transport.Link) *transport.Link {
+	c := &connection{
+		bus: bus,
+		event: events.Event{
+			Type:        events.ConnectionOpened,
+			Time:        time.Now(),
+			Destination: destination.String(),
+		},
+	}
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		c.event.InboundTag = inbound.Tag
+		if inbound.Source.IsValid() {
+			c.event.Source = inbound.Source.String()
+		}
+		if inbound.User != nil {
+			c.event.Email = inbound.User.Email
+		}
+	}
+	if outbounds := session.OutboundsFromContext(ctx); len(outbounds) > 0 {
+		c.event.OutboundTag = outbounds[len(outbounds)-1].Tag
+	}
+	opened := c.event
+	bus.Publish(&opened)
+
+	return &transport.Link{
+		Reader: &eventReader{Reader: link.Reader, connection: c},
+		Writer: &eventWriter{Writer: link.Writer, connection: c},
+	}
+}
+
+type eventReader struct {
+	buf.Reader
+	connection *connection
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (r *eventReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	r.connection.uplink.Add(int64(mb.Len()))
+	if err != nil {
+		r.connection.end(0)
+	}
+	return mb, err
+}
+
+// ReadMultiBufferTimeout implements buf.TimeoutReader.
+func (r *eventReader) ReadMultiBufferTimeout(timeout time.Duration) (buf.MultiBuffer, error) {
+	reader, ok := r.Reader.(buf.TimeoutReader)
+	if !ok {
+		return nil, buf.ErrNotTimeoutReader
+	}
+	mb, err := reader.ReadMultiBufferTimeout(timeout)
+	r.connection.uplink.Add(int64(mb.Len()))
+	if err != nil && err != buf.ErrReadTimeout {
+		r.connection.end(0)
+	}
+	return mb, err
+}
+
+// Interrupt implements common.Interruptible.
+func (r *eventReader) Interrupt() {
+	common.Interrupt(r.Reader)
+	r.connection.end(0)
+}
+
+type eventWriter struct {
+	buf.Writer
+	connection *connection
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *eventWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	w.connection.downlink.Add(int64(mb.Len()))
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+// Close implements common.Closable.
+func (w *eventWriter) Close() error {
+	w.connection.end(1)
+	return common.Close(w.Writer)
+}
+
+// Interrupt implements common.Interruptible.
+func (w *eventWriter) Interrupt() {
+	w.connection.end(1)
+	common.Interrupt(w.Writer)
+}
@@ -2,15 +2,21 @@ package dispatcher
 
 import (
 	"context"
+	"strings"
 
 	"github.com/xtls/xray-core/common"
 	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/protocol/bittorrent"
+	"github.com/xtls/xray-core/common/protocol/dtls"
 	"github.com/xtls/xray-core/common/protocol/http"
 	"github.com/xtls/xray-core/common/protocol/quic"
+	"github.com/xtls/xray-core/common/protocol/rdp"
+	"github.com/xtls/xray-core/common/protocol/ssh"
+	"github.com/xtls/xray-core/common/protocol/stun"
 	"github.com/xtls/xray-core/common/protocol/tls"
+	"github.com/xtls/xray-core/common/session"
 )
 
 type SniffResult interface {
@@ -41,6 +47,13 @@ func NewSniffer(ctx context.Context) *Sniffer {
 			{func(c context.Context, b []byte) (SniffResult, error) { return bittorrent.SniffBittorrent(b) }, false, net.Network_TCP},
 			{func(c context.Context, b []byte) (SniffResult, error) { return quic.SniffQUIC(b) }, false, net.Network_UDP},
 			{func(c context.Context, b []byte) (SniffResult, error) { return bittorrent.SniffUTP(b) }, false, net.Network_UDP},
+			{func(c context.Context, b []byte) (SniffResult, error) { return ssh.SniffSSH(b) }, false, net.Network_TCP},
+			{func(c context.Context, b []byte) (SniffResult, error) { return rdp.SniffRDP(b) }, false, net.Network_TCP},
+			{func(c context.Context, b []byte) (SniffResult, error) { return stun.SniffSTUN(b) }, false, net.Network_UDP},
+			{func(c context.Context, b []byte) (SniffResult, error) { return dtls.SniffDTLS(b) }, false, net.Network_UDP},
+			// There is no sniffer for the cleartext mail protocols, as their servers send a banner
+			// before the client sends anything, which is after routing. Route them with a port rule,
+			// such as "port": "25,587,143".
 		},
 	}
 	if sniffer, err := newFakeDNSSniffer(ctx); err == nil {
@@ -133,10 +146,41 @@ func (c compositeResult) ProtocolForDomainResult() string {
 	return c.domainResult.Protocol()
 }
 
+func (c compositeResult) Fingerprint() *tls.Fingerprint {
+	if result, ok := c.protocolResult.(SnifferFingerprint); ok {
+		return result.Fingerprint()
+	}
+	return nil
+}
+
 type SnifferResultComposite interface {
 	ProtocolForDomainResult() string
 }
 
+// SnifferFingerprint is implemented by results of sniffers that read a TLS
+// ClientHello.
+type SnifferFingerprint interface {
+	Fingerprint() *tls.Fingerprint
+}
+
+// setFingerprintAttributes exposes the ClientHello fingerprint of the result
+// to routing as the ":ja3", ":ja4" and ":alpn" attributes.
+func setFingerprintAttributes(content *session.Content, result SniffResult) {
+	r, ok := result.(SnifferFingerprint)
+	if !ok {
+		return
+	}
+	fp := r.Fingerprint()
+	if fp == nil {
+		return
+	}
+	content.SetAttribute(":ja3", fp.JA3)
+	content.SetAttribute(":ja4", fp.JA4)
+	if len(fp.ALPN) > 0 {
+		content.SetAttribute(":alpn", strings.Join(fp.ALPN, ","))
+	}
+}
+
 type SnifferIsProtoSubsetOf interface {
 	IsProtoSubsetOf(protocolName string) bool
 }
@@ -15,7 +15,9 @@ import (
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/common/utils"
+	"github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/features/dns"
+	"github.com/xtls/xray-core/features/events"
 )
 
 // DNS is a DNS rely server.
@@ -31,6 +33,7 @@ type DNS struct {
 	domainMatcher          geodata.DomainMatcher
 	matcherInfos           []*DomainMatcherInfo
 	checkSystem            bool
+	events                 events.Bus
 }
 
 // DomainMatcherInfo contains information attached to index returned by Server.domainMatcher.
@@ -257,11 +260,18 @@ func (s *DNS) LookupIP(domain string, option dns.IPOption) ([]net.IP, uint32, er
 	}
 
 	// Name servers lookup
+	var ips []net.IP
+	var ttl uint32
+	var err error
 	if s.enableParallelQuery {
-		return s.parallelQuery(domain, option)
+		ips, ttl, err = s.parallelQuery(domain, option)
 	} else {
-		return s.serialQuery(domain, option)
+		ips, ttl, err = s.serialQuery(domain, option)
 	}
+	if err != nil && s.events != nil && !go_errors.Is(err, dns.ErrEmptyResponse) {
+		s.events.Publish(&events.Event{Type: events.DNSFailed, Domain: domain, Detail: err.Error()})
+	}
+	return ips, ttl, err
 }
 
 func (s *DNS) sortClients(domain string) []*Client {
@@ -534,6 +544,13 @@ func makeGroups( /*ctx context.Context,*/ clients []*Client) ([]group, []int) {
 
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
-		return New(ctx, config.(*Config))
+		d, err := New(ctx, config.(*Config))
+		if err != nil {
+			return nil, err
+		}
+		core.OptionalFeatures(ctx, func(bus events.Bus) {
+			d.events = bus
+		})
+		return d, nil
 	}))
 }
@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+
+	"github.com/xtls/xray-core/app/events"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/core"
+	feature_events "github.com/xtls/xray-core/features/events"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+type eventServer struct {
+	bus feature_events.Bus
+}
+
+// NewEventServer creates an EventServiceServer of bus.
+func NewEventServer(bus feature_events.Bus) EventServiceServer {
+	return &eventServer{bus: bus}
+}
+
+// Subscribe implements EventServiceServer.
+func (s *eventServer) Subscribe(request *SubscribeRequest, stream EventService_SubscribeServer) error {
+	if s.bus == nil {
+		return status.Error(codes.Unavailable, "events not enabled")
+	}
+	subscription := s.bus.Subscribe(events.ToFilter(request.Filter))
+	defer subscription.Close()
+
+	for {
+		select {
+		case e, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(events.ToProto(e)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *eventServer) mustEmbedUnimplementedEventServiceServer() {}
+
+type service struct {
+	bus feature_events.Bus
+}
+
+func (s *service) Register(server *grpc.Server) {
+	RegisterEventServiceServer(server, NewEventServer(s.bus))
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
+		s := new(service)
+
+		core.OptionalFeatures(ctx, func(bus feature_events.Bus) {
+			s.bus = bus
+		})
+
+		return s, nil
+	}))
+}
@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: app/events/command/command.proto
+
+package command
+
+import (
+	events "github.com/xtls/xray-core/app/events"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Config struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_app_events_command_command_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_app_events_command_command_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_app_events_command_command_proto_rawDescGZIP(), []int{0}
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *events.Filter         `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_app_events_command_command_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_events_command_command_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_app_events_command_command_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubscribeRequest) GetFilter() *events.Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+var File_app_events_command_command_proto protoreflect.FileDescriptor
+
+const file_app_events_command_command_proto_rawDesc = "" +
+	"\n" +
+	" app/events/command/command.proto\x12\x17xray.app.events.command\x1a\x17app/events/config.proto\"\b\n" +
+	"\x06Config\"C\n" +
+	"\x10SubscribeRequest\x12/\n" +
+	"\x06filter\x18\x01 \x01(\v2\x17.xray.app.events.FilterR\x06filter2b\n" +
+	"\fEventService\x12R\n" +
+	"\tSubscribe\x12).xray.app.events.command.SubscribeRequest\x1a\x16.xray.app.events.Event\"\x000\x01Bg\n" +
+	"\x1bcom.xray.app.events.commandP\x01Z,github.com/xtls/xray-core/app/events/command\xaa\x02\x17Xray.App.Events.Commandb\x06proto3"
+
+var (
+	file_app_events_command_command_proto_rawDescOnce sync.Once
+	file_app_events_command_command_proto_rawDescData []byte
+)
+
+func file_app_events_command_command_proto_rawDescGZIP() []byte {
+	file_app_events_command_command_proto_rawDescOnce.Do(func() {
+		file_app_events_command_command_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_app_events_command_command_proto_rawDesc), len(file_app_events_command_command_proto_rawDesc)))
+	})
+	return file_app_events_command_command_proto_rawDescData
+}
+
+var file_app_events_command_command_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_app_events_command_command_proto_goTypes = []any{
+	(*Config)(nil),           // 0: xray.app.events.command.Config
+	(*SubscribeRequest)(nil), // 1: xray.app.events.command.SubscribeRequest
+	(*events.Filter)(nil),    // 2: xray.app.events.Filter
+	(*events.Event)(nil),     // 3: xray.app.events.Event
+}
+var file_app_events_command_command_proto_depIdxs = []int32{
+	2, // 0: xray.app.events.command.SubscribeRequest.filter:type_name -> xray.app.events.Filter
+	1, // 1: xray.app.events.command.EventService.Subscribe:input_type -> xray.app.events.command.SubscribeRequest
+	3, // 2: xray.app.events.command.EventService.Subscribe:output_type -> xray.app.events.Event
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_app_events_command_command_proto_init() }
+func file_app_events_command_command_proto_init() {
+	if File_app_events_command_command_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_events_command_command_proto_rawDesc), len(file_app_events_command_command_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_app_events_command_command_proto_goTypes,
+		DependencyIndexes: file_app_events_command_command_proto_depIdxs,
+		MessageInfos:      file_app_events_command_command_proto_msgTypes,
+	}.Build()
+	File_app_events_command_command_proto = out.File
+	file_app_events_command_command_proto_goTypes = nil
+	file_app_events_command_command_proto_depIdxs = nil
+}
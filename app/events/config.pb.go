@@ -0,0 +1,519 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: app/events/config.proto
+
+package events
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type is the type of an event, as in features/events.
+type Type int32
+
+const (
+	Type_UNKNOWN           Type = 0
+	Type_CONNECTION_OPENED Type = 1
+	Type_CONNECTION_CLOSED Type = 2
+	Type_USER_ONLINE       Type = 3
+	Type_USER_OFFLINE      Type = 4
+	Type_OUTBOUND_ALIVE    Type = 5
+	Type_OUTBOUND_DEAD     Type = 6
+	Type_BALANCER_CHANGED  Type = 7
+	Type_CONFIG_CHANGED    Type = 8
+	Type_DNS_FAILED        Type = 9
+)
+
+// Enum value maps for Type.
+var (
+	Type_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "CONNECTION_OPENED",
+		2: "CONNECTION_CLOSED",
+		3: "USER_ONLINE",
+		4: "USER_OFFLINE",
+		5: "OUTBOUND_ALIVE",
+		6: "OUTBOUND_DEAD",
+		7: "BALANCER_CHANGED",
+		8: "CONFIG_CHANGED",
+		9: "DNS_FAILED",
+	}
+	Type_value = map[string]int32{
+		"UNKNOWN":           0,
+		"CONNECTION_OPENED": 1,
+		"CONNECTION_CLOSED": 2,
+		"USER_ONLINE":       3,
+		"USER_OFFLINE":      4,
+		"OUTBOUND_ALIVE":    5,
+		"OUTBOUND_DEAD":     6,
+		"BALANCER_CHANGED":  7,
+		"CONFIG_CHANGED":    8,
+		"DNS_FAILED":        9,
+	}
+)
+
+func (x Type) Enum() *Type {
+	p := new(Type)
+	*p = x
+	return p
+}
+
+func (x Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_app_events_config_proto_enumTypes[0].Descriptor()
+}
+
+func (Type) Type() protoreflect.EnumType {
+	return &file_app_events_config_proto_enumTypes[0]
+}
+
+func (x Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Type.Descriptor instead.
+func (Type) EnumDescriptor() ([]byte, []int) {
+	return file_app_events_config_proto_rawDescGZIP(), []int{0}
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  Type                   `protobuf:"varint,1,opt,name=type,proto3,enum=xray.app.events.Type" json:"type,omitempty"`
+	// Unix time in milliseconds.
+	Time        int64  `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	Email       string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	InboundTag  string `protobuf:"bytes,4,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	OutboundTag string `protobuf:"bytes,5,opt,name=outbound_tag,json=outboundTag,proto3" json:"outbound_tag,omitempty"`
+	Source      string `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Destination string `protobuf:"bytes,7,opt,name=destination,proto3" json:"destination,omitempty"`
+	Uplink      int64  `protobuf:"varint,8,opt,name=uplink,proto3" json:"uplink,omitempty"`
+	Downlink    int64  `protobuf:"varint,9,opt,name=downlink,proto3" json:"downlink,omitempty"`
+	// Duration of a closed connection in milliseconds.
+	Duration      int64  `protobuf:"varint,10,opt,name=duration,proto3" json:"duration,omitempty"`
+	Balancer      string `protobuf:"bytes,11,opt,name=balancer,proto3" json:"balancer,omitempty"`
+	Domain        string `protobuf:"bytes,12,opt,name=domain,proto3" json:"domain,omitempty"`
+	Detail        string `protobuf:"bytes,13,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_app_events_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_app_events_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_app_events_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_UNKNOWN
+}
+
+func (x *Event) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Event) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Event) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+func (x *Event) GetOutboundTag() string {
+	if x != nil {
+		return x.OutboundTag
+	}
+	return ""
+}
+
+func (x *Event) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Event) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Event) GetUplink() int64 {
+	if x != nil {
+		return x.Uplink
+	}
+	return 0
+}
+
+func (x *Event) GetDownlink() int64 {
+	if x != nil {
+		return x.Downlink
+	}
+	return 0
+}
+
+func (x *Event) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *Event) GetBalancer() string {
+	if x != nil {
+		return x.Balancer
+	}
+	return ""
+}
+
+func (x *Event) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Event) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+// Filter selects the events matching every non-empty field.
+type Filter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Types         []Type                 `protobuf:"varint,1,rep,packed,name=types,proto3,enum=xray.app.events.Type" json:"types,omitempty"`
+	Emails        []string               `protobuf:"bytes,2,rep,name=emails,proto3" json:"emails,omitempty"`
+	InboundTags   []string               `protobuf:"bytes,3,rep,name=inbound_tags,json=inboundTags,proto3" json:"inbound_tags,omitempty"`
+	OutboundTags  []string               `protobuf:"bytes,4,rep,name=outbound_tags,json=outboundTags,proto3" json:"outbound_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	mi := &file_app_events_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_app_events_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_app_events_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Filter) GetTypes() []Type {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *Filter) GetEmails() []string {
+	if x != nil {
+		return x.Emails
+	}
+	return nil
+}
+
+func (x *Filter) GetInboundTags() []string {
+	if x != nil {
+		return x.InboundTags
+	}
+	return nil
+}
+
+func (x *Filter) GetOutboundTags() []string {
+	if x != nil {
+		return x.OutboundTags
+	}
+	return nil
+}
+
+// Sink posts the events to a webhook as JSON.
+type Sink struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// URL of the webhook, or a Unix socket as in routing webhooks.
+	Url           string            `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Headers       map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Filter        *Filter           `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sink) Reset() {
+	*x = Sink{}
+	mi := &file_app_events_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sink) ProtoMessage() {}
+
+func (x *Sink) ProtoReflect() protoreflect.Message {
+	mi := &file_app_events_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sink.ProtoReflect.Descriptor instead.
+func (*Sink) Descriptor() ([]byte, []int) {
+	return file_app_events_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Sink) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Sink) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Sink) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type Config struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sinks         []*Sink                `protobuf:"bytes,1,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_app_events_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_app_events_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_app_events_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Config) GetSinks() []*Sink {
+	if x != nil {
+		return x.Sinks
+	}
+	return nil
+}
+
+var File_app_events_config_proto protoreflect.FileDescriptor
+
+const file_app_events_config_proto_rawDesc = "" +
+	"\n" +
+	"\x17app/events/config.proto\x12\x0fxray.app.events\"\xf6\x02\n" +
+	"\x05Event\x12)\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x15.xray.app.events.TypeR\x04type\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1f\n" +
+	"\vinbound_tag\x18\x04 \x01(\tR\n" +
+	"inboundTag\x12!\n" +
+	"\foutbound_tag\x18\x05 \x01(\tR\voutboundTag\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\a \x01(\tR\vdestination\x12\x16\n" +
+	"\x06uplink\x18\b \x01(\x03R\x06uplink\x12\x1a\n" +
+	"\bdownlink\x18\t \x01(\x03R\bdownlink\x12\x1a\n" +
+	"\bduration\x18\n" +
+	" \x01(\x03R\bduration\x12\x1a\n" +
+	"\bbalancer\x18\v \x01(\tR\bbalancer\x12\x16\n" +
+	"\x06domain\x18\f \x01(\tR\x06domain\x12\x16\n" +
+	"\x06detail\x18\r \x01(\tR\x06detail\"\x95\x01\n" +
+	"\x06Filter\x12+\n" +
+	"\x05types\x18\x01 \x03(\x0e2\x15.xray.app.events.TypeR\x05types\x12\x16\n" +
+	"\x06emails\x18\x02 \x03(\tR\x06emails\x12!\n" +
+	"\finbound_tags\x18\x03 \x03(\tR\vinboundTags\x12#\n" +
+	"\routbound_tags\x18\x04 \x03(\tR\foutboundTags\"\xc3\x01\n" +
+	"\x04Sink\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12<\n" +
+	"\aheaders\x18\x02 \x03(\v2\".xray.app.events.Sink.HeadersEntryR\aheaders\x12/\n" +
+	"\x06filter\x18\x03 \x01(\v2\x17.xray.app.events.FilterR\x06filter\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"5\n" +
+	"\x06Config\x12+\n" +
+	"\x05sinks\x18\x01 \x03(\v2\x15.xray.app.events.SinkR\x05sinks*\xc5\x01\n" +
+	"\x04Type\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\x15\n" +
+	"\x11CONNECTION_OPENED\x10\x01\x12\x15\n" +
+	"\x11CONNECTION_CLOSED\x10\x02\x12\x0f\n" +
+	"\vUSER_ONLINE\x10\x03\x12\x10\n" +
+	"\fUSER_OFFLINE\x10\x04\x12\x12\n" +
+	"\x0eOUTBOUND_ALIVE\x10\x05\x12\x11\n" +
+	"\rOUTBOUND_DEAD\x10\x06\x12\x14\n" +
+	"\x10BALANCER_CHANGED\x10\a\x12\x12\n" +
+	"\x0eCONFIG_CHANGED\x10\b\x12\x0e\n" +
+	"\n" +
+	"DNS_FAILED\x10\tBO\n" +
+	"\x13com.xray.app.eventsP\x01Z$github.com/xtls/xray-core/app/events\xaa\x02\x0fXray.App.Eventsb\x06proto3"
+
+var (
+	file_app_events_config_proto_rawDescOnce sync.Once
+	file_app_events_config_proto_rawDescData []byte
+)
+
+func file_app_events_config_proto_rawDescGZIP() []byte {
+	file_app_events_config_proto_rawDescOnce.Do(func() {
+		file_app_events_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_app_events_config_proto_rawDesc), len(file_app_events_config_proto_rawDesc)))
+	})
+	return file_app_events_config_proto_rawDescData
+}
+
+var file_app_events_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_app_events_config_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_app_events_config_proto_goTypes = []any{
+	(Type)(0),      // 0: xray.app.events.Type
+	(*Event)(nil),  // 1: xray.app.events.Event
+	(*Filter)(nil), // 2: xray.app.events.Filter
+	(*Sink)(nil),   // 3: xray.app.events.Sink
+	(*Config)(nil), // 4: xray.app.events.Config
+	nil,            // 5: xray.app.events.Sink.HeadersEntry
+}
+var file_app_events_config_proto_depIdxs = []int32{
+	0, // 0: xray.app.events.Event.type:type_name -> xray.app.events.Type
+	0, // 1: xray.app.events.Filter.types:type_name -> xray.app.events.Type
+	5, // 2: xray.app.events.Sink.headers:type_name -> xray.app.events.Sink.HeadersEntry
+	2, // 3: xray.app.events.Sink.filter:type_name -> xray.app.events.Filter
+	3, // 4: xray.app.events.Config.sinks:type_name -> xray.app.events.Sink
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_app_events_config_proto_init() }
+func file_app_events_config_proto_init() {
+	if File_app_events_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_events_config_proto_rawDesc), len(file_app_events_config_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_app_events_config_proto_goTypes,
+		DependencyIndexes: file_app_events_config_proto_depIdxs,
+		EnumInfos:         file_app_events_config_proto_enumTypes,
+		MessageInfos:      file_app_events_config_proto_msgTypes,
+	}.Build()
+	File_app_events_config_proto = out.File
+	file_app_events_config_proto_goTypes = nil
+	file_app_events_config_proto_depIdxs = nil
+}
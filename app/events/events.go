@@ -0,0 +1,212 @@
+// Package events implements the event bus, which streams the events of
+// connections, users, outbounds and the config to the API and webhooks.
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/features/events"
+)
+
+// subscriptionSize is the number of events a subscriber may fall behind
+// before the events are dropped.
+const subscriptionSize = 256
+
+// Bus is an implementation of events.Bus.
+type Bus struct {
+	access        sync.RWMutex
+	subscriptions map[*subscription]struct{}
+	sinks         []*sink
+
+	// Connections of the online users by email.
+	onlineAccess sync.Mutex
+	online       map[string]int
+}
+
+// New creates a new Bus.
+func New(config *Config) *Bus {
+	b := &Bus{
+		subscriptions: make(map[*subscription]struct{}),
+		online:        make(map[string]int),
+	}
+	for _, s := range config.Sinks {
+		b.sinks = append(b.sinks, newSink(s))
+	}
+	return b
+}
+
+// Type implements common.HasType.
+func (*Bus) Type() interface{} {
+	return events.BusType()
+}
+
+// Start implements common.Runnable.
+func (b *Bus) Start() error {
+	for _, s := range b.sinks {
+		s.start()
+	}
+	return nil
+}
+
+// Close implements common.Closable.
+func (b *Bus) Close() error {
+	b.access.Lock()
+	subscriptions := b.subscriptions
+	b.subscriptions = make(map[*subscription]struct{})
+	b.access.Unlock()
+	for s := range subscriptions {
+		s.close()
+	}
+	for _, s := range b.sinks {
+		s.Close()
+	}
+	return nil
+}
+
+// Publish implements events.Bus. The users come online with their first
+// connection, and go offline with their last.
+func (b *Bus) Publish(e *events.Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Email == "" || (e.Type != events.ConnectionOpened && e.Type != events.ConnectionClosed) {
+		b.deliver(e)
+		return
+	}
+
+	b.onlineAccess.Lock()
+	count := b.online[e.Email]
+	if e.Type == events.ConnectionOpened {
+		b.online[e.Email] = count + 1
+	} else if count <= 1 {
+		delete(b.online, e.Email)
+	} else {
+		b.online[e.Email] = count - 1
+	}
+	b.onlineAccess.Unlock()
+
+	switch {
+	case e.Type == events.ConnectionOpened && count == 0:
+		b.deliver(&events.Event{Type: events.UserOnline, Time: e.Time, Email: e.Email, InboundTag: e.InboundTag, Source: e.Source})
+		b.deliver(e)
+	case e.Type == events.ConnectionClosed && count == 1:
+		b.deliver(e)
+		b.deliver(&events.Event{Type: events.UserOffline, Time: e.Time, Email: e.Email, InboundTag: e.InboundTag, Source: e.Source})
+	default:
+		b.deliver(e)
+	}
+}
+
+func (b *Bus) deliver(e *events.Event) {
+	b.access.RLock()
+	defer b.access.RUnlock()
+	for s := range b.subscriptions {
+		s.send(e)
+	}
+	for _, s := range b.sinks {
+		s.send(e)
+	}
+}
+
+// Subscribe implements events.Bus.
+func (b *Bus) Subscribe(filter *events.Filter) events.Subscription {
+	s := &subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan *events.Event, subscriptionSize),
+	}
+	b.access.Lock()
+	b.subscriptions[s] = struct{}{}
+	b.access.Unlock()
+	return s
+}
+
+type subscription struct {
+	bus     *Bus
+	filter  *events.Filter
+	events  chan *events.Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// send is called with the read lock of the bus, which close waits for.
+func (s *subscription) send(e *events.Event) {
+	if !s.filter.Match(e) {
+		return
+	}
+	select {
+	case s.events <- e:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *subscription) close() {
+	s.once.Do(func() {
+		close(s.events)
+	})
+}
+
+// Events implements events.Subscription.
+func (s *subscription) Events() <-chan *events.Event {
+	return s.events
+}
+
+// Dropped implements events.Subscription.
+func (s *subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close implements common.Closable.
+func (s *subscription) Close() error {
+	s.bus.access.Lock()
+	delete(s.bus.subscriptions, s)
+	s.bus.access.Unlock()
+	s.close()
+	return nil
+}
+
+// ToFilter converts the filter to events.Filter.
+func ToFilter(f *Filter) *events.Filter {
+	if f == nil {
+		return nil
+	}
+	filter := &events.Filter{
+		Emails:       f.Emails,
+		InboundTags:  f.InboundTags,
+		OutboundTags: f.OutboundTags,
+	}
+	for _, t := range f.Types {
+		filter.Types = append(filter.Types, events.Type(t))
+	}
+	return filter
+}
+
+// ToProto converts the event to its message.
+func ToProto(e *events.Event) *Event {
+	return &Event{
+		Type:        Type(e.Type),
+		Time:        e.Time.UnixMilli(),
+		Email:       e.Email,
+		InboundTag:  e.InboundTag,
+		OutboundTag: e.OutboundTag,
+		Source:      e.Source,
+		Destination: e.Destination,
+		Uplink:      e.Uplink,
+		Downlink:    e.Downlink,
+		Duration:    e.Duration.Milliseconds(),
+		Balancer:    e.Balancer,
+		Domain:      e.Domain,
+		Detail:      e.Detail,
+	}
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return New(config.(*Config)), nil
+	}))
+}
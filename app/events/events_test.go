@@ -0,0 +1,75 @@
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/app/events"
+	"github.com/xtls/xray-core/features/events"
+)
+
+func receive(t *testing.T, s events.Subscription) []events.Type {
+	var types []events.Type
+	for {
+		select {
+		case e := <-s.Events():
+			types = append(types, e.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestUserOnline(t *testing.T) {
+	bus := New(&Config{})
+	s := bus.Subscribe(nil)
+	defer s.Close()
+
+	bus.Publish(&events.Event{Type: events.ConnectionOpened, Email: "a"})
+	bus.Publish(&events.Event{Type: events.ConnectionOpened, Email: "a"})
+	bus.Publish(&events.Event{Type: events.ConnectionClosed, Email: "a"})
+	bus.Publish(&events.Event{Type: events.ConnectionClosed, Email: "a"})
+
+	expected := []events.Type{
+		events.UserOnline, events.ConnectionOpened,
+		events.ConnectionOpened,
+		events.ConnectionClosed,
+		events.ConnectionClosed, events.UserOffline,
+	}
+	types := receive(t, s)
+	if len(types) != len(expected) {
+		t.Fatal("expected ", expected, ", got ", types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Error("event ", i, ": expected ", expected[i], ", got ", types[i])
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	bus := New(&Config{})
+	s := bus.Subscribe(ToFilter(&Filter{
+		Types:        []Type{Type_OUTBOUND_DEAD},
+		OutboundTags: []string{"proxy"},
+	}))
+	defer s.Close()
+
+	bus.Publish(&events.Event{Type: events.OutboundAlive, OutboundTag: "proxy"})
+	bus.Publish(&events.Event{Type: events.OutboundDead, OutboundTag: "direct"})
+	bus.Publish(&events.Event{Type: events.OutboundDead, OutboundTag: "proxy"})
+
+	if types := receive(t, s); len(types) != 1 || types[0] != events.OutboundDead {
+		t.Error("expected only the dead proxy, got ", types)
+	}
+}
+
+func TestCloseSubscription(t *testing.T) {
+	bus := New(&Config{})
+	s := bus.Subscribe(nil)
+	s.Close()
+
+	bus.Publish(&events.Event{Type: events.ConfigChanged})
+	if _, ok := <-s.Events(); ok {
+		t.Error("expected closed channel")
+	}
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/webhook"
+	"github.com/xtls/xray-core/features/events"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// sink posts the events matching its filter to a webhook, one at a time.
+type sink struct {
+	client  *webhook.Client
+	filter  *events.Filter
+	events  chan *events.Event
+	dropped atomic.Uint64
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+func newSink(config *Sink) *sink {
+	return &sink{
+		client: webhook.New(config.Url, &webhook.Options{Headers: config.Headers}),
+		filter: ToFilter(config.Filter),
+		events: make(chan *events.Event, subscriptionSize),
+		done:   make(chan struct{}),
+	}
+}
+
+func (s *sink) start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+func (s *sink) send(e *events.Event) {
+	if !s.filter.Match(e) {
+		return
+	}
+	select {
+	case s.events <- e:
+	default:
+		if s.dropped.Add(1)%subscriptionSize == 1 {
+			errors.LogWarning(context.Background(), "events: webhook is too slow, ", s.dropped.Load(), " events dropped")
+		}
+	}
+}
+
+func (s *sink) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case e := <-s.events:
+			body, err := protojson.Marshal(ToProto(e))
+			if err != nil {
+				errors.LogWarningInner(context.Background(), err, "events: failed to marshal event")
+				continue
+			}
+			if err := s.client.Post(context.Background(), body); err != nil {
+				errors.LogInfoInner(context.Background(), err, "events: failed to notify webhook")
+			}
+		}
+	}
+}
+
+// Close implements common.Closable.
+func (s *sink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+	s.client.Close()
+	return nil
+}
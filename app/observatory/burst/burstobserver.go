@@ -9,6 +9,7 @@ import (
 	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/common/signal/done"
 	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/events"
 	"github.com/xtls/xray-core/features/extension"
 	"github.com/xtls/xray-core/features/outbound"
 	"github.com/xtls/xray-core/features/routing"
@@ -100,6 +101,15 @@ func New(ctx context.Context, config *Config) (*Observer, error) {
 		return nil, errors.New("Cannot get depended features").Base(err)
 	}
 	hp := NewHealthPing(ctx, dispatcher, config.PingConfig)
+	core.OptionalFeatures(ctx, func(bus events.Bus) {
+		hp.OnAliveChange = func(tag string, alive bool) {
+			e := &events.Event{Type: events.OutboundAlive, OutboundTag: tag}
+			if !alive {
+				e.Type = events.OutboundDead
+			}
+			bus.Publish(e)
+		}
+	})
 	return &Observer{
 		config: config,
 		ctx:    ctx,
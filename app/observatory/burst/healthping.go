@@ -34,6 +34,10 @@ type HealthPing struct {
 
 	Settings *HealthPingSettings
 	Results  map[string]*HealthPingRTTS
+
+	// OnAliveChange, if set, is called with the access lock when an outbound
+	// becomes alive or dead.
+	OnAliveChange func(tag string, alive bool)
 }
 
 // NewHealthPing creates a new HealthPing with settings
@@ -247,7 +251,15 @@ func (h *HealthPing) PutResult(tag string, rtt time.Duration) {
 		r = NewHealthPingResult(h.Settings.SamplingCount, validity)
 		h.Results[tag] = r
 	}
+	before := r.getStatistics()
 	r.Put(rtt)
+	if h.OnAliveChange != nil {
+		after := r.getStatistics()
+		alive := after.All != after.Fail
+		if !ok || (before.All != before.Fail) != alive {
+			h.OnAliveChange(tag, alive)
+		}
+	}
 }
 
 // Cleanup removes results of removed handlers,
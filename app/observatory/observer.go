@@ -18,6 +18,7 @@ import (
 	"github.com/xtls/xray-core/common/task"
 	"github.com/xtls/xray-core/common/utils"
 	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/events"
 	"github.com/xtls/xray-core/features/extension"
 	"github.com/xtls/xray-core/features/outbound"
 	"github.com/xtls/xray-core/features/routing"
@@ -36,6 +37,7 @@ type Observer struct {
 
 	ohm        outbound.Manager
 	dispatcher routing.Dispatcher
+	events     events.Bus
 }
 
 func (o *Observer) GetObservation(ctx context.Context) (proto.Message, error) {
@@ -198,12 +200,17 @@ func (o *Observer) updateStatusForResult(outbound string, result *ProbeResult) {
 	o.statusLock.Lock()
 	defer o.statusLock.Unlock()
 	var status *OutboundStatus
+	changed := true
 	if location := o.findStatusLocationLockHolderOnly(outbound); location != -1 {
 		status = o.status[location]
+		changed = status.Alive != result.Alive
 	} else {
 		status = &OutboundStatus{}
 		o.status = append(o.status, status)
 	}
+	if changed && o.events != nil {
+		publishHealth(o.events, outbound, result)
+	}
 
 	status.LastTryTime = time.Now().Unix()
 	status.OutboundTag = outbound
@@ -237,12 +244,26 @@ func New(ctx context.Context, config *Config) (*Observer, error) {
 	if err != nil {
 		return nil, errors.New("Cannot get depended features").Base(err)
 	}
-	return &Observer{
+	o := &Observer{
 		config:     config,
 		ctx:        ctx,
 		ohm:        outboundManager,
 		dispatcher: dispatcher,
-	}, nil
+	}
+	core.OptionalFeatures(ctx, func(bus events.Bus) {
+		o.events = bus
+	})
+	return o, nil
+}
+
+// publishHealth publishes that the outbound became alive or dead.
+func publishHealth(bus events.Bus, outbound string, result *ProbeResult) {
+	e := &events.Event{Type: events.OutboundAlive, OutboundTag: outbound}
+	if !result.Alive {
+		e.Type = events.OutboundDead
+		e.Detail = result.LastErrorReason
+	}
+	bus.Publish(e)
 }
 
 func init() {
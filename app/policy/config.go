@@ -74,6 +74,7 @@ func (p *Policy) ToCorePolicy() policy.Session {
 		cp.Stats.UserUplink = p.Stats.UserUplink
 		cp.Stats.UserDownlink = p.Stats.UserDownlink
 		cp.Stats.UserOnline = p.Stats.UserOnline
+		cp.Stats.UserHandshake = p.Stats.UserHandshake
 	}
 	if p.Buffer != nil {
 		cp.Buffer.PerConnection = p.Buffer.Connection
@@ -291,10 +291,13 @@ func (x *Policy_Timeout) GetDownlinkOnly() *Second {
 }
 
 type Policy_Stats struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserUplink    bool                   `protobuf:"varint,1,opt,name=user_uplink,json=userUplink,proto3" json:"user_uplink,omitempty"`
-	UserDownlink  bool                   `protobuf:"varint,2,opt,name=user_downlink,json=userDownlink,proto3" json:"user_downlink,omitempty"`
-	UserOnline    bool                   `protobuf:"varint,3,opt,name=user_online,json=userOnline,proto3" json:"user_online,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	UserUplink   bool                   `protobuf:"varint,1,opt,name=user_uplink,json=userUplink,proto3" json:"user_uplink,omitempty"`
+	UserDownlink bool                   `protobuf:"varint,2,opt,name=user_downlink,json=userDownlink,proto3" json:"user_downlink,omitempty"`
+	UserOnline   bool                   `protobuf:"varint,3,opt,name=user_online,json=userOnline,proto3" json:"user_online,omitempty"`
+	// The unix time of the last handshake of users of protocols with their
+	// own handshakes, such as WireGuard peers.
+	UserHandshake bool `protobuf:"varint,4,opt,name=user_handshake,json=userHandshake,proto3" json:"user_handshake,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -350,6 +353,13 @@ func (x *Policy_Stats) GetUserOnline() bool {
 	return false
 }
 
+func (x *Policy_Stats) GetUserHandshake() bool {
+	if x != nil {
+		return x.UserHandshake
+	}
+	return false
+}
+
 type Policy_Buffer struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Buffer size per connection, in bytes. -1 for unlimited buffer.
@@ -469,7 +479,7 @@ const file_app_policy_config_proto_rawDesc = "" +
 	"\n" +
 	"\x17app/policy/config.proto\x12\x0fxray.app.policy\"\x1e\n" +
 	"\x06Second\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\rR\x05value\"\xc7\x04\n" +
+	"\x05value\x18\x01 \x01(\rR\x05value\"\xef\x04\n" +
 	"\x06Policy\x129\n" +
 	"\atimeout\x18\x01 \x01(\v2\x1f.xray.app.policy.Policy.TimeoutR\atimeout\x123\n" +
 	"\x05stats\x18\x02 \x01(\v2\x1d.xray.app.policy.Policy.StatsR\x05stats\x126\n" +
@@ -479,13 +489,14 @@ const file_app_policy_config_proto_rawDesc = "" +
 	"\x0fconnection_idle\x18\x02 \x01(\v2\x17.xray.app.policy.SecondR\x0econnectionIdle\x128\n" +
 	"\vuplink_only\x18\x03 \x01(\v2\x17.xray.app.policy.SecondR\n" +
 	"uplinkOnly\x12<\n" +
-	"\rdownlink_only\x18\x04 \x01(\v2\x17.xray.app.policy.SecondR\fdownlinkOnly\x1an\n" +
+	"\rdownlink_only\x18\x04 \x01(\v2\x17.xray.app.policy.SecondR\fdownlinkOnly\x1a\x95\x01\n" +
 	"\x05Stats\x12\x1f\n" +
 	"\vuser_uplink\x18\x01 \x01(\bR\n" +
 	"userUplink\x12#\n" +
 	"\ruser_downlink\x18\x02 \x01(\bR\fuserDownlink\x12\x1f\n" +
 	"\vuser_online\x18\x03 \x01(\bR\n" +
-	"userOnline\x1a(\n" +
+	"userOnline\x12%\n" +
+	"\x0euser_handshake\x18\x04 \x01(\bR\ruserHandshake\x1a(\n" +
 	"\x06Buffer\x12\x1e\n" +
 	"\n" +
 	"connection\x18\x01 \x01(\x05R\n" +
@@ -25,6 +25,65 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type SourcePool_Strategy int32
+
+const (
+	// A random address, other than the recently used ones.
+	SourcePool_Random     SourcePool_Strategy = 0
+	SourcePool_RoundRobin SourcePool_Strategy = 1
+	// The same address for the same user email.
+	SourcePool_StickyUser SourcePool_Strategy = 2
+	// The same address for the same target domain or IP.
+	SourcePool_StickyTarget SourcePool_Strategy = 3
+	// The same address for every connection until the interval passes.
+	SourcePool_Timed SourcePool_Strategy = 4
+)
+
+// Enum value maps for SourcePool_Strategy.
+var (
+	SourcePool_Strategy_name = map[int32]string{
+		0: "Random",
+		1: "RoundRobin",
+		2: "StickyUser",
+		3: "StickyTarget",
+		4: "Timed",
+	}
+	SourcePool_Strategy_value = map[string]int32{
+		"Random":       0,
+		"RoundRobin":   1,
+		"StickyUser":   2,
+		"StickyTarget": 3,
+		"Timed":        4,
+	}
+)
+
+func (x SourcePool_Strategy) Enum() *SourcePool_Strategy {
+	p := new(SourcePool_Strategy)
+	*p = x
+	return p
+}
+
+func (x SourcePool_Strategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SourcePool_Strategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_app_proxyman_config_proto_enumTypes[0].Descriptor()
+}
+
+func (SourcePool_Strategy) Type() protoreflect.EnumType {
+	return &file_app_proxyman_config_proto_enumTypes[0]
+}
+
+func (x SourcePool_Strategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SourcePool_Strategy.Descriptor instead.
+func (SourcePool_Strategy) EnumDescriptor() ([]byte, []int) {
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{7, 0}
+}
+
 type InboundConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -160,6 +219,7 @@ type ReceiverConfig struct {
 	StreamSettings             *internet.StreamConfig `protobuf:"bytes,3,opt,name=stream_settings,json=streamSettings,proto3" json:"stream_settings,omitempty"`
 	ReceiveOriginalDestination bool                   `protobuf:"varint,4,opt,name=receive_original_destination,json=receiveOriginalDestination,proto3" json:"receive_original_destination,omitempty"`
 	SniffingSettings           *SniffingConfig        `protobuf:"bytes,6,opt,name=sniffing_settings,json=sniffingSettings,proto3" json:"sniffing_settings,omitempty"`
+	MuxSettings                *MuxServerConfig       `protobuf:"bytes,7,opt,name=mux_settings,json=muxSettings,proto3" json:"mux_settings,omitempty"`
 	unknownFields              protoimpl.UnknownFields
 	sizeCache                  protoimpl.SizeCache
 }
@@ -229,6 +289,68 @@ func (x *ReceiverConfig) GetSniffingSettings() *SniffingConfig {
 	return nil
 }
 
+func (x *ReceiverConfig) GetMuxSettings() *MuxServerConfig {
+	if x != nil {
+		return x.MuxSettings
+	}
+	return nil
+}
+
+type MuxServerConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the sessions of a lost Mux connection are kept for the client to
+	// resume, if the client asks for it.
+	Resumable bool `protobuf:"varint,1,opt,name=resumable,proto3" json:"resumable,omitempty"`
+	// The longest in seconds the sessions are kept, 10 by default.
+	ResumeTimeout uint32 `protobuf:"varint,2,opt,name=resumeTimeout,proto3" json:"resumeTimeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MuxServerConfig) Reset() {
+	*x = MuxServerConfig{}
+	mi := &file_app_proxyman_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MuxServerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MuxServerConfig) ProtoMessage() {}
+
+func (x *MuxServerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_app_proxyman_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MuxServerConfig.ProtoReflect.Descriptor instead.
+func (*MuxServerConfig) Descriptor() ([]byte, []int) {
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MuxServerConfig) GetResumable() bool {
+	if x != nil {
+		return x.Resumable
+	}
+	return false
+}
+
+func (x *MuxServerConfig) GetResumeTimeout() uint32 {
+	if x != nil {
+		return x.ResumeTimeout
+	}
+	return 0
+}
+
 type InboundHandlerConfig struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Tag              string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
@@ -240,7 +362,7 @@ type InboundHandlerConfig struct {
 
 func (x *InboundHandlerConfig) Reset() {
 	*x = InboundHandlerConfig{}
-	mi := &file_app_proxyman_config_proto_msgTypes[3]
+	mi := &file_app_proxyman_config_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -252,7 +374,7 @@ func (x *InboundHandlerConfig) String() string {
 func (*InboundHandlerConfig) ProtoMessage() {}
 
 func (x *InboundHandlerConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_app_proxyman_config_proto_msgTypes[3]
+	mi := &file_app_proxyman_config_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -265,7 +387,7 @@ func (x *InboundHandlerConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InboundHandlerConfig.ProtoReflect.Descriptor instead.
 func (*InboundHandlerConfig) Descriptor() ([]byte, []int) {
-	return file_app_proxyman_config_proto_rawDescGZIP(), []int{3}
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *InboundHandlerConfig) GetTag() string {
@@ -297,7 +419,7 @@ type OutboundConfig struct {
 
 func (x *OutboundConfig) Reset() {
 	*x = OutboundConfig{}
-	mi := &file_app_proxyman_config_proto_msgTypes[4]
+	mi := &file_app_proxyman_config_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -309,7 +431,7 @@ func (x *OutboundConfig) String() string {
 func (*OutboundConfig) ProtoMessage() {}
 
 func (x *OutboundConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_app_proxyman_config_proto_msgTypes[4]
+	mi := &file_app_proxyman_config_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -322,7 +444,7 @@ func (x *OutboundConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OutboundConfig.ProtoReflect.Descriptor instead.
 func (*OutboundConfig) Descriptor() ([]byte, []int) {
-	return file_app_proxyman_config_proto_rawDescGZIP(), []int{4}
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{5}
 }
 
 type SenderConfig struct {
@@ -334,13 +456,15 @@ type SenderConfig struct {
 	MultiplexSettings *MultiplexingConfig     `protobuf:"bytes,4,opt,name=multiplex_settings,json=multiplexSettings,proto3" json:"multiplex_settings,omitempty"`
 	ViaCidr           string                  `protobuf:"bytes,5,opt,name=via_cidr,json=viaCidr,proto3" json:"via_cidr,omitempty"`
 	TargetStrategy    internet.DomainStrategy `protobuf:"varint,6,opt,name=target_strategy,json=targetStrategy,proto3,enum=xray.transport.internet.DomainStrategy" json:"target_strategy,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	// Send traffic through addresses rotated from the pool, instead of via.
+	ViaPool       *SourcePool `protobuf:"bytes,7,opt,name=via_pool,json=viaPool,proto3" json:"via_pool,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SenderConfig) Reset() {
 	*x = SenderConfig{}
-	mi := &file_app_proxyman_config_proto_msgTypes[5]
+	mi := &file_app_proxyman_config_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -352,7 +476,7 @@ func (x *SenderConfig) String() string {
 func (*SenderConfig) ProtoMessage() {}
 
 func (x *SenderConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_app_proxyman_config_proto_msgTypes[5]
+	mi := &file_app_proxyman_config_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -365,7 +489,7 @@ func (x *SenderConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SenderConfig.ProtoReflect.Descriptor instead.
 func (*SenderConfig) Descriptor() ([]byte, []int) {
-	return file_app_proxyman_config_proto_rawDescGZIP(), []int{5}
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *SenderConfig) GetVia() *net.IPOrDomain {
@@ -410,6 +534,84 @@ func (x *SenderConfig) GetTargetStrategy() internet.DomainStrategy {
 	return internet.DomainStrategy(0)
 }
 
+func (x *SenderConfig) GetViaPool() *SourcePool {
+	if x != nil {
+		return x.ViaPool
+	}
+	return nil
+}
+
+type SourcePool struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// IPs and CIDRs, where an address is picked within the CIDR.
+	Addresses []string            `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Strategy  SourcePool_Strategy `protobuf:"varint,2,opt,name=strategy,proto3,enum=xray.app.proxyman.SourcePool_Strategy" json:"strategy,omitempty"`
+	// Seconds between rotations of Timed, 60 by default.
+	Interval uint32 `protobuf:"varint,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	// How many recently used addresses Random avoids.
+	ExcludeRecent uint32 `protobuf:"varint,4,opt,name=exclude_recent,json=excludeRecent,proto3" json:"exclude_recent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourcePool) Reset() {
+	*x = SourcePool{}
+	mi := &file_app_proxyman_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourcePool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourcePool) ProtoMessage() {}
+
+func (x *SourcePool) ProtoReflect() protoreflect.Message {
+	mi := &file_app_proxyman_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourcePool.ProtoReflect.Descriptor instead.
+func (*SourcePool) Descriptor() ([]byte, []int) {
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SourcePool) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *SourcePool) GetStrategy() SourcePool_Strategy {
+	if x != nil {
+		return x.Strategy
+	}
+	return SourcePool_Random
+}
+
+func (x *SourcePool) GetInterval() uint32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *SourcePool) GetExcludeRecent() uint32 {
+	if x != nil {
+		return x.ExcludeRecent
+	}
+	return 0
+}
+
 type MultiplexingConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Whether or not Mux is enabled.
@@ -420,13 +622,18 @@ type MultiplexingConfig struct {
 	XudpConcurrency int32 `protobuf:"varint,3,opt,name=xudpConcurrency,proto3" json:"xudpConcurrency,omitempty"`
 	// "reject" (default), "allow" or "skip".
 	XudpProxyUDP443 string `protobuf:"bytes,4,opt,name=xudpProxyUDP443,proto3" json:"xudpProxyUDP443,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Whether the sessions are resumed in a new Mux connection when the
+	// connection is lost, if the server allows it.
+	Resumable bool `protobuf:"varint,5,opt,name=resumable,proto3" json:"resumable,omitempty"`
+	// How long in seconds to try resuming, 30 by default.
+	ResumeTimeout uint32 `protobuf:"varint,6,opt,name=resumeTimeout,proto3" json:"resumeTimeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *MultiplexingConfig) Reset() {
 	*x = MultiplexingConfig{}
-	mi := &file_app_proxyman_config_proto_msgTypes[6]
+	mi := &file_app_proxyman_config_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -438,7 +645,7 @@ func (x *MultiplexingConfig) String() string {
 func (*MultiplexingConfig) ProtoMessage() {}
 
 func (x *MultiplexingConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_app_proxyman_config_proto_msgTypes[6]
+	mi := &file_app_proxyman_config_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -451,7 +658,7 @@ func (x *MultiplexingConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexingConfig.ProtoReflect.Descriptor instead.
 func (*MultiplexingConfig) Descriptor() ([]byte, []int) {
-	return file_app_proxyman_config_proto_rawDescGZIP(), []int{6}
+	return file_app_proxyman_config_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *MultiplexingConfig) GetEnabled() bool {
@@ -482,6 +689,20 @@ func (x *MultiplexingConfig) GetXudpProxyUDP443() string {
 	return ""
 }
 
+func (x *MultiplexingConfig) GetResumable() bool {
+	if x != nil {
+		return x.Resumable
+	}
+	return false
+}
+
+func (x *MultiplexingConfig) GetResumeTimeout() uint32 {
+	if x != nil {
+		return x.ResumeTimeout
+	}
+	return 0
+}
+
 var File_app_proxyman_config_proto protoreflect.FileDescriptor
 
 const file_app_proxyman_config_proto_rawDesc = "" +
@@ -495,30 +716,52 @@ const file_app_proxyman_config_proto_rawDesc = "" +
 	"\fips_excluded\x18\x06 \x03(\v2\x1b.xray.common.geodata.IPRuleR\vipsExcluded\x12#\n" +
 	"\rmetadata_only\x18\x04 \x01(\bR\fmetadataOnly\x12\x1d\n" +
 	"\n" +
-	"route_only\x18\x05 \x01(\bR\trouteOnly\"\xe5\x02\n" +
+	"route_only\x18\x05 \x01(\bR\trouteOnly\"\xac\x03\n" +
 	"\x0eReceiverConfig\x126\n" +
 	"\tport_list\x18\x01 \x01(\v2\x19.xray.common.net.PortListR\bportList\x123\n" +
 	"\x06listen\x18\x02 \x01(\v2\x1b.xray.common.net.IPOrDomainR\x06listen\x12N\n" +
 	"\x0fstream_settings\x18\x03 \x01(\v2%.xray.transport.internet.StreamConfigR\x0estreamSettings\x12@\n" +
 	"\x1creceive_original_destination\x18\x04 \x01(\bR\x1areceiveOriginalDestination\x12N\n" +
-	"\x11sniffing_settings\x18\x06 \x01(\v2!.xray.app.proxyman.SniffingConfigR\x10sniffingSettingsJ\x04\b\x05\x10\x06\"\xc0\x01\n" +
+	"\x11sniffing_settings\x18\x06 \x01(\v2!.xray.app.proxyman.SniffingConfigR\x10sniffingSettings\x12E\n" +
+	"\fmux_settings\x18\a \x01(\v2\".xray.app.proxyman.MuxServerConfigR\vmuxSettingsJ\x04\b\x05\x10\x06\"U\n" +
+	"\x0fMuxServerConfig\x12\x1c\n" +
+	"\tresumable\x18\x01 \x01(\bR\tresumable\x12$\n" +
+	"\rresumeTimeout\x18\x02 \x01(\rR\rresumeTimeout\"\xc0\x01\n" +
 	"\x14InboundHandlerConfig\x12\x10\n" +
 	"\x03tag\x18\x01 \x01(\tR\x03tag\x12M\n" +
 	"\x11receiver_settings\x18\x02 \x01(\v2 .xray.common.serial.TypedMessageR\x10receiverSettings\x12G\n" +
 	"\x0eproxy_settings\x18\x03 \x01(\v2 .xray.common.serial.TypedMessageR\rproxySettings\"\x10\n" +
-	"\x0eOutboundConfig\"\x9d\x03\n" +
+	"\x0eOutboundConfig\"\xd7\x03\n" +
 	"\fSenderConfig\x12-\n" +
 	"\x03via\x18\x01 \x01(\v2\x1b.xray.common.net.IPOrDomainR\x03via\x12N\n" +
 	"\x0fstream_settings\x18\x02 \x01(\v2%.xray.transport.internet.StreamConfigR\x0estreamSettings\x12K\n" +
 	"\x0eproxy_settings\x18\x03 \x01(\v2$.xray.transport.internet.ProxyConfigR\rproxySettings\x12T\n" +
 	"\x12multiplex_settings\x18\x04 \x01(\v2%.xray.app.proxyman.MultiplexingConfigR\x11multiplexSettings\x12\x19\n" +
 	"\bvia_cidr\x18\x05 \x01(\tR\aviaCidr\x12P\n" +
-	"\x0ftarget_strategy\x18\x06 \x01(\x0e2'.xray.transport.internet.DomainStrategyR\x0etargetStrategy\"\xa4\x01\n" +
+	"\x0ftarget_strategy\x18\x06 \x01(\x0e2'.xray.transport.internet.DomainStrategyR\x0etargetStrategy\x128\n" +
+	"\bvia_pool\x18\a \x01(\v2\x1d.xray.app.proxyman.SourcePoolR\aviaPool\"\x86\x02\n" +
+	"\n" +
+	"SourcePool\x12\x1c\n" +
+	"\taddresses\x18\x01 \x03(\tR\taddresses\x12B\n" +
+	"\bstrategy\x18\x02 \x01(\x0e2&.xray.app.proxyman.SourcePool.StrategyR\bstrategy\x12\x1a\n" +
+	"\binterval\x18\x03 \x01(\rR\binterval\x12%\n" +
+	"\x0eexclude_recent\x18\x04 \x01(\rR\rexcludeRecent\"S\n" +
+	"\bStrategy\x12\n" +
+	"\n" +
+	"\x06Random\x10\x00\x12\x0e\n" +
+	"\n" +
+	"RoundRobin\x10\x01\x12\x0e\n" +
+	"\n" +
+	"StickyUser\x10\x02\x12\x10\n" +
+	"\fStickyTarget\x10\x03\x12\t\n" +
+	"\x05Timed\x10\x04\"\xe8\x01\n" +
 	"\x12MultiplexingConfig\x12\x18\n" +
 	"\aenabled\x18\x01 \x01(\bR\aenabled\x12 \n" +
 	"\vconcurrency\x18\x02 \x01(\x05R\vconcurrency\x12(\n" +
 	"\x0fxudpConcurrency\x18\x03 \x01(\x05R\x0fxudpConcurrency\x12(\n" +
-	"\x0fxudpProxyUDP443\x18\x04 \x01(\tR\x0fxudpProxyUDP443BU\n" +
+	"\x0fxudpProxyUDP443\x18\x04 \x01(\tR\x0fxudpProxyUDP443\x12\x1c\n" +
+	"\tresumable\x18\x05 \x01(\bR\tresumable\x12$\n" +
+	"\rresumeTimeout\x18\x06 \x01(\rR\rresumeTimeoutBU\n" +
 	"\x15com.xray.app.proxymanP\x01Z&github.com/xtls/xray-core/app/proxyman\xaa\x02\x11Xray.App.Proxymanb\x06proto3"
 
 var (
@@ -533,43 +776,50 @@ func file_app_proxyman_config_proto_rawDescGZIP() []byte {
 	return file_app_proxyman_config_proto_rawDescData
 }
 
-var file_app_proxyman_config_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_app_proxyman_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_app_proxyman_config_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_app_proxyman_config_proto_goTypes = []any{
-	(*InboundConfig)(nil),         // 0: xray.app.proxyman.InboundConfig
-	(*SniffingConfig)(nil),        // 1: xray.app.proxyman.SniffingConfig
-	(*ReceiverConfig)(nil),        // 2: xray.app.proxyman.ReceiverConfig
-	(*InboundHandlerConfig)(nil),  // 3: xray.app.proxyman.InboundHandlerConfig
-	(*OutboundConfig)(nil),        // 4: xray.app.proxyman.OutboundConfig
-	(*SenderConfig)(nil),          // 5: xray.app.proxyman.SenderConfig
-	(*MultiplexingConfig)(nil),    // 6: xray.app.proxyman.MultiplexingConfig
-	(*geodata.DomainRule)(nil),    // 7: xray.common.geodata.DomainRule
-	(*geodata.IPRule)(nil),        // 8: xray.common.geodata.IPRule
-	(*net.PortList)(nil),          // 9: xray.common.net.PortList
-	(*net.IPOrDomain)(nil),        // 10: xray.common.net.IPOrDomain
-	(*internet.StreamConfig)(nil), // 11: xray.transport.internet.StreamConfig
-	(*serial.TypedMessage)(nil),   // 12: xray.common.serial.TypedMessage
-	(*internet.ProxyConfig)(nil),  // 13: xray.transport.internet.ProxyConfig
-	(internet.DomainStrategy)(0),  // 14: xray.transport.internet.DomainStrategy
+	(SourcePool_Strategy)(0),      // 0: xray.app.proxyman.SourcePool.Strategy
+	(*InboundConfig)(nil),         // 1: xray.app.proxyman.InboundConfig
+	(*SniffingConfig)(nil),        // 2: xray.app.proxyman.SniffingConfig
+	(*ReceiverConfig)(nil),        // 3: xray.app.proxyman.ReceiverConfig
+	(*MuxServerConfig)(nil),       // 4: xray.app.proxyman.MuxServerConfig
+	(*InboundHandlerConfig)(nil),  // 5: xray.app.proxyman.InboundHandlerConfig
+	(*OutboundConfig)(nil),        // 6: xray.app.proxyman.OutboundConfig
+	(*SenderConfig)(nil),          // 7: xray.app.proxyman.SenderConfig
+	(*SourcePool)(nil),            // 8: xray.app.proxyman.SourcePool
+	(*MultiplexingConfig)(nil),    // 9: xray.app.proxyman.MultiplexingConfig
+	(*geodata.DomainRule)(nil),    // 10: xray.common.geodata.DomainRule
+	(*geodata.IPRule)(nil),        // 11: xray.common.geodata.IPRule
+	(*net.PortList)(nil),          // 12: xray.common.net.PortList
+	(*net.IPOrDomain)(nil),        // 13: xray.common.net.IPOrDomain
+	(*internet.StreamConfig)(nil), // 14: xray.transport.internet.StreamConfig
+	(*serial.TypedMessage)(nil),   // 15: xray.common.serial.TypedMessage
+	(*internet.ProxyConfig)(nil),  // 16: xray.transport.internet.ProxyConfig
+	(internet.DomainStrategy)(0),  // 17: xray.transport.internet.DomainStrategy
 }
 var file_app_proxyman_config_proto_depIdxs = []int32{
-	7,  // 0: xray.app.proxyman.SniffingConfig.domains_excluded:type_name -> xray.common.geodata.DomainRule
-	8,  // 1: xray.app.proxyman.SniffingConfig.ips_excluded:type_name -> xray.common.geodata.IPRule
-	9,  // 2: xray.app.proxyman.ReceiverConfig.port_list:type_name -> xray.common.net.PortList
-	10, // 3: xray.app.proxyman.ReceiverConfig.listen:type_name -> xray.common.net.IPOrDomain
-	11, // 4: xray.app.proxyman.ReceiverConfig.stream_settings:type_name -> xray.transport.internet.StreamConfig
-	1,  // 5: xray.app.proxyman.ReceiverConfig.sniffing_settings:type_name -> xray.app.proxyman.SniffingConfig
-	12, // 6: xray.app.proxyman.InboundHandlerConfig.receiver_settings:type_name -> xray.common.serial.TypedMessage
-	12, // 7: xray.app.proxyman.InboundHandlerConfig.proxy_settings:type_name -> xray.common.serial.TypedMessage
-	10, // 8: xray.app.proxyman.SenderConfig.via:type_name -> xray.common.net.IPOrDomain
-	11, // 9: xray.app.proxyman.SenderConfig.stream_settings:type_name -> xray.transport.internet.StreamConfig
-	13, // 10: xray.app.proxyman.SenderConfig.proxy_settings:type_name -> xray.transport.internet.ProxyConfig
-	6,  // 11: xray.app.proxyman.SenderConfig.multiplex_settings:type_name -> xray.app.proxyman.MultiplexingConfig
-	14, // 12: xray.app.proxyman.SenderConfig.target_strategy:type_name -> xray.transport.internet.DomainStrategy
-	13, // [13:13] is the sub-list for method output_type
-	13, // [13:13] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	10, // 0: xray.app.proxyman.SniffingConfig.domains_excluded:type_name -> xray.common.geodata.DomainRule
+	11, // 1: xray.app.proxyman.SniffingConfig.ips_excluded:type_name -> xray.common.geodata.IPRule
+	12, // 2: xray.app.proxyman.ReceiverConfig.port_list:type_name -> xray.common.net.PortList
+	13, // 3: xray.app.proxyman.ReceiverConfig.listen:type_name -> xray.common.net.IPOrDomain
+	14, // 4: xray.app.proxyman.ReceiverConfig.stream_settings:type_name -> xray.transport.internet.StreamConfig
+	2,  // 5: xray.app.proxyman.ReceiverConfig.sniffing_settings:type_name -> xray.app.proxyman.SniffingConfig
+	4,  // 6: xray.app.proxyman.ReceiverConfig.mux_settings:type_name -> xray.app.proxyman.MuxServerConfig
+	15, // 7: xray.app.proxyman.InboundHandlerConfig.receiver_settings:type_name -> xray.common.serial.TypedMessage
+	15, // 8: xray.app.proxyman.InboundHandlerConfig.proxy_settings:type_name -> xray.common.serial.TypedMessage
+	13, // 9: xray.app.proxyman.SenderConfig.via:type_name -> xray.common.net.IPOrDomain
+	14, // 10: xray.app.proxyman.SenderConfig.stream_settings:type_name -> xray.transport.internet.StreamConfig
+	16, // 11: xray.app.proxyman.SenderConfig.proxy_settings:type_name -> xray.transport.internet.ProxyConfig
+	9,  // 12: xray.app.proxyman.SenderConfig.multiplex_settings:type_name -> xray.app.proxyman.MultiplexingConfig
+	17, // 13: xray.app.proxyman.SenderConfig.target_strategy:type_name -> xray.transport.internet.DomainStrategy
+	8,  // 14: xray.app.proxyman.SenderConfig.via_pool:type_name -> xray.app.proxyman.SourcePool
+	0,  // 15: xray.app.proxyman.SourcePool.strategy:type_name -> xray.app.proxyman.SourcePool.Strategy
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_app_proxyman_config_proto_init() }
@@ -582,13 +832,14 @@ func file_app_proxyman_config_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_proxyman_config_proto_rawDesc), len(file_app_proxyman_config_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_app_proxyman_config_proto_goTypes,
 		DependencyIndexes: file_app_proxyman_config_proto_depIdxs,
+		EnumInfos:         file_app_proxyman_config_proto_enumTypes,
 		MessageInfos:      file_app_proxyman_config_proto_msgTypes,
 	}.Build()
 	File_app_proxyman_config_proto = out.File
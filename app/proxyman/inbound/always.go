@@ -2,6 +2,7 @@ package inbound
 
 import (
 	"context"
+	"time"
 
 	"github.com/xtls/xray-core/app/proxyman"
 	"github.com/xtls/xray-core/common"
@@ -89,6 +90,12 @@ func NewAlwaysOnInboundHandler(ctx context.Context, tag string, receiverConfig *
 		mux:            mux.NewServer(ctx),
 		tag:            tag,
 	}
+	if ms := receiverConfig.MuxSettings; ms != nil && ms.Resumable {
+		h.mux.ResumeTimeout = 10 * time.Second
+		if ms.ResumeTimeout > 0 {
+			h.mux.ResumeTimeout = time.Duration(ms.ResumeTimeout) * time.Second
+		}
+	}
 
 	uplinkCounter, downlinkCounter := getStatCounter(core.MustFromContext(ctx), tag)
 
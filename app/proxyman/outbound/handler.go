@@ -6,6 +6,7 @@ import (
 	goerrors "errors"
 	"io"
 	"math/big"
+	"time"
 
 	"github.com/xtls/xray-core/common/dice"
 
@@ -67,6 +68,7 @@ type Handler struct {
 	mux             *mux.ClientManager
 	xudp            *mux.ClientManager
 	udp443          string
+	sourcePool      *sourcePool
 	uplinkCounter   stats.Counter
 	downlinkCounter stats.Counter
 }
@@ -95,6 +97,13 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 				return nil, errors.New("failed to parse stream settings").Base(err).AtWarning()
 			}
 			h.streamSettings = mss
+			if s.ViaPool != nil {
+				pool, err := newSourcePool(s.ViaPool)
+				if err != nil {
+					return nil, errors.New("failed to build source pool").Base(err).AtWarning()
+				}
+				h.sourcePool = pool
+			}
 		default:
 			return nil, errors.New("settings is not SenderConfig")
 		}
@@ -128,6 +137,13 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 			if config.Concurrency == 0 {
 				config.Concurrency = 8 // same as before
 			}
+			var resumeTimeout time.Duration
+			if config.Resumable {
+				resumeTimeout = 30 * time.Second
+				if config.ResumeTimeout > 0 {
+					resumeTimeout = time.Duration(config.ResumeTimeout) * time.Second
+				}
+			}
 			if config.Concurrency > 0 {
 				h.mux = &mux.ClientManager{
 					Enabled: true,
@@ -138,6 +154,7 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 							Strategy: mux.ClientStrategy{
 								MaxConcurrency: uint32(config.Concurrency),
 								MaxConnection:  128,
+								ResumeTimeout:  resumeTimeout,
 							},
 						},
 					},
@@ -159,6 +176,7 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 							Strategy: mux.ClientStrategy{
 								MaxConcurrency: uint32(config.XudpConcurrency),
 								MaxConnection:  128,
+								ResumeTimeout:  resumeTimeout,
 							},
 						},
 					},
@@ -182,12 +200,18 @@ func (h *Handler) Dispatch(ctx context.Context, link *transport.Link) {
 	outbounds := session.OutboundsFromContext(ctx)
 	ob := outbounds[len(outbounds)-1]
 	content := session.ContentFromContext(ctx)
+	var localAddr net.Address
+	if h.sourcePool != nil && h.muxClient(ctx, ob) == nil {
+		// The source is picked ahead, so that the target is resolved for its family.
+		h.SetOutboundGateway(ctx, ob)
+		localAddr = ob.Gateway
+	}
 	if h.senderSettings != nil && h.senderSettings.TargetStrategy.HasStrategy() && ob.Target.Address.Family().IsDomain() && (content == nil || !content.SkipDNSResolve) {
 		strategy := h.senderSettings.TargetStrategy
 		if ob.Target.Network == net.Network_UDP && ob.OriginalTarget.Address != nil {
 			strategy = strategy.GetDynamicStrategy(ob.OriginalTarget.Address.Family())
 		}
-		ips, err := internet.LookupForIP(ob.Target.Address.Domain(), strategy, nil)
+		ips, err := internet.LookupForIP(ob.Target.Address.Domain(), strategy, localAddr)
 		if err != nil {
 			errors.LogInfoInner(ctx, err, "failed to resolve ip for target ", ob.Target.Address.Domain())
 			if h.senderSettings.TargetStrategy.ForceIP() {
@@ -208,38 +232,22 @@ func (h *Handler) Dispatch(ctx context.Context, link *transport.Link) {
 		link.Reader = &buf.EndpointOverrideReader{Reader: link.Reader, Dest: ob.Target.Address, OriginalDest: ob.OriginalTarget.Address}
 		link.Writer = &buf.EndpointOverrideWriter{Writer: link.Writer, Dest: ob.Target.Address, OriginalDest: ob.OriginalTarget.Address}
 	}
-	if h.mux != nil {
-		test := func(err error) {
-			if err != nil {
-				err := errors.New("failed to process mux outbound traffic").Base(err)
-				session.SubmitOutboundErrorToOriginator(ctx, err)
-				errors.LogInfo(ctx, err.Error())
-				common.Interrupt(link.Writer)
-				common.Interrupt(link.Reader)
-			}
-		}
-		if ob.Target.Network == net.Network_UDP && ob.Target.Port == 443 {
-			switch h.udp443 {
-			case "reject":
-				test(errors.New("XUDP rejected UDP/443 traffic").AtInfo())
-				return
-			case "skip":
-				goto out
-			}
-		}
-		if h.xudp != nil && ob.Target.Network == net.Network_UDP {
-			if !h.xudp.Enabled {
-				goto out
-			}
-			test(h.xudp.Dispatch(ctx, link))
-			return
+	if client := h.muxClient(ctx, ob); client != nil || h.rejectsMux(ctx, ob) {
+		var err error
+		if client != nil {
+			err = client.Dispatch(ctx, link)
+		} else {
+			err = errors.New("XUDP rejected UDP/443 traffic").AtInfo()
 		}
-		if h.mux.Enabled {
-			test(h.mux.Dispatch(ctx, link))
-			return
+		if err != nil {
+			err := errors.New("failed to process mux outbound traffic").Base(err)
+			session.SubmitOutboundErrorToOriginator(ctx, err)
+			errors.LogInfo(ctx, err.Error())
+			common.Interrupt(link.Writer)
+			common.Interrupt(link.Reader)
 		}
+		return
 	}
-out:
 	err := h.proxy.Process(ctx, link, h)
 	var errC error
 	if err != nil {
@@ -264,6 +272,45 @@ out:
 	common.Interrupt(link.Reader)
 }
 
+// muxClient returns the mux client that the flow is dispatched to, or nil if
+// the flow dials a connection of its own.
+func (h *Handler) muxClient(ctx context.Context, ob *session.Outbound) *mux.ClientManager {
+	// The connections of mux are shared by flows, while a chain from routing
+	// is particular to a flow.
+	if h.mux == nil || len(session.ChainFromContext(ctx)) > 0 {
+		return nil
+	}
+	if ob.Target.Network == net.Network_UDP && ob.Target.Port == 443 && (h.udp443 == "reject" || h.udp443 == "skip") {
+		return nil
+	}
+	if h.xudp != nil && ob.Target.Network == net.Network_UDP {
+		if !h.xudp.Enabled {
+			return nil
+		}
+		return h.xudp
+	}
+	if h.mux.Enabled {
+		return h.mux
+	}
+	return nil
+}
+
+// rejectsMux returns whether the flow is rejected by the mux settings.
+func (h *Handler) rejectsMux(ctx context.Context, ob *session.Outbound) bool {
+	return h.mux != nil && len(session.ChainFromContext(ctx)) == 0 && ob.Target.Network == net.Network_UDP && ob.Target.Port == 443 && h.udp443 == "reject"
+}
+
+// PickGateway implements outbound.GatewayPicker.
+func (h *Handler) PickGateway(ctx context.Context) net.Address {
+	outbounds := session.OutboundsFromContext(ctx)
+	ob := outbounds[len(outbounds)-1]
+	if h.senderSettings == nil || h.muxClient(ctx, ob) != nil || h.rejectsMux(ctx, ob) {
+		return nil
+	}
+	h.SetOutboundGateway(ctx, ob)
+	return ob.Gateway
+}
+
 func (h *Handler) DestIpAddress() net.IP {
 	return internet.DestIpAddress()
 }
@@ -272,7 +319,8 @@ func (h *Handler) DestIpAddress() net.IP {
 func (h *Handler) Dial(ctx context.Context, dest net.Destination) (stat.Connection, error) {
 	if h.senderSettings != nil {
 
-		if h.senderSettings.ProxySettings.HasTag() {
+		// A chain from routing replaces the proxy of the outbound.
+		if h.senderSettings.ProxySettings.HasTag() && len(session.ChainFromContext(ctx)) == 0 {
 
 			tag := h.senderSettings.ProxySettings.Tag
 			handler := h.outboundManager.GetHandler(tag)
@@ -302,7 +350,7 @@ func (h *Handler) Dial(ctx context.Context, dest net.Destination) (stat.Connecti
 			return nil, errors.New("failed to get outbound handler with tag: " + tag)
 		}
 
-		if h.senderSettings.Via != nil {
+		if h.senderSettings.Via != nil || h.sourcePool != nil {
 			outbounds := session.OutboundsFromContext(ctx)
 			ob := outbounds[len(outbounds)-1]
 			h.SetOutboundGateway(ctx, ob)
@@ -322,6 +370,10 @@ func (h *Handler) Dial(ctx context.Context, dest net.Destination) (stat.Connecti
 }
 
 func (h *Handler) SetOutboundGateway(ctx context.Context, ob *session.Outbound) {
+	if ob.Gateway == nil && h.sourcePool != nil && !h.senderSettings.ProxySettings.HasTag() && (h.streamSettings.SocketSettings == nil || len(h.streamSettings.SocketSettings.DialerProxy) == 0) {
+		ob.Gateway = h.sourcePool.pick(ctx, ob)
+		errors.LogDebug(ctx, "use ip from source pool as sendthrough: ", ob.Gateway)
+	}
 	if ob.Gateway == nil && h.senderSettings != nil && h.senderSettings.Via != nil && !h.senderSettings.ProxySettings.HasTag() && (h.streamSettings.SocketSettings == nil || len(h.streamSettings.SocketSettings.DialerProxy) == 0) {
 		var domain string
 		addr := h.senderSettings.Via.AsAddress()
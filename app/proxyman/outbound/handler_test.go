@@ -13,12 +13,14 @@ import (
 	. "github.com/xtls/xray-core/app/proxyman/outbound"
 	"github.com/xtls/xray-core/app/stats"
 	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/common/session"
 	core "github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/features/outbound"
 	"github.com/xtls/xray-core/proxy/freedom"
 	"github.com/xtls/xray-core/transport/internet/stat"
+	_ "github.com/xtls/xray-core/transport/internet/tcp"
 )
 
 func TestInterfaces(t *testing.T) {
@@ -173,3 +175,87 @@ func TestTagsCache(t *testing.T) {
 	stop_get = true
 	wg_get.Wait()
 }
+
+func TestSourcePool(t *testing.T) {
+	v, _ := core.New(&core.Config{})
+	v.AddFeature(outbound.Manager(new(Manager)))
+	ctx := context.WithValue(context.Background(), xrayKey, v)
+
+	pick := func(h outbound.Handler, email string, target net.Address) string {
+		ctx := session.ContextWithInbound(ctx, &session.Inbound{User: &protocol.MemoryUser{Email: email}})
+		ctx = session.ContextWithOutbounds(ctx, []*session.Outbound{{Target: net.TCPDestination(target, 443)}})
+		return h.(outbound.GatewayPicker).PickGateway(ctx).String()
+	}
+	newHandler := func(pool *proxyman.SourcePool) outbound.Handler {
+		h, err := NewHandler(ctx, &core.OutboundHandlerConfig{
+			SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{ViaPool: pool}),
+			ProxySettings:  serial.ToTypedMessage(&freedom.Config{}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	target := net.ParseAddress("1.1.1.1")
+
+	h := newHandler(&proxyman.SourcePool{
+		Addresses: []string{"192.0.2.1", "198.51.100.0/31", "2001:db8::/64"},
+		Strategy:  proxyman.SourcePool_RoundRobin,
+	})
+	var got []string
+	for range 5 {
+		got = append(got, pick(h, "", target))
+	}
+	if fmt.Sprint(got) != "[192.0.2.1 198.51.100.0 192.0.2.1 198.51.100.1 192.0.2.1]" {
+		t.Error("unexpected round robin ", got)
+	}
+	if addr := pick(h, "", net.ParseAddress("2606:4700::1111")); addr != "[2001:db8::5]" {
+		t.Error("unexpected address for IPv6 target ", addr)
+	}
+
+	h = newHandler(&proxyman.SourcePool{
+		Addresses: []string{"2001:db8::/64"},
+		Strategy:  proxyman.SourcePool_StickyUser,
+	})
+	if pick(h, "alice", target) != pick(h, "alice", target) || pick(h, "alice", target) == pick(h, "bob", target) {
+		t.Error("address is not sticky to user")
+	}
+
+	h = newHandler(&proxyman.SourcePool{
+		Addresses: []string{"2001:db8::/64"},
+		Strategy:  proxyman.SourcePool_StickyTarget,
+	})
+	if pick(h, "alice", net.DomainAddress("example.com")) != pick(h, "bob", net.DomainAddress("example.com")) {
+		t.Error("address is not sticky to target")
+	}
+
+	h = newHandler(&proxyman.SourcePool{
+		Addresses: []string{"192.0.2.0/30"},
+		Strategy:  proxyman.SourcePool_Timed,
+		Interval:  3600,
+	})
+	if pick(h, "alice", target) != pick(h, "bob", target) {
+		t.Error("address is rotated within the interval")
+	}
+
+	h = newHandler(&proxyman.SourcePool{
+		Addresses:     []string{"192.0.2.0/30"},
+		ExcludeRecent: 3,
+	})
+	for range 10 {
+		recent := map[string]bool{}
+		for range 3 {
+			recent[pick(h, "", target)] = true
+		}
+		if len(recent) != 3 {
+			t.Fatal("recently used address is picked ", recent)
+		}
+	}
+
+	if _, err := NewHandler(ctx, &core.OutboundHandlerConfig{
+		SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{ViaPool: &proxyman.SourcePool{Addresses: []string{"invalid"}}}),
+		ProxySettings:  serial.ToTypedMessage(&freedom.Config{}),
+	}); err == nil {
+		t.Error("expected error of invalid address")
+	}
+}
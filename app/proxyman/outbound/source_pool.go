@@ -0,0 +1,168 @@
+package outbound
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/xtls/xray-core/app/proxyman"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+)
+
+// poolEntry is an IP, or a CIDR of which an address is picked.
+type poolEntry struct {
+	ip       net.IP
+	hostBits int
+}
+
+// address returns the address at offset n within the entry. The offset
+// wraps around within the entry, and only the lower 64 bits of the host part
+// are picked for larger networks.
+func (e *poolEntry) address(n uint64) net.Address {
+	if e.hostBits < 64 {
+		n &= 1<<uint(e.hostBits) - 1
+	}
+	ip := make(net.IP, len(e.ip))
+	copy(ip, e.ip)
+	for i := len(ip) - 1; i >= 0 && n > 0; i-- {
+		sum := uint64(ip[i]) + n&0xff
+		ip[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+	return net.IPAddress(ip)
+}
+
+// sourcePool picks the local addresses of outbound connections by the
+// strategy of proxyman.SourcePool.
+type sourcePool struct {
+	strategy proxyman.SourcePool_Strategy
+	interval time.Duration
+	entries  []*poolEntry
+	// Entries by the family of addresses, for targets of IPs.
+	ipv4 []*poolEntry
+	ipv6 []*poolEntry
+
+	sync.Mutex
+	next   uint64
+	recent []net.Address
+	size   int
+}
+
+func newSourcePool(config *proxyman.SourcePool) (*sourcePool, error) {
+	p := &sourcePool{
+		strategy: config.Strategy,
+		interval: time.Duration(config.Interval) * time.Second,
+		size:     int(config.ExcludeRecent),
+	}
+	if p.interval == 0 {
+		p.interval = time.Minute
+	}
+	for _, s := range config.Addresses {
+		var e *poolEntry
+		if ip := net.ParseIP(s); ip != nil {
+			e = &poolEntry{ip: ip}
+		} else if _, ipnet, err := net.ParseCIDR(s); err == nil {
+			ones, bits := ipnet.Mask.Size()
+			e = &poolEntry{ip: ipnet.IP, hostBits: bits - ones}
+		} else {
+			return nil, errors.New("invalid address in source pool: ", s)
+		}
+		if ip4 := e.ip.To4(); ip4 != nil {
+			e.ip = ip4
+			p.ipv4 = append(p.ipv4, e)
+		} else {
+			p.ipv6 = append(p.ipv6, e)
+		}
+		p.entries = append(p.entries, e)
+	}
+	if len(p.entries) == 0 {
+		return nil, errors.New("empty source pool")
+	}
+	return p, nil
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func randomUint64() uint64 {
+	var b [8]byte
+	rand.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// pick returns the local address of a connection to the target of ob.
+func (p *sourcePool) pick(ctx context.Context, ob *session.Outbound) net.Address {
+	entries := p.entries
+	// An address of the other family can't reach a target of IP.
+	if target := ob.Target.Address; target != nil {
+		switch {
+		case target.Family().IsIPv4() && len(p.ipv4) > 0:
+			entries = p.ipv4
+		case target.Family().IsIPv6() && len(p.ipv6) > 0:
+			entries = p.ipv6
+		}
+	}
+	at := func(n uint64) net.Address {
+		count := uint64(len(entries))
+		return entries[n%count].address(n / count)
+	}
+
+	switch p.strategy {
+	case proxyman.SourcePool_RoundRobin:
+		p.Lock()
+		n := p.next
+		p.next++
+		p.Unlock()
+		return at(n)
+	case proxyman.SourcePool_StickyUser:
+		if inbound := session.InboundFromContext(ctx); inbound != nil && inbound.User != nil && inbound.User.Email != "" {
+			return at(hashString(inbound.User.Email))
+		}
+	case proxyman.SourcePool_StickyTarget:
+		if ob.Target.Address != nil {
+			return at(hashString(ob.Target.Address.String()))
+		}
+	case proxyman.SourcePool_Timed:
+		return at(uint64(time.Now().UnixNano() / int64(p.interval)))
+	}
+	return p.random(at)
+}
+
+// random picks a random address, other than the recently used ones if
+// possible.
+func (p *sourcePool) random(at func(uint64) net.Address) net.Address {
+	p.Lock()
+	defer p.Unlock()
+
+	var addr net.Address
+	for range 64 {
+		addr = at(randomUint64())
+		if !p.used(addr) {
+			break
+		}
+	}
+	if p.size > 0 {
+		if len(p.recent) >= p.size {
+			p.recent = p.recent[1:]
+		}
+		p.recent = append(p.recent, addr)
+	}
+	return addr
+}
+
+func (p *sourcePool) used(addr net.Address) bool {
+	for _, a := range p.recent {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	sync "sync"
+	"sync/atomic"
 
 	"github.com/xtls/xray-core/app/observatory"
 	"github.com/xtls/xray-core/common"
@@ -89,6 +90,27 @@ type Balancer struct {
 	fallbackTag string
 
 	override override
+
+	// onChange, if set, is called when the balancer picks another outbound.
+	onChange func(target, detail string)
+	picked   atomic.Pointer[string]
+}
+
+// changed reports the picked tag if it isn't the last one. Strategies that
+// rotate between the outbounds aren't reported.
+func (b *Balancer) changed(tag string) {
+	if b.onChange == nil {
+		return
+	}
+	switch b.strategy.(type) {
+	case *RandomStrategy, *RoundRobinStrategy:
+		if b.override.Get() == "" {
+			return
+		}
+	}
+	if last := b.picked.Swap(&tag); last == nil || *last != tag {
+		b.onChange(tag, "")
+	}
 }
 
 // PickOutbound picks the tag of a outbound
@@ -97,6 +119,7 @@ func (b *Balancer) PickOutbound() (string, error) {
 	if err != nil {
 		if b.fallbackTag != "" {
 			errors.LogInfo(context.Background(), "fallback to [", b.fallbackTag, "], due to error: ", err)
+			b.changed(b.fallbackTag)
 			return b.fallbackTag, nil
 		}
 		return "", err
@@ -110,11 +133,13 @@ func (b *Balancer) PickOutbound() (string, error) {
 	if tag == "" {
 		if b.fallbackTag != "" {
 			errors.LogInfo(context.Background(), "fallback to [", b.fallbackTag, "], due to empty tag returned")
+			b.changed(b.fallbackTag)
 			return b.fallbackTag, nil
 		}
 		// will use default handler
 		return "", errors.New("balancing strategy returns empty tag")
 	}
+	b.changed(tag)
 	return tag, nil
 }
 
@@ -153,6 +178,9 @@ func (r *Router) GetPrincipleTarget(tag string) ([]string, error) {
 func (r *Router) SetOverrideTarget(tag, target string) error {
 	if b, ok := r.balancers[tag]; ok {
 		b.override.Put(target)
+		if b.onChange != nil {
+			b.onChange(target, "override")
+		}
 		return nil
 	}
 	return errors.New("cannot find tag")
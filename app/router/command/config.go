@@ -44,6 +44,10 @@ func (c routingContext) GetRuleTag() string {
 	return ""
 }
 
+func (c routingContext) GetChain() []string {
+	return nil
+}
+
 // GetSkipDNSResolve is a mock implementation here to match the interface,
 // SkipDNSResolve is set from dns module, no use if coming from a protobuf object?
 // TODO: please confirm @Vigilans
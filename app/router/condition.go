@@ -395,6 +395,68 @@ func (m *ProcessNameMatcher) Apply(ctx routing.Context) bool {
 	return false
 }
 
+// ClientHelloMatcher matches an attribute set from the sniffed TLS ClientHello,
+// see dispatcher.SnifferFingerprint. Values are compared case-insensitively,
+// values prefixed with "regexp:" are regular expressions.
+type ClientHelloMatcher struct {
+	attribute string
+	// list means the attribute is a comma separated list, any item of which
+	// may match.
+	list     bool
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+func NewClientHelloMatcher(attribute string, list bool, values []string) (*ClientHelloMatcher, error) {
+	m := &ClientHelloMatcher{
+		attribute: attribute,
+		list:      list,
+	}
+	for _, v := range values {
+		if pattern, ok := strings.CutPrefix(v, "regexp:"); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errors.New("invalid regexp for ", attribute).Base(err)
+			}
+			m.patterns = append(m.patterns, re)
+			continue
+		}
+		m.values = append(m.values, v)
+	}
+	return m, nil
+}
+
+func (m *ClientHelloMatcher) match(value string) bool {
+	for _, v := range m.values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply implements Condition.
+func (m *ClientHelloMatcher) Apply(ctx routing.Context) bool {
+	value, found := ctx.GetAttributes()[m.attribute]
+	if !found {
+		return false
+	}
+	if !m.list {
+		return m.match(value)
+	}
+	for _, item := range strings.Split(value, ",") {
+		if m.match(item) {
+			return true
+		}
+	}
+	return false
+}
+
 // LocalOSMatcher matches the operating system Xray itself is running on. That never
 // changes while Xray is running, so the result is resolved when the rule is built.
 type LocalOSMatcher struct {
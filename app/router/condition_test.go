@@ -370,6 +370,41 @@ func TestLocalOSRule(t *testing.T) {
 	}
 }
 
+func TestClientHelloRule(t *testing.T) {
+	content := &session.Content{}
+	content.SetAttribute(":ja3", "cd08e31494f9531f560d64c695473da9")
+	content.SetAttribute(":ja4", "t13d1516h2_8daaf6152771_e5627efa2ab1")
+	content.SetAttribute(":alpn", "h2,http/1.1")
+
+	cases := []struct {
+		rule   *RoutingRule
+		output bool
+	}{
+		{rule: &RoutingRule{Ja3: []string{"CD08E31494F9531F560D64C695473DA9"}}, output: true},
+		{rule: &RoutingRule{Ja3: []string{"00000000000000000000000000000000"}}, output: false},
+		{rule: &RoutingRule{Ja4: []string{"regexp:^t13d"}}, output: true},
+		{rule: &RoutingRule{Ja4: []string{"regexp:^q13d"}}, output: false},
+		{rule: &RoutingRule{Alpn: []string{"http/1.1"}}, output: true},
+		{rule: &RoutingRule{Alpn: []string{"h3"}}, output: false},
+		{rule: &RoutingRule{Ja4: []string{"regexp:^t13d"}, Alpn: []string{"h3"}}, output: false},
+	}
+
+	for _, test := range cases {
+		cond, err := test.rule.BuildCondition()
+		common.Must(err)
+		if got := cond.Apply(withContent(content)); got != test.output {
+			t.Errorf("for rule %v: expected %v, got %v", test.rule, test.output, got)
+		}
+		if cond.Apply(withBackground()) {
+			t.Errorf("rule %v matched a connection without ClientHello", test.rule)
+		}
+	}
+
+	if _, err := (&RoutingRule{Ja4: []string{"regexp:("}}).BuildCondition(); err == nil {
+		t.Error("expected an error for an invalid regexp")
+	}
+}
+
 func BenchmarkMphDomainMatcher(b *testing.B) {
 	b.Setenv("xray.location.asset", filepath.Join("..", "..", "resources"))
 	rules, err := geodata.ParseDomainRules([]string{"geosite:cn"}, geodata.Domain_Substr)
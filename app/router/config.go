@@ -13,6 +13,7 @@ import (
 type Rule struct {
 	Tag       string
 	RuleTag   string
+	Chain     []string
 	Balancer  *Balancer
 	Condition Condition
 	Webhook   *WebhookNotifier
@@ -69,6 +70,25 @@ func (rr *RoutingRule) BuildCondition() (Condition, error) {
 		conds.Add(NewUserMatcher(rr.UserEmail))
 	}
 
+	for _, fp := range []struct {
+		attribute string
+		list      bool
+		values    []string
+	}{
+		{":ja3", false, rr.Ja3},
+		{":ja4", false, rr.Ja4},
+		{":alpn", true, rr.Alpn},
+	} {
+		if len(fp.values) == 0 {
+			continue
+		}
+		cond, err := NewClientHelloMatcher(fp.attribute, fp.list, fp.values)
+		if err != nil {
+			return nil, err
+		}
+		conds.Add(cond)
+	}
+
 	if len(rr.Attributes) > 0 {
 		configuredKeys := make(map[string]*regexp.Regexp)
 		for key, value := range rr.Attributes {
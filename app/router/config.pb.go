@@ -108,7 +108,14 @@ type RoutingRule struct {
 	Process        []string       `protobuf:"bytes,21,rep,name=process,proto3" json:"process,omitempty"`
 	Webhook        *WebhookConfig `protobuf:"bytes,22,opt,name=webhook,proto3" json:"webhook,omitempty"`
 	// List of operating systems for matching the one Xray itself is running on.
-	LocalOs       []string `protobuf:"bytes,23,rep,name=local_os,json=localOs,proto3" json:"local_os,omitempty"`
+	LocalOs []string `protobuf:"bytes,23,rep,name=local_os,json=localOs,proto3" json:"local_os,omitempty"`
+	// Fingerprints and ALPN of the sniffed TLS ClientHello.
+	Ja3  []string `protobuf:"bytes,24,rep,name=ja3,proto3" json:"ja3,omitempty"`
+	Ja4  []string `protobuf:"bytes,25,rep,name=ja4,proto3" json:"ja4,omitempty"`
+	Alpn []string `protobuf:"bytes,26,rep,name=alpn,proto3" json:"alpn,omitempty"`
+	// Tags of the outbounds through which the outbound of this rule is dialed,
+	// in order: the first dials directly, and the last is dialed by the outbound.
+	Chain         []string `protobuf:"bytes,27,rep,name=chain,proto3" json:"chain,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -287,6 +294,34 @@ func (x *RoutingRule) GetLocalOs() []string {
 	return nil
 }
 
+func (x *RoutingRule) GetJa3() []string {
+	if x != nil {
+		return x.Ja3
+	}
+	return nil
+}
+
+func (x *RoutingRule) GetJa4() []string {
+	if x != nil {
+		return x.Ja4
+	}
+	return nil
+}
+
+func (x *RoutingRule) GetAlpn() []string {
+	if x != nil {
+		return x.Alpn
+	}
+	return nil
+}
+
+func (x *RoutingRule) GetChain() []string {
+	if x != nil {
+		return x.Chain
+	}
+	return nil
+}
+
 type isRoutingRule_TargetTag interface {
 	isRoutingRule_TargetTag()
 }
@@ -310,8 +345,30 @@ type WebhookConfig struct {
 	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
 	Deduplication uint32                 `protobuf:"varint,2,opt,name=deduplication,proto3" json:"deduplication,omitempty"`
 	Headers       map[string]string      `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Go text/template of the payload. It is executed with the event, or the
+	// list of events if batched. The event is posted as JSON if empty.
+	Template string `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
+	// Seconds over which the events are collected into one payload, and the
+	// most events in it. The events aren't batched if batch_window is 0.
+	BatchWindow uint32 `protobuf:"varint,5,opt,name=batch_window,json=batchWindow,proto3" json:"batch_window,omitempty"`
+	BatchSize   uint32 `protobuf:"varint,6,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	// Times a failed payload is posted again, the seconds before the first
+	// retry, which double with every retry, and the seconds before a POST
+	// times out.
+	Retries       uint32 `protobuf:"varint,7,opt,name=retries,proto3" json:"retries,omitempty"`
+	RetryInterval uint32 `protobuf:"varint,8,opt,name=retry_interval,json=retryInterval,proto3" json:"retry_interval,omitempty"`
+	Timeout       uint32 `protobuf:"varint,9,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// Most payloads waiting to be posted, and the file that keeps them across
+	// restarts.
+	QueueSize uint32 `protobuf:"varint,10,opt,name=queue_size,json=queueSize,proto3" json:"queue_size,omitempty"`
+	QueuePath string `protobuf:"bytes,11,opt,name=queue_path,json=queuePath,proto3" json:"queue_path,omitempty"`
+	// Secret of the HMAC-SHA256 of the payloads in the X-Signature-256 header.
+	Secret string `protobuf:"bytes,12,opt,name=secret,proto3" json:"secret,omitempty"`
+	// Fields of the event whose values make the key of deduplication, such as
+	// "email", "sourceIP" or "destination". Defaults to "email".
+	DeduplicationKeys []string `protobuf:"bytes,13,rep,name=deduplication_keys,json=deduplicationKeys,proto3" json:"deduplication_keys,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *WebhookConfig) Reset() {
@@ -365,6 +422,76 @@ func (x *WebhookConfig) GetHeaders() map[string]string {
 	return nil
 }
 
+func (x *WebhookConfig) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *WebhookConfig) GetBatchWindow() uint32 {
+	if x != nil {
+		return x.BatchWindow
+	}
+	return 0
+}
+
+func (x *WebhookConfig) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *WebhookConfig) GetRetries() uint32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *WebhookConfig) GetRetryInterval() uint32 {
+	if x != nil {
+		return x.RetryInterval
+	}
+	return 0
+}
+
+func (x *WebhookConfig) GetTimeout() uint32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *WebhookConfig) GetQueueSize() uint32 {
+	if x != nil {
+		return x.QueueSize
+	}
+	return 0
+}
+
+func (x *WebhookConfig) GetQueuePath() string {
+	if x != nil {
+		return x.QueuePath
+	}
+	return ""
+}
+
+func (x *WebhookConfig) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *WebhookConfig) GetDeduplicationKeys() []string {
+	if x != nil {
+		return x.DeduplicationKeys
+	}
+	return nil
+}
+
 type BalancingRule struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Tag              string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
@@ -646,7 +773,7 @@ var File_app_router_config_proto protoreflect.FileDescriptor
 
 const file_app_router_config_proto_rawDesc = "" +
 	"\n" +
-	"\x17app/router/config.proto\x12\x0fxray.app.router\x1a!common/serial/typed_message.proto\x1a\x15common/net/port.proto\x1a\x18common/net/network.proto\x1a\x1bcommon/geodata/geodat.proto\"\xdc\a\n" +
+	"\x17app/router/config.proto\x12\x0fxray.app.router\x1a!common/serial/typed_message.proto\x1a\x15common/net/port.proto\x1a\x18common/net/network.proto\x1a\x1bcommon/geodata/geodat.proto\"\xaa\b\n" +
 	"\vRoutingRule\x12\x12\n" +
 	"\x03tag\x18\x01 \x01(\tH\x00R\x03tag\x12%\n" +
 	"\rbalancing_tag\x18\f \x01(\tH\x00R\fbalancingTag\x12\x19\n" +
@@ -671,16 +798,34 @@ const file_app_router_config_proto_rawDesc = "" +
 	"\x10vless_route_list\x18\x14 \x01(\v2\x19.xray.common.net.PortListR\x0evlessRouteList\x12\x18\n" +
 	"\aprocess\x18\x15 \x03(\tR\aprocess\x128\n" +
 	"\awebhook\x18\x16 \x01(\v2\x1e.xray.app.router.WebhookConfigR\awebhook\x12\x19\n" +
-	"\blocal_os\x18\x17 \x03(\tR\alocalOs\x1a=\n" +
+	"\blocal_os\x18\x17 \x03(\tR\alocalOs\x12\x10\n" +
+	"\x03ja3\x18\x18 \x03(\tR\x03ja3\x12\x10\n" +
+	"\x03ja4\x18\x19 \x03(\tR\x03ja4\x12\x12\n" +
+	"\x04alpn\x18\x1a \x03(\tR\x04alpn\x12\x14\n" +
+	"\x05chain\x18\x1b \x03(\tR\x05chain\x1a=\n" +
 	"\x0fAttributesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
 	"\n" +
-	"target_tag\"\xca\x01\n" +
+	"target_tag\"\x88\x04\n" +
 	"\rWebhookConfig\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12$\n" +
 	"\rdeduplication\x18\x02 \x01(\rR\rdeduplication\x12E\n" +
-	"\aheaders\x18\x03 \x03(\v2+.xray.app.router.WebhookConfig.HeadersEntryR\aheaders\x1a:\n" +
+	"\aheaders\x18\x03 \x03(\v2+.xray.app.router.WebhookConfig.HeadersEntryR\aheaders\x12\x1a\n" +
+	"\btemplate\x18\x04 \x01(\tR\btemplate\x12!\n" +
+	"\fbatch_window\x18\x05 \x01(\rR\vbatchWindow\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x06 \x01(\rR\tbatchSize\x12\x18\n" +
+	"\aretries\x18\a \x01(\rR\aretries\x12%\n" +
+	"\x0eretry_interval\x18\b \x01(\rR\rretryInterval\x12\x18\n" +
+	"\atimeout\x18\t \x01(\rR\atimeout\x12\x1d\n" +
+	"\n" +
+	"queue_size\x18\n" +
+	" \x01(\rR\tqueueSize\x12\x1d\n" +
+	"\n" +
+	"queue_path\x18\v \x01(\tR\tqueuePath\x12\x16\n" +
+	"\x06secret\x18\f \x01(\tR\x06secret\x12-\n" +
+	"\x12deduplication_keys\x18\r \x03(\tR\x11deduplicationKeys\x1a:\n" +
 	"\fHeadersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xdc\x01\n" +
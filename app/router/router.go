@@ -9,6 +9,7 @@ import (
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/features/dns"
+	"github.com/xtls/xray-core/features/events"
 	"github.com/xtls/xray-core/features/outbound"
 	"github.com/xtls/xray-core/features/routing"
 	routing_dns "github.com/xtls/xray-core/features/routing/dns"
@@ -24,6 +25,7 @@ type Router struct {
 	ctx        context.Context
 	ohm        outbound.Manager
 	dispatcher routing.Dispatcher
+	events     events.Bus
 	mu         sync.Mutex
 }
 
@@ -33,6 +35,17 @@ type Route struct {
 	outboundGroupTags []string
 	outboundTag       string
 	ruleTag           string
+	chain             []string
+}
+
+// balancerChanged returns the function that publishes the changes of the
+// target of the balancer.
+func (r *Router) balancerChanged(tag string) func(target, detail string) {
+	return func(target, detail string) {
+		if r.events != nil {
+			r.events.Publish(&events.Event{Type: events.BalancerChanged, Balancer: tag, OutboundTag: target, Detail: detail})
+		}
+	}
 }
 
 // Init initializes the Router.
@@ -50,6 +63,7 @@ func (r *Router) Init(ctx context.Context, config *Config, d dns.Client, ohm out
 			return err
 		}
 		balancer.InjectContext(ctx)
+		balancer.onChange = r.balancerChanged(rule.Tag)
 		r.balancers[rule.Tag] = balancer
 	}
 
@@ -64,6 +78,7 @@ func (r *Router) Init(ctx context.Context, config *Config, d dns.Client, ohm out
 			Condition: cond,
 			Tag:       rule.GetTag(),
 			RuleTag:   rule.GetRuleTag(),
+			Chain:     rule.GetChain(),
 		}
 		if wh := rule.GetWebhook(); wh != nil {
 			notifier, err := NewWebhookNotifier(wh)
@@ -105,7 +120,7 @@ func (r *Router) PickRoute(ctx routing.Context) (routing.Route, error) {
 	if rule.Webhook != nil {
 		rule.Webhook.Fire(originalCtx, tag)
 	}
-	return &Route{Context: ctx, outboundTag: tag, ruleTag: rule.RuleTag}, nil
+	return &Route{Context: ctx, outboundTag: tag, ruleTag: rule.RuleTag, chain: rule.Chain}, nil
 }
 
 // AddRule implements routing.Router.
@@ -143,6 +158,7 @@ func (r *Router) ReloadRules(config *Config, shouldAppend bool) error {
 			return err
 		}
 		balancer.InjectContext(r.ctx)
+		balancer.onChange = r.balancerChanged(rule.Tag)
 		r.balancers[rule.Tag] = balancer
 	}
 
@@ -170,6 +186,7 @@ func (r *Router) ReloadRules(config *Config, shouldAppend bool) error {
 			Condition: cond,
 			Tag:       rule.GetTag(),
 			RuleTag:   rule.GetRuleTag(),
+			Chain:     rule.GetChain(),
 		}
 		if wh := rule.GetWebhook(); wh != nil {
 			notifier, err := NewWebhookNotifier(wh)
@@ -237,6 +254,7 @@ func (r *Router) ListRule() []routing.Route {
 		ruleList = append(ruleList, &Route{
 			outboundTag: rule.Tag,
 			ruleTag:     rule.RuleTag,
+			chain:       rule.Chain,
 		})
 	}
 	return ruleList
@@ -315,9 +333,17 @@ func (r *Route) GetRuleTag() string {
 	return r.ruleTag
 }
 
+// GetChain implements routing.Route.
+func (r *Route) GetChain() []string {
+	return r.chain
+}
+
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		r := new(Router)
+		core.OptionalFeatures(ctx, func(bus events.Bus) {
+			r.events = bus
+		})
 		if err := core.RequireFeatures(ctx, func(d dns.Client, ohm outbound.Manager, dispatcher routing.Dispatcher) error {
 			return r.Init(ctx, config.(*Config), d, ohm, dispatcher)
 		}); err != nil {
@@ -4,14 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"io"
 	"net"
-	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/xtls/xray-core/common/errors"
-	"github.com/xtls/xray-core/common/utils"
+	"github.com/xtls/xray-core/common/webhook"
 	"github.com/xtls/xray-core/features/routing"
 	routing_session "github.com/xtls/xray-core/features/routing/session"
 )
@@ -34,15 +35,65 @@ type event struct {
 	Timestamp      int64   `json:"ts"`
 }
 
+// eventFields are the fields of the event that make the keys of
+// deduplication, by their JSON names. "sourceIP" is the source without its
+// port.
+var eventFields = map[string]func(ev *event) *string{
+	"email":          func(ev *event) *string { return ev.Email },
+	"protocol":       func(ev *event) *string { return ev.Protocol },
+	"network":        func(ev *event) *string { return ev.Network },
+	"source":         func(ev *event) *string { return ev.Source },
+	"destination":    func(ev *event) *string { return ev.Destination },
+	"originalTarget": func(ev *event) *string { return ev.OriginalTarget },
+	"routeTarget":    func(ev *event) *string { return ev.RouteTarget },
+	"inboundTag":     func(ev *event) *string { return ev.InboundTag },
+	"inboundName":    func(ev *event) *string { return ev.InboundName },
+	"inboundLocal":   func(ev *event) *string { return ev.InboundLocal },
+	"outboundTag":    func(ev *event) *string { return ev.OutboundTag },
+	"level": func(ev *event) *string {
+		if ev.Level == nil {
+			return nil
+		}
+		return ptr(strconv.FormatUint(uint64(*ev.Level), 10))
+	},
+	"sourceIP": func(ev *event) *string {
+		if ev.Source == nil {
+			return nil
+		}
+		host, _, err := net.SplitHostPort(*ev.Source)
+		if err != nil {
+			return nil
+		}
+		return ptr(host)
+	},
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
 type WebhookNotifier struct {
-	url           string
-	headers       map[string]string
 	deduplication uint32
-	client        *http.Client
+	keys          []func(ev *event) *string
+	template      *template.Template
+	batchWindow   time.Duration
+	batchSize     int
+	client        *webhook.Client
+	queue         *webhook.Queue
 	seen          sync.Map
 	done          chan struct{}
 	wg            sync.WaitGroup
 	closeOnce     sync.Once
+
+	// The events collected in the current batch window, which is numbered
+	// so that the timer of a flushed batch doesn't flush the next one.
+	access     sync.Mutex
+	batch      []*event
+	generation uint64
+	timer      *time.Timer
 }
 
 func NewWebhookNotifier(cfg *WebhookConfig) (*WebhookNotifier, error) {
@@ -50,32 +101,48 @@ func NewWebhookNotifier(cfg *WebhookConfig) (*WebhookNotifier, error) {
 		return nil, nil
 	}
 
-	httpURL, socketPath := utils.SplitHTTPUnixURL(cfg.Url)
 	h := &WebhookNotifier{
-		url:           httpURL,
 		deduplication: cfg.Deduplication,
-		client: &http.Client{
-			Timeout: 5 * time.Second,
-		},
+		batchWindow:   time.Duration(cfg.BatchWindow) * time.Second,
+		batchSize:     int(cfg.BatchSize),
+		client: webhook.New(cfg.Url, &webhook.Options{
+			Headers: cfg.Headers,
+			Secret:  cfg.Secret,
+			Timeout: time.Duration(cfg.Timeout) * time.Second,
+		}),
 		done: make(chan struct{}),
 	}
 
-	if socketPath != "" {
-		dialAddr := utils.ResolveSocketPath(socketPath)
-		h.client.Transport = &http.Transport{
-			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
-				var d net.Dialer
-				return d.DialContext(ctx, "unix", dialAddr)
-			},
+	keys := cfg.DeduplicationKeys
+	if len(keys) == 0 {
+		keys = []string{"email"}
+	}
+	for _, key := range keys {
+		field, found := eventFields[key]
+		if !found {
+			return nil, errors.New("webhook: unknown deduplication key: ", key)
 		}
+		h.keys = append(h.keys, field)
 	}
 
-	if len(cfg.Headers) > 0 {
-		h.headers = make(map[string]string, len(cfg.Headers))
-		for k, v := range cfg.Headers {
-			h.headers[k] = v
+	if cfg.Template != "" {
+		t, err := template.New("webhook").Funcs(templateFuncs).Parse(cfg.Template)
+		if err != nil {
+			return nil, errors.New("webhook: invalid template").Base(err)
 		}
+		h.template = t
+	}
+
+	queue, err := webhook.NewQueue(h.client, webhook.QueueOptions{
+		Size:    int(cfg.QueueSize),
+		Retries: cfg.Retries,
+		Backoff: time.Duration(cfg.RetryInterval) * time.Second,
+		Path:    cfg.QueuePath,
+	})
+	if err != nil {
+		return nil, errors.New("webhook: failed to create queue").Base(err)
 	}
+	h.queue = queue
 
 	if h.deduplication > 0 {
 		h.wg.Add(1)
@@ -86,27 +153,54 @@ func NewWebhookNotifier(cfg *WebhookConfig) (*WebhookNotifier, error) {
 }
 
 func (h *WebhookNotifier) Fire(ctx routing.Context, outboundTag string) {
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+
 	ev := buildEvent(ctx, outboundTag)
+	if h.isDuplicate(h.deduplicationKey(ev)) {
+		return
+	}
+
+	if h.batchWindow == 0 {
+		h.post(ev)
+		return
+	}
 
-	email := ""
-	if ev.Email != nil {
-		email = *ev.Email
+	h.access.Lock()
+	h.batch = append(h.batch, ev)
+	if len(h.batch) == 1 {
+		h.generation++
+		generation := h.generation
+		h.timer = time.AfterFunc(h.batchWindow, func() {
+			h.flush(generation)
+		})
 	}
-	if h.isDuplicate(email) {
+	if h.batchSize == 0 || len(h.batch) < h.batchSize {
+		h.access.Unlock()
 		return
 	}
+	batch := h.batch
+	h.batch = nil
+	h.timer.Stop()
+	h.access.Unlock()
+	h.post(batch)
+}
 
-	h.wg.Add(1)
-	select {
-	case <-h.done:
-		h.wg.Done()
+// flush posts the events of the batch window, if it is still the current
+// one.
+func (h *WebhookNotifier) flush(generation uint64) {
+	h.access.Lock()
+	if generation != h.generation || len(h.batch) == 0 {
+		h.access.Unlock()
 		return
-	default:
 	}
-	go func() {
-		defer h.wg.Done()
-		h.post(ev)
-	}()
+	batch := h.batch
+	h.batch = nil
+	h.access.Unlock()
+	h.post(batch)
 }
 
 func buildEvent(ctx routing.Context, outboundTag string) *event {
@@ -163,49 +257,55 @@ func enrichFromSession(ev *event, sctx *routing_session.Context) {
 	}
 }
 
-func (h *WebhookNotifier) post(ev *event) {
-	body, err := json.Marshal(ev)
-	if err != nil {
-		errors.LogWarning(context.Background(), "webhook: marshal failed: ", err)
-		return
-	}
-
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, h.url, bytes.NewReader(body))
-	if err != nil {
-		errors.LogWarning(context.Background(), "webhook: request build failed: ", err)
-		return
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	for k, v := range h.headers {
-		req.Header.Set(k, v)
+// post queues the payload of the event, or the batch of events.
+func (h *WebhookNotifier) post(v any) {
+	var body []byte
+	if h.template != nil {
+		var buf bytes.Buffer
+		if err := h.template.Execute(&buf, v); err != nil {
+			errors.LogWarning(context.Background(), "webhook: template failed: ", err)
+			return
+		}
+		body = buf.Bytes()
+	} else {
+		var err error
+		body, err = json.Marshal(v)
+		if err != nil {
+			errors.LogWarning(context.Background(), "webhook: marshal failed: ", err)
+			return
+		}
 	}
+	h.queue.Push(body)
+}
 
-	resp, err := h.client.Do(req)
-	if err != nil {
-		errors.LogInfo(context.Background(), "webhook: POST failed: ", err)
-		return
+// deduplicationKey returns the values of the deduplication keys of the
+// event, or "" if none is set.
+func (h *WebhookNotifier) deduplicationKey(ev *event) string {
+	values := make([]string, len(h.keys))
+	set := false
+	for i, field := range h.keys {
+		if v := field(ev); v != nil && *v != "" {
+			values[i] = *v
+			set = true
+		}
 	}
-	defer func() {
-		io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
-	}()
-	if resp.StatusCode >= 400 {
-		errors.LogWarning(context.Background(), "webhook: POST returned status ", resp.StatusCode)
+	if !set {
+		return ""
 	}
+	return strings.Join(values, "\x00")
 }
 
-func (h *WebhookNotifier) isDuplicate(email string) bool {
-	if h.deduplication == 0 || email == "" {
+func (h *WebhookNotifier) isDuplicate(key string) bool {
+	if h.deduplication == 0 || key == "" {
 		return false
 	}
 	ttl := time.Duration(h.deduplication) * time.Second
 	now := time.Now()
-	if v, loaded := h.seen.LoadOrStore(email, now); loaded {
+	if v, loaded := h.seen.LoadOrStore(key, now); loaded {
 		if now.Sub(v.(time.Time)) < ttl {
 			return true
 		}
-		h.seen.Store(email, now)
+		h.seen.Store(key, now)
 	}
 	return false
 }
@@ -231,11 +331,24 @@ func (h *WebhookNotifier) cleanupLoop() {
 	}
 }
 
+// Close posts the batched events, and stops the notifier. The payloads
+// waiting to be posted are kept in the queue file, if any.
 func (h *WebhookNotifier) Close() error {
 	h.closeOnce.Do(func() {
 		close(h.done)
+		h.access.Lock()
+		batch := h.batch
+		h.batch = nil
+		if h.timer != nil {
+			h.timer.Stop()
+		}
+		h.access.Unlock()
+		if len(batch) > 0 {
+			h.post(batch)
+		}
 	})
 	h.wg.Wait()
-	h.client.CloseIdleConnections()
+	h.queue.Close()
+	h.client.Close()
 	return nil
 }
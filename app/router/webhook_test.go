@@ -0,0 +1,70 @@
+package router_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/xtls/xray-core/app/router"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	routing_session "github.com/xtls/xray-core/features/routing/session"
+)
+
+func webhookContext(email string, source string) context.Context {
+	ctx := session.ContextWithInbound(context.Background(), &session.Inbound{
+		Tag:    "in",
+		Source: net.TCPDestination(net.ParseAddress(source), 40000),
+		User:   &protocol.MemoryUser{Email: email},
+	})
+	return session.ContextWithOutbounds(ctx, []*session.Outbound{{
+		Target: net.TCPDestination(net.DomainAddress("example.com"), 443),
+	}})
+}
+
+func TestWebhookBatchTemplate(t *testing.T) {
+	payloads := make(chan string, 4)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payloads <- string(body)
+	}))
+	defer s.Close()
+
+	h, err := NewWebhookNotifier(&WebhookConfig{
+		Url:               s.URL,
+		Template:          `{"text":"{{range $i, $e := .}}{{if $i}}, {{end}}{{$e.Email}}@{{$e.Destination}}{{end}}"}`,
+		BatchWindow:       60,
+		BatchSize:         2,
+		Deduplication:     60,
+		DeduplicationKeys: []string{"email", "sourceIP"},
+	})
+	common.Must(err)
+	defer h.Close()
+
+	h.Fire(routing_session.AsRoutingContext(webhookContext("a", "10.0.0.1")), "out")
+	h.Fire(routing_session.AsRoutingContext(webhookContext("a", "10.0.0.1")), "out")
+	h.Fire(routing_session.AsRoutingContext(webhookContext("a", "10.0.0.2")), "out")
+
+	select {
+	case payload := <-payloads:
+		if expected := `{"text":"a@example.com:443, a@example.com:443"}`; payload != expected {
+			t.Error("expected ", expected, ", got ", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for batch")
+	}
+}
+
+func TestWebhookInvalidConfig(t *testing.T) {
+	if _, err := NewWebhookNotifier(&WebhookConfig{Url: "http://127.0.0.1/", Template: "{{"}); err == nil {
+		t.Error("expected error of template")
+	}
+	if _, err := NewWebhookNotifier(&WebhookConfig{Url: "http://127.0.0.1/", DeduplicationKeys: []string{"unknown"}}); err == nil {
+		t.Error("expected error of deduplication key")
+	}
+}
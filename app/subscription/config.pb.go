@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: app/subscription/config.proto
+
+package subscription
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Provider struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// URL of a list of share links, optionally base64 encoded.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Outbound used to fetch the subscription.
+	Outbound string `protobuf:"bytes,2,opt,name=outbound,proto3" json:"outbound,omitempty"`
+	// Prefix prepended to the tag of every generated outbound.
+	TagPrefix string `protobuf:"bytes,3,opt,name=tag_prefix,json=tagPrefix,proto3" json:"tag_prefix,omitempty"`
+	// Refresh interval in nanoseconds.
+	Interval      int64  `protobuf:"varint,4,opt,name=interval,proto3" json:"interval,omitempty"`
+	UserAgent     string `protobuf:"bytes,5,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Provider) Reset() {
+	*x = Provider{}
+	mi := &file_app_subscription_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Provider) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Provider) ProtoMessage() {}
+
+func (x *Provider) ProtoReflect() protoreflect.Message {
+	mi := &file_app_subscription_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Provider.ProtoReflect.Descriptor instead.
+func (*Provider) Descriptor() ([]byte, []int) {
+	return file_app_subscription_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Provider) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Provider) GetOutbound() string {
+	if x != nil {
+		return x.Outbound
+	}
+	return ""
+}
+
+func (x *Provider) GetTagPrefix() string {
+	if x != nil {
+		return x.TagPrefix
+	}
+	return ""
+}
+
+func (x *Provider) GetInterval() int64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *Provider) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+type Config struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Providers     []*Provider            `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_app_subscription_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_app_subscription_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_app_subscription_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Config) GetProviders() []*Provider {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+var File_app_subscription_config_proto protoreflect.FileDescriptor
+
+const file_app_subscription_config_proto_rawDesc = "" +
+	"\n" +
+	"\x1dapp/subscription/config.proto\x12\x15xray.app.subscription\"\x92\x01\n" +
+	"\bProvider\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1a\n" +
+	"\boutbound\x18\x02 \x01(\tR\boutbound\x12\x1d\n" +
+	"\n" +
+	"tag_prefix\x18\x03 \x01(\tR\ttagPrefix\x12\x1a\n" +
+	"\binterval\x18\x04 \x01(\x03R\binterval\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x05 \x01(\tR\tuserAgent\"G\n" +
+	"\x06Config\x12=\n" +
+	"\tproviders\x18\x01 \x03(\v2\x1f.xray.app.subscription.ProviderR\tprovidersBa\n" +
+	"\x19com.xray.app.subscriptionP\x01Z*github.com/xtls/xray-core/app/subscription\xaa\x02\x15Xray.App.Subscriptionb\x06proto3"
+
+var (
+	file_app_subscription_config_proto_rawDescOnce sync.Once
+	file_app_subscription_config_proto_rawDescData []byte
+)
+
+func file_app_subscription_config_proto_rawDescGZIP() []byte {
+	file_app_subscription_config_proto_rawDescOnce.Do(func() {
+		file_app_subscription_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_app_subscription_config_proto_rawDesc), len(file_app_subscription_config_proto_rawDesc)))
+	})
+	return file_app_subscription_config_proto_rawDescData
+}
+
+var file_app_subscription_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_app_subscription_config_proto_goTypes = []any{
+	(*Provider)(nil), // 0: xray.app.subscription.Provider
+	(*Config)(nil),   // 1: xray.app.subscription.Config
+}
+var file_app_subscription_config_proto_depIdxs = []int32{
+	0, // 0: xray.app.subscription.Config.providers:type_name -> xray.app.subscription.Provider
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_app_subscription_config_proto_init() }
+func file_app_subscription_config_proto_init() {
+	if File_app_subscription_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_subscription_config_proto_rawDesc), len(file_app_subscription_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_app_subscription_config_proto_goTypes,
+		DependencyIndexes: file_app_subscription_config_proto_depIdxs,
+		MessageInfos:      file_app_subscription_config_proto_msgTypes,
+	}.Build()
+	File_app_subscription_config_proto = out.File
+	file_app_subscription_config_proto_goTypes = nil
+	file_app_subscription_config_proto_depIdxs = nil
+}
@@ -0,0 +1,239 @@
+package subscription
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/task"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/outbound"
+	"github.com/xtls/xray-core/features/routing"
+	"github.com/xtls/xray-core/transport/internet/tagged"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultInterval = time.Hour
+	fetchTimeout    = time.Minute
+	maxBodySize     = 16 << 20
+)
+
+// ParserFunc converts a subscription body into outbound handler configs.
+// The tag of each returned config is the remark of its share link.
+type ParserFunc func(content []byte) ([]*core.OutboundHandlerConfig, error)
+
+// Parser is registered by infra/conf, which cannot be imported here without
+// an import cycle.
+var Parser ParserFunc
+
+type Subscription struct {
+	providers []*provider
+}
+
+type provider struct {
+	ctx      context.Context
+	config   *Provider
+	instance *core.Instance
+	ohm      outbound.Manager
+	client   *http.Client
+	task     *task.Periodic
+
+	access  sync.Mutex
+	applied map[string]*core.OutboundHandlerConfig
+}
+
+func New(ctx context.Context, config *Config) (*Subscription, error) {
+	s := &Subscription{}
+	if len(config.Providers) == 0 {
+		return s, nil
+	}
+
+	instance := core.MustFromContext(ctx)
+	var dispatcher routing.Dispatcher
+	var ohm outbound.Manager
+	if err := core.RequireFeatures(ctx, func(d routing.Dispatcher, om outbound.Manager) {
+		dispatcher = d
+		ohm = om
+	}); err != nil {
+		return nil, errors.New("failed to get features for subscription").Base(err)
+	}
+
+	for _, pc := range config.Providers {
+		p := &provider{
+			ctx:      ctx,
+			config:   pc,
+			instance: instance,
+			ohm:      ohm,
+			client:   newClient(ctx, dispatcher, pc.Outbound),
+			applied:  make(map[string]*core.OutboundHandlerConfig),
+		}
+		interval := time.Duration(pc.Interval)
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+		p.task = &task.Periodic{
+			Interval: interval,
+			Execute:  p.refresh,
+		}
+		s.providers = append(s.providers, p)
+	}
+	return s, nil
+}
+
+func newClient(ctx context.Context, dispatcher routing.Dispatcher, outbound string) *http.Client {
+	return &http.Client{
+		Timeout: fetchTimeout,
+		Transport: &http.Transport{
+			Proxy: nil,
+			DialContext: func(_ context.Context, network, address string) (net.Conn, error) {
+				if tagged.Dialer == nil {
+					return nil, errors.New("tagged dialer is not initialized")
+				}
+				dest, err := net.ParseDestination(network + ":" + address)
+				if err != nil {
+					return nil, errors.New("cannot understand address").Base(err)
+				}
+				return tagged.Dialer(ctx, dispatcher, dest, outbound)
+			},
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+// refresh fetches the subscription and reconciles the outbounds. Errors are
+// only logged, so that the periodic task keeps running.
+func (p *provider) refresh() error {
+	if err := p.update(); err != nil {
+		errors.LogWarningInner(p.ctx, err, "failed to update subscription ", p.config.Url)
+	}
+	return nil
+}
+
+func (p *provider) update() error {
+	content, err := p.fetch()
+	if err != nil {
+		return err
+	}
+	if Parser == nil {
+		return errors.New("subscription parser is not registered")
+	}
+	configs, err := Parser(content)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return errors.New("no usable outbound in subscription, keeping current outbounds")
+	}
+	p.apply(configs)
+	return nil
+}
+
+func (p *provider) fetch() ([]byte, error) {
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, p.config.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+	userAgent := p.config.UserAgent
+	if userAgent == "" {
+		userAgent = "Xray/" + core.Version()
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		io.Copy(io.Discard, resp.Body)
+		return nil, errors.New("unexpected status code: ", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// apply adds, replaces and removes the outbounds owned by this provider so
+// that they match configs.
+func (p *provider) apply(configs []*core.OutboundHandlerConfig) {
+	p.access.Lock()
+	defer p.access.Unlock()
+
+	wanted := make(map[string]*core.OutboundHandlerConfig, len(configs))
+	for i, c := range configs {
+		name := c.Tag
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		tag := p.config.TagPrefix + name
+		for n := 2; wanted[tag] != nil; n++ {
+			tag = p.config.TagPrefix + name + "-" + strconv.Itoa(n)
+		}
+		c = proto.Clone(c).(*core.OutboundHandlerConfig)
+		c.Tag = tag
+		wanted[tag] = c
+	}
+
+	var added, removed int
+	for tag, old := range p.applied {
+		if c, found := wanted[tag]; found && proto.Equal(old, c) {
+			continue
+		}
+		if err := p.ohm.RemoveHandler(p.ctx, tag); err != nil {
+			errors.LogWarningInner(p.ctx, err, "failed to remove subscription outbound ", tag)
+		}
+		delete(p.applied, tag)
+		removed++
+	}
+	for tag, c := range wanted {
+		if p.applied[tag] != nil {
+			continue
+		}
+		if p.ohm.GetHandler(tag) != nil {
+			errors.LogWarning(p.ctx, "skip subscription outbound ", tag, ", tag already in use")
+			continue
+		}
+		if err := core.AddOutboundHandler(p.instance, c); err != nil {
+			errors.LogWarningInner(p.ctx, err, "failed to add subscription outbound ", tag)
+			continue
+		}
+		p.applied[tag] = c
+		added++
+	}
+	errors.LogInfo(p.ctx, "subscription ", p.config.Url, " updated, ", len(p.applied), " outbound(s), ", added, " added, ", removed, " removed")
+}
+
+func (s *Subscription) Type() interface{} {
+	return (*Subscription)(nil)
+}
+
+func (s *Subscription) Start() error {
+	for _, p := range s.providers {
+		go func() {
+			common.Must(p.task.Start())
+		}()
+	}
+	return nil
+}
+
+func (s *Subscription) Close() error {
+	var errs []error
+	for _, p := range s.providers {
+		if err := p.task.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Combine(errs...)
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return New(ctx, config.(*Config))
+	}))
+}
@@ -0,0 +1,90 @@
+package buf
+
+import (
+	"time"
+
+	"github.com/xtls/xray-core/common"
+)
+
+// TeeReader is a Reader that passes the MultiBuffers it reads to Tee before
+// returning them. End, if not nil, is called when the Reader ends, and may be
+// called more than once.
+type TeeReader struct {
+	Reader
+	Tee func(MultiBuffer)
+	End func()
+}
+
+func (r *TeeReader) end() {
+	if r.End != nil {
+		r.End()
+	}
+}
+
+// ReadMultiBuffer implements Reader.
+func (r *TeeReader) ReadMultiBuffer() (MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	if !mb.IsEmpty() {
+		r.Tee(mb)
+	}
+	if err != nil {
+		r.end()
+	}
+	return mb, err
+}
+
+// ReadMultiBufferTimeout implements TimeoutReader.
+func (r *TeeReader) ReadMultiBufferTimeout(timeout time.Duration) (MultiBuffer, error) {
+	reader, ok := r.Reader.(TimeoutReader)
+	if !ok {
+		return nil, ErrNotTimeoutReader
+	}
+	mb, err := reader.ReadMultiBufferTimeout(timeout)
+	if !mb.IsEmpty() {
+		r.Tee(mb)
+	}
+	if err != nil && err != ErrReadTimeout {
+		r.end()
+	}
+	return mb, err
+}
+
+// Interrupt implements common.Interruptible.
+func (r *TeeReader) Interrupt() {
+	common.Interrupt(r.Reader)
+	r.end()
+}
+
+// TeeWriter is a Writer that passes the MultiBuffers to Tee before writing
+// them. End, if not nil, is called when the Writer is closed or interrupted.
+type TeeWriter struct {
+	Writer
+	Tee func(MultiBuffer)
+	End func()
+}
+
+func (w *TeeWriter) end() {
+	if w.End != nil {
+		w.End()
+	}
+}
+
+// WriteMultiBuffer implements Writer.
+func (w *TeeWriter) WriteMultiBuffer(mb MultiBuffer) error {
+	if !mb.IsEmpty() {
+		w.Tee(mb)
+	}
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+// Close implements common.Closable.
+func (w *TeeWriter) Close() error {
+	w.end()
+	return common.Close(w.Writer)
+}
+
+// Interrupt implements common.Interruptible.
+func (w *TeeWriter) Interrupt() {
+	w.end()
+	common.Interrupt(w.Writer)
+}
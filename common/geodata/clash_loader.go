@@ -0,0 +1,161 @@
+package geodata
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/platform/filesystem"
+)
+
+// clashRuleSet holds the entries of a Clash rule-provider. The provider
+// behavior (domain, ipcidr or classical) is not stored in the file, so every
+// entry is classified on its own.
+type clashRuleSet struct {
+	domains []*Domain
+	cidrs   []*CIDR
+}
+
+func loadClashSite(file string) ([]*Domain, error) {
+	rs, err := loadClashRuleSet(file)
+	if err != nil {
+		return nil, err
+	}
+	return rs.domains, nil
+}
+
+func loadClashIP(file string) ([]*CIDR, error) {
+	rs, err := loadClashRuleSet(file)
+	if err != nil {
+		return nil, err
+	}
+	return rs.cidrs, nil
+}
+
+func loadClashRuleSet(file string) (*clashRuleSet, error) {
+	runtime.GC() // peak mem
+	f, err := filesystem.OpenAsset(file)
+	if err != nil {
+		return nil, errors.New("failed to open ", file).Base(err)
+	}
+	defer f.Close()
+	bs, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errors.New("failed to read ", file).Base(err)
+	}
+
+	var entries []string
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		var provider struct {
+			Payload []string `json:"payload"`
+		}
+		if err := yaml.Unmarshal(bs, &provider); err != nil {
+			return nil, errors.New("failed to parse Clash rule-provider ", file).Base(err)
+		}
+		entries = provider.Payload
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(bs))
+		for scanner.Scan() {
+			entries = append(entries, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, errors.New("failed to read ", file).Base(err)
+		}
+	}
+
+	rs := new(clashRuleSet)
+	skipped := 0
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" || strings.HasPrefix(e, "#") {
+			continue
+		}
+		if !rs.add(e) {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		errors.LogInfo(context.Background(), "ignore ", skipped, " unsupported Clash rule(s) in ", file)
+	}
+	return rs, nil
+}
+
+func (rs *clashRuleSet) add(entry string) bool {
+	if typ, rest, ok := strings.Cut(entry, ","); ok {
+		value, _, _ := strings.Cut(rest, ",")
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(strings.TrimSpace(typ)) {
+		case "DOMAIN":
+			rs.domains = append(rs.domains, &Domain{Type: Domain_Full, Value: value})
+		case "DOMAIN-SUFFIX":
+			rs.domains = append(rs.domains, &Domain{Type: Domain_Domain, Value: strings.TrimPrefix(value, ".")})
+		case "DOMAIN-KEYWORD":
+			rs.domains = append(rs.domains, &Domain{Type: Domain_Substr, Value: value})
+		case "DOMAIN-REGEX":
+			rs.domains = append(rs.domains, &Domain{Type: Domain_Regex, Value: value})
+		case "IP-CIDR", "IP-CIDR6":
+			cidr, err := parseCIDR(value)
+			if err != nil {
+				return false
+			}
+			rs.cidrs = append(rs.cidrs, cidr)
+		default:
+			return false
+		}
+		return true
+	}
+
+	if cidr, err := parseCIDR(entry); err == nil {
+		rs.cidrs = append(rs.cidrs, cidr)
+		return true
+	}
+	d := clashDomain(entry)
+	if d == nil {
+		return false
+	}
+	rs.domains = append(rs.domains, d)
+	return true
+}
+
+// clashDomain converts an entry of a domain behavior provider.
+// "+.example.com" matches the domain and its subdomains, ".example.com" only
+// its subdomains and "*" stands for exactly one label.
+func clashDomain(entry string) *Domain {
+	if rest, ok := strings.CutPrefix(entry, "+."); ok {
+		if rest == "" || strings.Contains(rest, "*") {
+			return nil
+		}
+		return &Domain{Type: Domain_Domain, Value: rest}
+	}
+	if !strings.Contains(entry, "*") {
+		if strings.HasPrefix(entry, ".") {
+			return suffixDomain(entry)
+		}
+		return &Domain{Type: Domain_Full, Value: entry}
+	}
+
+	subdomains := strings.HasPrefix(entry, ".")
+	labels := strings.Split(strings.TrimPrefix(entry, "."), ".")
+	for i, l := range labels {
+		if l == "*" {
+			labels[i] = `[^.]+`
+		} else {
+			labels[i] = regexp.QuoteMeta(l)
+		}
+	}
+	pattern := strings.Join(labels, `\.`) + "$"
+	if subdomains {
+		pattern = `\.` + pattern
+	} else {
+		pattern = "^" + pattern
+	}
+	return &Domain{Type: Domain_Regex, Value: pattern}
+}
@@ -46,6 +46,8 @@ func buildDomainRulesKey(rules []*DomainRule) string {
 			sb.WriteString(v.Geosite.Code)
 			sb.WriteString("@")
 			sb.WriteString(v.Geosite.Attrs)
+			sb.WriteString("#")
+			sb.WriteString(v.Geosite.Format.String())
 			sb.WriteString(",")
 		default:
 			panic("unknown domain rule type")
@@ -82,7 +84,7 @@ func (f *MphDomainMatcherFactory) BuildMatcher(rules []*DomainRule) (DomainMatch
 			}
 			g.Add(m, uint32(i))
 		case *DomainRule_Geosite:
-			domains, err := loadSiteWithAttrs(v.Geosite.File, v.Geosite.Code, v.Geosite.Attrs)
+			domains, err := loadSiteRule(v.Geosite)
 			if err != nil {
 				return nil, err
 			}
@@ -114,7 +116,7 @@ type CompactDomainMatcherFactory struct {
 }
 
 func (f *CompactDomainMatcherFactory) getOrCreateFrom(rule *GeoSiteRule) (strmatcher.MatcherSet, error) {
-	key := rule.File + ":" + rule.Code + "@" + rule.Attrs
+	key := rule.File + ":" + rule.Code + "@" + rule.Attrs + "#" + rule.Format.String()
 
 	f.Lock()
 	defer f.Unlock()
@@ -126,7 +128,7 @@ func (f *CompactDomainMatcherFactory) getOrCreateFrom(rule *GeoSiteRule) (strmat
 	errors.LogDebug(context.Background(), "geodata geosite matcher cache MISS ", key)
 
 	s := strmatcher.NewLinearAnyMatcher()
-	domains, err := loadSiteWithAttrs(rule.File, rule.Code, rule.Attrs)
+	domains, err := loadSiteRule(rule)
 	if err != nil {
 		return nil, err
 	}
@@ -21,6 +21,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Format of the file referenced by a GeoSiteRule or GeoIPRule.
+type RuleSetFormat int32
+
+const (
+	// Xray geosite.dat / geoip.dat, entries selected by code.
+	RuleSetFormat_Dat RuleSetFormat = 0
+	// sing-box binary rule-set (.srs).
+	RuleSetFormat_SingBox RuleSetFormat = 1
+	// Clash rule-provider, YAML payload or plain text.
+	RuleSetFormat_Clash RuleSetFormat = 2
+)
+
+// Enum value maps for RuleSetFormat.
+var (
+	RuleSetFormat_name = map[int32]string{
+		0: "Dat",
+		1: "SingBox",
+		2: "Clash",
+	}
+	RuleSetFormat_value = map[string]int32{
+		"Dat":     0,
+		"SingBox": 1,
+		"Clash":   2,
+	}
+)
+
+func (x RuleSetFormat) Enum() *RuleSetFormat {
+	p := new(RuleSetFormat)
+	*p = x
+	return p
+}
+
+func (x RuleSetFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RuleSetFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_geodata_geodat_proto_enumTypes[0].Descriptor()
+}
+
+func (RuleSetFormat) Type() protoreflect.EnumType {
+	return &file_common_geodata_geodat_proto_enumTypes[0]
+}
+
+func (x RuleSetFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RuleSetFormat.Descriptor instead.
+func (RuleSetFormat) EnumDescriptor() ([]byte, []int) {
+	return file_common_geodata_geodat_proto_rawDescGZIP(), []int{0}
+}
+
 // Type of domain value.
 type Domain_Type int32
 
@@ -62,11 +115,11 @@ func (x Domain_Type) String() string {
 }
 
 func (Domain_Type) Descriptor() protoreflect.EnumDescriptor {
-	return file_common_geodata_geodat_proto_enumTypes[0].Descriptor()
+	return file_common_geodata_geodat_proto_enumTypes[1].Descriptor()
 }
 
 func (Domain_Type) Type() protoreflect.EnumType {
-	return &file_common_geodata_geodat_proto_enumTypes[0]
+	return &file_common_geodata_geodat_proto_enumTypes[1]
 }
 
 func (x Domain_Type) Number() protoreflect.EnumNumber {
@@ -242,6 +295,7 @@ type GeoSiteRule struct {
 	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
 	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
 	Attrs         string                 `protobuf:"bytes,3,opt,name=attrs,proto3" json:"attrs,omitempty"`
+	Format        RuleSetFormat          `protobuf:"varint,4,opt,name=format,proto3,enum=xray.common.geodata.RuleSetFormat" json:"format,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -297,6 +351,13 @@ func (x *GeoSiteRule) GetAttrs() string {
 	return ""
 }
 
+func (x *GeoSiteRule) GetFormat() RuleSetFormat {
+	if x != nil {
+		return x.Format
+	}
+	return RuleSetFormat_Dat
+}
+
 type DomainRule struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Value:
@@ -594,6 +655,7 @@ type GeoIPRule struct {
 	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
 	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
 	ReverseMatch  bool                   `protobuf:"varint,3,opt,name=reverse_match,json=reverseMatch,proto3" json:"reverse_match,omitempty"`
+	Format        RuleSetFormat          `protobuf:"varint,4,opt,name=format,proto3,enum=xray.common.geodata.RuleSetFormat" json:"format,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -649,6 +711,13 @@ func (x *GeoIPRule) GetReverseMatch() bool {
 	return false
 }
 
+func (x *GeoIPRule) GetFormat() RuleSetFormat {
+	if x != nil {
+		return x.Format
+	}
+	return RuleSetFormat_Dat
+}
+
 type IPRule struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Value:
@@ -847,11 +916,12 @@ const file_common_geodata_geodat_proto_rawDesc = "" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x123\n" +
 	"\x06domain\x18\x02 \x03(\v2\x1b.xray.common.geodata.DomainR\x06domain\"A\n" +
 	"\vGeoSiteList\x122\n" +
-	"\x05entry\x18\x01 \x03(\v2\x1c.xray.common.geodata.GeoSiteR\x05entry\"K\n" +
+	"\x05entry\x18\x01 \x03(\v2\x1c.xray.common.geodata.GeoSiteR\x05entry\"\x87\x01\n" +
 	"\vGeoSiteRule\x12\x12\n" +
 	"\x04file\x18\x01 \x01(\tR\x04file\x12\x12\n" +
 	"\x04code\x18\x02 \x01(\tR\x04code\x12\x14\n" +
-	"\x05attrs\x18\x03 \x01(\tR\x05attrs\"\x8a\x01\n" +
+	"\x05attrs\x18\x03 \x01(\tR\x05attrs\x12:\n" +
+	"\x06format\x18\x04 \x01(\x0e2\".xray.common.geodata.RuleSetFormatR\x06format\"\x8a\x01\n" +
 	"\n" +
 	"DomainRule\x12<\n" +
 	"\ageosite\x18\x01 \x01(\v2 .xray.common.geodata.GeoSiteRuleH\x00R\ageosite\x125\n" +
@@ -868,15 +938,20 @@ const file_common_geodata_geodat_proto_rawDesc = "" +
 	"\x04cidr\x18\x02 \x03(\v2\x19.xray.common.geodata.CIDRR\x04cidr\x12#\n" +
 	"\rreverse_match\x18\x03 \x01(\bR\freverseMatch\"=\n" +
 	"\tGeoIPList\x120\n" +
-	"\x05entry\x18\x01 \x03(\v2\x1a.xray.common.geodata.GeoIPR\x05entry\"X\n" +
+	"\x05entry\x18\x01 \x03(\v2\x1a.xray.common.geodata.GeoIPR\x05entry\"\x94\x01\n" +
 	"\tGeoIPRule\x12\x12\n" +
 	"\x04file\x18\x01 \x01(\tR\x04file\x12\x12\n" +
 	"\x04code\x18\x02 \x01(\tR\x04code\x12#\n" +
-	"\rreverse_match\x18\x03 \x01(\bR\freverseMatch\"\x82\x01\n" +
+	"\rreverse_match\x18\x03 \x01(\bR\freverseMatch\x12:\n" +
+	"\x06format\x18\x04 \x01(\x0e2\".xray.common.geodata.RuleSetFormatR\x06format\"\x82\x01\n" +
 	"\x06IPRule\x126\n" +
 	"\x05geoip\x18\x01 \x01(\v2\x1e.xray.common.geodata.GeoIPRuleH\x00R\x05geoip\x127\n" +
 	"\x06custom\x18\x02 \x01(\v2\x1d.xray.common.geodata.CIDRRuleH\x00R\x06customB\a\n" +
-	"\x05valueB[\n" +
+	"\x05value*0\n" +
+	"\rRuleSetFormat\x12\a\n" +
+	"\x03Dat\x10\x00\x12\v\n" +
+	"\aSingBox\x10\x01\x12\t\n" +
+	"\x05Clash\x10\x02B[\n" +
 	"\x17com.xray.common.geodataP\x01Z(github.com/xtls/xray-core/common/geodata\xaa\x02\x13Xray.Common.Geodatab\x06proto3"
 
 var (
@@ -891,40 +966,43 @@ func file_common_geodata_geodat_proto_rawDescGZIP() []byte {
 	return file_common_geodata_geodat_proto_rawDescData
 }
 
-var file_common_geodata_geodat_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_common_geodata_geodat_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
 var file_common_geodata_geodat_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_common_geodata_geodat_proto_goTypes = []any{
-	(Domain_Type)(0),         // 0: xray.common.geodata.Domain.Type
-	(*Domain)(nil),           // 1: xray.common.geodata.Domain
-	(*GeoSite)(nil),          // 2: xray.common.geodata.GeoSite
-	(*GeoSiteList)(nil),      // 3: xray.common.geodata.GeoSiteList
-	(*GeoSiteRule)(nil),      // 4: xray.common.geodata.GeoSiteRule
-	(*DomainRule)(nil),       // 5: xray.common.geodata.DomainRule
-	(*CIDR)(nil),             // 6: xray.common.geodata.CIDR
-	(*CIDRRule)(nil),         // 7: xray.common.geodata.CIDRRule
-	(*GeoIP)(nil),            // 8: xray.common.geodata.GeoIP
-	(*GeoIPList)(nil),        // 9: xray.common.geodata.GeoIPList
-	(*GeoIPRule)(nil),        // 10: xray.common.geodata.GeoIPRule
-	(*IPRule)(nil),           // 11: xray.common.geodata.IPRule
-	(*Domain_Attribute)(nil), // 12: xray.common.geodata.Domain.Attribute
+	(RuleSetFormat)(0),       // 0: xray.common.geodata.RuleSetFormat
+	(Domain_Type)(0),         // 1: xray.common.geodata.Domain.Type
+	(*Domain)(nil),           // 2: xray.common.geodata.Domain
+	(*GeoSite)(nil),          // 3: xray.common.geodata.GeoSite
+	(*GeoSiteList)(nil),      // 4: xray.common.geodata.GeoSiteList
+	(*GeoSiteRule)(nil),      // 5: xray.common.geodata.GeoSiteRule
+	(*DomainRule)(nil),       // 6: xray.common.geodata.DomainRule
+	(*CIDR)(nil),             // 7: xray.common.geodata.CIDR
+	(*CIDRRule)(nil),         // 8: xray.common.geodata.CIDRRule
+	(*GeoIP)(nil),            // 9: xray.common.geodata.GeoIP
+	(*GeoIPList)(nil),        // 10: xray.common.geodata.GeoIPList
+	(*GeoIPRule)(nil),        // 11: xray.common.geodata.GeoIPRule
+	(*IPRule)(nil),           // 12: xray.common.geodata.IPRule
+	(*Domain_Attribute)(nil), // 13: xray.common.geodata.Domain.Attribute
 }
 var file_common_geodata_geodat_proto_depIdxs = []int32{
-	0,  // 0: xray.common.geodata.Domain.type:type_name -> xray.common.geodata.Domain.Type
-	12, // 1: xray.common.geodata.Domain.attribute:type_name -> xray.common.geodata.Domain.Attribute
-	1,  // 2: xray.common.geodata.GeoSite.domain:type_name -> xray.common.geodata.Domain
-	2,  // 3: xray.common.geodata.GeoSiteList.entry:type_name -> xray.common.geodata.GeoSite
-	4,  // 4: xray.common.geodata.DomainRule.geosite:type_name -> xray.common.geodata.GeoSiteRule
-	1,  // 5: xray.common.geodata.DomainRule.custom:type_name -> xray.common.geodata.Domain
-	6,  // 6: xray.common.geodata.CIDRRule.cidr:type_name -> xray.common.geodata.CIDR
-	6,  // 7: xray.common.geodata.GeoIP.cidr:type_name -> xray.common.geodata.CIDR
-	8,  // 8: xray.common.geodata.GeoIPList.entry:type_name -> xray.common.geodata.GeoIP
-	10, // 9: xray.common.geodata.IPRule.geoip:type_name -> xray.common.geodata.GeoIPRule
-	7,  // 10: xray.common.geodata.IPRule.custom:type_name -> xray.common.geodata.CIDRRule
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	1,  // 0: xray.common.geodata.Domain.type:type_name -> xray.common.geodata.Domain.Type
+	13, // 1: xray.common.geodata.Domain.attribute:type_name -> xray.common.geodata.Domain.Attribute
+	2,  // 2: xray.common.geodata.GeoSite.domain:type_name -> xray.common.geodata.Domain
+	3,  // 3: xray.common.geodata.GeoSiteList.entry:type_name -> xray.common.geodata.GeoSite
+	0,  // 4: xray.common.geodata.GeoSiteRule.format:type_name -> xray.common.geodata.RuleSetFormat
+	5,  // 5: xray.common.geodata.DomainRule.geosite:type_name -> xray.common.geodata.GeoSiteRule
+	2,  // 6: xray.common.geodata.DomainRule.custom:type_name -> xray.common.geodata.Domain
+	7,  // 7: xray.common.geodata.CIDRRule.cidr:type_name -> xray.common.geodata.CIDR
+	7,  // 8: xray.common.geodata.GeoIP.cidr:type_name -> xray.common.geodata.CIDR
+	9,  // 9: xray.common.geodata.GeoIPList.entry:type_name -> xray.common.geodata.GeoIP
+	0,  // 10: xray.common.geodata.GeoIPRule.format:type_name -> xray.common.geodata.RuleSetFormat
+	11, // 11: xray.common.geodata.IPRule.geoip:type_name -> xray.common.geodata.GeoIPRule
+	8,  // 12: xray.common.geodata.IPRule.custom:type_name -> xray.common.geodata.CIDRRule
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_common_geodata_geodat_proto_init() }
@@ -949,7 +1027,7 @@ func file_common_geodata_geodat_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_geodata_geodat_proto_rawDesc), len(file_common_geodata_geodat_proto_rawDesc)),
-			NumEnums:      1,
+			NumEnums:      2,
 			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   0,
@@ -25,6 +25,19 @@ func checkFile(file, code string) error {
 	return nil
 }
 
+func checkRuleSet(file string, format RuleSetFormat) error {
+	var err error
+	switch format {
+	case RuleSetFormat_SingBox:
+		_, err = loadSingBoxRules(file)
+	case RuleSetFormat_Clash:
+		_, err = loadClashRuleSet(file)
+	default:
+		err = errors.New("unknown rule-set format ", format)
+	}
+	return err
+}
+
 func loadFile(file, code string) ([]byte, error) {
 	runtime.GC() // peak mem
 	r, err := filesystem.OpenAsset(file)
@@ -52,6 +65,17 @@ func loadIP(file, code string) ([]*CIDR, error) {
 	return geoip.Cidr, nil
 }
 
+func loadIPRule(rule *GeoIPRule) ([]*CIDR, error) {
+	switch rule.Format {
+	case RuleSetFormat_SingBox:
+		return loadSingBoxIP(rule.File)
+	case RuleSetFormat_Clash:
+		return loadClashIP(rule.File)
+	default:
+		return loadIP(rule.File, rule.Code)
+	}
+}
+
 func loadSite(file, code string) ([]*Domain, error) {
 	bs, err := loadFile(file, code)
 	if err != nil {
@@ -205,3 +229,14 @@ func loadSiteWithAttrs(file, code, attrs string) ([]*Domain, error) {
 
 	return filtered, nil
 }
+
+func loadSiteRule(rule *GeoSiteRule) ([]*Domain, error) {
+	switch rule.Format {
+	case RuleSetFormat_SingBox:
+		return loadSingBoxSite(rule.File)
+	case RuleSetFormat_Clash:
+		return loadClashSite(rule.File)
+	default:
+		return loadSiteWithAttrs(rule.File, rule.Code, rule.Attrs)
+	}
+}
@@ -824,7 +824,7 @@ func (f *IPSetFactory) GetOrCreateFromGeoIPRules(rules []*GeoIPRule) (*IPSet, er
 
 	ipset, err := f.createFrom(func(add func(*CIDR)) error {
 		for _, r := range rules {
-			cidrs, err := loadIP(r.File, r.Code)
+			cidrs, err := loadIPRule(r)
 			if err != nil {
 				return err
 			}
@@ -849,6 +849,9 @@ func buildGeoIPRulesKey(rules []*GeoIPRule) string {
 		if ri.File != rj.File {
 			return ri.File < rj.File
 		}
+		if ri.Format != rj.Format {
+			return ri.Format < rj.Format
+		}
 		return ri.Code < rj.Code
 	})
 
@@ -856,11 +859,13 @@ func buildGeoIPRulesKey(rules []*GeoIPRule) string {
 	sb.Grow(len(rules) * 20) // geoip.dat:xx,
 	var last *GeoIPRule
 	for i, r := range rules {
-		if i == 0 || (r.File != last.File || r.Code != last.Code) {
+		if i == 0 || (r.File != last.File || r.Format != last.Format || r.Code != last.Code) {
 			last = r
 			sb.WriteString(r.File)
 			sb.WriteString(":")
 			sb.WriteString(r.Code)
+			sb.WriteString("#")
+			sb.WriteString(r.Format.String())
 			sb.WriteString(",")
 		}
 	}
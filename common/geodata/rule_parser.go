@@ -33,7 +33,9 @@ func ParseIPRules(rules []string) ([]*IPRule, error) {
 
 		var rule isIPRule_Value
 		var err error
-		if prefix > 0 {
+		if file, format, ok := cutRuleSetPrefix(r); ok {
+			rule, err = parseRuleSetIPRule(file, format, reverse)
+		} else if prefix > 0 {
 			rule, err = parseGeoIPRule(r[prefix:], reverse)
 		} else {
 			rule, err = parseCustomIPRule(r, reverse)
@@ -56,6 +58,36 @@ func cutReversePrefix(s string) (string, bool) {
 	return s, reverse
 }
 
+// cutRuleSetPrefix recognizes rules referencing a whole third-party rule-set
+// file, "srs:" for sing-box and "clash:" for Clash rule-providers.
+func cutRuleSetPrefix(s string) (string, RuleSetFormat, bool) {
+	if file, ok := strings.CutPrefix(s, "srs:"); ok {
+		return file, RuleSetFormat_SingBox, true
+	}
+	if file, ok := strings.CutPrefix(s, "clash:"); ok {
+		return file, RuleSetFormat_Clash, true
+	}
+	return "", RuleSetFormat_Dat, false
+}
+
+func parseRuleSetIPRule(file string, format RuleSetFormat, reverse bool) (*IPRule_Geoip, error) {
+	if file == "" {
+		return nil, errors.New("empty file")
+	}
+
+	if err := checkRuleSet(file, format); err != nil {
+		return nil, err
+	}
+
+	return &IPRule_Geoip{
+		Geoip: &GeoIPRule{
+			File:         file,
+			ReverseMatch: reverse,
+			Format:       format,
+		},
+	}, nil
+}
+
 func parseGeoIPRule(rule string, reverse bool) (*IPRule_Geoip, error) {
 	file, code, ok := strings.Cut(rule, ":")
 	if !ok {
@@ -147,7 +179,9 @@ func ParseDomainRule(r string, defaultType Domain_Type) (*DomainRule, error) {
 
 	var rule isDomainRule_Value
 	var err error
-	if prefix > 0 {
+	if file, format, ok := cutRuleSetPrefix(r); ok {
+		rule, err = parseRuleSetSiteRule(file, format)
+	} else if prefix > 0 {
 		rule, err = parseGeoSiteRule(r[prefix:])
 	} else {
 		rule, err = parseCustomDomainRule(r, defaultType)
@@ -176,7 +210,9 @@ func ParseDomainRules(rules []string, defaultType Domain_Type) ([]*DomainRule, e
 
 		var rule isDomainRule_Value
 		var err error
-		if prefix > 0 {
+		if file, format, ok := cutRuleSetPrefix(r); ok {
+			rule, err = parseRuleSetSiteRule(file, format)
+		} else if prefix > 0 {
 			rule, err = parseGeoSiteRule(r[prefix:])
 		} else {
 			rule, err = parseCustomDomainRule(r, defaultType)
@@ -223,6 +259,23 @@ func parseGeoSiteRule(rule string) (*DomainRule_Geosite, error) {
 	}, nil
 }
 
+func parseRuleSetSiteRule(file string, format RuleSetFormat) (*DomainRule_Geosite, error) {
+	if file == "" {
+		return nil, errors.New("empty file")
+	}
+
+	if err := checkRuleSet(file, format); err != nil {
+		return nil, err
+	}
+
+	return &DomainRule_Geosite{
+		Geosite: &GeoSiteRule{
+			File:   file,
+			Format: format,
+		},
+	}, nil
+}
+
 func parseCustomDomainRule(rule string, defaultType Domain_Type) (*DomainRule_Custom, error) {
 	domain := new(Domain)
 
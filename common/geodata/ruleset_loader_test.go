@@ -0,0 +1,224 @@
+package geodata_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sagernet/sing/common/domain"
+	"github.com/sagernet/sing/common/varbin"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/geodata"
+	"github.com/xtls/xray-core/common/net"
+)
+
+func writeSingBoxRuleSet(t *testing.T, path string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := bufio.NewWriter(&body)
+	putUvarint := func(v uint64) {
+		common.Must2(w.Write(binary.AppendUvarint(nil, v)))
+	}
+
+	putUvarint(3)
+
+	// domain, domain_suffix and domain_keyword
+	common.Must(w.WriteByte(0))
+	common.Must(w.WriteByte(2))
+	common.Must(domain.NewMatcher([]string{"full.example.com"}, []string{"google.com", ".sub.example.org"}, false).Write(w))
+	common.Must(w.WriteByte(3))
+	common.Must(varbin.Write(w, binary.BigEndian, []string{"keyword"}))
+	common.Must(w.WriteByte(0xFF))
+	common.Must(w.WriteByte(0))
+
+	// ip_cidr 10.0.0.0 - 10.0.1.255
+	common.Must(w.WriteByte(0))
+	common.Must(w.WriteByte(6))
+	common.Must(w.WriteByte(1))
+	common.Must(binary.Write(w, binary.BigEndian, uint64(1)))
+	for _, ip := range []string{"10.0.0.0", "10.0.1.255"} {
+		b := netip.MustParseAddr(ip).AsSlice()
+		putUvarint(uint64(len(b)))
+		common.Must2(w.Write(b))
+	}
+	common.Must(w.WriteByte(0xFF))
+	common.Must(w.WriteByte(0))
+
+	// domain_suffix with port, not expressible and skipped
+	common.Must(w.WriteByte(0))
+	common.Must(w.WriteByte(2))
+	common.Must(domain.NewMatcher(nil, []string{"skipped.com"}, false).Write(w))
+	common.Must(w.WriteByte(9))
+	common.Must(varbin.Write(w, binary.BigEndian, []uint16{443}))
+	common.Must(w.WriteByte(0xFF))
+	common.Must(w.WriteByte(0))
+	common.Must(w.Flush())
+
+	var out bytes.Buffer
+	out.WriteString("SRS")
+	out.WriteByte(2)
+	zw := zlib.NewWriter(&out)
+	common.Must2(zw.Write(body.Bytes()))
+	common.Must(zw.Close())
+	common.Must(os.WriteFile(path, out.Bytes(), 0o644))
+}
+
+func TestSingBoxRuleSet(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("xray.location.asset", dir)
+	writeSingBoxRuleSet(t, filepath.Join(dir, "rules.srs"))
+
+	domainRules, err := geodata.ParseDomainRules([]string{"srs:rules.srs"}, geodata.Domain_Substr)
+	if err != nil {
+		t.Fatalf("Failed to parse domain rules, got %s", err)
+	}
+	dm, err := geodata.DomainReg.BuildDomainMatcher(domainRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"full.example.com":     true,
+		"www.full.example.com": false,
+		"google.com":           true,
+		"www.google.com":       true,
+		"a.sub.example.org":    true,
+		"sub.example.org":      false,
+		"my-keyword.net":       true,
+		"skipped.com":          false,
+	} {
+		if got := dm.MatchAny(name); got != want {
+			t.Errorf("MatchAny(%s) = %t, want %t", name, got, want)
+		}
+	}
+
+	ipRules, err := geodata.ParseIPRules([]string{"srs:rules.srs"})
+	if err != nil {
+		t.Fatalf("Failed to parse ip rules, got %s", err)
+	}
+	im, err := geodata.IPReg.BuildIPMatcher(ipRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, want := range map[string]bool{
+		"10.0.0.1":   true,
+		"10.0.1.254": true,
+		"10.0.2.1":   false,
+	} {
+		if got := im.Match(net.ParseIP(ip)); got != want {
+			t.Errorf("Match(%s) = %t, want %t", ip, got, want)
+		}
+	}
+}
+
+func TestClashRuleProvider(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("xray.location.asset", dir)
+	common.Must(os.WriteFile(filepath.Join(dir, "classical.yaml"), []byte(`payload:
+  - DOMAIN,full.example.com
+  - DOMAIN-SUFFIX,google.com
+  - DOMAIN-KEYWORD,keyword
+  - IP-CIDR,10.0.0.0/24,no-resolve
+  - IP-CIDR6,2001:db8::/32
+  - PROCESS-NAME,curl
+`), 0o644))
+	common.Must(os.WriteFile(filepath.Join(dir, "domain.txt"), []byte(`# comment
++.example.org
+.sub.example.net
+*.wild.example.com
+plain.example.com
+`), 0o644))
+
+	domainRules, err := geodata.ParseDomainRules([]string{"clash:classical.yaml", "clash:domain.txt"}, geodata.Domain_Substr)
+	if err != nil {
+		t.Fatalf("Failed to parse domain rules, got %s", err)
+	}
+	dm, err := geodata.DomainReg.BuildDomainMatcher(domainRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"full.example.com":      true,
+		"www.google.com":        true,
+		"my-keyword.net":        true,
+		"example.org":           true,
+		"www.example.org":       true,
+		"sub.example.net":       false,
+		"a.b.sub.example.net":   true,
+		"a.wild.example.com":    true,
+		"a.b.wild.example.com":  false,
+		"plain.example.com":     true,
+		"www.plain.example.com": false,
+		"unrelated.example.com": false,
+	} {
+		if got := dm.MatchAny(name); got != want {
+			t.Errorf("MatchAny(%s) = %t, want %t", name, got, want)
+		}
+	}
+
+	ipRules, err := geodata.ParseIPRules([]string{"!clash:classical.yaml"})
+	if err != nil {
+		t.Fatalf("Failed to parse ip rules, got %s", err)
+	}
+	im, err := geodata.IPReg.BuildIPMatcher(ipRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, want := range map[string]bool{
+		"10.0.0.1":    false,
+		"2001:db8::1": false,
+		"10.0.1.1":    true,
+	} {
+		if got := im.Match(net.ParseIP(ip)); got != want {
+			t.Errorf("Match(%s) = %t, want %t", ip, got, want)
+		}
+	}
+
+	if _, err := geodata.ParseDomainRules([]string{"clash:missing.yaml"}, geodata.Domain_Substr); err == nil {
+		t.Fatal("expected error for missing rule-provider")
+	}
+}
+
+func TestRuleSetCacheKeyIncludesFormat(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("xray.location.asset", dir)
+	writeSingBoxRuleSet(t, filepath.Join(dir, "rules"))
+
+	build := func(rule string) (geodata.DomainMatcher, geodata.IPMatcher) {
+		domainRules, err := geodata.ParseDomainRules([]string{rule}, geodata.Domain_Substr)
+		if err != nil {
+			return nil, nil
+		}
+		dm, _ := geodata.DomainReg.BuildDomainMatcher(domainRules)
+		ipRules, err := geodata.ParseIPRules([]string{rule})
+		if err != nil {
+			return dm, nil
+		}
+		im, _ := geodata.IPReg.BuildIPMatcher(ipRules)
+		return dm, im
+	}
+
+	srsDomain, srsIP := build("srs:rules")
+	if srsDomain == nil || !srsDomain.MatchAny("google.com") || srsIP == nil || !srsIP.Match(net.ParseIP("10.0.0.1")) {
+		t.Fatal("failed to load sing-box rule-set")
+	}
+	// The cache only holds the matchers in use.
+	defer runtime.KeepAlive(srsDomain)
+	defer runtime.KeepAlive(srsIP)
+
+	// The same file as a Clash rule-provider must not be served from the
+	// cache of the sing-box rule-set.
+	dm, im := build("clash:rules")
+	if dm != nil && dm.MatchAny("google.com") {
+		t.Error("domain matcher of the sing-box rule-set reused for the Clash rule-provider")
+	}
+	if im != nil && im.Match(net.ParseIP("10.0.0.1")) {
+		t.Error("ip matcher of the sing-box rule-set reused for the Clash rule-provider")
+	}
+}
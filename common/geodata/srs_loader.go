@@ -0,0 +1,364 @@
+package geodata
+
+import (
+	"bufio"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/netip"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sagernet/sing/common/domain"
+	"github.com/sagernet/sing/common/varbin"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/platform/filesystem"
+	"go4.org/netipx"
+)
+
+// sing-box binary rule-set layout, see sing-box common/srs.
+var srsMagic = [3]byte{'S', 'R', 'S'}
+
+const srsMaxVersion = 4
+
+const (
+	srsRuleDefault uint8 = 0
+	srsRuleLogical uint8 = 1
+)
+
+const (
+	srsItemQueryType uint8 = iota
+	srsItemNetwork
+	srsItemDomain
+	srsItemDomainKeyword
+	srsItemDomainRegex
+	srsItemSourceIPCIDR
+	srsItemIPCIDR
+	srsItemSourcePort
+	srsItemSourcePortRange
+	srsItemPort
+	srsItemPortRange
+	srsItemProcessName
+	srsItemProcessPath
+	srsItemPackageName
+	srsItemWIFISSID
+	srsItemWIFIBSSID
+	srsItemAdGuardDomain
+	srsItemProcessPathRegex
+	srsItemNetworkType
+	srsItemNetworkIsExpensive
+	srsItemNetworkIsConstrained
+	srsItemFinal uint8 = 0xFF
+)
+
+// srsRule keeps the destination address items of a sing-box headless rule.
+// Within a rule they are ORed, which maps onto an Xray domain or IP list.
+type srsRule struct {
+	domains []*Domain
+	cidrs   []*CIDR
+	// other is set when the rule also constrains anything Xray cannot
+	// express in a domain or IP list, such as ports or process names.
+	other  bool
+	invert bool
+}
+
+func loadSingBoxSite(file string) ([]*Domain, error) {
+	rules, err := loadSingBoxRules(file)
+	if err != nil {
+		return nil, err
+	}
+	defer runtime.GC() // peak mem
+	var domains []*Domain
+	for _, r := range rules {
+		domains = append(domains, r.domains...)
+	}
+	return domains, nil
+}
+
+func loadSingBoxIP(file string) ([]*CIDR, error) {
+	rules, err := loadSingBoxRules(file)
+	if err != nil {
+		return nil, err
+	}
+	defer runtime.GC() // peak mem
+	var cidrs []*CIDR
+	for _, r := range rules {
+		cidrs = append(cidrs, r.cidrs...)
+	}
+	return cidrs, nil
+}
+
+func loadSingBoxRules(file string) ([]*srsRule, error) {
+	runtime.GC() // peak mem
+	f, err := filesystem.OpenAsset(file)
+	if err != nil {
+		return nil, errors.New("failed to open ", file).Base(err)
+	}
+	defer f.Close()
+	rules, err := readSingBoxRuleSet(f)
+	if err != nil {
+		return nil, errors.New("failed to read sing-box rule-set ", file).Base(err)
+	}
+
+	usable := rules[:0]
+	skipped := 0
+	for _, r := range rules {
+		if r.other || r.invert {
+			skipped++
+			continue
+		}
+		usable = append(usable, r)
+	}
+	if skipped > 0 {
+		errors.LogInfo(context.Background(), "ignore ", skipped, " sing-box rule(s) with unsupported conditions in ", file)
+	}
+	return usable, nil
+}
+
+func readSingBoxRuleSet(r io.Reader) ([]*srsRule, error) {
+	var magic [3]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	if magic != srsMagic {
+		return nil, errors.New("invalid rule-set magic")
+	}
+	var version [1]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return nil, err
+	}
+	if version[0] == 0 || version[0] > srsMaxVersion {
+		return nil, errors.New("unsupported rule-set version ", version[0])
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*srsRule
+	for i := uint64(0); i < count; i++ {
+		rule, err := readSingBoxRule(br)
+		if err != nil {
+			return nil, errors.New("failed to read rule ", i).Base(err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func readSingBoxRule(br *bufio.Reader) (*srsRule, error) {
+	ruleType, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch ruleType {
+	case srsRuleDefault:
+		return readSingBoxDefaultRule(br)
+	case srsRuleLogical:
+		// AND/OR combinations cannot be flattened into a plain list,
+		// so read them only to skip over their bytes.
+		if _, err := br.ReadByte(); err != nil {
+			return nil, err
+		}
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < count; i++ {
+			if _, err := readSingBoxRule(br); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := br.ReadByte(); err != nil {
+			return nil, err
+		}
+		return &srsRule{other: true}, nil
+	default:
+		return nil, errors.New("unknown rule type ", ruleType)
+	}
+}
+
+func readSingBoxDefaultRule(br *bufio.Reader) (*srsRule, error) {
+	rule := new(srsRule)
+	for {
+		itemType, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch itemType {
+		case srsItemDomain:
+			matcher, err := domain.ReadMatcher(br)
+			if err != nil {
+				return nil, err
+			}
+			full, suffix := matcher.Dump()
+			for _, d := range full {
+				rule.domains = append(rule.domains, &Domain{Type: Domain_Full, Value: d})
+			}
+			for _, d := range suffix {
+				rule.domains = append(rule.domains, suffixDomain(d))
+			}
+		case srsItemDomainKeyword:
+			values, err := varbin.ReadValue[[]string](br, binary.BigEndian)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range values {
+				rule.domains = append(rule.domains, &Domain{Type: Domain_Substr, Value: v})
+			}
+		case srsItemDomainRegex:
+			values, err := varbin.ReadValue[[]string](br, binary.BigEndian)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range values {
+				rule.domains = append(rule.domains, &Domain{Type: Domain_Regex, Value: v})
+			}
+		case srsItemAdGuardDomain:
+			matcher, err := domain.ReadAdGuardMatcher(br)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range matcher.Dump() {
+				if d := adGuardDomain(line); d != nil {
+					rule.domains = append(rule.domains, d)
+				} else {
+					errors.LogDebug(context.Background(), "ignore unsupported AdGuard rule ", line)
+				}
+			}
+		case srsItemIPCIDR:
+			cidrs, err := readSingBoxIPSet(br)
+			if err != nil {
+				return nil, err
+			}
+			rule.cidrs = append(rule.cidrs, cidrs...)
+		case srsItemSourceIPCIDR:
+			if _, err := readSingBoxIPSet(br); err != nil {
+				return nil, err
+			}
+			rule.other = true
+		case srsItemQueryType, srsItemSourcePort, srsItemPort:
+			if _, err := varbin.ReadValue[[]uint16](br, binary.BigEndian); err != nil {
+				return nil, err
+			}
+			rule.other = true
+		case srsItemNetwork, srsItemSourcePortRange, srsItemPortRange, srsItemProcessName,
+			srsItemProcessPath, srsItemPackageName, srsItemWIFISSID, srsItemWIFIBSSID, srsItemProcessPathRegex:
+			if _, err := varbin.ReadValue[[]string](br, binary.BigEndian); err != nil {
+				return nil, err
+			}
+			rule.other = true
+		case srsItemNetworkType:
+			if _, err := varbin.ReadValue[[]uint8](br, binary.BigEndian); err != nil {
+				return nil, err
+			}
+			rule.other = true
+		case srsItemNetworkIsExpensive, srsItemNetworkIsConstrained:
+			rule.other = true
+		case srsItemFinal:
+			invert, err := br.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			rule.invert = invert != 0
+			return rule, nil
+		default:
+			return nil, errors.New("unsupported rule item type ", itemType)
+		}
+	}
+}
+
+func readSingBoxIPSet(br *bufio.Reader) ([]*CIDR, error) {
+	version, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != 1 {
+		return nil, errors.New("unsupported ip set version ", version)
+	}
+	var count uint64
+	if err := binary.Read(br, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	var cidrs []*CIDR
+	for i := uint64(0); i < count; i++ {
+		from, err := readSingBoxAddr(br)
+		if err != nil {
+			return nil, err
+		}
+		to, err := readSingBoxAddr(br)
+		if err != nil {
+			return nil, err
+		}
+		r := netipx.IPRangeFrom(from, to)
+		if !r.IsValid() {
+			return nil, errors.New("invalid ip range ", from, "-", to)
+		}
+		for _, p := range r.Prefixes() {
+			cidrs = append(cidrs, &CIDR{
+				Ip:     p.Addr().AsSlice(),
+				Prefix: uint32(p.Bits()),
+			})
+		}
+	}
+	return cidrs, nil
+}
+
+func readSingBoxAddr(br *bufio.Reader) (netip.Addr, error) {
+	l, err := binary.ReadUvarint(br)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if l > 16 {
+		return netip.Addr{}, errors.New("invalid address length ", l)
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return netip.Addr{}, err
+	}
+	var addr netip.Addr
+	if err := addr.UnmarshalBinary(b); err != nil {
+		return netip.Addr{}, err
+	}
+	return addr, nil
+}
+
+// suffixDomain converts a sing-box domain suffix. A leading dot only
+// matches subdomains, which Xray can only express as a regular expression.
+func suffixDomain(suffix string) *Domain {
+	if rest, ok := strings.CutPrefix(suffix, "."); ok {
+		return &Domain{Type: Domain_Regex, Value: `\.` + regexp.QuoteMeta(rest) + `$`}
+	}
+	return &Domain{Type: Domain_Domain, Value: suffix}
+}
+
+// adGuardDomain converts the plain domain forms of AdGuard filter lines,
+// "||example.com^" and "|example.com^", and returns nil for anything else.
+func adGuardDomain(line string) *Domain {
+	value, ok := strings.CutSuffix(line, "^")
+	if !ok {
+		return nil
+	}
+	typ := Domain_Full
+	if v, ok := strings.CutPrefix(value, "||"); ok {
+		value = v
+		typ = Domain_Domain
+	} else if v, ok := strings.CutPrefix(value, "|"); ok {
+		value = v
+	} else {
+		return nil
+	}
+	if value == "" || strings.ContainsAny(value, "*/|^") {
+		return nil
+	}
+	return &Domain{Type: typ, Value: value}
+}
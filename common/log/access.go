@@ -27,6 +27,10 @@ type AccessMessage struct {
 	Reason interface{}
 	Email  string
 	Detour string
+	JA3    string
+	JA4    string
+	// Via is the local address of the outbound connection.
+	Via interface{}
 }
 
 func (m *AccessMessage) String() string {
@@ -50,11 +54,26 @@ func (m *AccessMessage) String() string {
 		builder.WriteString(reason)
 	}
 
+	if via := serial.ToString(m.Via); len(via) > 0 {
+		builder.WriteString(" via: ")
+		builder.WriteString(via)
+	}
+
 	if len(m.Email) > 0 {
 		builder.WriteString(" email: ")
 		builder.WriteString(m.Email)
 	}
 
+	if len(m.JA4) > 0 {
+		builder.WriteString(" ja4: ")
+		builder.WriteString(m.JA4)
+	}
+
+	if len(m.JA3) > 0 {
+		builder.WriteString(" ja3: ")
+		builder.WriteString(m.JA3)
+	}
+
 	return builder.String()
 }
 
@@ -2,6 +2,7 @@ package mux
 
 import (
 	"context"
+	"crypto/rand"
 	goerrors "errors"
 	"io"
 	"sync"
@@ -136,49 +137,82 @@ type DialingWorkerFactory struct {
 }
 
 func (f *DialingWorkerFactory) Create() (*ClientWorker, error) {
-	opts := []pipe.Option{pipe.WithSizeLimit(64 * 1024)}
-	uplinkReader, upLinkWriter := pipe.New(opts...)
-	downlinkReader, downlinkWriter := pipe.New(opts...)
-
-	c, err := NewClientWorker(transport.Link{
-		Reader: downlinkReader,
-		Writer: upLinkWriter,
-	}, f.Strategy)
+	link, process := f.newLink()
+	c, err := NewClientWorker(link, f.Strategy)
 	if err != nil {
 		return nil, err
 	}
 
-	go func(p proxy.Outbound, d internet.Dialer, c common.Closable) {
+	if f.Strategy.ResumeTimeout <= 0 {
+		go process(func() { common.Must(c.Close()) })
+		return c, nil
+	}
+	// The worker resumes its sessions in another connection when one ends.
+	lost := func(link transport.Link) func() {
+		return func() {
+			common.Interrupt(link.Reader)
+			common.Interrupt(link.Writer)
+		}
+	}
+	c.dial = func() transport.Link {
+		link, process := f.newLink()
+		go process(lost(link))
+		return link
+	}
+	go process(lost(link))
+	return c, nil
+}
+
+// newLink returns the link of a worker to a new connection, and the function
+// that processes the connection, calling ended after it ends.
+func (f *DialingWorkerFactory) newLink() (transport.Link, func(ended func())) {
+	opts := []pipe.Option{pipe.WithSizeLimit(64 * 1024)}
+	uplinkReader, upLinkWriter := pipe.New(opts...)
+	downlinkReader, downlinkWriter := pipe.New(opts...)
+
+	process := func(ended func()) {
 		outbounds := []*session.Outbound{{
 			Target: net.TCPDestination(muxCoolAddress, muxCoolPort),
 		}}
 		ctx := session.ContextWithOutbounds(context.Background(), outbounds)
 		ctx, cancel := context.WithCancel(ctx)
 
-		if errP := p.Process(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, d); errP != nil {
+		if errP := f.Proxy.Process(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, f.Dialer); errP != nil {
 			errC := errors.Cause(errP)
 			if !(goerrors.Is(errC, io.EOF) || goerrors.Is(errC, io.ErrClosedPipe) || goerrors.Is(errC, context.Canceled)) {
 				errors.LogInfoInner(ctx, errP, "failed to handler mux client connection")
 			}
 		}
-		common.Must(c.Close())
+		ended()
 		cancel()
-	}(f.Proxy, f.Dialer, c.done)
+	}
 
-	return c, nil
+	return transport.Link{
+		Reader: downlinkReader,
+		Writer: upLinkWriter,
+	}, process
 }
 
 type ClientStrategy struct {
 	MaxConcurrency uint32
 	MaxConnection  uint32
+	// ResumeTimeout is how long the sessions are kept to be resumed in a new
+	// connection when the connection is lost, if the server supports it.
+	ResumeTimeout time.Duration
 }
 
 type ClientWorker struct {
 	sessionManager *SessionManager
+	access         sync.Mutex
 	link           transport.Link
 	done           *done.Instance
 	timer          *time.Ticker
 	strategy       ClientStrategy
+	scheduler      *scheduler
+	// dial connects again to resume the sessions, and token identifies them
+	// once registered with the server.
+	dial  func() transport.Link
+	token [16]byte
 }
 
 var (
@@ -194,6 +228,7 @@ func NewClientWorker(stream transport.Link, s ClientStrategy) (*ClientWorker, er
 		done:           done.New(),
 		timer:          time.NewTicker(time.Second * 16),
 		strategy:       s,
+		scheduler:      newScheduler(stream.Writer),
 	}
 
 	go c.fetchOutput()
@@ -210,6 +245,12 @@ func (m *ClientWorker) ActiveConnections() uint32 {
 	return uint32(m.sessionManager.Size())
 }
 
+// Stalls returns how many times sessions waited for the peer to open the
+// window.
+func (m *ClientWorker) Stalls() uint64 {
+	return m.scheduler.stalls.Load()
+}
+
 // Closed returns true if this Client is closed.
 func (m *ClientWorker) Closed() bool {
 	return m.done.Done()
@@ -231,9 +272,11 @@ func (m *ClientWorker) monitor() {
 		checkCount := m.sessionManager.Count()
 		select {
 		case <-m.done.Wait():
+			m.scheduler.Close()
 			m.sessionManager.Close()
-			common.Interrupt(m.link.Writer)
-			common.Interrupt(m.link.Reader)
+			link := m.getLink()
+			common.Interrupt(link.Writer)
+			common.Interrupt(link.Reader)
 			return
 		case <-m.timer.C:
 			if m.sessionManager.CloseIfNoSessionAndIdle(checkSize, checkCount) {
@@ -256,7 +299,7 @@ func writeFirstPayload(reader buf.Reader, writer *Writer) error {
 	return nil
 }
 
-func fetchInput(ctx context.Context, s *Session, output buf.Writer) {
+func fetchInput(ctx context.Context, s *Session, scheduler *scheduler) {
 	outbounds := session.OutboundsFromContext(ctx)
 	ob := outbounds[len(outbounds)-1]
 	transferType := protocol.TransferTypeStream
@@ -264,11 +307,14 @@ func fetchInput(ctx context.Context, s *Session, output buf.Writer) {
 		transferType = protocol.TransferTypePacket
 	}
 	s.transferType = transferType
+	queue := scheduler.newQueue(s.ID, transferType)
 	var inbound *session.Inbound
 	if session.IsReverseMuxFromContext(ctx) {
 		inbound = session.InboundFromContext(ctx)
 	}
-	writer := NewWriter(s.ID, ob.Target, output, transferType, xudp.GetGlobalID(ctx), inbound)
+	writer := NewWriter(s.ID, ob.Target, queue, transferType, xudp.GetGlobalID(ctx), inbound)
+	writer.window = s.window
+	defer queue.Close()
 	defer s.Close(false)
 	defer writer.Close()
 
@@ -320,7 +366,8 @@ func (m *ClientWorker) Dispatch(ctx context.Context, link *transport.Link) bool
 	}
 	s.input = link.Reader
 	s.output = link.Writer
-	go fetchInput(ctx, s, m.link.Writer)
+	s.window = newSendWindow(false, &m.scheduler.stalls)
+	go fetchInput(ctx, s, m.scheduler)
 	if _, ok := link.Reader.(*pipe.Reader); !ok {
 		select {
 		case <-ctx.Done():
@@ -352,14 +399,14 @@ func (m *ClientWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	s, found := m.sessionManager.Get(meta.SessionID)
 	if !found {
 		// Notify remote peer to close this session.
-		closingWriter := NewResponseWriter(meta.SessionID, m.link.Writer, protocol.TransferTypeStream)
+		closingWriter := NewResponseWriter(meta.SessionID, m.scheduler, protocol.TransferTypeStream)
 		closingWriter.Close()
 
 		return buf.Copy(NewStreamReader(reader), buf.Discard)
 	}
 
 	rr := s.NewReader(reader, &meta.Target)
-	err := buf.Copy(rr, s.output)
+	err := buf.Copy(rr, s.receiveWriter())
 	if err != nil && buf.IsWriteError(err) {
 		errors.LogInfoInner(context.Background(), err, "failed to write to downstream. closing session ", s.ID)
 		s.Close(false)
@@ -379,40 +426,159 @@ func (m *ClientWorker) handleStatusEnd(meta *FrameMetadata, reader *buf.Buffered
 	return nil
 }
 
+// handleStatusWindowUpdate enables flow control in the session on the first
+// window update, which means that the peer supports it.
+func (m *ClientWorker) handleStatusWindowUpdate(meta *FrameMetadata) error {
+	if meta.Option.Has(OptionResume) && m.dial != nil && m.token == [16]byte{} {
+		if err := m.register(); err != nil {
+			return err
+		}
+	}
+	s, found := m.sessionManager.Get(meta.SessionID)
+	if !found || s.window == nil {
+		return nil
+	}
+	m.sessionManager.Lock()
+	if s.receiver == nil && !s.closed {
+		s.startReceiver(m.scheduler)
+	}
+	m.sessionManager.Unlock()
+	m.scheduler.acknowledge(s.ID, s.window.update(meta.Window))
+	return nil
+}
+
+// register makes the server keep the sessions for resumption when the
+// connection is lost.
+func (m *ClientWorker) register() error {
+	common.Must2(rand.Read(m.token[:]))
+	m.scheduler.setResumable()
+	meta := FrameMetadata{
+		Token:   m.token,
+		Timeout: uint16(min(m.strategy.ResumeTimeout, maxResumeTimeout) / time.Second),
+	}
+	return writeResume(m.scheduler, meta, nil)
+}
+
+func (m *ClientWorker) getLink() transport.Link {
+	m.access.Lock()
+	defer m.access.Unlock()
+	return m.link
+}
+
+// resume connects again after the connection is lost, and resumes the
+// sessions. It returns the reader of the new connection, or nil if the
+// sessions cannot be resumed.
+func (m *ClientWorker) resume() *buf.BufferedReader {
+	if m.token == [16]byte{} || m.done.Done() || m.sessionManager.Size() == 0 {
+		return nil
+	}
+	link := m.getLink()
+	m.scheduler.suspend(link.Writer)
+	common.Interrupt(link.Writer)
+	common.Interrupt(link.Reader)
+	errors.LogInfo(context.Background(), "mux connection lost, resuming ", m.sessionManager.Size(), " sessions")
+
+	deadline := time.Now().Add(m.strategy.ResumeTimeout)
+	for delay := 200 * time.Millisecond; ; delay = min(delay*2, 5*time.Second) {
+		link := m.dial()
+		reader, err := m.handshake(link)
+		if err == nil {
+			errors.LogInfo(context.Background(), "mux connection resumed")
+			return reader
+		}
+		common.Interrupt(link.Writer)
+		common.Interrupt(link.Reader)
+		if err == errResumeRejected {
+			errors.LogInfo(context.Background(), "mux resumption rejected by the server")
+			return nil
+		}
+		errors.LogInfoInner(context.Background(), err, "failed to resume mux connection")
+		wait := min(delay, time.Until(deadline))
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-m.done.Wait():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// handshake resumes the sessions in link.
+func (m *ClientWorker) handshake(link transport.Link) (*buf.BufferedReader, error) {
+	entries := resumeEntries(m.sessionManager, m.scheduler, true)
+	if err := writeResume(link.Writer, FrameMetadata{Token: m.token}, entries); err != nil {
+		return nil, err
+	}
+
+	reader := &buf.BufferedReader{Reader: link.Reader}
+	timer := time.AfterFunc(resumeHandshakeTimeout, func() {
+		common.Interrupt(link.Reader)
+	})
+	var meta FrameMetadata
+	err := meta.Unmarshal(reader, false)
+	if err == nil && meta.SessionStatus != SessionStatusResume {
+		err = errors.New("unexpected status: ", meta.SessionStatus)
+	}
+	if err == nil && meta.Option.Has(OptionError) {
+		err = errResumeRejected
+	}
+	var peers []resumeEntry
+	if err == nil {
+		peers, err = readResumeEntries(reader)
+	}
+	if !timer.Stop() {
+		return nil, errors.New("resumption timed out")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.access.Lock()
+	m.link = link
+	m.access.Unlock()
+	reconcile(m.sessionManager, m.scheduler, link.Writer, peers, true)
+	m.scheduler.resume(link.Writer)
+	return reader, nil
+}
+
 func (m *ClientWorker) fetchOutput() {
 	defer func() {
 		common.Must(m.done.Close())
 	}()
 
-	reader := &buf.BufferedReader{Reader: m.link.Reader}
+	reader := &buf.BufferedReader{Reader: m.getLink().Reader}
 
 	var meta FrameMetadata
 	for {
 		err := meta.Unmarshal(reader, false)
-		if err != nil {
-			if errors.Cause(err) != io.EOF {
-				errors.LogInfoInner(context.Background(), err, "failed to read metadata")
+		if err == nil {
+			switch meta.SessionStatus {
+			case SessionStatusKeepAlive:
+				err = m.handleStatueKeepAlive(&meta, reader)
+			case SessionStatusEnd:
+				err = m.handleStatusEnd(&meta, reader)
+			case SessionStatusNew:
+				err = m.handleStatusNew(&meta, reader)
+			case SessionStatusKeep:
+				err = m.handleStatusKeep(&meta, reader)
+			case SessionStatusWindowUpdate:
+				err = m.handleStatusWindowUpdate(&meta)
+			default:
+				status := meta.SessionStatus
+				errors.LogError(context.Background(), "unknown status: ", status)
+				return
 			}
-			break
-		}
-
-		switch meta.SessionStatus {
-		case SessionStatusKeepAlive:
-			err = m.handleStatueKeepAlive(&meta, reader)
-		case SessionStatusEnd:
-			err = m.handleStatusEnd(&meta, reader)
-		case SessionStatusNew:
-			err = m.handleStatusNew(&meta, reader)
-		case SessionStatusKeep:
-			err = m.handleStatusKeep(&meta, reader)
-		default:
-			status := meta.SessionStatus
-			errors.LogError(context.Background(), "unknown status: ", status)
-			return
+			if err == nil {
+				continue
+			}
+			errors.LogInfoInner(context.Background(), err, "failed to process data")
+		} else if errors.Cause(err) != io.EOF {
+			errors.LogInfoInner(context.Background(), err, "failed to read metadata")
 		}
 
-		if err != nil {
-			errors.LogInfoInner(context.Background(), err, "failed to process data")
+		if reader = m.resume(); reader == nil {
 			return
 		}
 	}
@@ -0,0 +1,201 @@
+package mux
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/transport/pipe"
+)
+
+const (
+	// initialWindow is how much a session with flow control may send before
+	// it receives the first window update.
+	initialWindow = 512 * 1024
+	// windowUpdateThreshold is how much the receiver delivers before it
+	// returns the window to the sender.
+	windowUpdateThreshold = initialWindow / 4
+)
+
+// sendWindow is the number of bytes a session may send to the peer.
+//
+// It is unlimited until enabled by the first window update of the peer, so
+// that sessions with peers without flow control are not affected.
+type sendWindow struct {
+	sync.Mutex
+	cond    *sync.Cond
+	enabled bool
+	closed  bool
+	// granted is the window granted by the peer in total, and sent the data
+	// sent. The sender may send more than the initial window before the first
+	// window update arrives.
+	granted uint64
+	sent    uint64
+	stalls  *atomic.Uint64
+}
+
+func newSendWindow(enabled bool, stalls *atomic.Uint64) *sendWindow {
+	w := &sendWindow{
+		enabled: enabled,
+		stalls:  stalls,
+	}
+	w.cond = sync.NewCond(&w.Mutex)
+	return w
+}
+
+func (w *sendWindow) credit() int64 {
+	return initialWindow + int64(w.granted) - int64(w.sent)
+}
+
+// acquire waits until the window is open and returns how many of size bytes
+// may be sent.
+func (w *sendWindow) acquire(size int32) (int32, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.enabled && w.credit() <= 0 && !w.closed {
+		w.stalls.Add(1)
+		for w.enabled && w.credit() <= 0 && !w.closed {
+			w.cond.Wait()
+		}
+	}
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if credit := w.credit(); w.enabled && credit < int64(size) {
+		return int32(credit), nil
+	}
+	return size, nil
+}
+
+// consume takes n bytes that are sent from the window.
+func (w *sendWindow) consume(n int32) {
+	w.Lock()
+	w.sent += uint64(n)
+	w.Unlock()
+}
+
+// update adds n bytes to the window and enables it. It returns the window
+// granted in total, which the peer has received.
+func (w *sendWindow) update(n uint32) uint64 {
+	w.Lock()
+	w.enabled = true
+	w.granted += uint64(n)
+	granted := w.granted
+	w.Unlock()
+	w.cond.Broadcast()
+	return granted
+}
+
+// resync sets the window granted by the peer in total after resumption, and
+// gives back the window of the data sent that the peer did not receive.
+func (w *sendWindow) resync(granted uint64, lost uint64) {
+	w.Lock()
+	w.enabled = true
+	w.granted = granted
+	w.sent -= lost
+	w.Unlock()
+	w.cond.Broadcast()
+}
+
+func (w *sendWindow) close() {
+	w.Lock()
+	w.closed = true
+	w.Unlock()
+	w.cond.Broadcast()
+}
+
+// writeWindowUpdate grants the peer to send n more bytes in session id.
+func writeWindowUpdate(writer buf.Writer, id uint16, n uint32) error {
+	meta := FrameMetadata{
+		SessionID:     id,
+		SessionStatus: SessionStatusWindowUpdate,
+		Window:        n,
+	}
+	frame := buf.New()
+	common.Must(meta.WriteTo(frame))
+	return writer.WriteMultiBuffer(buf.MultiBuffer{frame})
+}
+
+// startReceiver makes the data received in s delivered to its output without
+// blocking the reader of the Mux connection. The peer is granted more window
+// as the output accepts the data, so a peer that keeps to the window never
+// fills the buffer. The buffer is still limited to the window, which blocks
+// the reader of a peer that ignores it instead of buffering without a limit.
+func (s *Session) startReceiver(writer buf.Writer) {
+	reader, receiver := pipe.New(pipe.WithSizeLimit(initialWindow))
+	s.receiver = receiver
+	go func() {
+		for {
+			mb, err := reader.ReadMultiBuffer()
+			if err != nil {
+				break
+			}
+			n := uint32(mb.Len())
+			if err := s.output.WriteMultiBuffer(mb); err != nil {
+				errors.LogInfoInner(context.Background(), err, "failed to write to downstream. closing session ", s.ID)
+				common.Interrupt(reader)
+				s.Close(false)
+				return
+			}
+			s.deliver(writer, n)
+		}
+		if s.XUDP == nil {
+			common.Close(s.output)
+		}
+	}()
+}
+
+// deliver grants the peer the window of n bytes delivered to the output, once
+// enough is delivered. The window is kept while the connection is suspended.
+func (s *Session) deliver(writer buf.Writer, n uint32) {
+	s.grant.Lock()
+	defer s.grant.Unlock()
+
+	s.consumed += n
+	if s.consumed < windowUpdateThreshold {
+		return
+	}
+	if writeWindowUpdate(writer, s.ID, s.consumed) == nil {
+		s.granted += uint64(s.consumed)
+		s.consumed = 0
+	}
+}
+
+// resumeState returns the data received in s and the window granted to the
+// peer in total, granting what is delivered.
+func (s *Session) resumeState() (uint64, uint64) {
+	s.grant.Lock()
+	defer s.grant.Unlock()
+
+	s.granted += uint64(s.consumed)
+	s.consumed = 0
+	return s.received.Load(), s.granted
+}
+
+type receiveWriter struct {
+	*Session
+	writer buf.Writer
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w receiveWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	n := mb.Len()
+	if err := w.writer.WriteMultiBuffer(mb); err != nil {
+		return err
+	}
+	w.received.Add(uint64(n))
+	return nil
+}
+
+// receiveWriter returns the writer for the data received in s.
+func (s *Session) receiveWriter() buf.Writer {
+	if s.receiver != nil {
+		return receiveWriter{s, s.receiver}
+	}
+	return receiveWriter{s, s.output}
+}
@@ -0,0 +1,151 @@
+package mux_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/mux"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/pipe"
+)
+
+func TestFrameWindowUpdate(t *testing.T) {
+	frame := mux.FrameMetadata{
+		SessionID:     3,
+		SessionStatus: mux.SessionStatusWindowUpdate,
+		Window:        123456,
+	}
+	b := buf.New()
+	defer b.Release()
+	common.Must(frame.WriteTo(b))
+
+	var meta mux.FrameMetadata
+	common.Must(meta.Unmarshal(b, false))
+	if meta.SessionID != 3 || meta.SessionStatus != mux.SessionStatusWindowUpdate || meta.Window != 123456 {
+		t.Error("unexpected frame ", meta)
+	}
+}
+
+func TestFlowControlNoHeadOfLineBlocking(t *testing.T) {
+	// The first website never reads, the second does.
+	stuckReader, stuckWriter := pipe.New(pipe.WithSizeLimit(16 * 1024))
+	defer common.Interrupt(stuckReader)
+	stuckDownlinkReader, _ := pipe.New(pipe.WithoutSizeLimit())
+	websiteUplink, websiteDownlink := newLinkPair()
+
+	dispatcher := TestDispatcher{
+		OnDispatch: func(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+			if dest.Port == 1 {
+				return &transport.Link{Reader: stuckDownlinkReader, Writer: stuckWriter}, nil
+			}
+			return websiteDownlink, nil
+		},
+	}
+
+	muxServerUplink, muxServerDownlink := newLinkPair()
+	_, err := mux.NewServerWorker(context.Background(), &dispatcher, muxServerUplink)
+	common.Must(err)
+	client, err := mux.NewClientWorker(*muxServerDownlink, mux.ClientStrategy{})
+	common.Must(err)
+	defer client.Close()
+
+	dispatch := func(port net.Port) *transport.Link {
+		ctx := session.ContextWithOutbounds(context.Background(), []*session.Outbound{{
+			Target: net.TCPDestination(net.DomainAddress("www.example.com"), port),
+		}})
+		uplink, downlink := newLinkPair()
+		if !client.Dispatch(ctx, uplink) {
+			t.Fatal("failed to dispatch")
+		}
+		return downlink
+	}
+
+	bulk := dispatch(1)
+	go func() {
+		for range 256 {
+			b := buf.New()
+			b.Extend(buf.Size)
+			if bulk.Writer.WriteMultiBuffer(buf.MultiBuffer{b}) != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(500 * time.Millisecond)
+	interactive := dispatch(2)
+	common.Must(interactive.Writer.WriteMultiBuffer(buf.MultiBuffer{buf.FromBytes([]byte("hello"))}))
+
+	received := make(chan string, 1)
+	go func() {
+		mb, err := websiteUplink.Reader.ReadMultiBuffer()
+		if err == nil {
+			received <- mb.String()
+		}
+	}()
+	select {
+	case s := <-received:
+		if s != "hello" {
+			t.Error("unexpected data ", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked by the other session")
+	}
+
+	if client.Stalls() == 0 {
+		t.Error("expected the bulk session to be stalled")
+	}
+}
+
+func TestFlowControlPeerIgnoringWindow(t *testing.T) {
+	// The website never reads.
+	stuckReader, stuckWriter := pipe.New(pipe.WithSizeLimit(16 * 1024))
+	defer common.Interrupt(stuckReader)
+	stuckDownlinkReader, _ := pipe.New(pipe.WithoutSizeLimit())
+	dispatcher := TestDispatcher{
+		OnDispatch: func(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+			return &transport.Link{Reader: stuckDownlinkReader, Writer: stuckWriter}, nil
+		},
+	}
+
+	// The writes of the peer block as soon as the server stops reading.
+	serverReader, peerWriter := pipe.New(pipe.WithSizeLimit(16 * 1024))
+	defer common.Interrupt(serverReader)
+	_, serverWriter := pipe.New(pipe.WithoutSizeLimit())
+	_, err := mux.NewServerWorker(context.Background(), &dispatcher, &transport.Link{Reader: serverReader, Writer: serverWriter})
+	common.Must(err)
+
+	var sent atomic.Int64
+	go func() {
+		meta := mux.FrameMetadata{
+			SessionID:     1,
+			SessionStatus: mux.SessionStatusNew,
+			Target:        net.TCPDestination(net.DomainAddress("www.example.com"), 80),
+		}
+		meta.Option.Set(mux.OptionData | mux.OptionFlowControl)
+		for range 1024 {
+			frame := buf.New()
+			common.Must(meta.WriteTo(frame))
+			common.Must2(serial.WriteUint16(frame, buf.Size))
+			data := buf.New()
+			data.Extend(buf.Size)
+			if peerWriter.WriteMultiBuffer(buf.MultiBuffer{frame, data}) != nil {
+				return
+			}
+			sent.Add(buf.Size)
+			meta.SessionStatus = mux.SessionStatusKeep
+			meta.Option.Clear(mux.OptionFlowControl)
+		}
+	}()
+
+	time.Sleep(time.Second)
+	if n := sent.Load(); n > 2*1024*1024 {
+		t.Error("buffered ", n, " bytes beyond the window")
+	}
+}
@@ -21,11 +21,24 @@ const (
 	SessionStatusKeep      SessionStatus = 0x02
 	SessionStatusEnd       SessionStatus = 0x03
 	SessionStatusKeepAlive SessionStatus = 0x04
+	// SessionStatusWindowUpdate grants the peer to send more data in a
+	// session. It is only sent to peers that set OptionFlowControl.
+	SessionStatusWindowUpdate SessionStatus = 0x05
+	// SessionStatusResume registers a Mux connection for resumption, or
+	// resumes its sessions in a new connection. It is only sent to peers that
+	// set OptionResume.
+	SessionStatusResume SessionStatus = 0x06
 )
 
 const (
 	OptionData  bitmask.Byte = 0x01
 	OptionError bitmask.Byte = 0x02
+	// OptionFlowControl in a new session means that the sender supports flow
+	// control. Older implementations ignore it.
+	OptionFlowControl bitmask.Byte = 0x04
+	// OptionResume in the first window update of a session means that the
+	// sender supports resumption.
+	OptionResume bitmask.Byte = 0x08
 )
 
 type TargetNetwork byte
@@ -53,6 +66,9 @@ Frame format
 2 bytes - port
 n bytes - address
 
+Window update frames carry 4 bytes of window after the option instead.
+Resume frames carry 16 bytes of token and 2 bytes of timeout in seconds.
+
 */
 
 type FrameMetadata struct {
@@ -62,6 +78,9 @@ type FrameMetadata struct {
 	SessionStatus SessionStatus
 	GlobalID      [8]byte
 	Inbound       *session.Inbound
+	Window        uint32
+	Token         [16]byte
+	Timeout       uint16
 }
 
 func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
@@ -74,7 +93,12 @@ func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
 	common.Must(b.WriteByte(byte(f.SessionStatus)))
 	common.Must(b.WriteByte(byte(f.Option)))
 
-	if f.SessionStatus == SessionStatusNew {
+	if f.SessionStatus == SessionStatusWindowUpdate {
+		binary.BigEndian.PutUint32(b.Extend(4), f.Window)
+	} else if f.SessionStatus == SessionStatusResume {
+		common.Must2(b.Write(f.Token[:]))
+		binary.BigEndian.PutUint16(b.Extend(2), f.Timeout)
+	} else if f.SessionStatus == SessionStatusNew {
 		switch f.Target.Network {
 		case net.Network_TCP:
 			common.Must(b.WriteByte(byte(TargetNetworkTCP)))
@@ -141,6 +165,23 @@ func (f *FrameMetadata) UnmarshalFromBuffer(b *buf.Buffer, readSourceAndLocal bo
 	f.Option = bitmask.Byte(b.Byte(3))
 	f.Target.Network = net.Network_Unknown
 
+	if f.SessionStatus == SessionStatusWindowUpdate {
+		if b.Len() < 8 {
+			return errors.New("insufficient buffer: ", b.Len())
+		}
+		f.Window = binary.BigEndian.Uint32(b.BytesRange(4, 8))
+		return nil
+	}
+
+	if f.SessionStatus == SessionStatusResume {
+		if b.Len() < 22 {
+			return errors.New("insufficient buffer: ", b.Len())
+		}
+		copy(f.Token[:], b.BytesRange(4, 20))
+		f.Timeout = binary.BigEndian.Uint16(b.BytesRange(20, 22))
+		return nil
+	}
+
 	if f.SessionStatus == SessionStatusNew || (f.SessionStatus == SessionStatusKeep && b.Len() > 4 &&
 		TargetNetwork(b.Byte(4)) == TargetNetworkUDP) { // MUST check the flag first
 		if b.Len() < 8 {
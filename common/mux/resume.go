@@ -0,0 +1,307 @@
+package mux
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/common/session"
+)
+
+const (
+	// maxResumeTimeout is the longest a client asks to keep the sessions of
+	// a lost connection.
+	maxResumeTimeout = 10 * time.Minute
+	// maxResumableWorkers is the most connections of a user that a server
+	// registers for resumption at once.
+	maxResumableWorkers = 16
+	// maxSuspendedReplay is the most data of streams that a server keeps for
+	// the lost connections of a user.
+	maxSuspendedReplay = 16 * 1024 * 1024
+	// resumeHandshakeTimeout is how long a client waits for the reply to a
+	// resumption.
+	resumeHandshakeTimeout = 10 * time.Second
+
+	// resumeEntrySize is the size of a session in a resume frame: 2 bytes of
+	// session id, 8 bytes of data received and 8 bytes of window granted.
+	resumeEntrySize  = 18
+	maxResumeEntries = 65535 / resumeEntrySize
+)
+
+var errResumeRejected = errors.New("mux resumption rejected")
+
+// resumeEntry is the state of a session exchanged on resumption.
+type resumeEntry struct {
+	id       uint16
+	received uint64
+	granted  uint64
+}
+
+// writeResume writes a resume frame. Without entries, it registers the
+// connection for resumption.
+func writeResume(writer buf.Writer, meta FrameMetadata, entries []resumeEntry) error {
+	meta.SessionStatus = SessionStatusResume
+	if entries == nil {
+		frame := buf.New()
+		common.Must(meta.WriteTo(frame))
+		return writer.WriteMultiBuffer(buf.MultiBuffer{frame})
+	}
+	meta.Option.Set(OptionData)
+	if len(entries) > maxResumeEntries {
+		entries = entries[:maxResumeEntries]
+	}
+	var data buf.MultiBuffer
+	for _, e := range entries {
+		if len(data) == 0 || data[len(data)-1].Available() < resumeEntrySize {
+			data = append(data, buf.New())
+		}
+		b := data[len(data)-1].Extend(resumeEntrySize)
+		binary.BigEndian.PutUint16(b, e.id)
+		binary.BigEndian.PutUint64(b[2:], e.received)
+		binary.BigEndian.PutUint64(b[10:], e.granted)
+	}
+	if len(data) == 0 {
+		data = buf.MultiBuffer{buf.New()}
+	}
+	return writeMetaWithFrame(writer, meta, data)
+}
+
+// readResumeEntries reads the entries of a resume frame with data.
+func readResumeEntries(reader io.Reader) ([]resumeEntry, error) {
+	size, err := serial.ReadUint16(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size%resumeEntrySize != 0 {
+		return nil, errors.New("invalid size of resume entries: ", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(reader, b); err != nil {
+		return nil, err
+	}
+	entries := make([]resumeEntry, 0, size/resumeEntrySize)
+	for ; len(b) > 0; b = b[resumeEntrySize:] {
+		entries = append(entries, resumeEntry{
+			id:       binary.BigEndian.Uint16(b),
+			received: binary.BigEndian.Uint64(b[2:]),
+			granted:  binary.BigEndian.Uint64(b[10:]),
+		})
+	}
+	return entries, nil
+}
+
+// dataFrame returns a frame of a stream with data, which fits in a buffer.
+func dataFrame(id uint16, data []byte) buf.MultiBuffer {
+	meta := FrameMetadata{
+		SessionID:     id,
+		SessionStatus: SessionStatusKeep,
+	}
+	meta.Option.Set(OptionData)
+	frame := buf.New()
+	common.Must(meta.WriteTo(frame))
+	common.Must2(serial.WriteUint16(frame, uint16(len(data))))
+	b := buf.New()
+	common.Must2(b.Write(data))
+	return buf.MultiBuffer{frame, b}
+}
+
+func endFrame(id uint16) buf.MultiBuffer {
+	meta := FrameMetadata{
+		SessionID:     id,
+		SessionStatus: SessionStatusEnd,
+	}
+	frame := buf.New()
+	common.Must(meta.WriteTo(frame))
+	return buf.MultiBuffer{frame}
+}
+
+// resumeEntries returns the state of the sessions in m. On the client, the
+// sessions that are not sent yet are skipped.
+func resumeEntries(m *SessionManager, s *scheduler, client bool) []resumeEntry {
+	m.RLock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.RUnlock()
+
+	entries := make([]resumeEntry, 0, len(sessions))
+	for _, session := range sessions {
+		if session.window == nil || client && !s.started(session.ID) {
+			continue
+		}
+		received, granted := session.resumeState()
+		entries = append(entries, resumeEntry{
+			id:       session.ID,
+			received: received,
+			granted:  granted,
+		})
+	}
+	return entries
+}
+
+// reconcile resumes the sessions in m that the peer also has, from where the
+// peer is, and closes the others. On the client, the sessions that are not
+// sent yet are kept. It writes to writer before s is resumed.
+func reconcile(m *SessionManager, s *scheduler, writer buf.Writer, peers []resumeEntry, client bool) {
+	known := make(map[uint16]resumeEntry, len(peers))
+	for _, e := range peers {
+		known[e.id] = e
+	}
+
+	m.RLock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.RUnlock()
+
+	for _, session := range sessions {
+		e, found := known[session.ID]
+		delete(known, session.ID)
+		if !found && client && !s.started(session.ID) {
+			continue
+		}
+		if !found || session.window == nil {
+			session.Close(false)
+			continue
+		}
+		lost, ok := s.rewind(session.ID, e.received)
+		if !ok {
+			session.Close(false)
+			continue
+		}
+		session.window.resync(e.granted, lost)
+		m.Lock()
+		if session.receiver == nil && !session.closed {
+			session.startReceiver(s)
+		}
+		m.Unlock()
+	}
+
+	// The peer has sessions that ended here.
+	for id, e := range known {
+		if _, ok := s.rewind(id, e.received); !ok {
+			writer.WriteMultiBuffer(endFrame(id))
+		}
+	}
+}
+
+// resumeOwner is the inbound and the user of a connection, which a connection
+// resuming its sessions must match.
+type resumeOwner struct {
+	tag   string
+	email string
+}
+
+func resumeOwnerFromContext(ctx context.Context) resumeOwner {
+	var owner resumeOwner
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		owner.tag = inbound.Tag
+		if inbound.User != nil {
+			owner.email = inbound.User.Email
+		}
+	}
+	return owner
+}
+
+// resumableWorkers are the server workers registered for resumption, by
+// token, until they end or another worker resumes their sessions. workers
+// and replay are the number of workers and the data kept for the lost
+// connections of each owner.
+var resumableWorkers = struct {
+	sync.Mutex
+	m       map[[16]byte]*ServerWorker
+	workers map[resumeOwner]int
+	replay  map[resumeOwner]int64
+}{
+	m:       make(map[[16]byte]*ServerWorker),
+	workers: make(map[resumeOwner]int),
+	replay:  make(map[resumeOwner]int64),
+}
+
+// registerResumable adds w, and returns false if its owner has too many
+// workers registered.
+func registerResumable(w *ServerWorker) bool {
+	resumableWorkers.Lock()
+	defer resumableWorkers.Unlock()
+
+	if _, found := resumableWorkers.m[w.token]; found || resumableWorkers.workers[w.owner] >= maxResumableWorkers {
+		return false
+	}
+	resumableWorkers.m[w.token] = w
+	resumableWorkers.workers[w.owner]++
+	return true
+}
+
+// lookupResumable returns the worker registered with token by owner.
+func lookupResumable(token [16]byte, owner resumeOwner) *ServerWorker {
+	resumableWorkers.Lock()
+	defer resumableWorkers.Unlock()
+
+	if w := resumableWorkers.m[token]; w != nil && w.owner == owner {
+		return w
+	}
+	return nil
+}
+
+// suspendResumable accounts for the data kept by w after its connection is
+// lost, and returns false if its owner keeps too much.
+func suspendResumable(w *ServerWorker, replay int64) bool {
+	resumableWorkers.Lock()
+	defer resumableWorkers.Unlock()
+
+	if resumableWorkers.m[w.token] != w || resumableWorkers.replay[w.owner]+replay > maxSuspendedReplay {
+		return false
+	}
+	resumableWorkers.replay[w.owner] += replay
+	w.suspended = replay
+	return true
+}
+
+// releaseSuspended stops accounting for the data kept by w.
+func releaseSuspended(w *ServerWorker) {
+	if w.suspended == 0 {
+		return
+	}
+	if resumableWorkers.replay[w.owner] -= w.suspended; resumableWorkers.replay[w.owner] <= 0 {
+		delete(resumableWorkers.replay, w.owner)
+	}
+	w.suspended = 0
+}
+
+// adoptResumable registers w in place of o, and returns false if o is
+// already removed.
+func adoptResumable(o *ServerWorker, w *ServerWorker) bool {
+	resumableWorkers.Lock()
+	defer resumableWorkers.Unlock()
+
+	if resumableWorkers.m[o.token] != o {
+		return false
+	}
+	releaseSuspended(o)
+	resumableWorkers.m[o.token] = w
+	return true
+}
+
+// forgetResumable removes w, and returns false if it is already removed.
+func forgetResumable(w *ServerWorker) bool {
+	resumableWorkers.Lock()
+	defer resumableWorkers.Unlock()
+
+	if resumableWorkers.m[w.token] != w {
+		return false
+	}
+	delete(resumableWorkers.m, w.token)
+	releaseSuspended(w)
+	if resumableWorkers.workers[w.owner]--; resumableWorkers.workers[w.owner] <= 0 {
+		delete(resumableWorkers.workers, w.owner)
+	}
+	return true
+}
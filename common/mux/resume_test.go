@@ -0,0 +1,234 @@
+package mux_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/mux"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+)
+
+func TestFrameResume(t *testing.T) {
+	frame := mux.FrameMetadata{
+		SessionStatus: mux.SessionStatusResume,
+		Token:         [16]byte{1, 2, 3},
+		Timeout:       30,
+	}
+	b := buf.New()
+	defer b.Release()
+	common.Must(frame.WriteTo(b))
+
+	var meta mux.FrameMetadata
+	common.Must(meta.Unmarshal(b, false))
+	if meta.SessionStatus != mux.SessionStatusResume || meta.Token != frame.Token || meta.Timeout != 30 {
+		t.Error("unexpected frame ", meta)
+	}
+}
+
+// lossyOutbound connects to a mux server worker through pipes, and cuts the
+// connections on demand.
+type lossyOutbound struct {
+	dispatcher    *TestDispatcher
+	cut           chan struct{}
+	resumeTimeout time.Duration
+	// users are the users the connections are authenticated as, in turn.
+	users []string
+}
+
+func (o *lossyOutbound) Process(ctx context.Context, link *transport.Link, _ internet.Dialer) error {
+	inbound := &session.Inbound{Tag: "in", User: &protocol.MemoryUser{}}
+	if len(o.users) > 0 {
+		inbound.User.Email = o.users[0]
+		o.users = o.users[1:]
+	}
+	ctx = session.ContextWithInbound(context.Background(), inbound)
+	worker, err := mux.NewResumableServerWorker(ctx, o.dispatcher, link, o.resumeTimeout)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-o.cut:
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+	case <-worker.WaitClosed():
+	}
+	return nil
+}
+
+func pattern(offset int, size int) buf.MultiBuffer {
+	b := buf.New()
+	for i := range size {
+		common.Must(b.WriteByte(byte((offset + i) % 251)))
+	}
+	return buf.MultiBuffer{b}
+}
+
+// expectPattern reads size bytes written by pattern from reader, calling
+// progress after every read.
+func expectPattern(reader buf.Reader, size int, progress func(offset int)) error {
+	offset := 0
+	for offset < size {
+		progress(offset)
+		mb, err := reader.ReadMultiBuffer()
+		if err != nil {
+			return err
+		}
+		for _, b := range mb {
+			for _, c := range b.Bytes() {
+				if c != byte(offset%251) {
+					buf.ReleaseMulti(mb)
+					return errors.New("unexpected byte at ", offset, " ", c, " ", offset%251)
+				}
+				offset++
+			}
+		}
+		buf.ReleaseMulti(mb)
+	}
+	return nil
+}
+
+func TestResumeAfterConnectionLoss(t *testing.T) {
+	const size = 4 * 1024 * 1024
+	websiteUplink, websiteDownlink := newLinkPair()
+	outbound := &lossyOutbound{
+		dispatcher: &TestDispatcher{
+			OnDispatch: func(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+				return websiteDownlink, nil
+			},
+		},
+		cut:           make(chan struct{}),
+		resumeTimeout: 10 * time.Second,
+	}
+	picker := &mux.IncrementalWorkerPicker{
+		Factory: &mux.DialingWorkerFactory{
+			Proxy: outbound,
+			Strategy: mux.ClientStrategy{
+				ResumeTimeout: 10 * time.Second,
+			},
+		},
+	}
+	client, err := picker.PickAvailable()
+	common.Must(err)
+	defer client.Close()
+
+	ctx := session.ContextWithOutbounds(context.Background(), []*session.Outbound{{
+		Target: net.TCPDestination(net.DomainAddress("www.example.com"), 80),
+	}})
+	uplink, downlink := newLinkPair()
+	if !client.Dispatch(ctx, uplink) {
+		t.Fatal("failed to dispatch")
+	}
+
+	write := func(writer buf.Writer) {
+		for offset := 0; offset < size; offset += buf.Size {
+			if writer.WriteMultiBuffer(pattern(offset, buf.Size)) != nil {
+				return
+			}
+		}
+	}
+	go write(downlink.Writer)
+	go write(websiteUplink.Writer)
+
+	// The connection is cut twice in each direction while receiving.
+	read := func(reader buf.Reader) error {
+		cuts := []int{size / 4, size / 2}
+		return expectPattern(reader, size, func(offset int) {
+			if len(cuts) > 0 && offset >= cuts[0] {
+				cuts = cuts[1:]
+				outbound.cut <- struct{}{}
+			}
+		})
+	}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- read(websiteUplink.Reader)
+	}()
+	go func() {
+		errs <- read(downlink.Reader)
+	}()
+	for range 2 {
+		select {
+		case err := <-errs:
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			} else if err != nil {
+				t.Fatal("session closed")
+			}
+		case <-time.After(20 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	if client.Closed() {
+		t.Error("client worker closed")
+	}
+}
+
+// testResumeRefused cuts the connection of a session once, which must end the
+// session instead of resuming it.
+func testResumeRefused(t *testing.T, outbound *lossyOutbound) {
+	websiteUplink, websiteDownlink := newLinkPair()
+	outbound.dispatcher = &TestDispatcher{
+		OnDispatch: func(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+			return websiteDownlink, nil
+		},
+	}
+	outbound.cut = make(chan struct{})
+	picker := &mux.IncrementalWorkerPicker{
+		Factory: &mux.DialingWorkerFactory{
+			Proxy: outbound,
+			Strategy: mux.ClientStrategy{
+				ResumeTimeout: 2 * time.Second,
+			},
+		},
+	}
+	client, err := picker.PickAvailable()
+	common.Must(err)
+	defer client.Close()
+
+	ctx := session.ContextWithOutbounds(context.Background(), []*session.Outbound{{
+		Target: net.TCPDestination(net.DomainAddress("www.example.com"), 80),
+	}})
+	uplink, downlink := newLinkPair()
+	if !client.Dispatch(ctx, uplink) {
+		t.Fatal("failed to dispatch")
+	}
+	common.Must(websiteUplink.Writer.WriteMultiBuffer(pattern(0, 1024)))
+	if err := expectPattern(downlink.Reader, 1024, func(int) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	outbound.cut <- struct{}{}
+	errs := make(chan error, 1)
+	go func() {
+		_, err := downlink.Reader.ReadMultiBuffer()
+		errs <- err
+	}()
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("session resumed")
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestResumeRefusedForOtherUser(t *testing.T) {
+	testResumeRefused(t, &lossyOutbound{
+		resumeTimeout: 10 * time.Second,
+		users:         []string{"alice@example.com", "bob@example.com"},
+	})
+}
+
+func TestResumeDisabled(t *testing.T) {
+	testResumeRefused(t, &lossyOutbound{})
+}
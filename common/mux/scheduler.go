@@ -0,0 +1,460 @@
+package mux
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+)
+
+var errSuspended = errors.New("mux connection suspended")
+
+const (
+	// quantum is what a session of weight 1 may send in a round, at least a
+	// full data frame with its metadata.
+	quantum = buf.Size + 1024
+
+	streamWeight = 1
+	// Packets are mostly interactive, such as DNS, games and calls.
+	packetWeight = 2
+)
+
+// scheduler writes the frames of the sessions of a Mux connection with
+// deficit round robin, so that a session sending a lot of data does not delay
+// the others by more than a round.
+//
+// If the connection is resumable, the scheduler keeps the data of streams
+// until the peer acknowledges it, and is suspended instead of closed when the
+// connection is lost.
+type scheduler struct {
+	sync.Mutex
+	cond *sync.Cond
+	// writer is nil while suspended.
+	writer buf.Writer
+	// active are the queues with frames, in the order of the round.
+	active []*frameQueue
+	queues map[uint16]*frameQueue
+	// ended are the queues of the streams that ended, kept for resumption.
+	ended     map[uint16]*frameQueue
+	resumable bool
+	err       error
+	stalls    atomic.Uint64
+}
+
+func newScheduler(writer buf.Writer) *scheduler {
+	s := &scheduler{
+		writer: writer,
+		queues: make(map[uint16]*frameQueue),
+		ended:  make(map[uint16]*frameQueue),
+	}
+	s.cond = sync.NewCond(&s.Mutex)
+	go s.run()
+	return s
+}
+
+type queuedFrame struct {
+	mb buf.MultiBuffer
+	// replayed frames are sent again and not recorded.
+	replayed bool
+}
+
+type replayChunk struct {
+	offset uint64
+	data   []byte
+}
+
+// frameQueue is the queue of the frames of one session. It implements
+// buf.Writer, every WriteMultiBuffer being a whole frame.
+type frameQueue struct {
+	scheduler *scheduler
+	id        uint16
+	stream    bool
+	weight    int
+	frames    []queuedFrame
+	size      int
+	deficit   int
+	visited   bool
+	closed    bool
+	// started is set once a frame of the queue is sent.
+	started bool
+	// sent is the number of bytes of data sent.
+	sent uint64
+	// replay is the data sent from replayFrom on, which the peer has not
+	// acknowledged.
+	replay     []replayChunk
+	replayFrom uint64
+	endedAt    time.Time
+}
+
+// newQueue creates the queue of session id.
+func (s *scheduler) newQueue(id uint16, transferType protocol.TransferType) *frameQueue {
+	q := &frameQueue{
+		scheduler: s,
+		id:        id,
+		stream:    transferType == protocol.TransferTypeStream,
+		weight:    streamWeight,
+	}
+	if !q.stream {
+		q.weight = packetWeight
+	}
+	s.Lock()
+	if s.err == nil {
+		s.queues[id] = q
+	}
+	s.Unlock()
+	return q
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (q *frameQueue) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	s := q.scheduler
+	s.Lock()
+	defer s.Unlock()
+
+	for s.err == nil && !q.closed && q.size >= 2*q.weight*quantum {
+		s.cond.Wait()
+	}
+	if s.err != nil || q.closed {
+		buf.ReleaseMulti(mb)
+		return io.ErrClosedPipe
+	}
+	s.push(q, queuedFrame{mb: mb}, false)
+	return nil
+}
+
+// push adds a frame to q, at the front if first.
+func (s *scheduler) push(q *frameQueue, frame queuedFrame, first bool) {
+	if len(q.frames) == 0 {
+		s.active = append(s.active, q)
+	}
+	if first {
+		q.frames = append([]queuedFrame{frame}, q.frames...)
+	} else {
+		q.frames = append(q.frames, frame)
+	}
+	n := int(frame.mb.Len())
+	q.size += n
+	s.cond.Broadcast()
+}
+
+// Close implements common.Closable. The frames queued are still sent.
+func (q *frameQueue) Close() error {
+	s := q.scheduler
+	s.Lock()
+	q.closed = true
+	if len(q.frames) == 0 {
+		s.remove(q)
+	}
+	s.Unlock()
+	s.cond.Broadcast()
+	return nil
+}
+
+// remove forgets a closed queue that is sent, keeping its data for
+// resumption.
+func (s *scheduler) remove(q *frameQueue) {
+	if s.queues[q.id] == q {
+		delete(s.queues, q.id)
+	}
+	if s.err != nil || !s.resumable || !q.stream || !q.started {
+		return
+	}
+	now := time.Now()
+	for id, e := range s.ended {
+		if now.Sub(e.endedAt) > maxResumeTimeout {
+			delete(s.ended, id)
+		}
+	}
+	q.endedAt = now
+	s.ended[q.id] = q
+}
+
+// next returns the next frame to send and the writer to send it with, or nil
+// if the scheduler is closed.
+func (s *scheduler) next() (buf.MultiBuffer, buf.Writer) {
+	s.Lock()
+	defer s.Unlock()
+
+	for (len(s.active) == 0 || s.writer == nil) && s.err == nil {
+		s.cond.Wait()
+	}
+	if s.err != nil {
+		return nil, nil
+	}
+	for {
+		q := s.active[0]
+		if !q.visited {
+			q.visited = true
+			q.deficit += q.weight * quantum
+		}
+		frame := q.frames[0]
+		n := int(frame.mb.Len())
+		if q.deficit < n {
+			// Its share of this round is used up.
+			q.visited = false
+			s.active = append(s.active[1:], q)
+			continue
+		}
+		q.frames[0] = queuedFrame{}
+		q.frames = q.frames[1:]
+		q.deficit -= n
+		q.size -= n
+		if len(q.frames) == 0 {
+			q.frames = nil
+			q.deficit = 0
+			q.visited = false
+			s.active = s.active[1:]
+			if q.closed {
+				s.remove(q)
+			}
+		}
+		if !frame.replayed {
+			s.record(q, frame.mb)
+		}
+		s.cond.Broadcast()
+		return frame.mb, s.writer
+	}
+}
+
+// record counts the data in a frame of q to send, and keeps it if the
+// connection is resumable.
+func (s *scheduler) record(q *frameQueue, frame buf.MultiBuffer) {
+	if !q.started {
+		q.started = true
+		q.replayFrom = q.sent
+	}
+	if len(frame) < 2 {
+		return
+	}
+	// The first buffer is the metadata and the size of the data.
+	data := frame[1:]
+	n := uint64(data.Len())
+	if s.resumable && q.stream {
+		b := make([]byte, n)
+		data.Copy(b)
+		q.replay = append(q.replay, replayChunk{offset: q.sent, data: b})
+	} else {
+		q.replayFrom = q.sent + n
+	}
+	q.sent += n
+}
+
+// acknowledge drops the data of session id that the peer has received.
+func (s *scheduler) acknowledge(id uint16, received uint64) {
+	s.Lock()
+	defer s.Unlock()
+
+	q := s.queues[id]
+	if q == nil {
+		if q = s.ended[id]; q == nil {
+			return
+		}
+	}
+	i := 0
+	for i < len(q.replay) && q.replay[i].offset+uint64(len(q.replay[i].data)) <= received {
+		q.replayFrom = q.replay[i].offset + uint64(len(q.replay[i].data))
+		i++
+	}
+	q.replay = q.replay[i:]
+}
+
+func (s *scheduler) run() {
+	for {
+		frame, writer := s.next()
+		if frame == nil {
+			return
+		}
+		if err := writer.WriteMultiBuffer(frame); err != nil {
+			s.Lock()
+			resumable := s.resumable
+			s.Unlock()
+			if !resumable {
+				s.close(err)
+				return
+			}
+			s.suspend(writer)
+		}
+	}
+}
+
+// WriteMultiBuffer implements buf.Writer. It writes control frames right away,
+// which fails while suspended.
+func (s *scheduler) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	s.Lock()
+	writer, err := s.writer, s.err
+	s.Unlock()
+	if err != nil {
+		buf.ReleaseMulti(mb)
+		return err
+	}
+	if writer == nil {
+		buf.ReleaseMulti(mb)
+		return errSuspended
+	}
+	return writer.WriteMultiBuffer(mb)
+}
+
+// setResumable makes the scheduler keep the data of streams from now on.
+func (s *scheduler) setResumable() {
+	s.Lock()
+	s.resumable = true
+	s.Unlock()
+}
+
+// replaySize returns the size of the data kept for resumption.
+func (s *scheduler) replaySize() int64 {
+	s.Lock()
+	defer s.Unlock()
+
+	var n int64
+	for _, queues := range []map[uint16]*frameQueue{s.queues, s.ended} {
+		for _, q := range queues {
+			for _, chunk := range q.replay {
+				n += int64(len(chunk.data))
+			}
+		}
+	}
+	return n
+}
+
+// suspend stops sending with writer until resume.
+func (s *scheduler) suspend(writer buf.Writer) {
+	s.Lock()
+	if s.writer == writer {
+		s.writer = nil
+	}
+	s.Unlock()
+}
+
+// resume sends with writer.
+func (s *scheduler) resume(writer buf.Writer) {
+	s.Lock()
+	s.writer = writer
+	s.Unlock()
+	s.cond.Broadcast()
+}
+
+// started returns true if the scheduler has sent any frame of session id.
+func (s *scheduler) started(id uint16) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	q := s.queues[id]
+	return q != nil && q.started
+}
+
+// rewind makes session id send again what the peer has not received, which
+// must be kept. It returns how much of the data sent is lost, which is the
+// case for packets.
+func (s *scheduler) rewind(id uint16, received uint64) (uint64, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	q := s.queues[id]
+	ended := false
+	if q == nil {
+		if q = s.ended[id]; q == nil {
+			return 0, false
+		}
+		delete(s.ended, id)
+		ended = true
+	} else {
+		// The connection may be lost again before the replay is sent.
+		ended = s.dropReplayed(q)
+	}
+	if received > q.sent {
+		return 0, false
+	}
+	if !q.stream {
+		return q.sent - received, true
+	}
+	if received < q.replayFrom {
+		return 0, false
+	}
+
+	var frames []queuedFrame
+	for _, chunk := range q.replay {
+		end := chunk.offset + uint64(len(chunk.data))
+		if end <= received {
+			continue
+		}
+		data := chunk.data[max(received, chunk.offset)-chunk.offset:]
+		frames = append(frames, queuedFrame{mb: dataFrame(id, data), replayed: true})
+	}
+	if ended {
+		frames = append(frames, queuedFrame{mb: endFrame(id), replayed: true})
+		// Sent once more, then kept in case of another loss.
+		q.closed = true
+		s.queues[id] = q
+	}
+	for i := len(frames) - 1; i >= 0; i-- {
+		s.push(q, frames[i], true)
+	}
+	return 0, true
+}
+
+// dropReplayed removes the frames of q to send again, and returns true if
+// they end the stream.
+func (s *scheduler) dropReplayed(q *frameQueue) bool {
+	if len(q.frames) == 0 || !q.frames[0].replayed {
+		return false
+	}
+	ended := false
+	frames := q.frames[:0]
+	for _, frame := range q.frames {
+		if !frame.replayed {
+			frames = append(frames, frame)
+			continue
+		}
+		if len(frame.mb) == 1 {
+			ended = true
+		}
+		n := int(frame.mb.Len())
+		q.size -= n
+		buf.ReleaseMulti(frame.mb)
+	}
+	clear(q.frames[len(frames):])
+	q.frames = frames
+	if len(frames) == 0 {
+		q.frames = nil
+		q.deficit = 0
+		q.visited = false
+		for i, e := range s.active {
+			if e == q {
+				s.active = append(s.active[:i], s.active[i+1:]...)
+				break
+			}
+		}
+	}
+	return ended
+}
+
+// close stops the scheduler, dropping the frames not sent.
+func (s *scheduler) close(err error) {
+	s.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	for _, q := range s.active {
+		for _, frame := range q.frames {
+			buf.ReleaseMulti(frame.mb)
+		}
+		q.frames = nil
+		q.size = 0
+	}
+	s.active = nil
+	s.queues = nil
+	s.ended = nil
+	s.Unlock()
+	s.cond.Broadcast()
+}
+
+// Close implements common.Closable.
+func (s *scheduler) Close() error {
+	s.close(io.ErrClosedPipe)
+	return nil
+}
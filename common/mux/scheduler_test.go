@@ -0,0 +1,56 @@
+package mux
+
+import (
+	"testing"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/protocol"
+)
+
+type frameRecorder struct {
+	frames chan string
+}
+
+func (r *frameRecorder) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	r.frames <- mb[0].String()
+	buf.ReleaseMulti(mb)
+	return nil
+}
+
+func frame(name string, size int32) buf.MultiBuffer {
+	b := buf.New()
+	b.WriteString(name)
+	b.Extend(size - b.Len())
+	return buf.MultiBuffer{b}
+}
+
+func TestSchedulerFairness(t *testing.T) {
+	recorder := &frameRecorder{frames: make(chan string)}
+	s := newScheduler(recorder)
+	defer s.Close()
+
+	bulk := s.newQueue(1, protocol.TransferTypeStream)
+	common.Must(bulk.WriteMultiBuffer(frame("b", buf.Size)))
+	// The scheduler is blocked writing the first frame of bulk, which queues
+	// more.
+	common.Must(bulk.WriteMultiBuffer(frame("b", buf.Size)))
+	common.Must(bulk.WriteMultiBuffer(frame("b", buf.Size)))
+	packets := s.newQueue(2, protocol.TransferTypePacket)
+	common.Must(packets.WriteMultiBuffer(frame("p", 100)))
+
+	// A round of bulk is one full frame.
+	var order string
+	for range 4 {
+		order += (<-recorder.frames)[:1]
+	}
+	if order != "bpbb" {
+		t.Error("unexpected order ", order)
+	}
+	s.Lock()
+	n := len(s.active)
+	s.Unlock()
+	if n != 0 {
+		t.Error("unexpected queues with frames ", n)
+	}
+}
@@ -3,6 +3,7 @@ package mux
 import (
 	"context"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/xtls/xray-core/common"
@@ -21,6 +22,9 @@ import (
 
 type Server struct {
 	dispatcher routing.Dispatcher
+	// ResumeTimeout is the longest the sessions of a lost connection are
+	// kept for the client to resume them. Resumption is disabled if zero.
+	ResumeTimeout time.Duration
 }
 
 // NewServer creates a new mux.Server.
@@ -47,10 +51,10 @@ func (s *Server) Dispatch(ctx context.Context, dest net.Destination) (*transport
 	uplinkReader, uplinkWriter := pipe.New(opts...)
 	downlinkReader, downlinkWriter := pipe.New(opts...)
 
-	_, err := NewServerWorker(ctx, s.dispatcher, &transport.Link{
+	_, err := NewResumableServerWorker(ctx, s.dispatcher, &transport.Link{
 		Reader: uplinkReader,
 		Writer: downlinkWriter,
-	})
+	}, s.ResumeTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -63,13 +67,14 @@ func (s *Server) DispatchLink(ctx context.Context, dest net.Destination, link *t
 	if dest.Address != muxCoolAddress {
 		return s.dispatcher.DispatchLink(ctx, dest, link)
 	}
-	worker, err := NewServerWorker(ctx, s.dispatcher, link)
+	worker, err := NewResumableServerWorker(ctx, s.dispatcher, link, s.ResumeTimeout)
 	if err != nil {
 		return err
 	}
 	select {
 	case <-ctx.Done():
 	case <-worker.done.Wait():
+	case <-worker.finished.Wait():
 	}
 	return nil
 }
@@ -90,26 +95,52 @@ type ServerWorker struct {
 	sessionManager *SessionManager
 	done           *done.Instance
 	timer          *time.Ticker
+	scheduler      *scheduler
+	// finished is closed when the connection ends, which may be before done
+	// if the sessions are kept for resumption.
+	finished *done.Instance
+	// resumeTimeout is the longest the sessions are kept for resumption,
+	// which is disabled if zero.
+	resumeTimeout time.Duration
+	// token, timeout and owner are set when the client registers for
+	// resumption. suspended is the data kept after the connection is lost.
+	token     [16]byte
+	timeout   time.Duration
+	owner     resumeOwner
+	suspended int64
+	// adopted is set when another worker resumes the sessions.
+	adopted atomic.Bool
 }
 
 func NewServerWorker(ctx context.Context, d routing.Dispatcher, link *transport.Link) (*ServerWorker, error) {
+	return NewResumableServerWorker(ctx, d, link, 0)
+}
+
+// NewResumableServerWorker creates a ServerWorker which keeps the sessions up
+// to resumeTimeout for the client to resume them after the connection is lost.
+func NewResumableServerWorker(ctx context.Context, d routing.Dispatcher, link *transport.Link, resumeTimeout time.Duration) (*ServerWorker, error) {
 	worker := &ServerWorker{
+		resumeTimeout:  min(resumeTimeout, maxResumeTimeout),
 		dispatcher:     d,
 		link:           link,
 		sessionManager: NewSessionManager(),
 		done:           done.New(),
 		timer:          time.NewTicker(60 * time.Second),
+		scheduler:      newScheduler(link.Writer),
+		finished:       done.New(),
 	}
 	if inbound := session.InboundFromContext(ctx); inbound != nil {
 		inbound.CanSpliceCopy = 3
 	}
 	go worker.run(ctx)
-	go worker.monitor()
 	return worker, nil
 }
 
-func handle(ctx context.Context, s *Session, output buf.Writer) {
-	writer := NewResponseWriter(s.ID, output, s.transferType)
+func handle(ctx context.Context, s *Session, scheduler *scheduler) {
+	queue := scheduler.newQueue(s.ID, s.transferType)
+	defer queue.Close()
+	writer := NewResponseWriter(s.ID, queue, s.transferType)
+	writer.window = s.window
 	if err := buf.Copy(s.input, writer); err != nil {
 		errors.LogInfoInner(ctx, err, "session ", s.ID, " ends.")
 		writer.hasError = true
@@ -127,6 +158,10 @@ func (w *ServerWorker) monitor() {
 		checkCount := w.sessionManager.Count()
 		select {
 		case <-w.done.Wait():
+			if w.adopted.Load() {
+				return
+			}
+			w.scheduler.Close()
 			w.sessionManager.Close()
 			common.Interrupt(w.link.Writer)
 			common.Interrupt(w.link.Reader)
@@ -162,6 +197,26 @@ func (w *ServerWorker) handleStatusKeepAlive(meta *FrameMetadata, reader *buf.Bu
 	return nil
 }
 
+// enableFlowControl enables flow control in a new session if the peer
+// supports it, and acknowledges it with a window update.
+func (w *ServerWorker) enableFlowControl(meta *FrameMetadata, s *Session) error {
+	if !meta.Option.Has(OptionFlowControl) {
+		return nil
+	}
+	s.window = newSendWindow(true, &w.scheduler.stalls)
+	s.startReceiver(w.scheduler)
+	ack := FrameMetadata{
+		SessionID:     s.ID,
+		SessionStatus: SessionStatusWindowUpdate,
+	}
+	if w.resumeTimeout > 0 {
+		ack.Option.Set(OptionResume)
+	}
+	frame := buf.New()
+	common.Must(ack.WriteTo(frame))
+	return w.scheduler.WriteMultiBuffer(buf.MultiBuffer{frame})
+}
+
 func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata, reader *buf.BufferedReader) error {
 	ctx = session.SubContextFromMuxInbound(ctx)
 	if meta.Inbound != nil && meta.Inbound.Source.IsValid() && meta.Inbound.Local.IsValid() {
@@ -253,11 +308,15 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 			XUDP:         x,
 		}
 		x.Status = Active
+		if err := w.enableFlowControl(meta, x.Mux); err != nil {
+			x.Mux.Close(false)
+			return err
+		}
 		if !w.sessionManager.Add(x.Mux) {
 			x.Mux.Close(false)
 			return errors.New("failed to add new session")
 		}
-		go handle(ctx, x.Mux, w.link.Writer)
+		go handle(ctx, x.Mux, w.scheduler)
 		return nil
 	}
 
@@ -278,17 +337,21 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 	if meta.Target.Network == net.Network_UDP {
 		s.transferType = protocol.TransferTypePacket
 	}
+	if err := w.enableFlowControl(meta, s); err != nil {
+		s.Close(false)
+		return err
+	}
 	if !w.sessionManager.Add(s) {
 		s.Close(false)
 		return errors.New("failed to add new session")
 	}
-	go handle(ctx, s, w.link.Writer)
+	go handle(ctx, s, w.scheduler)
 	if !meta.Option.Has(OptionData) {
 		return nil
 	}
 
 	rr := s.NewReader(reader, &meta.Target)
-	err = buf.Copy(rr, s.output)
+	err = buf.Copy(rr, s.receiveWriter())
 
 	if err != nil && buf.IsWriteError(err) {
 		s.Close(false)
@@ -305,14 +368,14 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	s, found := w.sessionManager.Get(meta.SessionID)
 	if !found {
 		// Notify remote peer to close this session.
-		closingWriter := NewResponseWriter(meta.SessionID, w.link.Writer, protocol.TransferTypeStream)
+		closingWriter := NewResponseWriter(meta.SessionID, w.scheduler, protocol.TransferTypeStream)
 		closingWriter.Close()
 
 		return buf.Copy(NewStreamReader(reader), buf.Discard)
 	}
 
 	rr := s.NewReader(reader, &meta.Target)
-	err := buf.Copy(rr, s.output)
+	err := buf.Copy(rr, s.receiveWriter())
 
 	if err != nil && buf.IsWriteError(err) {
 		errors.LogInfoInner(context.Background(), err, "failed to write to downstream writer. closing session ", s.ID)
@@ -323,6 +386,100 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	return err
 }
 
+func (w *ServerWorker) handleStatusWindowUpdate(meta *FrameMetadata) error {
+	if s, found := w.sessionManager.Get(meta.SessionID); found && s.window != nil {
+		w.scheduler.acknowledge(s.ID, s.window.update(meta.Window))
+	}
+	return nil
+}
+
+// handleStatusResume registers the connection for resumption, which resumes
+// sessions only as the first frame.
+func (w *ServerWorker) handleStatusResume(ctx context.Context, meta *FrameMetadata) error {
+	if meta.Option.Has(OptionData) {
+		return errors.New("unexpected resumption")
+	}
+	if w.resumeTimeout == 0 || w.token != [16]byte{} {
+		return nil
+	}
+	w.token = meta.Token
+	w.timeout = min(time.Duration(meta.Timeout)*time.Second, w.resumeTimeout)
+	w.owner = resumeOwnerFromContext(ctx)
+	if !registerResumable(w) {
+		w.token = [16]byte{}
+		errors.LogInfo(ctx, "mux connection not resumable, too many of ", w.owner.email, " in ", w.owner.tag)
+		return nil
+	}
+	w.scheduler.setResumable()
+	return nil
+}
+
+// resume takes over the sessions of the worker registered with the token in
+// meta, and returns false if they are gone. The connection of the worker may
+// not be found lost yet.
+func (w *ServerWorker) resume(ctx context.Context, meta *FrameMetadata, reader *buf.BufferedReader) (bool, error) {
+	peers, err := readResumeEntries(reader)
+	if err != nil {
+		return false, err
+	}
+	var o *ServerWorker
+	if w.resumeTimeout > 0 {
+		o = lookupResumable(meta.Token, resumeOwnerFromContext(ctx))
+	}
+	if o != nil {
+		common.Interrupt(o.link.Reader)
+		common.Interrupt(o.link.Writer)
+		select {
+		case <-o.finished.Wait():
+		case <-time.After(resumeHandshakeTimeout):
+		}
+	}
+	if o == nil || !o.finished.Done() || o.done.Done() || !adoptResumable(o, w) {
+		reply := FrameMetadata{Token: meta.Token}
+		reply.Option.Set(OptionError)
+		return false, writeResume(w.link.Writer, reply, nil)
+	}
+	o.adopted.Store(true)
+	common.Must(o.done.Close())
+
+	w.scheduler.Close()
+	w.sessionManager = o.sessionManager
+	w.scheduler = o.scheduler
+	w.token = o.token
+	w.timeout = o.timeout
+	w.owner = o.owner
+	reconcile(w.sessionManager, w.scheduler, w.link.Writer, peers, false)
+	entries := resumeEntries(w.sessionManager, w.scheduler, false)
+	if err := writeResume(w.link.Writer, FrameMetadata{Token: w.token}, entries); err != nil {
+		return true, err
+	}
+	w.scheduler.resume(w.link.Writer)
+	errors.LogInfo(ctx, "mux connection resumed with ", len(entries), " sessions")
+	return true, nil
+}
+
+// detach keeps the sessions for the client to resume after the connection is
+// lost, and returns false if they are not kept.
+func (w *ServerWorker) detach(ctx context.Context) bool {
+	if w.token == [16]byte{} || w.sessionManager.Closed() || w.sessionManager.Size() == 0 {
+		return false
+	}
+	if !suspendResumable(w, w.scheduler.replaySize()) {
+		errors.LogInfo(ctx, "mux connection lost, too much data kept for resumption")
+		return false
+	}
+	w.scheduler.suspend(w.link.Writer)
+	common.Interrupt(w.link.Writer)
+	common.Interrupt(w.link.Reader)
+	time.AfterFunc(w.timeout, func() {
+		if forgetResumable(w) {
+			common.Must(w.done.Close())
+		}
+	})
+	errors.LogInfo(ctx, "mux connection lost, keeping ", w.sessionManager.Size(), " sessions for ", w.timeout)
+	return true
+}
+
 func (w *ServerWorker) handleStatusEnd(meta *FrameMetadata, reader *buf.BufferedReader) error {
 	if s, found := w.sessionManager.Get(meta.SessionID); found {
 		s.Close(false)
@@ -333,13 +490,20 @@ func (w *ServerWorker) handleStatusEnd(meta *FrameMetadata, reader *buf.Buffered
 	return nil
 }
 
-func (w *ServerWorker) handleFrame(ctx context.Context, reader *buf.BufferedReader) error {
+func (w *ServerWorker) handleFrame(ctx context.Context, reader *buf.BufferedReader, first bool) error {
 	var meta FrameMetadata
 	err := meta.Unmarshal(reader, session.IsReverseMuxFromContext(ctx))
 	if err != nil {
 		return errors.New("failed to read metadata").Base(err)
 	}
 
+	if first && meta.SessionStatus == SessionStatusResume && meta.Option.Has(OptionData) {
+		if resumed, err := w.resume(ctx, &meta, reader); !resumed || err != nil {
+			return errors.New("failed to resume").Base(err)
+		}
+		return nil
+	}
+
 	switch meta.SessionStatus {
 	case SessionStatusKeepAlive:
 		err = w.handleStatusKeepAlive(&meta, reader)
@@ -349,6 +513,10 @@ func (w *ServerWorker) handleFrame(ctx context.Context, reader *buf.BufferedRead
 		err = w.handleStatusNew(session.ContextWithIsReverseMux(ctx, false), &meta, reader)
 	case SessionStatusKeep:
 		err = w.handleStatusKeep(&meta, reader)
+	case SessionStatusWindowUpdate:
+		err = w.handleStatusWindowUpdate(&meta)
+	case SessionStatusResume:
+		err = w.handleStatusResume(ctx, &meta)
 	default:
 		status := meta.SessionStatus
 		return errors.New("unknown status: ", status).AtError()
@@ -362,23 +530,37 @@ func (w *ServerWorker) handleFrame(ctx context.Context, reader *buf.BufferedRead
 
 func (w *ServerWorker) run(ctx context.Context) {
 	defer func() {
-		common.Must(w.done.Close())
+		common.Must(w.finished.Close())
 	}()
 
 	reader := &buf.BufferedReader{Reader: w.link.Reader}
 
+	// The monitor starts after the first frame, which may resume the sessions
+	// of another worker.
+	first := true
 	for {
 		select {
 		case <-ctx.Done():
-			return
 		default:
-			err := w.handleFrame(ctx, reader)
-			if err != nil {
-				if errors.Cause(err) != io.EOF {
-					errors.LogInfoInner(ctx, err, "unexpected EOF")
-				}
-				return
+			err := w.handleFrame(ctx, reader, first)
+			if first {
+				first = false
+				go w.monitor()
+			}
+			if err == nil {
+				continue
 			}
+			if errors.Cause(err) != io.EOF {
+				errors.LogInfoInner(ctx, err, "unexpected EOF")
+			}
+		}
+		if first {
+			go w.monitor()
+		}
+		if !w.detach(ctx) {
+			forgetResumable(w)
+			common.Must(w.done.Close())
 		}
+		return
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"io"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/xtls/xray-core/common"
@@ -163,6 +164,16 @@ type Session struct {
 	closed       bool
 	done         *done.Instance
 	XUDP         *XUDP
+	// window and receiver are set if the session has flow control.
+	window   *sendWindow
+	receiver *pipe.Writer
+	// received is the data received in total, and granted the window granted
+	// to the peer in total, which are exchanged on resumption. consumed is the
+	// data delivered but not granted yet.
+	received atomic.Uint64
+	grant    sync.Mutex
+	granted  uint64
+	consumed uint32
 }
 
 // Close closes all resources associated with this session.
@@ -179,9 +190,18 @@ func (s *Session) Close(locked bool) error {
 	if s.done != nil {
 		s.done.Close()
 	}
+	if s.window != nil {
+		s.window.close()
+	}
+	if s.receiver != nil {
+		// The receiver closes the output after delivering what is received.
+		common.Close(s.receiver)
+	}
 	if s.XUDP == nil {
 		common.Interrupt(s.input)
-		common.Close(s.output)
+		if s.receiver == nil {
+			common.Close(s.output)
+		}
 	} else {
 		// Stop existing handle(), then trigger writer.Close().
 		// Note that s.output may be dispatcher.SizeStatWriter.
@@ -18,6 +18,8 @@ type Writer struct {
 	transferType protocol.TransferType
 	globalID     [8]byte
 	inbound      *session.Inbound
+	// window limits the data sent if the session has flow control.
+	window *sendWindow
 }
 
 func NewWriter(id uint16, dest net.Destination, writer buf.Writer, transferType protocol.TransferType, globalID [8]byte, inbound *session.Inbound) *Writer {
@@ -54,6 +56,9 @@ func (w *Writer) getNextFrameMeta() FrameMetadata {
 	} else {
 		w.followup = true
 		meta.SessionStatus = SessionStatusNew
+		if w.window != nil {
+			meta.Option.Set(OptionFlowControl)
+		}
 	}
 
 	return meta
@@ -104,12 +109,29 @@ func (w *Writer) WriteMultiBuffer(mb buf.MultiBuffer) error {
 	for !mb.IsEmpty() {
 		var chunk buf.MultiBuffer
 		if w.transferType == protocol.TransferTypeStream {
-			mb, chunk = buf.SplitSize(mb, 8*1024)
+			size := int32(8 * 1024)
+			if w.window != nil {
+				var err error
+				if size, err = w.window.acquire(size); err != nil {
+					return err
+				}
+			}
+			mb, chunk = buf.SplitSize(mb, size)
 		} else {
+			if w.window != nil {
+				// A packet is never split, the window may be exceeded by
+				// one packet.
+				if _, err := w.window.acquire(mb[0].Len()); err != nil {
+					return err
+				}
+			}
 			mb2, b := buf.SplitFirst(mb)
 			mb = mb2
 			chunk = buf.MultiBuffer{b}
 		}
+		if w.window != nil {
+			w.window.consume(chunk.Len())
+		}
 		if err := w.writeData(chunk); err != nil {
 			return err
 		}
@@ -0,0 +1,323 @@
+// Package pcapng writes proxied flows to pcapng as raw IP packets. Only the
+// payload of a flow is known to a proxy, so the IP, TCP and UDP headers are
+// synthesized: a TCP flow starts with a handshake and ends with FINs, and a
+// domain is given an address in 198.18.0.0/15, which is reserved for
+// benchmarking.
+package pcapng
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+)
+
+const (
+	blockSectionHeader  = 0x0A0D0D0A
+	blockInterface      = 0x00000001
+	blockEnhancedPacket = 0x00000006
+	byteOrderMagic      = 0x1A2B3C4D
+	linkTypeRaw         = 101
+	enhancedPacketLen   = 32
+	maxSegmentSize      = 32 * 1024
+
+	tcpFlagFin    = 0x01
+	tcpFlagSyn    = 0x02
+	tcpFlagPsh    = 0x08
+	tcpFlagAck    = 0x10
+	protocolTCP   = 6
+	protocolUDP   = 17
+	maxUDPPayload = 65535 - 8 - 40
+)
+
+// Direction is the direction of the data in a flow.
+type Direction byte
+
+const (
+	// Uplink is the data from the client to the server.
+	Uplink Direction = 1
+	// Downlink is the data from the server to the client.
+	Downlink Direction = 2
+)
+
+// Writer writes packets to a pcapng section with a single raw IP interface.
+// It is safe for concurrent use.
+type Writer struct {
+	sync.Mutex
+	writer io.Writer
+}
+
+// NewWriter writes the header of a section to w, and returns a Writer of the
+// packets in it.
+func NewWriter(w io.Writer) (*Writer, error) {
+	b := make([]byte, 28+20)
+	binary.LittleEndian.PutUint32(b, blockSectionHeader)
+	binary.LittleEndian.PutUint32(b[4:], 28)
+	binary.LittleEndian.PutUint32(b[8:], byteOrderMagic)
+	binary.LittleEndian.PutUint16(b[12:], 1)
+	binary.LittleEndian.PutUint16(b[14:], 0)
+	// The section length is unspecified.
+	binary.LittleEndian.PutUint64(b[16:], 0xFFFFFFFFFFFFFFFF)
+	binary.LittleEndian.PutUint32(b[24:], 28)
+
+	idb := b[28:]
+	binary.LittleEndian.PutUint32(idb, blockInterface)
+	binary.LittleEndian.PutUint32(idb[4:], 20)
+	binary.LittleEndian.PutUint16(idb[8:], linkTypeRaw)
+	binary.LittleEndian.PutUint32(idb[12:], 0)
+	binary.LittleEndian.PutUint32(idb[16:], 20)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	return &Writer{writer: w}, nil
+}
+
+// WritePacket writes an IP packet in an enhanced packet block, with a single
+// call to the underlying writer.
+func (w *Writer) WritePacket(t time.Time, packet []byte) error {
+	padded := (len(packet) + 3) &^ 3
+	size := enhancedPacketLen + padded
+	b := make([]byte, size)
+	binary.LittleEndian.PutUint32(b, blockEnhancedPacket)
+	binary.LittleEndian.PutUint32(b[4:], uint32(size))
+	binary.LittleEndian.PutUint32(b[8:], 0)
+	us := uint64(t.UnixMicro())
+	binary.LittleEndian.PutUint32(b[12:], uint32(us>>32))
+	binary.LittleEndian.PutUint32(b[16:], uint32(us))
+	binary.LittleEndian.PutUint32(b[20:], uint32(len(packet)))
+	binary.LittleEndian.PutUint32(b[24:], uint32(len(packet)))
+	copy(b[28:], packet)
+	binary.LittleEndian.PutUint32(b[size-4:], uint32(size))
+
+	w.Lock()
+	defer w.Unlock()
+	_, err := w.writer.Write(b)
+	return err
+}
+
+type endpoint struct {
+	ip   net.IP
+	port uint16
+}
+
+// endpointOf returns the IP endpoint of dest, synthesizing the address of a
+// domain, or using fallback if dest is unknown.
+func endpointOf(dest net.Destination, fallback net.Address) endpoint {
+	e := endpoint{port: uint16(dest.Port)}
+	switch {
+	case dest.Address == nil:
+		e.ip = fallback.IP()
+	case dest.Address.Family().IsDomain():
+		h := fnv.New32a()
+		h.Write([]byte(dest.Address.Domain()))
+		sum := h.Sum32()
+		e.ip = net.IP{198, 18 | byte(sum>>16)&1, byte(sum >> 8), byte(sum)}
+	default:
+		e.ip = dest.Address.IP()
+	}
+	if ip := e.ip.To4(); ip != nil {
+		e.ip = ip
+	}
+	return e
+}
+
+// Flow writes the data of a connection from source to destination as
+// packets. It is not safe for concurrent use.
+type Flow struct {
+	writer    *Writer
+	udp       bool
+	client    endpoint
+	server    endpoint
+	clientSeq uint32
+	serverSeq uint32
+}
+
+// NewFlow starts a flow, writing the TCP handshake at time t.
+func NewFlow(w *Writer, source, destination net.Destination, t time.Time) (*Flow, error) {
+	f := &Flow{
+		writer: w,
+		udp:    destination.Network == net.Network_UDP,
+		client: endpointOf(source, net.LocalHostIP),
+		server: endpointOf(destination, nil),
+	}
+	if len(f.client.ip) != len(f.server.ip) {
+		f.client.ip = f.client.ip.To16()
+		f.server.ip = f.server.ip.To16()
+	}
+	if f.udp {
+		return f, nil
+	}
+	f.clientSeq = uint32(t.UnixNano())
+	f.serverSeq = f.clientSeq * 7
+	if err := f.segment(t, Uplink, tcpFlagSyn, nil); err != nil {
+		return nil, err
+	}
+	f.clientSeq++
+	if err := f.segment(t, Downlink, tcpFlagSyn|tcpFlagAck, nil); err != nil {
+		return nil, err
+	}
+	f.serverSeq++
+	if err := f.segment(t, Uplink, tcpFlagAck, nil); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes the data in mb, as TCP segments or as a UDP datagram per
+// buffer. It doesn't take the ownership of mb.
+func (f *Flow) Write(dir Direction, mb buf.MultiBuffer) error {
+	t := time.Now()
+	for _, b := range mb {
+		data := b.Bytes()
+		if f.udp {
+			if err := f.datagram(t, dir, b.UDP, data); err != nil {
+				return err
+			}
+			continue
+		}
+		for len(data) > 0 {
+			n := min(len(data), maxSegmentSize)
+			if err := f.segment(t, dir, tcpFlagPsh|tcpFlagAck, data[:n]); err != nil {
+				return err
+			}
+			data = data[n:]
+		}
+	}
+	return nil
+}
+
+// Close ends the flow, writing the FINs of a TCP flow.
+func (f *Flow) Close() error {
+	if f.udp {
+		return nil
+	}
+	t := time.Now()
+	if err := f.segment(t, Uplink, tcpFlagFin|tcpFlagAck, nil); err != nil {
+		return err
+	}
+	f.clientSeq++
+	if err := f.segment(t, Downlink, tcpFlagFin|tcpFlagAck, nil); err != nil {
+		return err
+	}
+	f.serverSeq++
+	return f.segment(t, Uplink, tcpFlagAck, nil)
+}
+
+// segment writes a TCP segment, advancing the sequence number of its sender
+// by the data.
+func (f *Flow) segment(t time.Time, dir Direction, flags byte, data []byte) error {
+	src, dst := f.client, f.server
+	seq, ack := &f.clientSeq, f.serverSeq
+	if dir == Downlink {
+		src, dst = f.server, f.client
+		seq, ack = &f.serverSeq, f.clientSeq
+	}
+	if flags&tcpFlagAck == 0 {
+		ack = 0
+	}
+	tcp := make([]byte, 20+len(data))
+	binary.BigEndian.PutUint16(tcp, src.port)
+	binary.BigEndian.PutUint16(tcp[2:], dst.port)
+	binary.BigEndian.PutUint32(tcp[4:], *seq)
+	binary.BigEndian.PutUint32(tcp[8:], ack)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+	binary.BigEndian.PutUint16(tcp[14:], 65535)
+	copy(tcp[20:], data)
+	binary.BigEndian.PutUint16(tcp[16:], transportChecksum(src.ip, dst.ip, protocolTCP, tcp))
+	*seq += uint32(len(data))
+	return f.writer.WritePacket(t, ipPacket(src.ip, dst.ip, protocolTCP, tcp))
+}
+
+// datagram writes a UDP datagram, from or to the address of a packet if it
+// differs from the destination of the flow.
+func (f *Flow) datagram(t time.Time, dir Direction, addr *net.Destination, data []byte) error {
+	peer := f.server
+	if addr != nil {
+		peer = endpointOf(*addr, nil)
+	}
+	src, dst := f.client, peer
+	if dir == Downlink {
+		src, dst = peer, f.client
+	}
+	if len(src.ip) != len(dst.ip) {
+		if src4, dst4 := src.ip.To4(), dst.ip.To4(); src4 != nil && dst4 != nil {
+			src.ip, dst.ip = src4, dst4
+		} else {
+			src.ip, dst.ip = src.ip.To16(), dst.ip.To16()
+		}
+	}
+	if len(data) > maxUDPPayload {
+		data = data[:maxUDPPayload]
+	}
+	udp := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint16(udp, src.port)
+	binary.BigEndian.PutUint16(udp[2:], dst.port)
+	binary.BigEndian.PutUint16(udp[4:], uint16(len(udp)))
+	copy(udp[8:], data)
+	binary.BigEndian.PutUint16(udp[6:], transportChecksum(src.ip, dst.ip, protocolUDP, udp))
+	return f.writer.WritePacket(t, ipPacket(src.ip, dst.ip, protocolUDP, udp))
+}
+
+// ipPacket returns payload in an IPv4 or IPv6 packet.
+func ipPacket(src, dst net.IP, protocol byte, payload []byte) []byte {
+	if len(src) == net.IPv4len {
+		packet := make([]byte, 20+len(payload))
+		packet[0] = 0x45
+		binary.BigEndian.PutUint16(packet[2:], uint16(len(packet)))
+		// Don't fragment.
+		binary.BigEndian.PutUint16(packet[6:], 0x4000)
+		packet[8] = 64
+		packet[9] = protocol
+		copy(packet[12:], src)
+		copy(packet[16:], dst)
+		binary.BigEndian.PutUint16(packet[10:], ^fold(checksum(0, packet[:20])))
+		copy(packet[20:], payload)
+		return packet
+	}
+	packet := make([]byte, 40+len(payload))
+	packet[0] = 0x60
+	binary.BigEndian.PutUint16(packet[4:], uint16(len(payload)))
+	packet[6] = protocol
+	packet[7] = 64
+	copy(packet[8:], src)
+	copy(packet[24:], dst)
+	copy(packet[40:], payload)
+	return packet
+}
+
+// transportChecksum returns the checksum of a TCP or UDP packet with its
+// checksum field zeroed.
+func transportChecksum(src, dst net.IP, protocol byte, packet []byte) uint16 {
+	sum := checksum(0, src)
+	sum = checksum(sum, dst)
+	sum += uint32(protocol) + uint32(len(packet))
+	sum = checksum(sum, packet)
+	c := ^fold(sum)
+	if c == 0 && protocol == protocolUDP {
+		return 0xFFFF
+	}
+	return c
+}
+
+// checksum adds the 16-bit words of b to sum.
+func checksum(sum uint32, b []byte) uint32 {
+	for ; len(b) >= 2; b = b[2:] {
+		sum += uint32(b[0])<<8 | uint32(b[1])
+	}
+	if len(b) == 1 {
+		sum += uint32(b[0]) << 8
+	}
+	return sum
+}
+
+func fold(sum uint32) uint16 {
+	for sum > 0xFFFF {
+		sum = sum>>16 + sum&0xFFFF
+	}
+	return uint16(sum)
+}
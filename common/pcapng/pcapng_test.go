@@ -0,0 +1,114 @@
+package pcapng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+)
+
+func parse(t *testing.T, b []byte) [][]byte {
+	if len(b) < 48 || binary.LittleEndian.Uint32(b) != blockSectionHeader || binary.LittleEndian.Uint32(b[28:]) != blockInterface {
+		t.Fatal("invalid pcapng header")
+	}
+	var packets [][]byte
+	for b = b[48:]; len(b) > 0; {
+		size := binary.LittleEndian.Uint32(b[4:])
+		if size%4 != 0 || binary.LittleEndian.Uint32(b[size-4:]) != size {
+			t.Fatal("invalid block size ", size)
+		}
+		if binary.LittleEndian.Uint32(b) == blockEnhancedPacket {
+			n := binary.LittleEndian.Uint32(b[20:])
+			packets = append(packets, b[28:28+n])
+		}
+		b = b[size:]
+	}
+	return packets
+}
+
+func bufferOf(data string) buf.MultiBuffer {
+	b := buf.New()
+	b.WriteString(data)
+	return buf.MultiBuffer{b}
+}
+
+func TestTCPFlow(t *testing.T) {
+	var output bytes.Buffer
+	w, err := NewWriter(&output)
+	common.Must(err)
+	f, err := NewFlow(w, net.TCPDestination(net.ParseAddress("10.0.0.1"), 40000), net.TCPDestination(net.DomainAddress("www.example.com"), 80), time.Now())
+	common.Must(err)
+	mb := bufferOf("hello")
+	common.Must(f.Write(Uplink, mb))
+	common.Must(f.Write(Downlink, mb))
+	buf.ReleaseMulti(mb)
+	common.Must(f.Close())
+
+	packets := parse(t, output.Bytes())
+	flags := []byte{tcpFlagSyn, tcpFlagSyn | tcpFlagAck, tcpFlagAck, tcpFlagPsh | tcpFlagAck, tcpFlagPsh | tcpFlagAck, tcpFlagFin | tcpFlagAck, tcpFlagFin | tcpFlagAck, tcpFlagAck}
+	if len(packets) != len(flags) {
+		t.Fatal("unexpected number of packets ", len(packets))
+	}
+	for i, packet := range packets {
+		if packet[0] != 0x45 || packet[9] != protocolTCP {
+			t.Fatal("unexpected IP header of packet ", i)
+		}
+		if fold(checksum(0, packet[:20])) != 0xFFFF {
+			t.Error("wrong IP checksum of packet ", i)
+		}
+		if transportChecksum(packet[12:16], packet[16:20], protocolTCP, packet[20:]) != 0 {
+			t.Error("wrong TCP checksum of packet ", i)
+		}
+		if packet[20+13] != flags[i] {
+			t.Error("unexpected flags of packet ", i, ": ", packet[20+13])
+		}
+	}
+	if !bytes.Equal(packets[0][12:16], []byte{10, 0, 0, 1}) || packets[0][16] != 198 || packets[0][17]&0xFE != 18 {
+		t.Error("unexpected addresses ", packets[0][12:20])
+	}
+	if string(packets[3][40:]) != "hello" || string(packets[4][40:]) != "hello" {
+		t.Error("unexpected payloads")
+	}
+	// The data of the server follows its SYN.
+	if binary.BigEndian.Uint32(packets[4][24:]) != binary.BigEndian.Uint32(packets[1][24:])+1 {
+		t.Error("unexpected sequence number")
+	}
+	// The FIN of the client follows its data.
+	if binary.BigEndian.Uint32(packets[5][24:]) != binary.BigEndian.Uint32(packets[3][24:])+5 {
+		t.Error("unexpected sequence number")
+	}
+}
+
+func TestUDPFlow(t *testing.T) {
+	var output bytes.Buffer
+	w, err := NewWriter(&output)
+	common.Must(err)
+	f, err := NewFlow(w, net.UDPDestination(net.ParseAddress("10.0.0.1"), 40000), net.UDPDestination(net.ParseAddress("2001:db8::1"), 53), time.Now())
+	common.Must(err)
+	mb := bufferOf("query")
+	common.Must(f.Write(Uplink, mb))
+	mb[0].UDP = &net.Destination{Network: net.Network_UDP, Address: net.ParseAddress("192.0.2.1"), Port: 443}
+	common.Must(f.Write(Downlink, mb))
+	buf.ReleaseMulti(mb)
+	common.Must(f.Close())
+
+	packets := parse(t, output.Bytes())
+	if len(packets) != 2 {
+		t.Fatal("unexpected number of packets ", len(packets))
+	}
+	// The IPv4 client is mapped into IPv6 to talk to the server.
+	if packets[0][0] != 0x60 || packets[0][6] != protocolUDP || net.IP(packets[0][8:24]).To4() == nil || string(packets[0][48:]) != "query" {
+		t.Error("unexpected uplink ", packets[0])
+	}
+	// The packet from another address than the server is IPv4.
+	if packets[1][0] != 0x45 || !bytes.Equal(packets[1][12:16], []byte{192, 0, 2, 1}) || binary.BigEndian.Uint16(packets[1][20:]) != 443 {
+		t.Error("unexpected downlink ", packets[1])
+	}
+	if transportChecksum(packets[1][12:16], packets[1][16:20], protocolUDP, packets[1][20:]) != 0xFFFF {
+		t.Error("wrong UDP checksum")
+	}
+}
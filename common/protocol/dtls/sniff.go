@@ -0,0 +1,56 @@
+package dtls
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/xtls/xray-core/common"
+)
+
+type SniffHeader struct{}
+
+func (h *SniffHeader) Protocol() string {
+	return "dtls"
+}
+
+func (h *SniffHeader) Domain() string {
+	return ""
+}
+
+const (
+	recordHeaderSize    = 13
+	handshakeHeaderSize = 12
+
+	contentTypeHandshake = 22
+	handshakeClientHello = 1
+)
+
+var errNotDTLS = errors.New("not dtls")
+
+// SniffDTLS detects the ClientHello of DTLS 1.0 and 1.2, which also sets up
+// the SRTP keys of WebRTC media.
+func SniffDTLS(b []byte) (*SniffHeader, error) {
+	if len(b) < recordHeaderSize+handshakeHeaderSize {
+		return nil, common.ErrNoClue
+	}
+	if b[0] != contentTypeHandshake {
+		return nil, errNotDTLS
+	}
+	// DTLS 1.0 is 0xfeff and DTLS 1.2 is 0xfefd, the record of a
+	// ClientHello may carry either.
+	if version := binary.BigEndian.Uint16(b[1:]); version != 0xfeff && version != 0xfefd {
+		return nil, errNotDTLS
+	}
+	// The epoch of the first flight is 0.
+	if binary.BigEndian.Uint16(b[3:]) != 0 {
+		return nil, errNotDTLS
+	}
+	length := int(binary.BigEndian.Uint16(b[11:]))
+	if length < handshakeHeaderSize || recordHeaderSize+length > len(b) {
+		return nil, errNotDTLS
+	}
+	if b[recordHeaderSize] != handshakeClientHello {
+		return nil, errNotDTLS
+	}
+	return &SniffHeader{}, nil
+}
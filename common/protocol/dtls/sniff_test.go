@@ -0,0 +1,32 @@
+package dtls_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/common/protocol/dtls"
+)
+
+func TestSniffDTLS(t *testing.T) {
+	// Record header of DTLS 1.0, epoch 0, followed by the header of a
+	// ClientHello.
+	hello := []byte("\x16\xfe\xff\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10" +
+		"\x01\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x04" +
+		"\xfe\xfd\x00\x00")
+	header, err := SniffDTLS(hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Protocol() != "dtls" {
+		t.Error("unexpected protocol ", header.Protocol())
+	}
+
+	hello[4] = 1 // epoch 1
+	if _, err := SniffDTLS(hello); err == nil {
+		t.Error("expected an error for an encrypted record")
+	}
+	hello[4] = 0
+	hello[2] = 0x03 // TLS 1.2
+	if _, err := SniffDTLS(hello); err == nil {
+		t.Error("expected an error for TLS")
+	}
+}
@@ -66,6 +66,15 @@ func SniffHTTP(b []byte, c context.Context) (*SniffHeader, error) {
 	if content == nil || len(content.Attributes) != 0 {
 		ShouldSniffAttr = false
 	}
+	if bytes.HasPrefix(b, http2Preface) {
+		if !ShouldSniffAttr {
+			content = nil
+		}
+		return sniffHTTP2(b, content)
+	}
+	if len(b) < len(http2Preface) && bytes.HasPrefix(http2Preface, b) {
+		return nil, common.ErrNoClue
+	}
 	if err := beginWithHTTPMethod(b); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"golang.org/x/net/http2/hpack"
+)
+
+// http2Preface is the connection preface of cleartext HTTP/2 with prior
+// knowledge (h2c).
+var http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+const (
+	http2FrameHeaders = 0x1
+
+	http2FlagPadded   = 0x8
+	http2FlagPriority = 0x20
+)
+
+// sniffHTTP2 reads the authority of the first request after the connection
+// preface, which b must begin with.
+func sniffHTTP2(b []byte, content *session.Content) (*SniffHeader, error) {
+	b = b[len(http2Preface):]
+	for len(b) >= 9 {
+		length := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		frameType, flags := b[3], b[4]
+		if len(b) < 9+length {
+			break
+		}
+		payload := b[9 : 9+length]
+		b = b[9+length:]
+		if frameType != http2FrameHeaders {
+			continue
+		}
+
+		if flags&http2FlagPadded != 0 {
+			if len(payload) < 1 || int(payload[0]) >= len(payload) {
+				return nil, errNotHTTPMethod
+			}
+			payload = payload[1 : len(payload)-int(payload[0])]
+		}
+		if flags&http2FlagPriority != 0 {
+			if len(payload) < 5 {
+				return nil, errNotHTTPMethod
+			}
+			payload = payload[5:]
+		}
+		return sniffHTTP2Headers(payload, content)
+	}
+	return nil, protocol.ErrProtoNeedMoreData
+}
+
+func sniffHTTP2Headers(block []byte, content *session.Content) (*SniffHeader, error) {
+	sh := &SniffHeader{
+		version: HTTP2,
+	}
+	var authority, host string
+	decoder := hpack.NewDecoder(4096, func(f hpack.HeaderField) {
+		switch f.Name {
+		case ":authority":
+			authority = f.Value
+		case "host":
+			host = f.Value
+		}
+		if content != nil && f.Name != ":scheme" && f.Name != ":authority" {
+			content.SetAttribute(f.Name, f.Value)
+		}
+	})
+	// A header block continued in CONTINUATION frames fails to decode, but
+	// the fields before are still emitted.
+	decoder.Write(block)
+
+	if authority == "" {
+		authority = host
+	}
+	if authority != "" {
+		dest, err := ParseHost(strings.ToLower(authority), net.Port(80))
+		if err != nil {
+			return nil, err
+		}
+		sh.host = dest.Address.String()
+	}
+	return sh, nil
+}
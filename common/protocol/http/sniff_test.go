@@ -1,10 +1,15 @@
 package http_test
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
+	"github.com/xtls/xray-core/common/protocol"
 	. "github.com/xtls/xray-core/common/protocol/http"
+	"github.com/xtls/xray-core/common/session"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
 )
 
 func TestHTTPHeaders(t *testing.T) {
@@ -104,3 +109,47 @@ first_name=John&last_name=Doe&action=Submit`,
 		}
 	}
 }
+
+func TestHTTP2Preface(t *testing.T) {
+	var block bytes.Buffer
+	encoder := hpack.NewEncoder(&block)
+	for _, f := range []hpack.HeaderField{
+		{Name: ":method", Value: "GET"},
+		{Name: ":scheme", Value: "http"},
+		{Name: ":authority", Value: "Example.com:8080"},
+		{Name: ":path", Value: "/index.html"},
+	} {
+		encoder.WriteField(f)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(http2.ClientPreface)
+	framer := http2.NewFramer(&b, nil)
+	framer.WriteSettings(http2.Setting{ID: http2.SettingInitialWindowSize, Val: 65535})
+	preface := b.Len()
+	framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: block.Bytes(),
+		EndHeaders:    true,
+		EndStream:     true,
+	})
+
+	if _, err := SniffHTTP(b.Bytes()[:10], context.TODO()); err == nil {
+		t.Error("expected an error for a partial preface")
+	}
+	if _, err := SniffHTTP(b.Bytes()[:preface], context.TODO()); err != protocol.ErrProtoNeedMoreData {
+		t.Error("expected to need more data, but got ", err)
+	}
+
+	content := &session.Content{}
+	header, err := SniffHTTP(b.Bytes(), session.ContextWithContent(context.Background(), content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Protocol() != "http2" || header.Domain() != "example.com" {
+		t.Error("unexpected result ", header.Protocol(), " ", header.Domain())
+	}
+	if content.Attribute(":path") != "/index.html" || content.Attribute(":method") != "GET" {
+		t.Error("unexpected attributes ", content.Attributes)
+	}
+}
@@ -17,7 +17,8 @@ import (
 )
 
 type SniffHeader struct {
-	domain string
+	domain      string
+	fingerprint *ptls.Fingerprint
 }
 
 func (s SniffHeader) Protocol() string {
@@ -28,6 +29,11 @@ func (s SniffHeader) Domain() string {
 	return s.domain
 }
 
+// Fingerprint returns the fingerprint of the TLS ClientHello, if any.
+func (s SniffHeader) Fingerprint() *ptls.Fingerprint {
+	return s.fingerprint
+}
+
 const (
 	versionDraft29 uint32 = 0xff00001d
 	version1       uint32 = 0x1
@@ -255,14 +261,14 @@ func SniffQUIC(b []byte) (*SniffHeader, error) {
 		}
 
 		tlsHdr := &ptls.SniffHeader{}
-		err = ptls.ReadClientHello(cryptoDataBuf.BytesRange(0, cryptoLen), tlsHdr)
+		err = ptls.ReadQUICClientHello(cryptoDataBuf.BytesRange(0, cryptoLen), tlsHdr)
 		if err != nil {
 			// The crypto data may have not been fully recovered in current packets,
 			// So we continue to sniff rest packets.
 			b = restPayload
 			continue
 		}
-		return &SniffHeader{domain: tlsHdr.Domain()}, nil
+		return &SniffHeader{domain: tlsHdr.Domain(), fingerprint: tlsHdr.Fingerprint()}, nil
 	}
 	// All payload is parsed as valid QUIC packets, but we need more packets for crypto data to read client hello.
 	return nil, protocol.ErrProtoNeedMoreData
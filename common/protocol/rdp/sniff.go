@@ -0,0 +1,43 @@
+package rdp
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/xtls/xray-core/common"
+)
+
+type SniffHeader struct{}
+
+func (h *SniffHeader) Protocol() string {
+	return "rdp"
+}
+
+func (h *SniffHeader) Domain() string {
+	return ""
+}
+
+var errNotRDP = errors.New("not rdp")
+
+// SniffRDP detects the X.224 Connection Request in a TPKT that begins every
+// RDP connection, see MS-RDPBCGR 2.2.1.1.
+func SniffRDP(b []byte) (*SniffHeader, error) {
+	if len(b) < 11 {
+		return nil, common.ErrNoClue
+	}
+	// TPKT version 3, reserved 0.
+	if b[0] != 3 || b[1] != 0 {
+		return nil, errNotRDP
+	}
+	length := int(binary.BigEndian.Uint16(b[2:]))
+	// The length indicator of X.224 counts the bytes after itself.
+	li := int(b[4])
+	if length < 11 || li != length-5 {
+		return nil, errNotRDP
+	}
+	// CR TPDU code with credit 0, destination reference 0.
+	if b[5] != 0xe0 || b[6] != 0 || b[7] != 0 {
+		return nil, errNotRDP
+	}
+	return &SniffHeader{}, nil
+}
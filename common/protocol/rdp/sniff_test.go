@@ -0,0 +1,28 @@
+package rdp_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/common/protocol/rdp"
+)
+
+func TestSniffRDP(t *testing.T) {
+	// mstsc: TPKT, X.224 Connection Request with a cookie and an RDP
+	// Negotiation Request.
+	request := []byte("\x03\x00\x00\x2a\x25\xe0\x00\x00\x00\x00\x00Cookie: mstshash=user\r\n\x01\x00\x08\x00\x0b\x00\x00\x00")
+	header, err := SniffRDP(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Protocol() != "rdp" {
+		t.Error("unexpected protocol ", header.Protocol())
+	}
+
+	request[5] = 0xd0 // Connection Confirm
+	if _, err := SniffRDP(request); err == nil {
+		t.Error("expected an error for a Connection Confirm")
+	}
+	if _, err := SniffRDP([]byte("\x16\x03\x01\x02\x00\x01\x00\x01\xfc\x03\x03")); err == nil {
+		t.Error("expected an error for TLS")
+	}
+}
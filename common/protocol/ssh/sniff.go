@@ -0,0 +1,52 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/xtls/xray-core/common"
+)
+
+type SniffHeader struct{}
+
+func (h *SniffHeader) Protocol() string {
+	return "ssh"
+}
+
+func (h *SniffHeader) Domain() string {
+	return ""
+}
+
+var (
+	// identification begins the version exchange of RFC 4253, which the
+	// client sends without waiting for the server.
+	identification = []byte("SSH-")
+
+	errNotSSH = errors.New("not ssh")
+)
+
+func SniffSSH(b []byte) (*SniffHeader, error) {
+	if len(b) < len(identification) {
+		if bytes.HasPrefix(identification, b) {
+			return nil, common.ErrNoClue
+		}
+		return nil, errNotSSH
+	}
+	if !bytes.HasPrefix(b, identification) {
+		return nil, errNotSSH
+	}
+	// "SSH-2.0-softwareversion", or "SSH-1.99-" for servers compatible
+	// with version 1.
+	version, _, found := bytes.Cut(b[len(identification):], []byte("-"))
+	if !found {
+		if len(b) < 255 {
+			return nil, common.ErrNoClue
+		}
+		return nil, errNotSSH
+	}
+	switch string(version) {
+	case "2.0", "1.99", "1.5":
+		return &SniffHeader{}, nil
+	}
+	return nil, errNotSSH
+}
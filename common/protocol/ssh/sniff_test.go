@@ -0,0 +1,37 @@
+package ssh_test
+
+import (
+	"testing"
+
+	"github.com/xtls/xray-core/common"
+	. "github.com/xtls/xray-core/common/protocol/ssh"
+)
+
+func TestSniffSSH(t *testing.T) {
+	cases := []struct {
+		input string
+		err   error
+		ok    bool
+	}{
+		{input: "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n", ok: true},
+		{input: "SSH-1.99-Cisco-1.25\r\n", ok: true},
+		{input: "SS", err: common.ErrNoClue},
+		{input: "SSH-2.0", err: common.ErrNoClue},
+		{input: "SSH-3.0-Future\r\n"},
+		{input: "GET / HTTP/1.1\r\n"},
+	}
+	for _, test := range cases {
+		header, err := SniffSSH([]byte(test.input))
+		if test.ok {
+			if err != nil || header.Protocol() != "ssh" {
+				t.Errorf("expected ssh for %q, but got %v", test.input, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected an error for %q", test.input)
+		} else if test.err != nil && err != test.err {
+			t.Errorf("expected %v for %q, but got %v", test.err, test.input, err)
+		}
+	}
+}
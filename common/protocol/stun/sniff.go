@@ -0,0 +1,45 @@
+package stun
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/xtls/xray-core/common"
+)
+
+type SniffHeader struct{}
+
+func (h *SniffHeader) Protocol() string {
+	return "stun"
+}
+
+func (h *SniffHeader) Domain() string {
+	return ""
+}
+
+const (
+	headerSize  = 20
+	magicCookie = 0x2112A442
+)
+
+var errNotSTUN = errors.New("not stun")
+
+// SniffSTUN detects STUN messages of RFC 5389, which WebRTC uses for ICE
+// connectivity checks and TURN allocations.
+func SniffSTUN(b []byte) (*SniffHeader, error) {
+	if len(b) < headerSize {
+		return nil, common.ErrNoClue
+	}
+	// The two most significant bits of the message type are zero.
+	if b[0]&0xc0 != 0 {
+		return nil, errNotSTUN
+	}
+	if binary.BigEndian.Uint32(b[4:]) != magicCookie {
+		return nil, errNotSTUN
+	}
+	length := int(binary.BigEndian.Uint16(b[2:]))
+	if length%4 != 0 || length != len(b)-headerSize {
+		return nil, errNotSTUN
+	}
+	return &SniffHeader{}, nil
+}
@@ -0,0 +1,29 @@
+package stun_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/common/protocol/stun"
+)
+
+func TestSniffSTUN(t *testing.T) {
+	// Binding Request with a USERNAME attribute as sent by ICE.
+	request := []byte("\x00\x01\x00\x08\x21\x12\xa4\x42" +
+		"\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c" +
+		"\x00\x06\x00\x04abcd")
+	header, err := SniffSTUN(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Protocol() != "stun" {
+		t.Error("unexpected protocol ", header.Protocol())
+	}
+
+	if _, err := SniffSTUN(request[:len(request)-4]); err == nil {
+		t.Error("expected an error for a wrong length")
+	}
+	request[4] = 0
+	if _, err := SniffSTUN(request); err == nil {
+		t.Error("expected an error without the magic cookie")
+	}
+}
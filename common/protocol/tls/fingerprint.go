@@ -0,0 +1,201 @@
+package tls
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint identifies the TLS implementation of a client by its
+// ClientHello.
+type Fingerprint struct {
+	// JA3 is the MD5 hash of the JA3 string, in lowercase hex.
+	JA3 string
+	// JA4 is the JA4 fingerprint, such as "t13d1516h2_8daaf6152771_e5627efa2ab1".
+	JA4 string
+	// ALPN lists the protocols offered by the client.
+	ALPN []string
+}
+
+type clientHello struct {
+	version             uint16
+	cipherSuites        []uint16
+	extensions          []uint16
+	curves              []uint16
+	points              []byte
+	signatureAlgorithms []uint16
+	supportedVersions   []uint16
+	alpn                []string
+}
+
+// isGREASE reports whether v is a GREASE value of RFC 8701, which is ignored
+// by both JA3 and JA4.
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+func withoutGREASE(values []uint16) []uint16 {
+	out := make([]uint16, 0, len(values))
+	for _, v := range values {
+		if !isGREASE(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func readUint16s(b []byte) []uint16 {
+	values := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		values = append(values, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return values
+}
+
+// readUint16List reads a list of uint16 prefixed with its length in bytes.
+func readUint16List(b []byte) []uint16 {
+	if len(b) < 2 || int(b[0])<<8|int(b[1]) != len(b)-2 {
+		return nil
+	}
+	return readUint16s(b[2:])
+}
+
+func readALPN(b []byte) []string {
+	if len(b) < 2 || int(b[0])<<8|int(b[1]) != len(b)-2 {
+		return nil
+	}
+	var protocols []string
+	for b = b[2:]; len(b) > 0; {
+		l := int(b[0])
+		if l == 0 || len(b) < 1+l {
+			return protocols
+		}
+		protocols = append(protocols, string(b[1:1+l]))
+		b = b[1+l:]
+	}
+	return protocols
+}
+
+func (c *clientHello) fingerprint(quic bool) *Fingerprint {
+	return &Fingerprint{
+		JA3:  c.ja3(),
+		JA4:  c.ja4(quic),
+		ALPN: c.alpn,
+	}
+}
+
+// ja3 follows https://github.com/salesforce/ja3.
+func (c *clientHello) ja3() string {
+	join := func(values []uint16) string {
+		s := make([]string, len(values))
+		for i, v := range values {
+			s[i] = strconv.Itoa(int(v))
+		}
+		return strings.Join(s, "-")
+	}
+	points := make([]string, len(c.points))
+	for i, p := range c.points {
+		points[i] = strconv.Itoa(int(p))
+	}
+	s := strconv.Itoa(int(c.version)) + "," +
+		join(withoutGREASE(c.cipherSuites)) + "," +
+		join(withoutGREASE(c.extensions)) + "," +
+		join(withoutGREASE(c.curves)) + "," +
+		strings.Join(points, "-")
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// ja4 follows https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4.md.
+func (c *clientHello) ja4(quic bool) string {
+	var b strings.Builder
+	if quic {
+		b.WriteByte('q')
+	} else {
+		b.WriteByte('t')
+	}
+
+	version := c.version
+	if versions := withoutGREASE(c.supportedVersions); len(versions) > 0 {
+		version = slices.Max(versions)
+	}
+	switch version {
+	case 0x0304:
+		b.WriteString("13")
+	case 0x0303:
+		b.WriteString("12")
+	case 0x0302:
+		b.WriteString("11")
+	case 0x0301:
+		b.WriteString("10")
+	case 0x0300:
+		b.WriteString("s3")
+	default:
+		b.WriteString("00")
+	}
+
+	extensions := withoutGREASE(c.extensions)
+	if slices.Contains(extensions, 0x00) {
+		b.WriteByte('d')
+	} else {
+		b.WriteByte('i')
+	}
+	ciphers := withoutGREASE(c.cipherSuites)
+	fmt.Fprintf(&b, "%02d%02d", min(len(ciphers), 99), min(len(extensions), 99))
+
+	alpn := "00"
+	if len(c.alpn) > 0 && c.alpn[0] != "" {
+		first := c.alpn[0]
+		alpn = string(first[0]) + string(first[len(first)-1])
+		if !isAlphanumeric(first[0]) || !isAlphanumeric(first[len(first)-1]) {
+			h := hex.EncodeToString([]byte(first))
+			alpn = string(h[0]) + string(h[len(h)-1])
+		}
+	}
+	b.WriteString(alpn)
+
+	b.WriteByte('_')
+	b.WriteString(truncatedHash(hexList(slices.Sorted(slices.Values(ciphers)))))
+
+	b.WriteByte('_')
+	var sorted []uint16
+	for _, e := range extensions {
+		if e != 0x00 && e != 0x10 {
+			sorted = append(sorted, e)
+		}
+	}
+	slices.Sort(sorted)
+	s := hexList(sorted)
+	if algorithms := withoutGREASE(c.signatureAlgorithms); len(algorithms) > 0 {
+		s += "_" + hexList(algorithms)
+	}
+	if len(sorted) == 0 {
+		s = ""
+	}
+	b.WriteString(truncatedHash(s))
+	return b.String()
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func hexList(values []uint16) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprintf("%04x", v)
+	}
+	return strings.Join(s, ",")
+}
+
+func truncatedHash(s string) string {
+	if s == "" {
+		return "000000000000"
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
@@ -9,7 +9,9 @@ import (
 )
 
 type SniffHeader struct {
-	domain string
+	domain      string
+	quic        bool
+	fingerprint *Fingerprint
 }
 
 func (h *SniffHeader) Protocol() string {
@@ -20,6 +22,12 @@ func (h *SniffHeader) Domain() string {
 	return h.domain
 }
 
+// Fingerprint returns the fingerprint of the ClientHello, or nil if the
+// ClientHello could not be parsed completely.
+func (h *SniffHeader) Fingerprint() *Fingerprint {
+	return h.fingerprint
+}
+
 var (
 	errNotTLS         = errors.New("not TLS header")
 	errNotClientHello = errors.New("not client hello")
@@ -30,11 +38,13 @@ func IsValidTLSVersion(major, minor byte) bool {
 }
 
 // ReadClientHello returns server name (if any) from TLS client hello message.
+// The fingerprint of the client hello is computed as well.
 // https://github.com/golang/go/blob/master/src/crypto/tls/handshake_messages.go#L300
 func ReadClientHello(data []byte, h *SniffHeader) error {
 	if len(data) < 42 {
 		return common.ErrNoClue
 	}
+	hello := &clientHello{version: uint16(data[4])<<8 | uint16(data[5])}
 	sessionIDLen := int(data[38])
 	if sessionIDLen > 32 || len(data) < 39+sessionIDLen {
 		return common.ErrNoClue
@@ -49,6 +59,7 @@ func ReadClientHello(data []byte, h *SniffHeader) error {
 	if cipherSuiteLen%2 == 1 || len(data) < 2+cipherSuiteLen {
 		return errNotClientHello
 	}
+	hello.cipherSuites = readUint16s(data[2 : 2+cipherSuiteLen])
 	data = data[2+cipherSuiteLen:]
 	if len(data) < 1 {
 		return common.ErrNoClue
@@ -71,16 +82,21 @@ func ReadClientHello(data []byte, h *SniffHeader) error {
 
 	for len(data) != 0 {
 		if len(data) < 4 {
-			return errNotClientHello
+			return h.done(hello, false, errNotClientHello)
 		}
 		extension := uint16(data[0])<<8 | uint16(data[1])
 		length := int(data[2])<<8 | int(data[3])
 		data = data[4:]
 		if len(data) < length {
-			return errNotClientHello
+			return h.done(hello, false, errNotClientHello)
 		}
+		hello.extensions = append(hello.extensions, extension)
 
-		if extension == 0x00 { /* extensionServerName */
+		switch extension {
+		case 0x00: /* extensionServerName */
+			if h.domain != "" {
+				break
+			}
 			d := data[:length]
 			if len(d) < 2 {
 				return errNotClientHello
@@ -115,17 +131,51 @@ func ReadClientHello(data []byte, h *SniffHeader) error {
 					if b == '.' {
 						return errNotClientHello
 					}
-					serverName := string(d[:nameLen])
-					h.domain = serverName
-					return nil
+					h.domain = string(d[:nameLen])
+					break
 				}
 				d = d[nameLen:]
 			}
+		case 0x0a: /* extensionSupportedCurves */
+			hello.curves = readUint16List(data[:length])
+		case 0x0b: /* extensionSupportedPoints */
+			if length > 0 && int(data[0]) == length-1 {
+				hello.points = data[1:length]
+			}
+		case 0x0d: /* extensionSignatureAlgorithms */
+			hello.signatureAlgorithms = readUint16List(data[:length])
+		case 0x10: /* extensionALPN */
+			hello.alpn = readALPN(data[:length])
+		case 0x2b: /* extensionSupportedVersions */
+			if length > 0 && int(data[0]) == length-1 {
+				hello.supportedVersions = readUint16s(data[1:length])
+			}
 		}
 		data = data[length:]
 	}
 
-	return errNotTLS
+	return h.done(hello, true, errNotTLS)
+}
+
+// done finishes ReadClientHello. A client hello parsed completely is reported
+// with its fingerprint, even without a server name, and one cut short only if
+// it has a server name.
+func (h *SniffHeader) done(hello *clientHello, complete bool, err error) error {
+	if complete {
+		h.fingerprint = hello.fingerprint(h.quic)
+		return nil
+	}
+	if h.domain == "" {
+		return err
+	}
+	return nil
+}
+
+// ReadQUICClientHello is ReadClientHello for the client hello carried in QUIC
+// CRYPTO frames.
+func ReadQUICClientHello(data []byte, h *SniffHeader) error {
+	h.quic = true
+	return ReadClientHello(data, h)
 }
 
 func SniffTLS(b []byte) (*SniffHeader, error) {
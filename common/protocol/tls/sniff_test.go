@@ -1,6 +1,9 @@
 package tls_test
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"reflect"
 	"testing"
 
 	. "github.com/xtls/xray-core/common/protocol/tls"
@@ -159,3 +162,105 @@ func TestTLSHeaders(t *testing.T) {
 		}
 	}
 }
+
+func buildClientHello(sni string) []byte {
+	u16 := func(v ...uint16) []byte {
+		var b []byte
+		for _, x := range v {
+			b = append(b, byte(x>>8), byte(x))
+		}
+		return b
+	}
+	withLen := func(b []byte) []byte {
+		return append(u16(uint16(len(b))), b...)
+	}
+	ext := func(typ uint16, body []byte) []byte {
+		return append(u16(typ), withLen(body)...)
+	}
+
+	var exts []byte
+	exts = append(exts, ext(0x0a0a, nil)...)
+	if sni != "" {
+		exts = append(exts, ext(0x0000, withLen(append([]byte{0}, withLen([]byte(sni))...)))...)
+	}
+	exts = append(exts, ext(0x0017, nil)...)
+	exts = append(exts, ext(0xff01, []byte{0})...)
+	exts = append(exts, ext(0x000a, withLen(u16(0x2a2a, 0x001d, 0x0017, 0x0018)))...)
+	exts = append(exts, ext(0x000b, []byte{1, 0})...)
+	exts = append(exts, ext(0x0023, nil)...)
+	exts = append(exts, ext(0x0010, withLen([]byte("\x02h2\x08http/1.1")))...)
+	exts = append(exts, ext(0x0005, []byte{1, 0, 0, 0, 0})...)
+	exts = append(exts, ext(0x000d, withLen(u16(0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601)))...)
+	exts = append(exts, ext(0x0012, nil)...)
+	exts = append(exts, ext(0x0033, nil)...)
+	exts = append(exts, ext(0x002d, []byte{1, 1})...)
+	exts = append(exts, ext(0x002b, []byte{6, 0x3a, 0x3a, 0x03, 0x04, 0x03, 0x03})...)
+	exts = append(exts, ext(0x001b, []byte{2, 0, 2})...)
+	exts = append(exts, ext(0x4469, nil)...)
+	exts = append(exts, ext(0x1a1a, []byte{0})...)
+	exts = append(exts, ext(0x0015, make([]byte, 8))...)
+
+	body := u16(0x0303)
+	body = append(body, make([]byte, 32)...)
+	body = append(body, 0)
+	body = append(body, withLen(u16(0x4a4a, 0x1301, 0x1302, 0x1303, 0xc02b, 0xc02f, 0xc02c, 0xc030, 0xcca9, 0xcca8, 0xc013, 0xc014, 0x009c, 0x009d, 0x002f, 0x0035))...)
+	body = append(body, 1, 0)
+	body = append(body, withLen(exts)...)
+
+	hs := append([]byte{1, 0}, u16(uint16(len(body)))...)
+	hs = append(hs, body...)
+	return append([]byte{0x16, 0x03, 0x01}, withLen(hs)...)
+}
+
+func TestTLSFingerprint(t *testing.T) {
+	header, err := SniffTLS(buildClientHello("example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Domain() != "example.com" {
+		t.Error("domain: ", header.Domain())
+	}
+	fp := header.Fingerprint()
+	if fp == nil {
+		t.Fatal("no fingerprint")
+	}
+	if want := "t13d1516h2_8daaf6152771_e5627efa2ab1"; fp.JA4 != want {
+		t.Errorf("JA4 = %s, want %s", fp.JA4, want)
+	}
+	ja3 := md5.Sum([]byte("771,4865-4866-4867-49195-49199-49196-49200-52393-52392-49171-49172-156-157-47-53,0-23-65281-10-11-35-16-5-13-18-51-45-43-27-17513-21,29-23-24,0"))
+	if want := hex.EncodeToString(ja3[:]); fp.JA3 != want {
+		t.Errorf("JA3 = %s, want %s", fp.JA3, want)
+	}
+	if want := []string{"h2", "http/1.1"}; !reflect.DeepEqual(fp.ALPN, want) {
+		t.Errorf("ALPN = %v, want %v", fp.ALPN, want)
+	}
+}
+
+func TestTLSFingerprintWithoutServerName(t *testing.T) {
+	header, err := SniffTLS(buildClientHello(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Domain() != "" {
+		t.Error("domain: ", header.Domain())
+	}
+	fp := header.Fingerprint()
+	if fp == nil {
+		t.Fatal("no fingerprint")
+	}
+	if want := "t13i1515h2_8daaf6152771_e5627efa2ab1"; fp.JA4 != want {
+		t.Errorf("JA4 = %s, want %s", fp.JA4, want)
+	}
+	ja3 := md5.Sum([]byte("771,4865-4866-4867-49195-49199-49196-49200-52393-52392-49171-49172-156-157-47-53,23-65281-10-11-35-16-5-13-18-51-45-43-27-17513-21,29-23-24,0"))
+	if want := hex.EncodeToString(ja3[:]); fp.JA3 != want {
+		t.Errorf("JA3 = %s, want %s", fp.JA3, want)
+	}
+
+	// A client hello cut short is still not reported without a server name.
+	hello := buildClientHello("")
+	hello = hello[:len(hello)-4]
+	hello[3], hello[4] = byte((len(hello)-5)>>8), byte(len(hello)-5)
+	if _, err := SniffTLS(hello); err == nil {
+		t.Error("expected an error for a client hello cut short")
+	}
+}
@@ -28,6 +28,8 @@ const (
 	mitmServerNameKey         ctx.SessionKey = 12 // used by TLS dialer
 
 	streamSettingsKey ctx.SessionKey = 13
+	rejecterKey       ctx.SessionKey = 14 // used by blackhole to reject through the inbound
+	chainKey          ctx.SessionKey = 15 // outbounds to dial through, set by routing
 )
 
 func ContextWithInbound(ctx context.Context, inbound *Inbound) context.Context {
@@ -202,3 +204,32 @@ func ContextWithStreamSettings(ctx context.Context, streamSettings any) context.
 func StreamSettingsFromContext(ctx context.Context) any {
 	return ctx.Value(streamSettingsKey)
 }
+
+// Rejecter rejects the connection of an inbound the way its network does,
+// such as with a TCP RST or an ICMP port unreachable.
+type Rejecter func() error
+
+func ContextWithRejecter(ctx context.Context, rejecter Rejecter) context.Context {
+	return context.WithValue(ctx, rejecterKey, rejecter)
+}
+
+func RejecterFromContext(ctx context.Context) Rejecter {
+	if rejecter, ok := ctx.Value(rejecterKey).(Rejecter); ok {
+		return rejecter
+	}
+	return nil
+}
+
+// ContextWithChain returns a context in which an outbound dials through the
+// outbounds of chain, the last of which is dialed through the rest, and the
+// first dials directly.
+func ContextWithChain(ctx context.Context, chain []string) context.Context {
+	return context.WithValue(ctx, chainKey, chain)
+}
+
+func ChainFromContext(ctx context.Context) []string {
+	if chain, ok := ctx.Value(chainKey).([]string); ok {
+		return chain
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package webhook
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/xtls/xray-core/common/errors"
+)
+
+const maxBackoff = 5 * time.Minute
+
+// doneRecord is the length of the record that marks the oldest payload in
+// the file as posted or dropped.
+const doneRecord = math.MaxUint32
+
+// QueueOptions are the options of a Queue.
+type QueueOptions struct {
+	// Size is the number of payloads waiting to be posted, after which the
+	// oldest are dropped. Defaults to 1024.
+	Size int
+	// Retries is the number of times a payload is posted again after it
+	// failed, before it is dropped.
+	Retries uint32
+	// Backoff is the wait before the first retry, which doubles with every
+	// retry. Defaults to 1 second.
+	Backoff time.Duration
+	// Path of the file that keeps the payloads waiting to be posted across
+	// restarts. They are only kept in memory if empty.
+	Path string
+}
+
+// Queue posts payloads through a Client one at a time, in order, retrying
+// the failed ones with exponential backoff.
+type Queue struct {
+	client   *Client
+	options  QueueOptions
+	access   sync.Mutex
+	payloads [][]byte
+	// inflight is the payload being posted, which is kept in the file until
+	// it is posted or dropped.
+	inflight []byte
+	// file is the file the records are appended to, and done the number of
+	// payloads marked as done in it, which are removed when it is compacted.
+	file   *os.File
+	done   int
+	signal chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewQueue creates a Queue of the client, and loads the payloads kept in
+// its file.
+func NewQueue(client *Client, options QueueOptions) (*Queue, error) {
+	if options.Size <= 0 {
+		options.Size = 1024
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = time.Second
+	}
+	q := &Queue{
+		client:  client,
+		options: options,
+		signal:  make(chan struct{}, 1),
+	}
+	if options.Path != "" {
+		payloads, done, err := load(options.Path)
+		if err != nil {
+			return nil, errors.New("failed to load webhook queue ", options.Path).Base(err)
+		}
+		if len(payloads) > options.Size {
+			payloads = payloads[len(payloads)-options.Size:]
+			done = 1
+		}
+		q.payloads = payloads
+		if done > 0 {
+			q.save()
+		}
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	if len(q.payloads) > 0 {
+		q.signal <- struct{}{}
+	}
+	q.wg.Add(1)
+	go q.run()
+	return q, nil
+}
+
+// Push queues the payload.
+func (q *Queue) Push(body []byte) {
+	q.access.Lock()
+	defer q.access.Unlock()
+	if q.ctx.Err() != nil {
+		return
+	}
+	if len(q.payloads) >= q.options.Size {
+		q.payloads = q.payloads[len(q.payloads)-q.options.Size+1:]
+		q.payloads = append(q.payloads, body)
+		errors.LogWarning(context.Background(), "webhook: queue is full, dropped the oldest payload")
+		q.save()
+	} else {
+		q.payloads = append(q.payloads, body)
+		q.append(body)
+	}
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) run() {
+	defer q.wg.Done()
+	for {
+		q.access.Lock()
+		for len(q.payloads) == 0 {
+			q.access.Unlock()
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-q.signal:
+			}
+			q.access.Lock()
+		}
+		body := q.payloads[0]
+		q.payloads = q.payloads[1:]
+		q.inflight = body
+		q.access.Unlock()
+
+		q.post(body)
+		if q.ctx.Err() != nil {
+			return
+		}
+
+		q.access.Lock()
+		q.inflight = nil
+		q.markDone()
+		q.access.Unlock()
+	}
+}
+
+// post posts the payload until it succeeds, the retries run out, or the
+// queue is closed.
+func (q *Queue) post(body []byte) {
+	backoff := q.options.Backoff
+	for attempt := uint32(0); ; attempt++ {
+		err := q.client.Post(q.ctx, body)
+		if err == nil || q.ctx.Err() != nil {
+			return
+		}
+		if attempt >= q.options.Retries {
+			errors.LogWarningInner(context.Background(), err, "webhook: dropped payload after ", attempt+1, " attempts")
+			return
+		}
+		errors.LogInfoInner(context.Background(), err, "webhook: failed to notify, retrying in ", backoff)
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// append appends the payload to the file. It is called with the lock.
+func (q *Queue) append(body []byte) {
+	q.write(record(nil, body))
+}
+
+// markDone marks the oldest payload in the file as done, and compacts the
+// file once there are as many done as the queue holds. It is called with the
+// lock.
+func (q *Queue) markDone() {
+	if q.options.Path == "" {
+		return
+	}
+	q.done++
+	if q.done >= q.options.Size {
+		q.save()
+		return
+	}
+	q.write(binary.BigEndian.AppendUint32(nil, doneRecord))
+}
+
+// write appends the records to the file. It is called with the lock.
+func (q *Queue) write(b []byte) {
+	if q.options.Path == "" {
+		return
+	}
+	if q.file == nil {
+		f, err := os.OpenFile(q.options.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			errors.LogWarningInner(context.Background(), err, "webhook: failed to save queue")
+			return
+		}
+		q.file = f
+	}
+	if _, err := q.file.Write(b); err != nil {
+		errors.LogWarningInner(context.Background(), err, "webhook: failed to save queue")
+	}
+}
+
+// save replaces the file with the payloads waiting to be posted, which
+// compacts it. It is called with the lock.
+func (q *Queue) save() {
+	if q.options.Path == "" {
+		return
+	}
+	if q.file != nil {
+		q.file.Close()
+		q.file = nil
+	}
+	var b []byte
+	if q.inflight != nil {
+		b = record(b, q.inflight)
+	}
+	for _, body := range q.payloads {
+		b = record(b, body)
+	}
+	if err := writeFile(q.options.Path, b); err != nil {
+		errors.LogWarningInner(context.Background(), err, "webhook: failed to save queue")
+		return
+	}
+	q.done = 0
+}
+
+// writeFile replaces the file at path with b, which is synced to the disk
+// before, so that a crash leaves either the old or the new file.
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Close stops posting. The payloads waiting to be posted are kept in the
+// file, if any.
+func (q *Queue) Close() error {
+	q.access.Lock()
+	q.cancel()
+	q.access.Unlock()
+	q.wg.Wait()
+	q.access.Lock()
+	if q.done > 0 {
+		q.save()
+	} else if q.file != nil {
+		q.file.Close()
+		q.file = nil
+	}
+	q.access.Unlock()
+	return nil
+}
+
+// record appends the payload to b, prefixed with its length.
+func record(b []byte, body []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+	return append(b, body...)
+}
+
+// load reads the payloads of the file that are not done, and returns the
+// number of records that compacting the file removes. A truncated last
+// record, as from a crash while appending it, is ignored.
+func load(path string) ([][]byte, int, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var payloads [][]byte
+	var done int
+	for len(b) >= 4 {
+		n := binary.BigEndian.Uint32(b)
+		if n == doneRecord {
+			if len(payloads) > 0 {
+				payloads = payloads[1:]
+			}
+			done++
+			b = b[4:]
+			continue
+		}
+		if uint64(len(b)-4) < uint64(n) {
+			break
+		}
+		payloads = append(payloads, b[4:4+n])
+		b = b[4+n:]
+	}
+	if len(b) > 0 {
+		done++
+	}
+	return payloads, done, nil
+}
@@ -0,0 +1,168 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	. "github.com/xtls/xray-core/common/webhook"
+)
+
+type server struct {
+	*httptest.Server
+	access   sync.Mutex
+	fails    int
+	payloads []string
+	received chan struct{}
+}
+
+func newServer(fails int) *server {
+	s := &server{fails: fails, received: make(chan struct{}, 16)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.access.Lock()
+		defer s.access.Unlock()
+		if s.fails > 0 {
+			s.fails--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		s.payloads = append(s.payloads, string(body))
+		s.received <- struct{}{}
+	}))
+	return s
+}
+
+func (s *server) wait(t *testing.T, n int) []string {
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for payload ", i)
+		}
+	}
+	s.access.Lock()
+	defer s.access.Unlock()
+	return s.payloads
+}
+
+func TestSignature(t *testing.T) {
+	var signature string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+	}))
+	defer s.Close()
+
+	c := New(s.URL, &Options{Secret: "secret"})
+	defer c.Close()
+	common.Must(c.Post(t.Context(), []byte(`{}`)))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`{}`))
+	if expected := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != expected {
+		t.Error("expected ", expected, ", got ", signature)
+	}
+}
+
+func TestQueueRetry(t *testing.T) {
+	s := newServer(2)
+	defer s.Close()
+
+	c := New(s.URL, nil)
+	q, err := NewQueue(c, QueueOptions{Retries: 2, Backoff: 10 * time.Millisecond})
+	common.Must(err)
+	defer q.Close()
+
+	q.Push([]byte("a"))
+	q.Push([]byte("b"))
+	if payloads := s.wait(t, 2); payloads[0] != "a" || payloads[1] != "b" {
+		t.Error("expected [a b], got ", payloads)
+	}
+}
+
+func TestQueuePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+	down := newServer(1 << 30)
+	c := New(down.URL, nil)
+	q, err := NewQueue(c, QueueOptions{Retries: 1 << 30, Backoff: time.Hour, Path: path})
+	common.Must(err)
+	q.Push([]byte("a"))
+	q.Push([]byte("b"))
+	q.Push([]byte("c"))
+	common.Must(q.Close())
+	down.Close()
+
+	s := newServer(0)
+	defer s.Close()
+	q, err = NewQueue(New(s.URL, nil), QueueOptions{Size: 2, Path: path})
+	common.Must(err)
+	defer q.Close()
+
+	if payloads := s.wait(t, 2); payloads[0] != "b" || payloads[1] != "c" {
+		t.Error("expected [b c], got ", payloads)
+	}
+}
+
+func TestQueueCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+	s := newServer(0)
+	defer s.Close()
+	q, err := NewQueue(New(s.URL, nil), QueueOptions{Size: 4, Path: path})
+	common.Must(err)
+	defer q.Close()
+
+	waitSize := func(size int64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			b, _ := os.ReadFile(path)
+			if int64(len(b)) == size {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("expected the file to be of ", size, " bytes, got ", len(b))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// The payloads are appended, and marked as done once posted.
+	q.Push([]byte("a"))
+	q.Push([]byte("b"))
+	q.Push([]byte("c"))
+	s.wait(t, 3)
+	waitSize(3*5 + 3*4)
+
+	// The file is compacted once there are as many done as the queue holds.
+	q.Push([]byte("d"))
+	s.wait(t, 1)
+	waitSize(0)
+}
+
+func TestQueueTruncatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+	// A payload, one marked as done and a truncated one.
+	common.Must(os.WriteFile(path, []byte("\x00\x00\x00\x01a\xff\xff\xff\xff\x00\x00\x00\x01b\x00\x00\x00\x05c"), 0o600))
+
+	down := newServer(1 << 30)
+	defer down.Close()
+	q, err := NewQueue(New(down.URL, nil), QueueOptions{Retries: 1 << 30, Backoff: time.Hour, Path: path})
+	common.Must(err)
+	q.Push([]byte("d"))
+	common.Must(q.Close())
+
+	b, err := os.ReadFile(path)
+	common.Must(err)
+	if expected := "\x00\x00\x00\x01b\x00\x00\x00\x01d"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+}
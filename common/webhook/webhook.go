@@ -0,0 +1,117 @@
+// Package webhook posts JSON payloads to webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/utils"
+)
+
+// SignatureHeader is the header of the HMAC-SHA256 of the payload, as
+// "sha256=<hex>".
+const SignatureHeader = "X-Signature-256"
+
+// Options are the options of a Client.
+type Options struct {
+	// Headers sent with every payload.
+	Headers map[string]string
+	// Secret of the signatures of the payloads. They aren't signed if empty.
+	Secret string
+	// Timeout of a POST. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// Client posts to a webhook, which is an HTTP URL, or an HTTP server on a
+// Unix socket, such as "/path/to.sock:/hook".
+type Client struct {
+	url     string
+	headers map[string]string
+	secret  []byte
+	client  *http.Client
+}
+
+// New creates a Client of the webhook at url.
+func New(url string, options *Options) *Client {
+	if options == nil {
+		options = &Options{}
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	httpURL, socketPath := utils.SplitHTTPUnixURL(url)
+	c := &Client{
+		url: httpURL,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+	if options.Secret != "" {
+		c.secret = []byte(options.Secret)
+	}
+
+	if socketPath != "" {
+		dialAddr := utils.ResolveSocketPath(socketPath)
+		c.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", dialAddr)
+			},
+		}
+	}
+
+	if len(options.Headers) > 0 {
+		c.headers = make(map[string]string, len(options.Headers))
+		for k, v := range options.Headers {
+			c.headers[k] = v
+		}
+	}
+	return c
+}
+
+// Post posts the payload, as JSON unless the headers set another
+// Content-Type. It returns an error if the webhook can't be reached, or
+// responds with an error status.
+func (c *Client) Post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("failed to build request").Base(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if c.secret != nil {
+		mac := hmac.New(sha256.New, c.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.New("POST failed").Base(err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode >= 400 {
+		return errors.New("POST returned status ", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close closes the idle connections to the webhook.
+func (c *Client) Close() {
+	c.client.CloseIdleConnections()
+}
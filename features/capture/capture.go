@@ -0,0 +1,49 @@
+package capture
+
+import (
+	"context"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/features"
+	"github.com/xtls/xray-core/transport"
+)
+
+// Filter selects the flows of a capture. A flow matches if it was routed by
+// one of the rule tags, or belongs to one of the users. An empty filter
+// matches every flow.
+type Filter struct {
+	RuleTags []string
+	Users    []string
+}
+
+// Session is a running capture, which produces a pcapng stream.
+type Session interface {
+	common.Closable
+
+	// ReadChunk returns the next chunk of the pcapng stream, or io.EOF after
+	// the capture has ended and all its chunks are read.
+	ReadChunk(ctx context.Context) ([]byte, error)
+	// Dropped returns the number of packets dropped because the stream was
+	// not read fast enough.
+	Dropped() uint64
+}
+
+// Capturer is a feature that captures the application-layer payload of the
+// flows between the dispatcher and the outbounds.
+type Capturer interface {
+	features.Feature
+
+	// Capture returns the outbound link of a flow, which was routed by the rule
+	// of ruleTag, wrapped to be captured if any capture matches the flow. The
+	// flow ends when both directions of the link end.
+	Capture(ctx context.Context, ruleTag string, link *transport.Link) *transport.Link
+	// Open starts a capture of the flows matching filter, which ends after
+	// duration or maxBytes of pcapng, or when it is closed.
+	Open(filter *Filter, duration time.Duration, maxBytes int64) (Session, error)
+}
+
+// CapturerType returns the type of Capturer interface. Can be used to implement common.HasType.
+func CapturerType() interface{} {
+	return (*Capturer)(nil)
+}
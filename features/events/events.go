@@ -0,0 +1,127 @@
+package events
+
+import (
+	"slices"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/features"
+)
+
+// Type is the type of an Event.
+type Type int32
+
+const (
+	ConnectionOpened Type = iota + 1
+	ConnectionClosed
+	UserOnline
+	UserOffline
+	OutboundAlive
+	OutboundDead
+	BalancerChanged
+	ConfigChanged
+	DNSFailed
+)
+
+var typeNames = map[Type]string{
+	ConnectionOpened: "connection.opened",
+	ConnectionClosed: "connection.closed",
+	UserOnline:       "user.online",
+	UserOffline:      "user.offline",
+	OutboundAlive:    "outbound.alive",
+	OutboundDead:     "outbound.dead",
+	BalancerChanged:  "balancer.changed",
+	ConfigChanged:    "config.changed",
+	DNSFailed:        "dns.failed",
+}
+
+func (t Type) String() string {
+	if name, found := typeNames[t]; found {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseType returns the Type of a name such as "connection.opened".
+func ParseType(name string) (Type, bool) {
+	for t, n := range typeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Event is an event of the instance. Only the fields relevant to its type
+// are set.
+type Event struct {
+	Type Type
+	Time time.Time
+
+	// Email of the user of a connection, or of the user online or offline.
+	Email       string
+	InboundTag  string
+	OutboundTag string
+	Source      string
+	Destination string
+
+	// Bytes and duration of a closed connection.
+	Uplink   int64
+	Downlink int64
+	Duration time.Duration
+
+	// Tag of the balancer, whose target changed to OutboundTag.
+	Balancer string
+	// Domain that failed to resolve.
+	Domain string
+	// Detail of the event, such as the error, or the API method that changed
+	// the config.
+	Detail string
+}
+
+// Filter selects events. An event matches if it matches every non-empty
+// field of the filter.
+type Filter struct {
+	Types        []Type
+	Emails       []string
+	InboundTags  []string
+	OutboundTags []string
+}
+
+// Match returns whether the event matches the filter.
+func (f *Filter) Match(e *Event) bool {
+	if f == nil {
+		return true
+	}
+	return (len(f.Types) == 0 || slices.Contains(f.Types, e.Type)) &&
+		(len(f.Emails) == 0 || slices.Contains(f.Emails, e.Email)) &&
+		(len(f.InboundTags) == 0 || slices.Contains(f.InboundTags, e.InboundTag)) &&
+		(len(f.OutboundTags) == 0 || slices.Contains(f.OutboundTags, e.OutboundTag))
+}
+
+// Subscription receives the events matching its filter.
+type Subscription interface {
+	common.Closable
+
+	// Events returns the channel of the events, which is closed after the
+	// subscription is closed.
+	Events() <-chan *Event
+	// Dropped returns the number of events dropped because the channel was
+	// not read fast enough.
+	Dropped() uint64
+}
+
+// Bus is a feature that delivers the events of the instance to its
+// subscribers.
+type Bus interface {
+	features.Feature
+
+	// Publish publishes an event. It doesn't block on slow subscribers.
+	Publish(e *Event)
+	Subscribe(filter *Filter) Subscription
+}
+
+// BusType returns the type of Bus interface. Can be used to implement common.HasType.
+func BusType() interface{} {
+	return (*Bus)(nil)
+}
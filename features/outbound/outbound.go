@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/features"
 	"github.com/xtls/xray-core/transport"
@@ -20,6 +21,13 @@ type Handler interface {
 	ProxySettings() *serial.TypedMessage
 }
 
+// GatewayPicker is implemented by a Handler that picks the local address of
+// the connection of a flow ahead of dispatching it, so that it can be logged.
+type GatewayPicker interface {
+	// PickGateway returns the local address, or nil if it isn't known.
+	PickGateway(ctx context.Context) net.Address
+}
+
 type HandlerSelector interface {
 	Select([]string) []string
 }
@@ -30,6 +30,9 @@ type Stats struct {
 	UserDownlink bool
 	// Whether or not to enable online map for user.
 	UserOnline bool
+	// Whether or not to enable stat counter for the last handshake time of
+	// user, in protocols with their own handshakes such as WireGuard.
+	UserHandshake bool
 }
 
 // Buffer contains settings for internal buffer.
@@ -133,9 +136,10 @@ func SessionDefault() Session {
 			DownlinkOnly:   time.Second * 1,
 		},
 		Stats: Stats{
-			UserUplink:   false,
-			UserDownlink: false,
-			UserOnline:   false,
+			UserUplink:    false,
+			UserDownlink:  false,
+			UserOnline:    false,
+			UserHandshake: false,
 		},
 		Buffer: defaultBufferPolicy(),
 	}
@@ -34,6 +34,9 @@ type Route interface {
 
 	// GetRuleTag returns the matching rule tag for debugging if exists
 	GetRuleTag() string
+
+	// GetChain returns the tags of the outbounds through which the outbound is dialed, in order.
+	GetChain() []string
 }
 
 // RouterType return the type of Router interface. Can be used to implement common.HasType.
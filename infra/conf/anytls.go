@@ -0,0 +1,99 @@
+package conf
+
+import (
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/proxy/anytls"
+	"google.golang.org/protobuf/proto"
+)
+
+// AnyTLSClientConfig is configuration of an AnyTLS server
+type AnyTLSClientConfig struct {
+	Address                  *Address `json:"address"`
+	Port                     uint16   `json:"port"`
+	Level                    byte     `json:"level"`
+	Email                    string   `json:"email"`
+	Password                 string   `json:"password"`
+	IdleSessionCheckInterval uint32   `json:"idleSessionCheckInterval"`
+	IdleSessionTimeout       uint32   `json:"idleSessionTimeout"`
+	MinIdleSession           uint32   `json:"minIdleSession"`
+}
+
+// Build implements Buildable
+func (c *AnyTLSClientConfig) Build() (proto.Message, error) {
+	if c.Address == nil {
+		return nil, errors.New("AnyTLS server address is not set.")
+	}
+	if c.Port == 0 {
+		return nil, errors.New("Invalid AnyTLS port.")
+	}
+	if c.Password == "" {
+		return nil, errors.New("AnyTLS password is not specified.")
+	}
+
+	return &anytls.ClientConfig{
+		Server: &protocol.ServerEndpoint{
+			Address: c.Address.Build(),
+			Port:    uint32(c.Port),
+			User: &protocol.User{
+				Level: uint32(c.Level),
+				Email: c.Email,
+				Account: serial.ToTypedMessage(&anytls.Account{
+					Password: c.Password,
+				}),
+			},
+		},
+		IdleSessionCheckInterval: c.IdleSessionCheckInterval,
+		IdleSessionTimeout:       c.IdleSessionTimeout,
+		MinIdleSession:           c.MinIdleSession,
+	}, nil
+}
+
+// AnyTLSUserConfig is user configuration
+type AnyTLSUserConfig struct {
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// AnyTLSServerConfig is Inbound configuration
+type AnyTLSServerConfig struct {
+	Users         []*AnyTLSUserConfig `json:"users"`
+	Clients       []*AnyTLSUserConfig `json:"clients"`
+	PaddingScheme *StringList         `json:"paddingScheme"`
+}
+
+// Build implements Buildable
+func (c *AnyTLSServerConfig) Build() (proto.Message, error) {
+	if c.Clients != nil {
+		c.Users = c.Clients
+	}
+
+	config := &anytls.ServerConfig{
+		Users: make([]*protocol.User, len(c.Users)),
+	}
+	for idx, user := range c.Users {
+		if user.Password == "" {
+			return nil, errors.New("AnyTLS password is not specified.")
+		}
+		config.Users[idx] = &protocol.User{
+			Level: uint32(user.Level),
+			Email: user.Email,
+			Account: serial.ToTypedMessage(&anytls.Account{
+				Password: user.Password,
+			}),
+		}
+	}
+
+	if c.PaddingScheme != nil {
+		config.PaddingScheme = strings.Join(*c.PaddingScheme, "\n")
+		if _, err := anytls.ParsePaddingScheme(config.PaddingScheme); err != nil {
+			return nil, errors.New("invalid AnyTLS padding scheme").Base(err)
+		}
+	}
+
+	return config, nil
+}
@@ -3,7 +3,9 @@ package conf
 import (
 	"strings"
 
+	captureservice "github.com/xtls/xray-core/app/capture/command"
 	"github.com/xtls/xray-core/app/commander"
+	eventservice "github.com/xtls/xray-core/app/events/command"
 	loggerservice "github.com/xtls/xray-core/app/log/command"
 	observatoryservice "github.com/xtls/xray-core/app/observatory/command"
 	handlerservice "github.com/xtls/xray-core/app/proxyman/command"
@@ -14,9 +16,90 @@ import (
 )
 
 type APIConfig struct {
-	Tag      string   `json:"tag"`
-	Listen   string   `json:"listen"`
-	Services []string `json:"services"`
+	Tag      string            `json:"tag"`
+	Listen   string            `json:"listen"`
+	Services []string          `json:"services"`
+	TLS      *APITLSConfig     `json:"tls"`
+	Tokens   []*APITokenConfig `json:"tokens"`
+	REST     bool              `json:"rest"`
+}
+
+type APITLSConfig struct {
+	CertificateFile string `json:"certificateFile"`
+	KeyFile         string `json:"keyFile"`
+	ClientCAFile    string `json:"clientCaFile"`
+}
+
+func (c *APITLSConfig) Build() (*commander.TLSConfig, error) {
+	if c.CertificateFile == "" || c.KeyFile == "" {
+		return nil, errors.New("certificateFile and keyFile of API TLS are required")
+	}
+	return &commander.TLSConfig{
+		CertificateFile: c.CertificateFile,
+		KeyFile:         c.KeyFile,
+		ClientCaFile:    c.ClientCAFile,
+	}, nil
+}
+
+// apiRoles are the methods allowed for the roles of API tokens.
+var apiRoles = map[string][]string{
+	"stats": {
+		"/xray.app.stats.command.StatsService/*",
+		"/xray.app.events.command.EventService/Subscribe",
+		"/xray.core.app.observatory.command.ObservatoryService/*",
+		"/xray.app.router.command.RoutingService/SubscribeRoutingStats",
+		"/xray.app.router.command.RoutingService/TestRoute",
+		"/xray.app.router.command.RoutingService/GetBalancerInfo",
+		"/xray.app.router.command.RoutingService/ListRule",
+	},
+	// The users and the configs of the handlers carry credentials and keys,
+	// so they are not in "stats". Listing the handlers is left to "admin".
+	"users": {
+		"/xray.app.proxyman.command.HandlerService/AlterInbound",
+		"/xray.app.proxyman.command.HandlerService/GetInboundUsers",
+		"/xray.app.proxyman.command.HandlerService/GetInboundUsersCount",
+	},
+	"admin": {
+		"*",
+	},
+}
+
+type APITokenConfig struct {
+	Name    string   `json:"name"`
+	Token   string   `json:"token"`
+	Role    string   `json:"role"`
+	Methods []string `json:"methods"`
+}
+
+func (c *APITokenConfig) Build() (*commander.Token, error) {
+	if c.Token == "" {
+		return nil, errors.New("API token can't be empty")
+	}
+	token := &commander.Token{
+		Name:   c.Name,
+		Secret: c.Token,
+		Method: c.Methods,
+	}
+	switch role := strings.ToLower(c.Role); role {
+	case "":
+	case "users":
+		// Managing users involves reading them and their traffic.
+		token.Method = append(token.Method, apiRoles["stats"]...)
+		fallthrough
+	default:
+		methods, found := apiRoles[role]
+		if !found {
+			return nil, errors.New("unknown role of API token: ", c.Role)
+		}
+		token.Method = append(token.Method, methods...)
+	}
+	if len(token.Method) == 0 {
+		return nil, errors.New("API token ", c.Name, " allows no method")
+	}
+	if token.Name == "" {
+		token.Name = "token"
+	}
+	return token, nil
 }
 
 func (c *APIConfig) Build() (*commander.Config, error) {
@@ -39,12 +122,32 @@ func (c *APIConfig) Build() (*commander.Config, error) {
 			services = append(services, serial.ToTypedMessage(&observatoryservice.Config{}))
 		case "routingservice":
 			services = append(services, serial.ToTypedMessage(&routerservice.Config{}))
+		case "captureservice":
+			services = append(services, serial.ToTypedMessage(&captureservice.Config{}))
+		case "eventservice":
+			services = append(services, serial.ToTypedMessage(&eventservice.Config{}))
 		}
 	}
 
-	return &commander.Config{
+	config := &commander.Config{
 		Tag:     c.Tag,
 		Listen:  c.Listen,
 		Service: services,
-	}, nil
+		Rest:    c.REST,
+	}
+	if c.TLS != nil {
+		tls, err := c.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Tls = tls
+	}
+	for _, t := range c.Tokens {
+		token, err := t.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Token = append(config.Token, token)
+	}
+	return config, nil
 }
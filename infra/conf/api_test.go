@@ -0,0 +1,102 @@
+package conf_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/xtls/xray-core/app/commander"
+	. "github.com/xtls/xray-core/infra/conf"
+	"google.golang.org/protobuf/proto"
+)
+
+// apiAllows returns whether the method patterns of a token allow method, the
+// way the commander matches them.
+func apiAllows(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if service, ok := strings.CutSuffix(pattern, "/*"); pattern == "*" || pattern == method || ok && strings.HasPrefix(method, service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAPIConfig(t *testing.T) {
+	parser := func(s string) (proto.Message, error) {
+		config := new(APIConfig)
+		if err := json.Unmarshal([]byte(s), config); err != nil {
+			return nil, err
+		}
+		return config.Build()
+	}
+
+	runMultiTestCase(t, []TestCase{
+		{
+			Input: `{
+				"tag": "api",
+				"rest": true,
+				"tls": {"certificateFile": "server.crt", "keyFile": "server.key", "clientCaFile": "ca.crt"},
+				"tokens": [
+					{"name": "panel", "token": "secret", "role": "admin"},
+					{"token": "other", "methods": ["/xray.app.stats.command.StatsService/QueryStats"]}
+				]
+			}`,
+			Parser: parser,
+			Output: &commander.Config{
+				Tag:  "api",
+				Rest: true,
+				Tls: &commander.TLSConfig{
+					CertificateFile: "server.crt",
+					KeyFile:         "server.key",
+					ClientCaFile:    "ca.crt",
+				},
+				Token: []*commander.Token{
+					{Name: "panel", Secret: "secret", Method: []string{"*"}},
+					{Name: "token", Secret: "other", Method: []string{"/xray.app.stats.command.StatsService/QueryStats"}},
+				},
+			},
+		},
+	})
+
+	config, err := parser(`{"tag": "api", "tokens": [{"token": "secret", "role": "users"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := config.(*commander.Config).Token[0].Method
+	if methods[0] != "/xray.app.stats.command.StatsService/*" || !apiAllows(methods, "/xray.app.proxyman.command.HandlerService/GetInboundUsers") {
+		t.Error("unexpected methods of users role ", methods)
+	}
+	if apiAllows(methods, "/xray.app.proxyman.command.HandlerService/ListInbounds") {
+		t.Error("users role allows listing the inbounds")
+	}
+
+	config, err = parser(`{"tag": "api", "tokens": [{"token": "secret", "role": "stats"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods = config.(*commander.Config).Token[0].Method
+	if !apiAllows(methods, "/xray.app.stats.command.StatsService/QueryStats") {
+		t.Error("stats role denies QueryStats")
+	}
+	for _, method := range []string{
+		"/xray.app.proxyman.command.HandlerService/GetInboundUsers",
+		"/xray.app.proxyman.command.HandlerService/GetInboundUsersCount",
+		"/xray.app.proxyman.command.HandlerService/ListInbounds",
+		"/xray.app.proxyman.command.HandlerService/ListOutbounds",
+	} {
+		if apiAllows(methods, method) {
+			t.Error("stats role allows ", method)
+		}
+	}
+
+	for _, input := range []string{
+		`{"tag": "api", "tls": {"certificateFile": "server.crt"}}`,
+		`{"tag": "api", "tokens": [{"token": ""}]}`,
+		`{"tag": "api", "tokens": [{"token": "secret"}]}`,
+		`{"tag": "api", "tokens": [{"token": "secret", "role": "unknown"}]}`,
+	} {
+		if _, err := parser(input); err == nil {
+			t.Error("expected error for ", input)
+		}
+	}
+}
@@ -15,10 +15,45 @@ func (*NoneResponse) Build() (proto.Message, error) {
 	return new(blackhole.NoneResponse), nil
 }
 
-type HTTPResponse struct{}
+type HTTPResponse struct {
+	Status  uint32            `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+func (v *HTTPResponse) Build() (proto.Message, error) {
+	if v.Status != 0 && (v.Status < 100 || v.Status > 999) {
+		return nil, errors.New("invalid HTTP status: ", v.Status)
+	}
+	return &blackhole.HTTPResponse{
+		Status: v.Status,
+		Header: v.Headers,
+		Body:   v.Body,
+	}, nil
+}
+
+type ResetResponse struct{}
+
+func (*ResetResponse) Build() (proto.Message, error) {
+	return new(blackhole.ResetResponse), nil
+}
+
+type TLSAlertResponse struct{}
+
+func (*TLSAlertResponse) Build() (proto.Message, error) {
+	return new(blackhole.TLSAlertResponse), nil
+}
+
+type TarpitResponse struct {
+	Interval uint32 `json:"interval"`
+	Timeout  uint32 `json:"timeout"`
+}
 
-func (*HTTPResponse) Build() (proto.Message, error) {
-	return new(blackhole.HTTPResponse), nil
+func (v *TarpitResponse) Build() (proto.Message, error) {
+	return &blackhole.TarpitResponse{
+		Interval: v.Interval,
+		Timeout:  v.Timeout,
+	}, nil
 }
 
 type BlackholeConfig struct {
@@ -44,8 +79,11 @@ func (v *BlackholeConfig) Build() (proto.Message, error) {
 
 var configLoader = NewJSONConfigLoader(
 	ConfigCreatorCache{
-		"none": func() interface{} { return new(NoneResponse) },
-		"http": func() interface{} { return new(HTTPResponse) },
+		"none":     func() interface{} { return new(NoneResponse) },
+		"http":     func() interface{} { return new(HTTPResponse) },
+		"reset":    func() interface{} { return new(ResetResponse) },
+		"tlsAlert": func() interface{} { return new(TLSAlertResponse) },
+		"tarpit":   func() interface{} { return new(TarpitResponse) },
 	},
 	"type",
 	"",
@@ -25,6 +25,47 @@ func TestHTTPResponseJSON(t *testing.T) {
 				Response: serial.ToTypedMessage(&blackhole.HTTPResponse{}),
 			},
 		},
+		{
+			Input: `{
+				"response": {
+					"type": "http",
+					"status": 451,
+					"headers": {"Content-Type": "text/html"},
+					"body": "<h1>Blocked</h1>"
+				}
+			}`,
+			Parser: loadJSON(creator),
+			Output: &blackhole.Config{
+				Response: serial.ToTypedMessage(&blackhole.HTTPResponse{
+					Status: 451,
+					Header: map[string]string{"Content-Type": "text/html"},
+					Body:   "<h1>Blocked</h1>",
+				}),
+			},
+		},
+		{
+			Input: `{
+				"response": {
+					"type": "tarpit",
+					"interval": 5
+				}
+			}`,
+			Parser: loadJSON(creator),
+			Output: &blackhole.Config{
+				Response: serial.ToTypedMessage(&blackhole.TarpitResponse{Interval: 5}),
+			},
+		},
+		{
+			Input: `{
+				"response": {
+					"type": "reset"
+				}
+			}`,
+			Parser: loadJSON(creator),
+			Output: &blackhole.Config{
+				Response: serial.ToTypedMessage(&blackhole.ResetResponse{}),
+			},
+		},
 		{
 			Input:  `{}`,
 			Parser: loadJSON(creator),
@@ -0,0 +1,17 @@
+package conf
+
+import (
+	"github.com/xtls/xray-core/app/capture"
+)
+
+type CaptureConfig struct {
+	MaxDuration uint32 `json:"maxDuration"`
+	MaxBytes    uint64 `json:"maxBytes"`
+}
+
+func (c *CaptureConfig) Build() (*capture.Config, error) {
+	return &capture.Config{
+		MaxDuration: c.MaxDuration,
+		MaxBytes:    c.MaxBytes,
+	}, nil
+}
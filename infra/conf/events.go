@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"github.com/xtls/xray-core/app/events"
+	"github.com/xtls/xray-core/common/errors"
+	feature_events "github.com/xtls/xray-core/features/events"
+)
+
+type EventFilterConfig struct {
+	Types        *StringList `json:"types"`
+	Users        *StringList `json:"users"`
+	InboundTags  *StringList `json:"inboundTags"`
+	OutboundTags *StringList `json:"outboundTags"`
+}
+
+func (c *EventFilterConfig) Build() (*events.Filter, error) {
+	filter := &events.Filter{}
+	if c.Types != nil {
+		for _, name := range *c.Types {
+			t, ok := feature_events.ParseType(name)
+			if !ok {
+				return nil, errors.New("unknown event type: ", name)
+			}
+			filter.Types = append(filter.Types, events.Type(t))
+		}
+	}
+	if c.Users != nil {
+		filter.Emails = *c.Users
+	}
+	if c.InboundTags != nil {
+		filter.InboundTags = *c.InboundTags
+	}
+	if c.OutboundTags != nil {
+		filter.OutboundTags = *c.OutboundTags
+	}
+	return filter, nil
+}
+
+type EventSinkConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	EventFilterConfig
+}
+
+type EventsConfig struct {
+	Sinks []*EventSinkConfig `json:"sinks"`
+}
+
+func (c *EventsConfig) Build() (*events.Config, error) {
+	config := &events.Config{}
+	for _, s := range c.Sinks {
+		if s.URL == "" {
+			return nil, errors.New("events: empty sink url")
+		}
+		filter, err := s.EventFilterConfig.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Sinks = append(config.Sinks, &events.Sink{
+			Url:     s.URL,
+			Headers: s.Headers,
+			Filter:  filter,
+		})
+	}
+	return config, nil
+}
@@ -0,0 +1,106 @@
+package conf
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/proxy/fallback"
+)
+
+// FallbackDestConfig is one of the destinations of a fallback.
+type FallbackDestConfig struct {
+	Type   string          `json:"type"`
+	Dest   json.RawMessage `json:"dest"`
+	Weight uint32          `json:"weight"`
+}
+
+// FallbackHealthCheckConfig is the health check of the destinations of a
+// fallback, in seconds.
+type FallbackHealthCheckConfig struct {
+	Interval uint32 `json:"interval"`
+	Timeout  uint32 `json:"timeout"`
+}
+
+// Build implements Buildable.
+func (c *FallbackHealthCheckConfig) Build() *fallback.HealthCheck {
+	if c == nil {
+		return nil
+	}
+	return &fallback.HealthCheck{
+		Interval: c.Interval,
+		Timeout:  c.Timeout,
+	}
+}
+
+// parseFallbackDest returns dest, which is either a port or an address.
+func parseFallbackDest(dest json.RawMessage) string {
+	var i uint16
+	var s string
+	if err := json.Unmarshal(dest, &i); err == nil {
+		s = strconv.Itoa(int(i))
+	} else {
+		_ = json.Unmarshal(dest, &s)
+	}
+	return s
+}
+
+// inferFallbackType returns the type of dest if typ is not set, and dest in
+// the form of that type. The type is empty if dest is not valid.
+func inferFallbackType(typ, dest string) (string, string) {
+	if typ != "" || dest == "" {
+		return typ, dest
+	}
+	if dest == "serve-ws-none" {
+		return "serve", dest
+	}
+	if filepath.IsAbs(dest) || dest[0] == '@' {
+		if strings.HasPrefix(dest, "@@") && (runtime.GOOS == "linux" || runtime.GOOS == "android") {
+			fullAddr := make([]byte, len(syscall.RawSockaddrUnix{}.Path)) // may need padding to work with haproxy
+			copy(fullAddr, dest[1:])
+			dest = string(fullAddr)
+		}
+		return "unix", dest
+	}
+	if _, err := strconv.Atoi(dest); err == nil {
+		dest = "localhost:" + dest
+	}
+	if _, _, err := net.SplitHostPort(dest); err == nil {
+		return "tcp", dest
+	}
+	return "", dest
+}
+
+// buildFallbackDests builds the destinations of a fallback besides "dest".
+func buildFallbackDests(protocol string, dests []*FallbackDestConfig) ([]*fallback.Destination, error) {
+	var result []*fallback.Destination
+	for _, d := range dests {
+		typ, dest := inferFallbackType(d.Type, parseFallbackDest(d.Dest))
+		if typ == "" {
+			return nil, errors.New(protocol, ` fallbacks: please fill in a valid value for every "dest" in "dests"`)
+		}
+		result = append(result, &fallback.Destination{
+			Type:   typ,
+			Dest:   dest,
+			Weight: d.Weight,
+		})
+	}
+	return result, nil
+}
+
+// checkFallbackPathRegexp returns an error if pathRegexp is invalid.
+func checkFallbackPathRegexp(protocol string, pathRegexp string) error {
+	if pathRegexp == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(pathRegexp); err != nil {
+		return errors.New(protocol, ` fallbacks: invalid "pathRegexp"`).Base(err)
+	}
+	return nil
+}
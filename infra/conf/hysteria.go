@@ -31,15 +31,35 @@ func (c *HysteriaClientConfig) Build() (proto.Message, error) {
 }
 
 type HysteriaUserConfig struct {
-	Auth  string `json:"auth"`
-	Level uint32 `json:"level"`
-	Email string `json:"email"`
+	Auth  string    `json:"auth"`
+	Level uint32    `json:"level"`
+	Email string    `json:"email"`
+	Up    Bandwidth `json:"up"`
+	Down  Bandwidth `json:"down"`
+}
+
+type HysteriaBandwidthConfig struct {
+	Up   Bandwidth `json:"up"`
+	Down Bandwidth `json:"down"`
+}
+
+func (c *HysteriaBandwidthConfig) Build() (*account.Bandwidth, error) {
+	up, err := c.Up.Bps()
+	if err != nil {
+		return nil, err
+	}
+	down, err := c.Down.Bps()
+	if err != nil {
+		return nil, err
+	}
+	return &account.Bandwidth{Up: up, Down: down}, nil
 }
 
 type HysteriaServerConfig struct {
-	Version int32                 `json:"version"`
-	Users   []*HysteriaUserConfig `json:"users"`
-	Clients []*HysteriaUserConfig `json:"clients"`
+	Version int32                               `json:"version"`
+	Users   []*HysteriaUserConfig               `json:"users"`
+	Clients []*HysteriaUserConfig               `json:"clients"`
+	Levels  map[uint32]*HysteriaBandwidthConfig `json:"levels"`
 }
 
 func (c *HysteriaServerConfig) Build() (proto.Message, error) {
@@ -56,8 +76,14 @@ func (c *HysteriaServerConfig) Build() (proto.Message, error) {
 		config.Users = make([]*protocol.User, len(c.Users))
 		processUser := func(idx int) error {
 			user := c.Users[idx]
+			bandwidth, err := (&HysteriaBandwidthConfig{Up: user.Up, Down: user.Down}).Build()
+			if err != nil {
+				return errors.New("invalid bandwidth of user ", user.Email).Base(err)
+			}
 			acc := &account.Account{
 				Auth: user.Auth,
+				Up:   bandwidth.Up,
+				Down: bandwidth.Down,
 			}
 			config.Users[idx] = &protocol.User{
 				Email:   user.Email,
@@ -71,5 +97,16 @@ func (c *HysteriaServerConfig) Build() (proto.Message, error) {
 		}
 	}
 
+	if len(c.Levels) > 0 {
+		config.LevelBandwidth = make(map[uint32]*account.Bandwidth, len(c.Levels))
+		for level, b := range c.Levels {
+			bandwidth, err := b.Build()
+			if err != nil {
+				return nil, errors.New("invalid bandwidth of level ", level).Base(err)
+			}
+			config.LevelBandwidth[level] = bandwidth
+		}
+	}
+
 	return config, nil
 }
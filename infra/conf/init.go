@@ -1,5 +1,8 @@
 package conf
 
+import "github.com/xtls/xray-core/app/subscription"
+
 func init() {
 	RegisterConfigureFilePostProcessingStage("FakeDNS", &FakeDNSPostProcessingStage{})
+	subscription.Parser = parseSubscription
 }
@@ -0,0 +1,71 @@
+package conf
+
+import (
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/proxy/mirror"
+	"google.golang.org/protobuf/proto"
+)
+
+type MirrorSinkConfig struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+	Path    string `json:"path"`
+	Tag     string `json:"tag"`
+}
+
+// Build implements Buildable.
+func (c *MirrorSinkConfig) Build() (*mirror.Sink, error) {
+	switch strings.ToLower(c.Type) {
+	case "tcp":
+		if c.Address == "" {
+			return nil, errors.New("address of tcp sink not specified")
+		}
+		return &mirror.Sink{Type: mirror.Sink_TCP, Address: c.Address}, nil
+	case "pcap", "pcapng":
+		if c.Path == "" {
+			return nil, errors.New("path of pcap sink not specified")
+		}
+		return &mirror.Sink{Type: mirror.Sink_PCAP, Path: c.Path}, nil
+	case "outbound":
+		if c.Tag == "" {
+			return nil, errors.New("tag of outbound sink not specified")
+		}
+		return &mirror.Sink{Type: mirror.Sink_OUTBOUND, Tag: c.Tag}, nil
+	default:
+		return nil, errors.New("unknown mirror sink type: ", c.Type)
+	}
+}
+
+type MirrorConfig struct {
+	OutboundTag string              `json:"outboundTag"`
+	Sinks       []*MirrorSinkConfig `json:"sinks"`
+	Sampling    *float32            `json:"sampling"`
+	MaxBytes    uint64              `json:"maxBytes"`
+}
+
+// Build implements Buildable.
+func (c *MirrorConfig) Build() (proto.Message, error) {
+	if c.OutboundTag == "" {
+		return nil, errors.New("outboundTag of mirror not specified")
+	}
+	config := &mirror.Config{
+		OutboundTag: c.OutboundTag,
+		MaxBytes:    c.MaxBytes,
+	}
+	if c.Sampling != nil {
+		if *c.Sampling <= 0 || *c.Sampling > 1 {
+			return nil, errors.New("sampling of mirror must be in (0, 1]")
+		}
+		config.Sampling = *c.Sampling
+	}
+	for _, s := range c.Sinks {
+		sink, err := s.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Sinks = append(config.Sinks, sink)
+	}
+	return config, nil
+}
@@ -0,0 +1,50 @@
+package conf_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/proxy/mirror"
+)
+
+func TestMirrorConfig(t *testing.T) {
+	creator := func() Buildable {
+		return new(MirrorConfig)
+	}
+
+	runMultiTestCase(t, []TestCase{
+		{
+			Input: `{
+				"outboundTag": "direct",
+				"sinks": [
+					{"type": "tcp", "address": "127.0.0.1:9000"},
+					{"type": "pcap", "path": "/tmp/mirror.pcapng"},
+					{"type": "outbound", "tag": "ids"}
+				],
+				"sampling": 0.25,
+				"maxBytes": 65536
+			}`,
+			Parser: loadJSON(creator),
+			Output: &mirror.Config{
+				OutboundTag: "direct",
+				Sinks: []*mirror.Sink{
+					{Type: mirror.Sink_TCP, Address: "127.0.0.1:9000"},
+					{Type: mirror.Sink_PCAP, Path: "/tmp/mirror.pcapng"},
+					{Type: mirror.Sink_OUTBOUND, Tag: "ids"},
+				},
+				Sampling: 0.25,
+				MaxBytes: 65536,
+			},
+		},
+	})
+
+	for _, input := range []string{
+		`{"outboundTag": "direct", "sampling": 0}`,
+		`{"sinks": [{"type": "tcp", "address": "127.0.0.1:9000"}]}`,
+		`{"outboundTag": "direct", "sinks": [{"type": "pcap"}]}`,
+	} {
+		if _, err := loadJSON(creator)(input); err == nil {
+			t.Error("expected error for ", input)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package conf
+
+import (
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/proxy/naive"
+	"google.golang.org/protobuf/proto"
+)
+
+// NaiveClientConfig is configuration of a naive server
+type NaiveClientConfig struct {
+	Address  *Address `json:"address"`
+	Port     uint16   `json:"port"`
+	Level    byte     `json:"level"`
+	Email    string   `json:"email"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+}
+
+// Build implements Buildable
+func (c *NaiveClientConfig) Build() (proto.Message, error) {
+	if c.Address == nil {
+		return nil, errors.New("naive server address is not set.")
+	}
+	if c.Port == 0 {
+		return nil, errors.New("Invalid naive port.")
+	}
+	if c.Username == "" {
+		return nil, errors.New("naive username is not specified.")
+	}
+
+	return &naive.ClientConfig{
+		Server: &protocol.ServerEndpoint{
+			Address: c.Address.Build(),
+			Port:    uint32(c.Port),
+			User: &protocol.User{
+				Level: uint32(c.Level),
+				Email: c.Email,
+				Account: serial.ToTypedMessage(&naive.Account{
+					Username: c.Username,
+					Password: c.Password,
+				}),
+			},
+		},
+	}, nil
+}
+
+// NaiveUserConfig is user configuration
+type NaiveUserConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// NaiveServerConfig is Inbound configuration
+type NaiveServerConfig struct {
+	Users   []*NaiveUserConfig `json:"users"`
+	Clients []*NaiveUserConfig `json:"clients"`
+}
+
+// Build implements Buildable
+func (c *NaiveServerConfig) Build() (proto.Message, error) {
+	if c.Clients != nil {
+		c.Users = c.Clients
+	}
+
+	config := &naive.ServerConfig{
+		Users: make([]*protocol.User, len(c.Users)),
+	}
+	for idx, user := range c.Users {
+		if user.Username == "" {
+			return nil, errors.New("naive username is not specified.")
+		}
+		config.Users[idx] = &protocol.User{
+			Level: uint32(user.Level),
+			Email: user.Email,
+			Account: serial.ToTypedMessage(&naive.Account{
+				Username: user.Username,
+				Password: user.Password,
+			}),
+		}
+	}
+
+	return config, nil
+}
@@ -5,14 +5,15 @@ import (
 )
 
 type Policy struct {
-	Handshake         *uint32 `json:"handshake"`
-	ConnectionIdle    *uint32 `json:"connIdle"`
-	UplinkOnly        *uint32 `json:"uplinkOnly"`
-	DownlinkOnly      *uint32 `json:"downlinkOnly"`
-	StatsUserUplink   bool    `json:"statsUserUplink"`
-	StatsUserDownlink bool    `json:"statsUserDownlink"`
-	StatsUserOnline   bool    `json:"statsUserOnline"`
-	BufferSize        *int32  `json:"bufferSize"`
+	Handshake          *uint32 `json:"handshake"`
+	ConnectionIdle     *uint32 `json:"connIdle"`
+	UplinkOnly         *uint32 `json:"uplinkOnly"`
+	DownlinkOnly       *uint32 `json:"downlinkOnly"`
+	StatsUserUplink    bool    `json:"statsUserUplink"`
+	StatsUserDownlink  bool    `json:"statsUserDownlink"`
+	StatsUserOnline    bool    `json:"statsUserOnline"`
+	StatsUserHandshake bool    `json:"statsUserHandshake"`
+	BufferSize         *int32  `json:"bufferSize"`
 }
 
 func (t *Policy) Build() (*policy.Policy, error) {
@@ -33,9 +34,10 @@ func (t *Policy) Build() (*policy.Policy, error) {
 	p := &policy.Policy{
 		Timeout: config,
 		Stats: &policy.Policy_Stats{
-			UserUplink:   t.StatsUserUplink,
-			UserDownlink: t.StatsUserDownlink,
-			UserOnline:   t.StatsUserOnline,
+			UserUplink:    t.StatsUserUplink,
+			UserDownlink:  t.StatsUserDownlink,
+			UserOnline:    t.StatsUserOnline,
+			UserHandshake: t.StatsUserHandshake,
 		},
 	}
 
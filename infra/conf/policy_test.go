@@ -38,3 +38,18 @@ func TestBufferSize(t *testing.T) {
 		}
 	}
 }
+
+func TestStatsUserHandshake(t *testing.T) {
+	pConf := Policy{
+		StatsUserOnline:    true,
+		StatsUserHandshake: true,
+	}
+	p, err := pConf.Build()
+	common.Must(err)
+	if !p.Stats.UserHandshake {
+		t.Error("expected user handshake stats to be enabled")
+	}
+	if cp := p.ToCorePolicy(); !cp.Stats.UserHandshake || !cp.Stats.UserOnline {
+		t.Error("unexpected core policy stats ", cp.Stats)
+	}
+}
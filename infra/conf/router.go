@@ -118,15 +118,26 @@ func (c *RouterConfig) Build() (*router.Config, error) {
 }
 
 type RouterRule struct {
-	RuleTag     string `json:"ruleTag"`
-	OutboundTag string `json:"outboundTag"`
-	BalancerTag string `json:"balancerTag"`
+	RuleTag     string   `json:"ruleTag"`
+	OutboundTag string   `json:"outboundTag"`
+	BalancerTag string   `json:"balancerTag"`
+	Chain       []string `json:"chain"`
 }
 
 type WebhookRuleConfig struct {
-	URL           string            `json:"url"`
-	Deduplication uint32            `json:"deduplication"`
-	Headers       map[string]string `json:"headers"`
+	URL               string            `json:"url"`
+	Deduplication     uint32            `json:"deduplication"`
+	DeduplicationKeys []string          `json:"deduplicationKeys"`
+	Headers           map[string]string `json:"headers"`
+	Template          string            `json:"template"`
+	BatchWindow       uint32            `json:"batchWindow"`
+	BatchSize         uint32            `json:"batchSize"`
+	Retries           uint32            `json:"retries"`
+	RetryInterval     uint32            `json:"retryInterval"`
+	Timeout           uint32            `json:"timeout"`
+	QueueSize         uint32            `json:"queueSize"`
+	QueuePath         string            `json:"queuePath"`
+	Secret            string            `json:"secret"`
 }
 
 func parseFieldRule(msg json.RawMessage) (*router.RoutingRule, error) {
@@ -149,6 +160,9 @@ func parseFieldRule(msg json.RawMessage) (*router.RoutingRule, error) {
 		LocalPort  *PortList          `json:"localPort"`
 		Process    *StringList        `json:"process"`
 		LocalOS    *StringList        `json:"localOS"`
+		JA3        *StringList        `json:"ja3"`
+		JA4        *StringList        `json:"ja4"`
+		ALPN       *StringList        `json:"alpn"`
 		Webhook    *WebhookRuleConfig `json:"webhook"`
 	}
 	rawFieldRule := new(RawFieldRule)
@@ -160,6 +174,21 @@ func parseFieldRule(msg json.RawMessage) (*router.RoutingRule, error) {
 	rule := new(router.RoutingRule)
 	rule.RuleTag = rawFieldRule.RuleTag
 	switch {
+	case len(rawFieldRule.Chain) > 0:
+		// The last outbound of the chain is dialed through the rest.
+		if len(rawFieldRule.OutboundTag) > 0 || len(rawFieldRule.BalancerTag) > 0 {
+			return nil, errors.New("chain can't be specified with outboundTag or balancerTag in routing rule")
+		}
+		for _, tag := range rawFieldRule.Chain {
+			if tag == "" {
+				return nil, errors.New("empty outbound tag in chain of routing rule")
+			}
+		}
+		last := len(rawFieldRule.Chain) - 1
+		rule.TargetTag = &router.RoutingRule_Tag{
+			Tag: rawFieldRule.Chain[last],
+		}
+		rule.Chain = rawFieldRule.Chain[:last]
 	case len(rawFieldRule.OutboundTag) > 0:
 		rule.TargetTag = &router.RoutingRule_Tag{
 			Tag: rawFieldRule.OutboundTag,
@@ -169,7 +198,7 @@ func parseFieldRule(msg json.RawMessage) (*router.RoutingRule, error) {
 			BalancingTag: rawFieldRule.BalancerTag,
 		}
 	default:
-		return nil, errors.New("neither outboundTag nor balancerTag is specified in routing rule")
+		return nil, errors.New("none of outboundTag, balancerTag and chain is specified in routing rule")
 	}
 
 	if rawFieldRule.Domain != nil {
@@ -266,11 +295,34 @@ func parseFieldRule(msg json.RawMessage) (*router.RoutingRule, error) {
 		rule.LocalOs = *rawFieldRule.LocalOS
 	}
 
+	if rawFieldRule.JA3 != nil && len(*rawFieldRule.JA3) > 0 {
+		rule.Ja3 = *rawFieldRule.JA3
+	}
+
+	if rawFieldRule.JA4 != nil && len(*rawFieldRule.JA4) > 0 {
+		rule.Ja4 = *rawFieldRule.JA4
+	}
+
+	if rawFieldRule.ALPN != nil && len(*rawFieldRule.ALPN) > 0 {
+		rule.Alpn = *rawFieldRule.ALPN
+	}
+
 	if rawFieldRule.Webhook != nil && rawFieldRule.Webhook.URL != "" {
+		wh := rawFieldRule.Webhook
 		rule.Webhook = &router.WebhookConfig{
-			Url:           rawFieldRule.Webhook.URL,
-			Deduplication: rawFieldRule.Webhook.Deduplication,
-			Headers:       rawFieldRule.Webhook.Headers,
+			Url:               wh.URL,
+			Deduplication:     wh.Deduplication,
+			DeduplicationKeys: wh.DeduplicationKeys,
+			Headers:           wh.Headers,
+			Template:          wh.Template,
+			BatchWindow:       wh.BatchWindow,
+			BatchSize:         wh.BatchSize,
+			Retries:           wh.Retries,
+			RetryInterval:     wh.RetryInterval,
+			Timeout:           wh.Timeout,
+			QueueSize:         wh.QueueSize,
+			QueuePath:         wh.QueuePath,
+			Secret:            wh.Secret,
 		}
 	}
 
@@ -234,5 +234,36 @@ func TestRouterConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			Input: `{
+				"rules": [
+					{
+						"inboundTag": ["in"],
+						"chain": ["hop-a", "hop-b", "exit"]
+					}
+				]
+			}`,
+			Parser: createParser(),
+			Output: &router.Config{
+				Rule: []*router.RoutingRule{
+					{
+						InboundTag: []string{"in"},
+						TargetTag: &router.RoutingRule_Tag{
+							Tag: "exit",
+						},
+						Chain: []string{"hop-a", "hop-b"},
+					},
+				},
+			},
+		},
 	})
+
+	for _, input := range []string{
+		`{"rules": [{"inboundTag": ["in"], "outboundTag": "exit", "chain": ["hop", "exit"]}]}`,
+		`{"rules": [{"inboundTag": ["in"], "chain": ["", "exit"]}]}`,
+	} {
+		if _, err := createParser()(input); err == nil {
+			t.Error("expected error of ", input)
+		}
+	}
 }
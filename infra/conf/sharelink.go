@@ -0,0 +1,505 @@
+package conf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+)
+
+// ParseShareLink converts a vless://, vmess://, trojan://, ss:// or
+// hysteria2:// share link into an outbound config. The link remark becomes
+// the outbound tag.
+func ParseShareLink(link string) (*OutboundDetourConfig, error) {
+	raw, err := ShareLinkToJSON(link)
+	if err != nil {
+		return nil, err
+	}
+	config := new(OutboundDetourConfig)
+	if err := json.Unmarshal(raw, config); err != nil {
+		return nil, errors.New("invalid outbound generated from share link").Base(err)
+	}
+	return config, nil
+}
+
+// ShareLinkToJSON is like ParseShareLink, but returns the outbound as JSON
+// that only carries the fields present in the link.
+func ShareLinkToJSON(link string) (json.RawMessage, error) {
+	link = strings.TrimSpace(link)
+	scheme, _, ok := strings.Cut(link, "://")
+	if !ok {
+		return nil, errors.New("invalid share link: ", link)
+	}
+
+	var outbound map[string]interface{}
+	var err error
+	switch strings.ToLower(scheme) {
+	case "vless":
+		outbound, err = parseVLESSLink(link)
+	case "vmess":
+		outbound, err = parseVMessLink(link)
+	case "trojan":
+		outbound, err = parseTrojanLink(link)
+	case "ss":
+		outbound, err = parseShadowsocksLink(link)
+	case "hysteria2", "hy2":
+		outbound, err = parseHysteria2Link(link)
+	default:
+		return nil, errors.New("unsupported share link scheme: ", scheme)
+	}
+	if err != nil {
+		return nil, errors.New("failed to parse ", scheme, " share link").Base(err)
+	}
+	return json.Marshal(outbound)
+}
+
+// DecodeSubscription splits a subscription body into share links. The body is
+// either a plain list of links, one per line, or the base64 encoding of such
+// a list.
+func DecodeSubscription(content []byte) []string {
+	content = bytes.TrimSpace(content)
+	if !bytes.Contains(content, []byte("://")) {
+		compact := bytes.Join(bytes.Fields(content), nil)
+		if decoded, err := decodeBase64String(string(compact)); err == nil {
+			content = decoded
+		}
+	}
+
+	var links []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.Contains(line, "://") {
+			continue
+		}
+		links = append(links, line)
+	}
+	return links
+}
+
+func decodeBase64String(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func parseLinkURL(link string) (*url.URL, string, uint16, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return nil, "", 0, errors.New("missing server address")
+	}
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	if err != nil || port == 0 {
+		return nil, "", 0, errors.New("invalid server port: ", u.Port())
+	}
+	return u, host, uint16(port), nil
+}
+
+// linkStreamSettings builds streamSettings from the query parameters shared
+// by VLESS and Trojan links.
+func linkStreamSettings(q url.Values, defaultSecurity string) (map[string]interface{}, error) {
+	network := strings.ToLower(q.Get("type"))
+	if network == "" {
+		network = "tcp"
+	}
+	stream := map[string]interface{}{}
+
+	switch network {
+	case "tcp", "raw":
+		network = "raw"
+		if q.Get("headerType") == "http" {
+			request := map[string]interface{}{}
+			if path := q.Get("path"); path != "" {
+				request["path"] = strings.Split(path, ",")
+			}
+			if host := q.Get("host"); host != "" {
+				request["headers"] = map[string]interface{}{"Host": strings.Split(host, ",")}
+			}
+			stream["rawSettings"] = map[string]interface{}{
+				"header": map[string]interface{}{"type": "http", "request": request},
+			}
+		}
+	case "ws", "websocket":
+		network = "ws"
+		stream["wsSettings"] = hostPathSettings(q)
+	case "httpupgrade":
+		stream["httpupgradeSettings"] = hostPathSettings(q)
+	case "xhttp", "splithttp":
+		network = "xhttp"
+		settings := hostPathSettings(q)
+		if mode := q.Get("mode"); mode != "" {
+			settings["mode"] = mode
+		}
+		if extra := q.Get("extra"); extra != "" {
+			if !json.Valid([]byte(extra)) {
+				return nil, errors.New("invalid xhttp extra")
+			}
+			settings["extra"] = json.RawMessage(extra)
+		}
+		stream["xhttpSettings"] = settings
+	case "grpc":
+		settings := map[string]interface{}{}
+		if serviceName := q.Get("serviceName"); serviceName != "" {
+			settings["serviceName"] = serviceName
+		}
+		if authority := q.Get("authority"); authority != "" {
+			settings["authority"] = authority
+		}
+		if q.Get("mode") == "multi" {
+			settings["multiMode"] = true
+		}
+		stream["grpcSettings"] = settings
+	case "kcp", "mkcp":
+		network = "kcp"
+	default:
+		return nil, errors.New("unsupported transport: ", network)
+	}
+	stream["network"] = network
+
+	security := q.Get("security")
+	if security == "" {
+		security = defaultSecurity
+	}
+	switch security {
+	case "none", "":
+	case "tls":
+		settings := map[string]interface{}{}
+		if sni := q.Get("sni"); sni != "" {
+			settings["serverName"] = sni
+		}
+		if fp := q.Get("fp"); fp != "" {
+			settings["fingerprint"] = fp
+		}
+		if alpn := q.Get("alpn"); alpn != "" {
+			settings["alpn"] = strings.Split(alpn, ",")
+		}
+		if pcs := q.Get("pcs"); pcs != "" {
+			settings["pinnedPeerCertSha256"] = pcs
+		}
+		if vcn := q.Get("vcn"); vcn != "" {
+			settings["verifyPeerCertByName"] = vcn
+		}
+		if ech := q.Get("ech"); ech != "" {
+			settings["echConfigList"] = ech
+		}
+		stream["security"] = "tls"
+		stream["tlsSettings"] = settings
+	case "reality":
+		settings := map[string]interface{}{
+			"serverName":  q.Get("sni"),
+			"fingerprint": q.Get("fp"),
+			"password":    q.Get("pbk"),
+		}
+		if settings["fingerprint"] == "" {
+			settings["fingerprint"] = "chrome"
+		}
+		if sid := q.Get("sid"); sid != "" {
+			settings["shortId"] = sid
+		}
+		if spx := q.Get("spx"); spx != "" {
+			settings["spiderX"] = spx
+		}
+		if pqv := q.Get("pqv"); pqv != "" {
+			settings["mldsa65Verify"] = pqv
+		}
+		stream["security"] = "reality"
+		stream["realitySettings"] = settings
+	default:
+		return nil, errors.New("unsupported security: ", security)
+	}
+
+	return stream, nil
+}
+
+func hostPathSettings(q url.Values) map[string]interface{} {
+	settings := map[string]interface{}{}
+	if host := q.Get("host"); host != "" {
+		settings["host"] = host
+	}
+	if path := q.Get("path"); path != "" {
+		settings["path"] = path
+	}
+	return settings
+}
+
+func parseVLESSLink(link string) (map[string]interface{}, error) {
+	u, host, port, err := parseLinkURL(link)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	settings := map[string]interface{}{
+		"address":    host,
+		"port":       port,
+		"id":         u.User.Username(),
+		"encryption": "none",
+	}
+	if encryption := q.Get("encryption"); encryption != "" {
+		settings["encryption"] = encryption
+	}
+	if flow := q.Get("flow"); flow != "" {
+		settings["flow"] = flow
+	}
+	stream, err := linkStreamSettings(q, "none")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"tag":            u.Fragment,
+		"protocol":       "vless",
+		"settings":       settings,
+		"streamSettings": stream,
+	}, nil
+}
+
+func parseTrojanLink(link string) (map[string]interface{}, error) {
+	u, host, port, err := parseLinkURL(link)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	settings := map[string]interface{}{
+		"address":  host,
+		"port":     port,
+		"password": u.User.Username(),
+	}
+	stream, err := linkStreamSettings(q, "tls")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"tag":            u.Fragment,
+		"protocol":       "trojan",
+		"settings":       settings,
+		"streamSettings": stream,
+	}, nil
+}
+
+// vmessLink is the JSON carried by a v2rayN style vmess:// link.
+type vmessLink struct {
+	Version  json.RawMessage `json:"v"`
+	Remark   string          `json:"ps"`
+	Address  string          `json:"add"`
+	Port     json.RawMessage `json:"port"`
+	ID       string          `json:"id"`
+	Security string          `json:"scy"`
+	Network  string          `json:"net"`
+	Type     string          `json:"type"`
+	Host     string          `json:"host"`
+	Path     string          `json:"path"`
+	TLS      string          `json:"tls"`
+	SNI      string          `json:"sni"`
+	ALPN     string          `json:"alpn"`
+	FP       string          `json:"fp"`
+}
+
+func parseVMessLink(link string) (map[string]interface{}, error) {
+	decoded, err := decodeBase64String(strings.TrimPrefix(link[len("vmess://"):], "//"))
+	if err != nil {
+		return nil, err
+	}
+	var v vmessLink
+	if err := json.Unmarshal(decoded, &v); err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(strings.Trim(string(v.Port), `"`), 10, 16)
+	if err != nil || port == 0 {
+		return nil, errors.New("invalid server port: ", string(v.Port))
+	}
+	if v.Address == "" {
+		return nil, errors.New("missing server address")
+	}
+
+	settings := map[string]interface{}{
+		"address": v.Address,
+		"port":    port,
+		"id":      v.ID,
+	}
+	if v.Security != "" {
+		settings["security"] = v.Security
+	}
+
+	q := url.Values{}
+	q.Set("type", v.Network)
+	q.Set("security", v.TLS)
+	q.Set("host", v.Host)
+	q.Set("path", v.Path)
+	q.Set("sni", v.SNI)
+	q.Set("alpn", v.ALPN)
+	q.Set("fp", v.FP)
+	switch v.Network {
+	case "tcp", "raw", "":
+		q.Set("headerType", v.Type)
+	case "grpc":
+		q.Set("serviceName", v.Path)
+		q.Set("mode", v.Type)
+	case "xhttp", "splithttp":
+		q.Set("mode", v.Type)
+	}
+	stream, err := linkStreamSettings(q, "none")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"tag":            v.Remark,
+		"protocol":       "vmess",
+		"settings":       settings,
+		"streamSettings": stream,
+	}, nil
+}
+
+func parseShadowsocksLink(link string) (map[string]interface{}, error) {
+	rest, remark, _ := strings.Cut(link[len("ss://"):], "#")
+	if remark != "" {
+		if r, err := url.PathUnescape(remark); err == nil {
+			remark = r
+		}
+	}
+	rest, query, _ := strings.Cut(rest, "?")
+	rest = strings.TrimSuffix(rest, "/")
+	if q, err := url.ParseQuery(query); err == nil && q.Get("plugin") != "" {
+		return nil, errors.New("shadowsocks plugins are not supported")
+	}
+
+	var userInfo, hostPort string
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		// SIP002, the user info is base64 encoded or percent encoded for 2022 ciphers
+		userInfo, hostPort = rest[:at], rest[at+1:]
+		if decoded, err := decodeBase64String(userInfo); err == nil && strings.Contains(string(decoded), ":") {
+			userInfo = string(decoded)
+		} else if u, err := url.PathUnescape(userInfo); err == nil {
+			userInfo = u
+		}
+	} else {
+		decoded, err := decodeBase64String(rest)
+		if err != nil {
+			return nil, err
+		}
+		at := strings.LastIndex(string(decoded), "@")
+		if at < 0 {
+			return nil, errors.New("missing server address")
+		}
+		userInfo, hostPort = string(decoded[:at]), string(decoded[at+1:])
+	}
+
+	method, password, ok := strings.Cut(userInfo, ":")
+	if !ok {
+		return nil, errors.New("missing method or password")
+	}
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil || port == 0 {
+		return nil, errors.New("invalid server port: ", portStr)
+	}
+	return map[string]interface{}{
+		"tag":      remark,
+		"protocol": "shadowsocks",
+		"settings": map[string]interface{}{
+			"address":  host,
+			"port":     port,
+			"method":   method,
+			"password": password,
+		},
+	}, nil
+}
+
+func parseHysteria2Link(link string) (map[string]interface{}, error) {
+	// The port may be a port hopping list such as "443,20000-30000", which
+	// url.Parse rejects, so cut it out of the authority first.
+	scheme, rest, _ := strings.Cut(link, "://")
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority := rest[:end]
+	ports := "443"
+	if colon := strings.LastIndex(authority, ":"); colon > strings.LastIndex(authority, "]") && colon > strings.LastIndex(authority, "@") {
+		ports = authority[colon+1:]
+		authority = authority[:colon]
+	}
+	first, _, _ := strings.Cut(ports, ",")
+	first, _, _ = strings.Cut(first, "-")
+	port, err := strconv.ParseUint(first, 10, 16)
+	if err != nil || port == 0 {
+		return nil, errors.New("invalid server port: ", ports)
+	}
+
+	u, err := url.Parse(scheme + "://" + authority + rest[end:])
+	if err != nil {
+		return nil, err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return nil, errors.New("missing server address")
+	}
+
+	auth := u.User.Username()
+	if password, ok := u.User.Password(); ok {
+		auth += ":" + password
+	}
+	q := u.Query()
+
+	tlsSettings := map[string]interface{}{"alpn": []string{"h3"}}
+	if sni := q.Get("sni"); sni != "" {
+		tlsSettings["serverName"] = sni
+	}
+	if pin := q.Get("pinSHA256"); pin != "" {
+		tlsSettings["pinnedPeerCertSha256"] = pin
+	}
+	stream := map[string]interface{}{
+		"network": "hysteria",
+		"hysteriaSettings": map[string]interface{}{
+			"version": 2,
+			"auth":    auth,
+		},
+		"security":    "tls",
+		"tlsSettings": tlsSettings,
+	}
+
+	finalmask := map[string]interface{}{}
+	switch obfs := q.Get("obfs"); obfs {
+	case "":
+	case "salamander":
+		finalmask["udp"] = []interface{}{map[string]interface{}{
+			"type":     "salamander",
+			"settings": map[string]interface{}{"password": q.Get("obfs-password")},
+		}}
+	default:
+		return nil, errors.New("unsupported obfs: ", obfs)
+	}
+	if ports != first {
+		finalmask["quicParams"] = map[string]interface{}{
+			"udpHop": map[string]interface{}{"ports": ports},
+		}
+	}
+	if len(finalmask) > 0 {
+		stream["finalmask"] = finalmask
+	}
+
+	return map[string]interface{}{
+		"tag":      u.Fragment,
+		"protocol": "hysteria",
+		"settings": map[string]interface{}{
+			"version": 2,
+			"address": host,
+			"port":    port,
+		},
+		"streamSettings": stream,
+	}, nil
+}
@@ -0,0 +1,417 @@
+package conf
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+)
+
+// ShareLink converts the outbound into a vless://, vmess://, trojan://, ss://
+// or hysteria2:// share link. It is the inverse of ParseShareLink, so settings
+// that links cannot carry, such as sockopt or mux, are dropped.
+func (c *OutboundDetourConfig) ShareLink() (string, error) {
+	var settings []byte
+	if c.Settings != nil {
+		settings = *c.Settings
+	}
+	var link string
+	var err error
+	switch strings.ToLower(c.Protocol) {
+	case "vless":
+		link, err = vlessShareLink(c.Tag, settings, c.StreamSetting)
+	case "vmess":
+		link, err = vmessShareLink(c.Tag, settings, c.StreamSetting)
+	case "trojan":
+		link, err = trojanShareLink(c.Tag, settings, c.StreamSetting)
+	case "shadowsocks", "ss":
+		link, err = shadowsocksShareLink(c.Tag, settings, c.StreamSetting)
+	case "hysteria":
+		link, err = hysteria2ShareLink(c.Tag, settings, c.StreamSetting)
+	default:
+		return "", errors.New("protocol ", c.Protocol, " has no share link format")
+	}
+	if err != nil {
+		return "", errors.New("failed to export ", c.Protocol, " outbound ", c.Tag).Base(err)
+	}
+	return link, nil
+}
+
+func linkHostPort(address *Address, port uint16) (string, error) {
+	if address == nil || address.Address == nil {
+		return "", errors.New("missing server address")
+	}
+	if port == 0 {
+		return "", errors.New("missing server port")
+	}
+	host := address.String()
+	if address.Family().IsIP() {
+		host = address.IP().String()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+// linkQuery is the inverse of linkStreamSettings.
+func linkQuery(stream *StreamConfig, q url.Values) error {
+	if stream == nil {
+		stream = new(StreamConfig)
+	}
+	network := "raw"
+	if stream.Method != nil {
+		network = strings.ToLower(string(*stream.Method))
+	}
+	if stream.Network != nil {
+		network = strings.ToLower(string(*stream.Network))
+	}
+
+	switch network {
+	case "raw", "tcp":
+		q.Set("type", "tcp")
+		raw := stream.RAWSettings
+		if raw == nil {
+			raw = stream.TCPSettings
+		}
+		if raw != nil && len(raw.HeaderConfig) > 0 {
+			var header struct {
+				Type    string `json:"type"`
+				Request struct {
+					Path    StringList             `json:"path"`
+					Headers map[string]*StringList `json:"headers"`
+				} `json:"request"`
+			}
+			if err := json.Unmarshal(raw.HeaderConfig, &header); err != nil {
+				return errors.New("invalid raw header").Base(err)
+			}
+			switch header.Type {
+			case "", "none":
+			case "http":
+				q.Set("headerType", "http")
+				if len(header.Request.Path) > 0 {
+					q.Set("path", strings.Join(header.Request.Path, ","))
+				}
+				for name, values := range header.Request.Headers {
+					if strings.EqualFold(name, "host") && values != nil {
+						q.Set("host", strings.Join(*values, ","))
+					}
+				}
+			default:
+				return errors.New("unsupported raw header: ", header.Type)
+			}
+		}
+	case "ws", "websocket":
+		q.Set("type", "ws")
+		if ws := stream.WSSettings; ws != nil {
+			setLinkParam(q, "host", ws.Host)
+			setLinkParam(q, "path", ws.Path)
+		}
+	case "httpupgrade":
+		q.Set("type", "httpupgrade")
+		if hu := stream.HTTPUPGRADESettings; hu != nil {
+			setLinkParam(q, "host", hu.Host)
+			setLinkParam(q, "path", hu.Path)
+		}
+	case "xhttp", "splithttp":
+		q.Set("type", "xhttp")
+		xhttp := stream.XHTTPSettings
+		if xhttp == nil {
+			xhttp = stream.SplitHTTPSettings
+		}
+		if xhttp != nil {
+			setLinkParam(q, "host", xhttp.Host)
+			setLinkParam(q, "path", xhttp.Path)
+			setLinkParam(q, "mode", xhttp.Mode)
+			if len(xhttp.Extra) > 0 {
+				var extra json.RawMessage
+				if err := json.Unmarshal(xhttp.Extra, &extra); err != nil {
+					return errors.New("invalid xhttp extra").Base(err)
+				}
+				q.Set("extra", string(extra))
+			}
+		}
+	case "grpc":
+		q.Set("type", "grpc")
+		if grpc := stream.GRPCSettings; grpc != nil {
+			setLinkParam(q, "serviceName", grpc.ServiceName)
+			setLinkParam(q, "authority", grpc.Authority)
+			if grpc.MultiMode {
+				q.Set("mode", "multi")
+			}
+		}
+	case "kcp", "mkcp":
+		q.Set("type", "kcp")
+	default:
+		return errors.New("unsupported transport: ", network)
+	}
+
+	switch strings.ToLower(stream.Security) {
+	case "", "none":
+		q.Set("security", "none")
+	case "tls":
+		q.Set("security", "tls")
+		if tls := stream.TLSSettings; tls != nil {
+			setLinkParam(q, "sni", tls.ServerName)
+			setLinkParam(q, "fp", tls.Fingerprint)
+			if tls.ALPN != nil {
+				setLinkParam(q, "alpn", strings.Join(*tls.ALPN, ","))
+			}
+			setLinkParam(q, "pcs", tls.PinnedPeerCertSha256)
+			setLinkParam(q, "vcn", tls.VerifyPeerCertByName)
+			setLinkParam(q, "ech", tls.ECHConfigList)
+		}
+	case "reality":
+		reality := stream.REALITYSettings
+		if reality == nil {
+			return errors.New("missing realitySettings")
+		}
+		password := reality.Password
+		if password == "" {
+			password = reality.PublicKey
+		}
+		if password == "" {
+			return errors.New("missing REALITY password")
+		}
+		q.Set("security", "reality")
+		q.Set("pbk", password)
+		setLinkParam(q, "sni", reality.ServerName)
+		setLinkParam(q, "fp", reality.Fingerprint)
+		setLinkParam(q, "sid", reality.ShortId)
+		setLinkParam(q, "spx", reality.SpiderX)
+		setLinkParam(q, "pqv", reality.Mldsa65Verify)
+	default:
+		return errors.New("unsupported security: ", stream.Security)
+	}
+	return nil
+}
+
+func setLinkParam(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func vlessShareLink(tag string, settings []byte, stream *StreamConfig) (string, error) {
+	config := new(VLessOutboundConfig)
+	if err := json.Unmarshal(settings, config); err != nil {
+		return "", err
+	}
+	if config.Address == nil && len(config.Vnext) > 0 {
+		if len(config.Vnext[0].Users) == 0 {
+			return "", errors.New("missing user")
+		}
+		if err := json.Unmarshal(config.Vnext[0].Users[0], config); err != nil {
+			return "", err
+		}
+		config.Address, config.Port = config.Vnext[0].Address, config.Vnext[0].Port
+	}
+	hostPort, err := linkHostPort(config.Address, config.Port)
+	if err != nil {
+		return "", err
+	}
+	if config.Reverse != nil {
+		return "", errors.New("reverse proxy has no share link format")
+	}
+
+	q := url.Values{}
+	q.Set("encryption", "none")
+	setLinkParam(q, "encryption", config.Encryption)
+	setLinkParam(q, "flow", config.Flow)
+	if err := linkQuery(stream, q); err != nil {
+		return "", err
+	}
+	return (&url.URL{
+		Scheme:   "vless",
+		User:     url.User(config.Id),
+		Host:     hostPort,
+		RawQuery: q.Encode(),
+		Fragment: tag,
+	}).String(), nil
+}
+
+func trojanShareLink(tag string, settings []byte, stream *StreamConfig) (string, error) {
+	config := new(TrojanClientConfig)
+	if err := json.Unmarshal(settings, config); err != nil {
+		return "", err
+	}
+	if config.Address == nil && len(config.Servers) > 0 {
+		server := config.Servers[0]
+		config.Address, config.Port, config.Password = server.Address, server.Port, server.Password
+	}
+	hostPort, err := linkHostPort(config.Address, config.Port)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	if err := linkQuery(stream, q); err != nil {
+		return "", err
+	}
+	return (&url.URL{
+		Scheme:   "trojan",
+		User:     url.User(config.Password),
+		Host:     hostPort,
+		RawQuery: q.Encode(),
+		Fragment: tag,
+	}).String(), nil
+}
+
+func vmessShareLink(tag string, settings []byte, stream *StreamConfig) (string, error) {
+	config := new(VMessOutboundConfig)
+	if err := json.Unmarshal(settings, config); err != nil {
+		return "", err
+	}
+	if config.Address == nil && len(config.Receivers) > 0 {
+		if len(config.Receivers[0].Users) == 0 {
+			return "", errors.New("missing user")
+		}
+		if err := json.Unmarshal(config.Receivers[0].Users[0], config); err != nil {
+			return "", err
+		}
+		config.Address, config.Port = config.Receivers[0].Address, config.Receivers[0].Port
+	}
+	if _, err := linkHostPort(config.Address, config.Port); err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	if err := linkQuery(stream, q); err != nil {
+		return "", err
+	}
+	v := vmessLink{
+		Version:  json.RawMessage(`"2"`),
+		Remark:   tag,
+		Address:  config.Address.String(),
+		Port:     json.RawMessage(strconv.Quote(strconv.Itoa(int(config.Port)))),
+		ID:       config.ID,
+		Security: config.Security,
+		Network:  q.Get("type"),
+		Host:     q.Get("host"),
+		Path:     q.Get("path"),
+		SNI:      q.Get("sni"),
+		ALPN:     q.Get("alpn"),
+		FP:       q.Get("fp"),
+	}
+	if config.Address.Family().IsIP() {
+		v.Address = config.Address.IP().String()
+	}
+	switch v.Network {
+	case "tcp":
+		v.Type = q.Get("headerType")
+	case "grpc":
+		v.Path = q.Get("serviceName")
+		v.Type = q.Get("mode")
+	case "xhttp":
+		v.Type = q.Get("mode")
+	}
+	switch security := q.Get("security"); security {
+	case "none":
+	case "tls":
+		v.TLS = security
+	default:
+		return "", errors.New("unsupported security: ", security)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return "vmess://" + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func shadowsocksShareLink(tag string, settings []byte, stream *StreamConfig) (string, error) {
+	config := new(ShadowsocksClientConfig)
+	if err := json.Unmarshal(settings, config); err != nil {
+		return "", err
+	}
+	if config.Address == nil && len(config.Servers) > 0 {
+		server := config.Servers[0]
+		config.Address, config.Port = server.Address, server.Port
+		config.Cipher, config.Password = server.Cipher, server.Password
+	}
+	hostPort, err := linkHostPort(config.Address, config.Port)
+	if err != nil {
+		return "", err
+	}
+	if stream != nil && stream.Security != "" && stream.Security != "none" {
+		return "", errors.New("shadowsocks links cannot carry security settings")
+	}
+
+	// SIP002 requires percent encoding instead of base64 for 2022 ciphers.
+	var userInfo string
+	if strings.HasPrefix(config.Cipher, "2022-") {
+		userInfo = url.PathEscape(config.Cipher) + ":" + url.PathEscape(config.Password)
+	} else {
+		userInfo = base64.RawURLEncoding.EncodeToString([]byte(config.Cipher + ":" + config.Password))
+	}
+	link := "ss://" + userInfo + "@" + hostPort
+	if tag != "" {
+		link += "#" + url.PathEscape(tag)
+	}
+	return link, nil
+}
+
+func hysteria2ShareLink(tag string, settings []byte, stream *StreamConfig) (string, error) {
+	config := new(HysteriaClientConfig)
+	if err := json.Unmarshal(settings, config); err != nil {
+		return "", err
+	}
+	if config.Version != 2 {
+		return "", errors.New("only hysteria version 2 has a share link format")
+	}
+	hostPort, err := linkHostPort(config.Address, config.Port)
+	if err != nil {
+		return "", err
+	}
+	if stream == nil || stream.HysteriaSettings == nil {
+		return "", errors.New("missing hysteriaSettings")
+	}
+
+	q := url.Values{}
+	if tls := stream.TLSSettings; tls != nil {
+		setLinkParam(q, "sni", tls.ServerName)
+		setLinkParam(q, "pinSHA256", tls.PinnedPeerCertSha256)
+	}
+	if mask := stream.FinalMask; mask != nil {
+		for _, m := range mask.Udp {
+			if m.Type != "salamander" {
+				return "", errors.New("unsupported finalmask: ", m.Type)
+			}
+			var obfs struct {
+				Password string `json:"password"`
+			}
+			if m.Settings != nil {
+				if err := json.Unmarshal(*m.Settings, &obfs); err != nil {
+					return "", err
+				}
+			}
+			q.Set("obfs", "salamander")
+			q.Set("obfs-password", obfs.Password)
+		}
+		if mask.QuicParams != nil && len(mask.QuicParams.UdpHop.PortList.Range) > 0 {
+			ports := []string{strconv.Itoa(int(config.Port))}
+			for i, r := range mask.QuicParams.UdpHop.PortList.Range {
+				if i == 0 && r.From == r.To && r.From == uint32(config.Port) {
+					continue
+				}
+				if r.From == r.To {
+					ports = append(ports, strconv.Itoa(int(r.From)))
+				} else {
+					ports = append(ports, strconv.Itoa(int(r.From))+"-"+strconv.Itoa(int(r.To)))
+				}
+			}
+			if len(ports) > 1 {
+				hostPort += "," + strings.Join(ports[1:], ",")
+			}
+		}
+	}
+	return (&url.URL{
+		Scheme:   "hysteria2",
+		User:     url.User(stream.HysteriaSettings.Auth),
+		Host:     hostPort,
+		Path:     "/",
+		RawQuery: q.Encode(),
+		Fragment: tag,
+	}).String(), nil
+}
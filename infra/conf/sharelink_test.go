@@ -0,0 +1,204 @@
+package conf_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/xtls/xray-core/infra/conf"
+)
+
+const testRealityKey = "dZ6Ld7-kV0uiFmlQTjZQWMRBLeGvbbOYZbMrh_uMMEA"
+
+func TestShareLinkToJSON(t *testing.T) {
+	vmessJSON := `{"v":"2","ps":"vm","add":"example.com","port":"443","id":"27848739-7e62-4138-9fd3-098a63964b6b","scy":"auto","net":"ws","host":"cdn.example.com","path":"/ws","tls":"tls","sni":"example.com"}`
+
+	for _, tt := range []struct {
+		link string
+		want string
+	}{
+		{
+			link: "vless://27848739-7e62-4138-9fd3-098a63964b6b@example.com:443?encryption=none&flow=xtls-rprx-vision&security=reality&sni=www.example.com&fp=chrome&pbk=" + testRealityKey + "&sid=6ba85179e30d4fc2&type=xhttp&path=%2Fx&mode=auto#node%201",
+			want: `{
+				"tag": "node 1",
+				"protocol": "vless",
+				"settings": {"address": "example.com", "port": 443, "id": "27848739-7e62-4138-9fd3-098a63964b6b", "encryption": "none", "flow": "xtls-rprx-vision"},
+				"streamSettings": {
+					"network": "xhttp",
+					"xhttpSettings": {"path": "/x", "mode": "auto"},
+					"security": "reality",
+					"realitySettings": {"serverName": "www.example.com", "fingerprint": "chrome", "password": "` + testRealityKey + `", "shortId": "6ba85179e30d4fc2"}
+				}
+			}`,
+		},
+		{
+			link: "vmess://" + base64.StdEncoding.EncodeToString([]byte(vmessJSON)),
+			want: `{
+				"tag": "vm",
+				"protocol": "vmess",
+				"settings": {"address": "example.com", "port": 443, "id": "27848739-7e62-4138-9fd3-098a63964b6b", "security": "auto"},
+				"streamSettings": {
+					"network": "ws",
+					"wsSettings": {"host": "cdn.example.com", "path": "/ws"},
+					"security": "tls",
+					"tlsSettings": {"serverName": "example.com"}
+				}
+			}`,
+		},
+		{
+			link: "trojan://secret@example.com:443?sni=example.com&alpn=h2,http%2F1.1#tj",
+			want: `{
+				"tag": "tj",
+				"protocol": "trojan",
+				"settings": {"address": "example.com", "port": 443, "password": "secret"},
+				"streamSettings": {
+					"network": "raw",
+					"security": "tls",
+					"tlsSettings": {"serverName": "example.com", "alpn": ["h2", "http/1.1"]}
+				}
+			}`,
+		},
+		{
+			link: "ss://" + base64.RawURLEncoding.EncodeToString([]byte("aes-256-gcm:pass")) + "@1.2.3.4:8388#ss%20node",
+			want: `{
+				"tag": "ss node",
+				"protocol": "shadowsocks",
+				"settings": {"address": "1.2.3.4", "port": 8388, "method": "aes-256-gcm", "password": "pass"}
+			}`,
+		},
+		{
+			link: "ss://" + base64.StdEncoding.EncodeToString([]byte("chacha20-ietf-poly1305:pass@example.com:8388")) + "#legacy",
+			want: `{
+				"tag": "legacy",
+				"protocol": "shadowsocks",
+				"settings": {"address": "example.com", "port": 8388, "method": "chacha20-ietf-poly1305", "password": "pass"}
+			}`,
+		},
+		{
+			link: "hysteria2://auth@example.com:443,20000-30000/?sni=example.com&obfs=salamander&obfs-password=obfs#hy",
+			want: `{
+				"tag": "hy",
+				"protocol": "hysteria",
+				"settings": {"version": 2, "address": "example.com", "port": 443},
+				"streamSettings": {
+					"network": "hysteria",
+					"hysteriaSettings": {"version": 2, "auth": "auth"},
+					"security": "tls",
+					"tlsSettings": {"serverName": "example.com", "alpn": ["h3"]},
+					"finalmask": {
+						"udp": [{"type": "salamander", "settings": {"password": "obfs"}}],
+						"quicParams": {"udpHop": {"ports": "443,20000-30000"}}
+					}
+				}
+			}`,
+		},
+	} {
+		t.Run(tt.link, func(t *testing.T) {
+			raw, err := ShareLinkToJSON(tt.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(raw, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("ShareLinkToJSON() = %s", raw)
+			}
+
+			config, err := ParseShareLink(tt.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.Build(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestShareLinkRoundTrip(t *testing.T) {
+	vmessJSON := `{"v":"2","ps":"vm","add":"2001:db8::1","port":"443","id":"27848739-7e62-4138-9fd3-098a63964b6b","net":"grpc","path":"svc","type":"multi","tls":"tls","sni":"example.com"}`
+
+	for _, link := range []string{
+		"vless://27848739-7e62-4138-9fd3-098a63964b6b@example.com:443?encryption=none&flow=xtls-rprx-vision&security=reality&sni=www.example.com&fp=chrome&pbk=" + testRealityKey + "&sid=6ba85179e30d4fc2&spx=%2F&type=xhttp&path=%2Fx&mode=auto&extra=%7B%22xmux%22%3A%7B%7D%7D#node%201",
+		"vless://27848739-7e62-4138-9fd3-098a63964b6b@1.2.3.4:80?type=tcp&headerType=http&host=a.com,b.com&path=%2F",
+		"vmess://" + base64.StdEncoding.EncodeToString([]byte(vmessJSON)),
+		"trojan://p%40ss@example.com:443?type=ws&host=cdn.example.com&path=%2Fws&alpn=http%2F1.1#tj",
+		"ss://" + base64.RawURLEncoding.EncodeToString([]byte("aes-256-gcm:pass")) + "@1.2.3.4:8388#ss%20node",
+		"ss://2022-blake3-aes-128-gcm:YctPZ6U7xPPcU%2Bgp3u%2B0tx%2FtRizJN9K8y%2BuKlW2qjlI%3D@example.com:8388",
+		"hysteria2://auth@example.com:443,20000-30000/?sni=example.com&obfs=salamander&obfs-password=obfs#hy",
+	} {
+		t.Run(link, func(t *testing.T) {
+			config, err := ParseShareLink(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exported, err := config.ShareLink()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ShareLinkToJSON(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ShareLinkToJSON(exported)
+			if err != nil {
+				t.Fatalf("failed to parse exported link %s: %s", exported, err)
+			}
+			if string(got) != string(want) {
+				t.Fatalf("exported link %s parses to %s, want %s", exported, got, want)
+			}
+		})
+	}
+}
+
+func TestShareLinkExportUnsupported(t *testing.T) {
+	for _, outbound := range []string{
+		`{"protocol": "freedom"}`,
+		`{"protocol": "vless", "settings": {"address": "example.com", "port": 443, "id": "a"}, "streamSettings": {"network": "hysteria"}}`,
+		`{"protocol": "vmess", "settings": {"address": "example.com", "port": 443, "id": "a"}, "streamSettings": {"security": "reality", "realitySettings": {"password": "a"}}}`,
+		`{"protocol": "trojan", "settings": {"password": "a"}}`,
+	} {
+		config := new(OutboundDetourConfig)
+		if err := json.Unmarshal([]byte(outbound), config); err != nil {
+			t.Fatal(err)
+		}
+		if link, err := config.ShareLink(); err == nil {
+			t.Errorf("expected error for %s, got %s", outbound, link)
+		}
+	}
+}
+
+func TestShareLinkInvalid(t *testing.T) {
+	for _, link := range []string{
+		"socks://example.com:1080",
+		"vless://id@example.com?security=tls",
+		"vless://id@example.com:443?type=quic",
+		"ss://bm9wYXNz@example.com:8388",
+		"ss://YWVzLTI1Ni1nY206cGFzcw@example.com:8388/?plugin=obfs-local",
+	} {
+		if _, err := ShareLinkToJSON(link); err == nil {
+			t.Errorf("expected error for %s", link)
+		}
+	}
+}
+
+func TestDecodeSubscription(t *testing.T) {
+	links := "vless://a@example.com:443#a\r\n\r\ntrojan://b@example.com:443#b\n"
+	want := []string{"vless://a@example.com:443#a", "trojan://b@example.com:443#b"}
+
+	for _, content := range []string{
+		links,
+		base64.StdEncoding.EncodeToString([]byte(links)),
+		base64.RawURLEncoding.EncodeToString([]byte(links)),
+	} {
+		if got := DecodeSubscription([]byte(content)); !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeSubscription(%q) = %v", content, got)
+		}
+	}
+}
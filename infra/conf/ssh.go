@@ -0,0 +1,67 @@
+package conf
+
+import (
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/proxy/ssh"
+	"google.golang.org/protobuf/proto"
+)
+
+// SSHClientConfig is configuration of an SSH server
+type SSHClientConfig struct {
+	Address              *Address    `json:"address"`
+	Port                 uint16      `json:"port"`
+	User                 string      `json:"user"`
+	Password             string      `json:"password"`
+	PrivateKey           *StringList `json:"privateKey"`
+	PrivateKeyFile       string      `json:"privateKeyFile"`
+	PrivateKeyPassphrase string      `json:"privateKeyPassphrase"`
+	UseAgent             bool        `json:"useAgent"`
+	AgentSocket          string      `json:"agentSocket"`
+	HostKey              *StringList `json:"hostKey"`
+	KeepAlive            uint32      `json:"keepAlive"`
+	ClientVersion        string      `json:"clientVersion"`
+}
+
+// Build implements Buildable
+func (c *SSHClientConfig) Build() (proto.Message, error) {
+	if c.Address == nil {
+		return nil, errors.New("SSH server address is not set.")
+	}
+	if c.User == "" {
+		return nil, errors.New("SSH user is not specified.")
+	}
+
+	config := &ssh.Config{
+		Address:              c.Address.Build(),
+		Port:                 uint32(c.Port),
+		User:                 c.User,
+		Password:             c.Password,
+		PrivateKeyPassphrase: c.PrivateKeyPassphrase,
+		UseAgent:             c.UseAgent,
+		AgentSocket:          c.AgentSocket,
+		KeepAlive:            c.KeepAlive,
+		ClientVersion:        c.ClientVersion,
+	}
+	if c.Port == 0 {
+		config.Port = 22
+	}
+	if c.PrivateKeyFile != "" || c.PrivateKey != nil {
+		var s []string
+		if c.PrivateKey != nil {
+			s = *c.PrivateKey
+		}
+		key, err := readFileOrString(c.PrivateKeyFile, s)
+		if err != nil {
+			return nil, errors.New("failed to read SSH private key").Base(err)
+		}
+		config.PrivateKey = key
+	}
+	if c.HostKey != nil {
+		config.HostKey = *c.HostKey
+	}
+	if config.Password == "" && len(config.PrivateKey) == 0 && !config.UseAgent {
+		return nil, errors.New("SSH password, private key or agent is not specified.")
+	}
+
+	return config, nil
+}
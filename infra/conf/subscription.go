@@ -0,0 +1,83 @@
+package conf
+
+import (
+	"context"
+
+	"github.com/xtls/xray-core/app/subscription"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/infra/conf/cfgcommon/duration"
+	"google.golang.org/protobuf/proto"
+)
+
+type SubscriptionProviderConfig struct {
+	URL       string            `json:"url"`
+	Outbound  string            `json:"outbound"`
+	TagPrefix string            `json:"tagPrefix"`
+	Interval  duration.Duration `json:"interval"`
+	UserAgent string            `json:"userAgent"`
+}
+
+func (c *SubscriptionProviderConfig) Build() (*subscription.Provider, error) {
+	if err := validateHTTPS(c.URL); err != nil {
+		return nil, errors.New("invalid subscription url: ", c.URL).Base(err)
+	}
+	if c.TagPrefix == "" {
+		return nil, errors.New("subscription tagPrefix is required")
+	}
+	if c.Interval < 0 {
+		return nil, errors.New("invalid subscription interval")
+	}
+	return &subscription.Provider{
+		Url:       c.URL,
+		Outbound:  c.Outbound,
+		TagPrefix: c.TagPrefix,
+		Interval:  int64(c.Interval),
+		UserAgent: c.UserAgent,
+	}, nil
+}
+
+type SubscriptionConfig struct {
+	Providers []*SubscriptionProviderConfig `json:"providers"`
+}
+
+func (c *SubscriptionConfig) Build() (proto.Message, error) {
+	config := &subscription.Config{}
+	prefixes := make(map[string]bool, len(c.Providers))
+	for _, p := range c.Providers {
+		built, err := p.Build()
+		if err != nil {
+			return nil, err
+		}
+		if prefixes[built.TagPrefix] {
+			return nil, errors.New("duplicated subscription tagPrefix: ", built.TagPrefix)
+		}
+		prefixes[built.TagPrefix] = true
+		config.Providers = append(config.Providers, built)
+	}
+	return config, nil
+}
+
+// parseSubscription builds outbounds from every share link in a
+// subscription body, skipping the links that cannot be used.
+func parseSubscription(content []byte) ([]*core.OutboundHandlerConfig, error) {
+	links := DecodeSubscription(content)
+	if len(links) == 0 {
+		return nil, errors.New("no share link found in subscription")
+	}
+	configs := make([]*core.OutboundHandlerConfig, 0, len(links))
+	for _, link := range links {
+		oc, err := ParseShareLink(link)
+		if err != nil {
+			errors.LogInfoInner(context.Background(), err, "skip share link in subscription")
+			continue
+		}
+		built, err := oc.Build()
+		if err != nil {
+			errors.LogInfoInner(context.Background(), err, "skip share link in subscription")
+			continue
+		}
+		configs = append(configs, built)
+	}
+	return configs, nil
+}
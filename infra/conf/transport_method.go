@@ -23,6 +23,7 @@ import (
 	"github.com/xtls/xray-core/transport/internet/kcp"
 	"github.com/xtls/xray-core/transport/internet/splithttp"
 	"github.com/xtls/xray-core/transport/internet/tcp"
+	"github.com/xtls/xray-core/transport/internet/tuic"
 	"github.com/xtls/xray-core/transport/internet/websocket"
 	"google.golang.org/protobuf/proto"
 )
@@ -527,6 +528,9 @@ type KCPConfig struct {
 	DownCap          *uint32 `json:"downlinkCapacity"`
 	CwndMultiplier   *uint32 `json:"cwndMultiplier"`
 	MaxSendingWindow *uint32 `json:"maxSendingWindow"`
+	DataShards       uint32  `json:"dataShards"`
+	ParityShards     uint32  `json:"parityShards"`
+	AdaptiveParity   bool    `json:"adaptiveParity"`
 
 	HeaderConfig json.RawMessage `json:"header"`
 	Seed         *string         `json:"seed"`
@@ -558,6 +562,9 @@ func (c *KCPConfig) Build() (proto.Message, error) {
 	if c.MaxSendingWindow != nil {
 		config.MaxSendingWindow = *c.MaxSendingWindow
 	}
+	config.DataShards = c.DataShards
+	config.ParityShards = c.ParityShards
+	config.AdaptiveParity = c.AdaptiveParity
 
 	if config.Mtu < 21 {
 		return nil, errors.New("Mtu must be at least 21").AtError()
@@ -571,6 +578,15 @@ func (c *KCPConfig) Build() (proto.Message, error) {
 	if config.GetSendingBufferSize() == 0 {
 		return nil, errors.New("MaxSendingWindow must be >= Mtu").AtError()
 	}
+	if (config.DataShards == 0) != (config.ParityShards == 0) {
+		return nil, errors.New("dataShards and parityShards must be set together").AtError()
+	}
+	if config.DataShards+config.ParityShards > 255 {
+		return nil, errors.New("dataShards + parityShards must be at most 255").AtError()
+	}
+	if config.FECEnabled() && config.Mtu < 21+kcp.FECOverhead {
+		return nil, errors.New("Mtu must be at least ", 21+kcp.FECOverhead, " with FEC").AtError()
+	}
 
 	return config, nil
 }
@@ -812,3 +828,51 @@ func readFileOrString(f string, s []string) ([]byte, error) {
 	}
 	return nil, errors.New("both file and bytes are empty.")
 }
+
+type TuicConfig struct {
+	UUID           string `json:"uuid"`
+	Password       string `json:"password"`
+	UdpRelayMode   string `json:"udpRelayMode"`
+	ZeroRtt        bool   `json:"zeroRtt"`
+	UdpIdleTimeout int64  `json:"udpIdleTimeout"`
+	AuthTimeout    int64  `json:"authTimeout"`
+	Heartbeat      int64  `json:"heartbeat"`
+}
+
+func (c *TuicConfig) Build() (proto.Message, error) {
+	config := &tuic.Config{
+		Uuid:           c.UUID,
+		Password:       c.Password,
+		UdpRelayMode:   strings.ToLower(c.UdpRelayMode),
+		ZeroRtt:        c.ZeroRtt,
+		UdpIdleTimeout: c.UdpIdleTimeout,
+		AuthTimeout:    c.AuthTimeout,
+		Heartbeat:      c.Heartbeat,
+	}
+
+	switch config.UdpRelayMode {
+	case "":
+		config.UdpRelayMode = "native"
+	case "native", "quic":
+	default:
+		return nil, errors.New("unknown udpRelayMode: ", c.UdpRelayMode)
+	}
+	if c.UdpIdleTimeout != 0 && (c.UdpIdleTimeout < 2 || c.UdpIdleTimeout > 600) {
+		return nil, errors.New("UdpIdleTimeout must be between 2 and 600")
+	}
+	if c.AuthTimeout < 0 || c.Heartbeat < 0 {
+		return nil, errors.New("authTimeout and heartbeat must not be negative")
+	}
+
+	if config.UdpIdleTimeout == 0 {
+		config.UdpIdleTimeout = 60
+	}
+	if config.AuthTimeout == 0 {
+		config.AuthTimeout = 3
+	}
+	if config.Heartbeat == 0 {
+		config.Heartbeat = 10
+	}
+
+	return config, nil
+}
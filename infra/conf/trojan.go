@@ -2,14 +2,8 @@ package conf
 
 import (
 	"encoding/json"
-	"path/filepath"
-	"runtime"
-	"strconv"
-	"strings"
-	"syscall"
 
 	"github.com/xtls/xray-core/common/errors"
-	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/common/task"
@@ -100,6 +94,13 @@ type TrojanInboundFallback struct {
 	Type string          `json:"type"`
 	Dest json.RawMessage `json:"dest"`
 	Xver uint64          `json:"xver"`
+
+	Host        string                     `json:"host"`
+	PathRegexp  string                     `json:"pathRegexp"`
+	Methods     *StringList                `json:"methods"`
+	Protocol    string                     `json:"protocol"`
+	Dests       []*FallbackDestConfig      `json:"dests"`
+	HealthCheck *FallbackHealthCheckConfig `json:"healthCheck"`
 }
 
 // TrojanUserConfig is user configuration
@@ -149,20 +150,30 @@ func (c *TrojanServerConfig) Build() (proto.Message, error) {
 	}
 
 	for _, fb := range c.Fallbacks {
-		var i uint16
-		var s string
-		if err := json.Unmarshal(fb.Dest, &i); err == nil {
-			s = strconv.Itoa(int(i))
-		} else {
-			_ = json.Unmarshal(fb.Dest, &s)
+		dests, err := buildFallbackDests("Trojan", fb.Dests)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkFallbackPathRegexp("Trojan", fb.PathRegexp); err != nil {
+			return nil, err
+		}
+		var methods []string
+		if fb.Methods != nil {
+			methods = *fb.Methods
 		}
 		config.Fallbacks = append(config.Fallbacks, &trojan.Fallback{
-			Name: fb.Name,
-			Alpn: fb.Alpn,
-			Path: fb.Path,
-			Type: fb.Type,
-			Dest: s,
-			Xver: fb.Xver,
+			Name:        fb.Name,
+			Alpn:        fb.Alpn,
+			Path:        fb.Path,
+			Type:        fb.Type,
+			Dest:        parseFallbackDest(fb.Dest),
+			Xver:        fb.Xver,
+			Host:        fb.Host,
+			PathRegexp:  fb.PathRegexp,
+			Methods:     methods,
+			Protocol:    fb.Protocol,
+			Dests:       dests,
+			HealthCheck: fb.HealthCheck.Build(),
 		})
 	}
 	for _, fb := range config.Fallbacks {
@@ -174,26 +185,8 @@ func (c *TrojanServerConfig) Build() (proto.Message, error) {
 		if fb.Path != "" && fb.Path[0] != '/' {
 			return nil, errors.New(`Trojan fallbacks: "path" must be empty or start with "/"`)
 		}
-		if fb.Type == "" && fb.Dest != "" {
-			if fb.Dest == "serve-ws-none" {
-				fb.Type = "serve"
-			} else if filepath.IsAbs(fb.Dest) || fb.Dest[0] == '@' {
-				fb.Type = "unix"
-				if strings.HasPrefix(fb.Dest, "@@") && (runtime.GOOS == "linux" || runtime.GOOS == "android") {
-					fullAddr := make([]byte, len(syscall.RawSockaddrUnix{}.Path)) // may need padding to work with haproxy
-					copy(fullAddr, fb.Dest[1:])
-					fb.Dest = string(fullAddr)
-				}
-			} else {
-				if _, err := strconv.Atoi(fb.Dest); err == nil {
-					fb.Dest = "localhost:" + fb.Dest
-				}
-				if _, _, err := net.SplitHostPort(fb.Dest); err == nil {
-					fb.Type = "tcp"
-				}
-			}
-		}
-		if fb.Type == "" {
+		fb.Type, fb.Dest = inferFallbackType(fb.Type, fb.Dest)
+		if fb.Type == "" && (fb.Dest != "" || len(fb.Dests) == 0) {
 			return nil, errors.New(`Trojan fallbacks: please fill in a valid value for every "dest"`)
 		}
 		if fb.Xver > 2 {
@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/common/uuid"
+	"github.com/xtls/xray-core/proxy/tuic"
+	"github.com/xtls/xray-core/proxy/tuic/account"
+	"google.golang.org/protobuf/proto"
+)
+
+type TuicClientConfig struct {
+	Address *Address `json:"address"`
+	Port    uint16   `json:"port"`
+}
+
+func (c *TuicClientConfig) Build() (proto.Message, error) {
+	if c.Address == nil {
+		return nil, errors.New("TUIC server address is not set.")
+	}
+
+	config := &tuic.ClientConfig{}
+	config.Server = &protocol.ServerEndpoint{
+		Address: c.Address.Build(),
+		Port:    uint32(c.Port),
+	}
+
+	return config, nil
+}
+
+type TuicUserConfig struct {
+	UUID     string `json:"uuid"`
+	Password string `json:"password"`
+	Level    uint32 `json:"level"`
+	Email    string `json:"email"`
+}
+
+type TuicServerConfig struct {
+	Users   []*TuicUserConfig `json:"users"`
+	Clients []*TuicUserConfig `json:"clients"`
+}
+
+func (c *TuicServerConfig) Build() (proto.Message, error) {
+	config := new(tuic.ServerConfig)
+
+	if c.Clients != nil {
+		c.Users = c.Clients
+	}
+	config.Users = make([]*protocol.User, len(c.Users))
+	for idx, user := range c.Users {
+		if _, err := uuid.ParseString(user.UUID); err != nil {
+			return nil, errors.New("invalid TUIC user uuid: ", user.UUID).Base(err)
+		}
+		config.Users[idx] = &protocol.User{
+			Email: user.Email,
+			Level: user.Level,
+			Account: serial.ToTypedMessage(&account.Account{
+				Id:       user.UUID,
+				Password: user.Password,
+			}),
+		}
+	}
+
+	return config, nil
+}
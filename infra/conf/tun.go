@@ -6,7 +6,9 @@ import (
 	"math/big"
 	"net"
 	"strconv"
+	"strings"
 
+	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/proxy/tun"
 	"google.golang.org/protobuf/proto"
 )
@@ -20,6 +22,8 @@ type TunConfig struct {
 	UserLevel              uint32   `json:"userLevel"`
 	AutoSystemRoutingTable []string `json:"autoSystemRoutingTable"`
 	AutoOutboundsInterface *string  `json:"autoOutboundsInterface"`
+	DNSHijack              string   `json:"dnsHijack"`
+	FakeDNS                bool     `json:"fakeDns"`
 }
 
 func (v *TunConfig) Build() (proto.Message, error) {
@@ -31,6 +35,24 @@ func (v *TunConfig) Build() (proto.Message, error) {
 		DNS:                    v.DNS,
 		UserLevel:              v.UserLevel,
 		AutoSystemRoutingTable: v.AutoSystemRoutingTable,
+		FakeDns:                v.FakeDNS,
+	}
+	switch strings.ToLower(v.DNSHijack) {
+	case "", "none":
+	case "dns":
+		if len(v.DNS) == 0 {
+			return nil, errors.New(`dnsHijack "dns" requires dns addresses`)
+		}
+		config.DnsHijack = tun.DNSHijack_Configured
+	case "all":
+		config.DnsHijack = tun.DNSHijack_All
+	default:
+		return nil, errors.New("unknown dnsHijack: ", v.DNSHijack)
+	}
+	for _, dns := range v.DNS {
+		if net.ParseIP(dns) == nil {
+			return nil, errors.New("invalid dns address: ", dns)
+		}
 	}
 	if v.AutoOutboundsInterface != nil {
 		config.AutoOutboundsInterface = *v.AutoOutboundsInterface
@@ -0,0 +1,57 @@
+package conf_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/proxy/tun"
+)
+
+func TestTunConfig(t *testing.T) {
+	creator := func() Buildable {
+		return new(TunConfig)
+	}
+
+	runMultiTestCase(t, []TestCase{
+		{
+			Input: `{
+				"name": "xray0",
+				"dns": ["10.0.0.53", "fd00::53"],
+				"dnsHijack": "dns",
+				"fakeDns": true
+			}`,
+			Parser: loadJSON(creator),
+			Output: &tun.Config{
+				Name:      "xray0",
+				Desc:      "Wintun",
+				MTU:       1500,
+				DNS:       []string{"10.0.0.53", "fd00::53"},
+				DnsHijack: tun.DNSHijack_Configured,
+				FakeDns:   true,
+			},
+		},
+		{
+			Input: `{
+				"name": "xray0",
+				"dnsHijack": "all"
+			}`,
+			Parser: loadJSON(creator),
+			Output: &tun.Config{
+				Name:      "xray0",
+				Desc:      "Wintun",
+				MTU:       1500,
+				DnsHijack: tun.DNSHijack_All,
+			},
+		},
+	})
+
+	for _, input := range []string{
+		`{"name": "xray0", "dnsHijack": "dns"}`,
+		`{"name": "xray0", "dnsHijack": "udp"}`,
+		`{"name": "xray0", "dns": ["dns.google"]}`,
+	} {
+		if _, err := loadJSON(creator)(input); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
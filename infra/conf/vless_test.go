@@ -7,6 +7,7 @@ import (
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/serial"
 	. "github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/proxy/fallback"
 	"github.com/xtls/xray-core/proxy/vless"
 	"github.com/xtls/xray-core/proxy/vless/inbound"
 	"github.com/xtls/xray-core/proxy/vless/outbound"
@@ -155,5 +156,77 @@ func TestVLessInbound(t *testing.T) {
 				},
 			},
 		},
+		{
+			Input: `{
+				"clients": [
+					{
+						"id": "27848739-7e62-4138-9fd3-098a63964b6b"
+					}
+				],
+				"decryption": "none",
+				"fallbacks": [
+					{
+						"host": "*.example.com",
+						"pathRegexp": "^/api/",
+						"methods": ["GET", "POST"],
+						"dests": [
+							{
+								"dest": 8080,
+								"weight": 2
+							},
+							{
+								"dest": "10.0.0.2:8080"
+							}
+						],
+						"healthCheck": {
+							"interval": 10,
+							"timeout": 3
+						}
+					},
+					{
+						"protocol": "ssh",
+						"dest": 22
+					}
+				]
+			}`,
+			Parser: loadJSON(creator),
+			Output: &inbound.Config{
+				Users: []*protocol.User{
+					{
+						Account: serial.ToTypedMessage(&vless.Account{
+							Id: "27848739-7e62-4138-9fd3-098a63964b6b",
+						}),
+					},
+				},
+				Decryption: "none",
+				Fallbacks: []*inbound.Fallback{
+					{
+						Host:       "*.example.com",
+						PathRegexp: "^/api/",
+						Methods:    []string{"GET", "POST"},
+						Dests: []*fallback.Destination{
+							{
+								Type:   "tcp",
+								Dest:   "localhost:8080",
+								Weight: 2,
+							},
+							{
+								Type: "tcp",
+								Dest: "10.0.0.2:8080",
+							},
+						},
+						HealthCheck: &fallback.HealthCheck{
+							Interval: 10,
+							Timeout:  3,
+						},
+					},
+					{
+						Protocol: "ssh",
+						Type:     "tcp",
+						Dest:     "localhost:22",
+					},
+				},
+			},
+		},
 	})
 }
@@ -31,6 +31,9 @@ var (
 		"trojan":        func() interface{} { return new(TrojanServerConfig) },
 		"wireguard":     func() interface{} { return &WireGuardConfig{IsClient: false} },
 		"hysteria":      func() interface{} { return new(HysteriaServerConfig) },
+		"tuic":          func() interface{} { return new(TuicServerConfig) },
+		"anytls":        func() interface{} { return new(AnyTLSServerConfig) },
+		"naive":         func() interface{} { return new(NaiveServerConfig) },
 		"tun":           func() interface{} { return new(TunConfig) },
 	}, "protocol", "settings")
 
@@ -38,6 +41,7 @@ var (
 		"block":       func() interface{} { return new(BlackholeConfig) },
 		"blackhole":   func() interface{} { return new(BlackholeConfig) },
 		"loopback":    func() interface{} { return new(LoopbackConfig) },
+		"mirror":      func() interface{} { return new(MirrorConfig) },
 		"direct":      func() interface{} { return new(FreedomConfig) },
 		"freedom":     func() interface{} { return new(FreedomConfig) },
 		"http":        func() interface{} { return new(HTTPClientConfig) },
@@ -47,6 +51,10 @@ var (
 		"vmess":       func() interface{} { return new(VMessOutboundConfig) },
 		"trojan":      func() interface{} { return new(TrojanClientConfig) },
 		"hysteria":    func() interface{} { return new(HysteriaClientConfig) },
+		"tuic":        func() interface{} { return new(TuicClientConfig) },
+		"anytls":      func() interface{} { return new(AnyTLSClientConfig) },
+		"naive":       func() interface{} { return new(NaiveClientConfig) },
+		"ssh":         func() interface{} { return new(SSHClientConfig) },
 		"dns":         func() interface{} { return new(DNSOutboundConfig) },
 		"wireguard":   func() interface{} { return &WireGuardConfig{IsClient: true} },
 	}, "protocol", "settings")
@@ -104,6 +112,25 @@ type MuxConfig struct {
 	Concurrency     int16  `json:"concurrency"`
 	XudpConcurrency int16  `json:"xudpConcurrency"`
 	XudpProxyUDP443 string `json:"xudpProxyUDP443"`
+	Resumable       bool   `json:"resumable"`
+	ResumeTimeout   uint32 `json:"resumeTimeout"`
+}
+
+// InboundMuxConfig is the Mux config of an inbound.
+type InboundMuxConfig struct {
+	Resumable     bool   `json:"resumable"`
+	ResumeTimeout uint32 `json:"resumeTimeout"`
+}
+
+// Build implements Buildable.
+func (m *InboundMuxConfig) Build() (*proxyman.MuxServerConfig, error) {
+	if m.ResumeTimeout > 600 {
+		return nil, errors.New(`"resumeTimeout" must not exceed 600 seconds`)
+	}
+	return &proxyman.MuxServerConfig{
+		Resumable:     m.Resumable,
+		ResumeTimeout: m.ResumeTimeout,
+	}, nil
 }
 
 // Build creates MultiplexingConfig, Concurrency < 0 completely disables mux.
@@ -115,22 +142,28 @@ func (m *MuxConfig) Build() (*proxyman.MultiplexingConfig, error) {
 	default:
 		return nil, errors.New(`unknown "xudpProxyUDP443": `, m.XudpProxyUDP443)
 	}
+	if m.ResumeTimeout > 600 {
+		return nil, errors.New(`"resumeTimeout" must not exceed 600 seconds`)
+	}
 	return &proxyman.MultiplexingConfig{
 		Enabled:         m.Enabled,
 		Concurrency:     int32(m.Concurrency),
 		XudpConcurrency: int32(m.XudpConcurrency),
 		XudpProxyUDP443: m.XudpProxyUDP443,
+		Resumable:       m.Resumable,
+		ResumeTimeout:   m.ResumeTimeout,
 	}, nil
 }
 
 type InboundDetourConfig struct {
-	Protocol       string           `json:"protocol"`
-	PortList       *PortList        `json:"port"`
-	ListenOn       *Address         `json:"listen"`
-	Settings       *json.RawMessage `json:"settings"`
-	Tag            string           `json:"tag"`
-	StreamSetting  *StreamConfig    `json:"streamSettings"`
-	SniffingConfig *SniffingConfig  `json:"sniffing"`
+	Protocol       string            `json:"protocol"`
+	PortList       *PortList         `json:"port"`
+	ListenOn       *Address          `json:"listen"`
+	Settings       *json.RawMessage  `json:"settings"`
+	Tag            string            `json:"tag"`
+	StreamSetting  *StreamConfig     `json:"streamSettings"`
+	SniffingConfig *SniffingConfig   `json:"sniffing"`
+	MuxSettings    *InboundMuxConfig `json:"mux"`
 }
 
 // Build implements Buildable.
@@ -186,6 +219,13 @@ func (c *InboundDetourConfig) Build() (*core.InboundHandlerConfig, error) {
 		}
 		receiverSettings.SniffingSettings = s
 	}
+	if c.MuxSettings != nil {
+		ms, err := c.MuxSettings.Build()
+		if err != nil {
+			return nil, errors.New("failed to build mux config").Base(err)
+		}
+		receiverSettings.MuxSettings = ms
+	}
 
 	settings := []byte("{}")
 	if c.Settings != nil {
@@ -211,14 +251,58 @@ func (c *InboundDetourConfig) Build() (*core.InboundHandlerConfig, error) {
 }
 
 type OutboundDetourConfig struct {
-	Protocol       string           `json:"protocol"`
-	SendThrough    *string          `json:"sendThrough"`
-	Tag            string           `json:"tag"`
-	Settings       *json.RawMessage `json:"settings"`
-	StreamSetting  *StreamConfig    `json:"streamSettings"`
-	ProxySettings  *ProxyConfig     `json:"proxySettings"`
-	MuxSettings    *MuxConfig       `json:"mux"`
-	TargetStrategy string           `json:"targetStrategy"`
+	Protocol        string            `json:"protocol"`
+	SendThrough     *string           `json:"sendThrough"`
+	SendThroughPool *SourcePoolConfig `json:"sendThroughPool"`
+	Tag             string            `json:"tag"`
+	Settings        *json.RawMessage  `json:"settings"`
+	StreamSetting   *StreamConfig     `json:"streamSettings"`
+	ProxySettings   *ProxyConfig      `json:"proxySettings"`
+	MuxSettings     *MuxConfig        `json:"mux"`
+	TargetStrategy  string            `json:"targetStrategy"`
+}
+
+// SourcePoolConfig is the addresses that outbound connections are sent
+// through in rotation.
+type SourcePoolConfig struct {
+	Addresses     []string `json:"addresses"`
+	Strategy      string   `json:"strategy"`
+	Interval      uint32   `json:"interval"`
+	ExcludeRecent uint32   `json:"excludeRecent"`
+}
+
+// Build implements Buildable.
+func (c *SourcePoolConfig) Build() (*proxyman.SourcePool, error) {
+	pool := &proxyman.SourcePool{
+		Interval:      c.Interval,
+		ExcludeRecent: c.ExcludeRecent,
+	}
+	switch strings.ToLower(c.Strategy) {
+	case "", "random":
+		pool.Strategy = proxyman.SourcePool_Random
+	case "roundrobin":
+		pool.Strategy = proxyman.SourcePool_RoundRobin
+	case "stickyuser":
+		pool.Strategy = proxyman.SourcePool_StickyUser
+	case "stickytarget":
+		pool.Strategy = proxyman.SourcePool_StickyTarget
+	case "timed":
+		pool.Strategy = proxyman.SourcePool_Timed
+	default:
+		return nil, errors.New("unsupported strategy of sendThroughPool: ", c.Strategy)
+	}
+	for _, s := range c.Addresses {
+		if net.ParseIP(s) == nil {
+			if _, _, err := net.ParseCIDR(s); err != nil {
+				return nil, errors.New("invalid address in sendThroughPool: ", s)
+			}
+		}
+		pool.Addresses = append(pool.Addresses, s)
+	}
+	if len(pool.Addresses) == 0 {
+		return nil, errors.New("sendThroughPool is empty")
+	}
+	return pool, nil
 }
 
 func (c *OutboundDetourConfig) checkChainProxyConfig() error {
@@ -314,6 +398,17 @@ func (c *OutboundDetourConfig) Build() (*core.OutboundHandlerConfig, error) {
 		senderSettings.Via = address.Build()
 	}
 
+	if c.SendThroughPool != nil {
+		if c.SendThrough != nil {
+			return nil, errors.New("sendThrough and sendThroughPool can't be used together")
+		}
+		pool, err := c.SendThroughPool.Build()
+		if err != nil {
+			return nil, err
+		}
+		senderSettings.ViaPool = pool
+	}
+
 	if c.StreamSetting != nil {
 		ss, err := c.StreamSetting.Build()
 		if err != nil {
@@ -409,6 +504,9 @@ type Config struct {
 	BurstObservatory *BurstObservatoryConfig `json:"burstObservatory"`
 	Version          *VersionConfig          `json:"version"`
 	Geodata          *GeodataConfig          `json:"geodata"`
+	Subscription     *SubscriptionConfig     `json:"subscription"`
+	Capture          *CaptureConfig          `json:"capture"`
+	Events           *EventsConfig           `json:"events"`
 }
 
 func (c *Config) findInboundTag(tag string) int {
@@ -491,6 +589,18 @@ func (c *Config) Override(o *Config, fn string) {
 		c.Geodata = o.Geodata
 	}
 
+	if o.Subscription != nil {
+		c.Subscription = o.Subscription
+	}
+
+	if o.Capture != nil {
+		c.Capture = o.Capture
+	}
+
+	if o.Events != nil {
+		c.Events = o.Events
+	}
+
 	// update the Inbound in slice if the only one in override config has same tag
 	if len(o.InboundConfigs) > 0 {
 		for i := range o.InboundConfigs {
@@ -653,6 +763,30 @@ func (c *Config) Build() (*core.Config, error) {
 		config.App = append(config.App, serial.ToTypedMessage(r))
 	}
 
+	if c.Subscription != nil {
+		r, err := c.Subscription.Build()
+		if err != nil {
+			return nil, errors.New("failed to build subscription configuration").Base(err)
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
+	if c.Capture != nil {
+		r, err := c.Capture.Build()
+		if err != nil {
+			return nil, errors.New("failed to build capture configuration").Base(err)
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
+	if c.Events != nil {
+		r, err := c.Events.Build()
+		if err != nil {
+			return nil, errors.New("failed to build events configuration").Base(err)
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
 	var inbounds []InboundDetourConfig
 
 	if len(c.InboundConfigs) > 0 {
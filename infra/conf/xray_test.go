@@ -18,6 +18,7 @@ import (
 	"github.com/xtls/xray-core/common/serial"
 	core "github.com/xtls/xray-core/core"
 	. "github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/proxy/freedom"
 	"github.com/xtls/xray-core/proxy/vmess"
 	"github.com/xtls/xray-core/proxy/vmess/inbound"
 	"github.com/xtls/xray-core/transport/internet"
@@ -255,6 +256,13 @@ func TestMuxConfig_Build(t *testing.T) {
 			XudpConcurrency: 0,
 			XudpProxyUDP443: "reject",
 		}},
+		{"resumable", `{"enabled": true, "resumable": true, "resumeTimeout": 60}`, &proxyman.MultiplexingConfig{
+			Enabled:         true,
+			XudpProxyUDP443: "reject",
+			Resumable:       true,
+			ResumeTimeout:   60,
+		}},
+		{"resume timeout too long", `{"enabled": true, "resumable": true, "resumeTimeout": 3600}`, nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -267,6 +275,30 @@ func TestMuxConfig_Build(t *testing.T) {
 	}
 }
 
+func TestInboundMuxConfig_Build(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		want   *proxyman.MuxServerConfig
+	}{
+		{"default", `{}`, &proxyman.MuxServerConfig{}},
+		{"resumable", `{"resumable": true, "resumeTimeout": 5}`, &proxyman.MuxServerConfig{
+			Resumable:     true,
+			ResumeTimeout: 5,
+		}},
+		{"resume timeout too long", `{"resumable": true, "resumeTimeout": 3600}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &InboundMuxConfig{}
+			common.Must(json.Unmarshal([]byte(tt.fields), m))
+			if got, _ := m.Build(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InboundMuxConfig.Build() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestConfig_Override(t *testing.T) {
 	tests := []struct {
 		name string
@@ -356,3 +388,45 @@ func TestConfig_Override(t *testing.T) {
 		})
 	}
 }
+
+func TestSourcePoolConfig(t *testing.T) {
+	parser := func(s string) (proto.Message, error) {
+		config := new(OutboundDetourConfig)
+		if err := json.Unmarshal([]byte(s), config); err != nil {
+			return nil, err
+		}
+		return config.Build()
+	}
+	runMultiTestCase(t, []TestCase{
+		{
+			Input: `{
+				"protocol": "freedom",
+				"sendThroughPool": {
+					"addresses": ["192.0.2.1", "2001:db8::/64"],
+					"strategy": "stickyUser"
+				}
+			}`,
+			Parser: parser,
+			Output: &core.OutboundHandlerConfig{
+				SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{
+					ViaPool: &proxyman.SourcePool{
+						Addresses: []string{"192.0.2.1", "2001:db8::/64"},
+						Strategy:  proxyman.SourcePool_StickyUser,
+					},
+				}),
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	})
+
+	for _, input := range []string{
+		`{"protocol": "freedom", "sendThroughPool": {}}`,
+		`{"protocol": "freedom", "sendThroughPool": {"addresses": ["example.com"]}}`,
+		`{"protocol": "freedom", "sendThroughPool": {"addresses": ["192.0.2.1"], "strategy": "unknown"}}`,
+		`{"protocol": "freedom", "sendThrough": "192.0.2.1", "sendThroughPool": {"addresses": ["192.0.2.2"]}}`,
+	} {
+		if _, err := parser(input); err == nil {
+			t.Error("expected error for ", input)
+		}
+	}
+}
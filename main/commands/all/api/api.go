@@ -9,6 +9,20 @@ var CmdAPI = &base.Command{
 	UsageLine: "{{.Exec}} api",
 	Short:     "Call an API in an Xray process",
 	Long: `{{.Exec}} {{.LongName}} provides tools to manipulate Xray via its API.
+
+Besides their own arguments, all the commands accept:
+
+	-token <token>
+		Bearer token of the API. Default the XRAY_API_TOKEN environment variable
+
+	-tls
+		Connect to the API server in TLS, with the system CAs
+
+	-ca <file>
+		Connect in TLS, and verify the server by the CAs in the PEM file
+
+	-cert <file>, -key <file>
+		Connect in TLS, with the client certificate in the PEM files
 `,
 	Commands: []*base.Command{
 		cmdRestartLogger,
@@ -34,5 +48,6 @@ var CmdAPI = &base.Command{
 		cmdOnlineStats,
 		cmdOnlineStatsIpList,
 		cmdGetAllOnlineUsers,
+		cmdCapture,
 	},
 }
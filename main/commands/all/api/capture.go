@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	captureService "github.com/xtls/xray-core/app/capture/command"
+	"github.com/xtls/xray-core/main/commands/base"
+)
+
+var cmdCapture = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} api capture [--server=127.0.0.1:8080] [-rule tag] [-user email] [-duration 60] [-o capture.pcapng]",
+	Short:       "Capture proxied flows to pcapng",
+	Long: `
+Capture the plaintext flows between the dispatcher and the outbounds of Xray
+to pcapng, with synthesized TCP and UDP headers. The capture must be enabled
+with "capture" in the config.
+
+Arguments:
+
+	-s, -server <server:port>
+		The API server address. Default 127.0.0.1:8080
+
+	-t, -timeout <seconds>
+		Timeout in seconds for connecting to the API. Default 3
+
+	-rule <tags>
+		Capture the flows routed by these comma-separated rule tags.
+
+	-user <emails>
+		Capture the flows of these comma-separated users.
+
+	-duration <seconds>
+		Duration of the capture. Default 60
+
+	-max <bytes>
+		Size limit of the capture. Default 0, the largest allowed
+
+	-o <file>
+		The pcapng file to write. Default stdout
+
+Example:
+
+	{{.Exec}} {{.LongName}} --server=127.0.0.1:8080 -rule blocked -duration 30 -o blocked.pcapng
+`,
+	Run: executeCapture,
+}
+
+func executeCapture(cmd *base.Command, args []string) {
+	setSharedFlags(cmd)
+	rules := cmd.Flag.String("rule", "", "")
+	users := cmd.Flag.String("user", "", "")
+	duration := cmd.Flag.Uint("duration", 60, "")
+	maxBytes := cmd.Flag.Uint64("max", 0, "")
+	output := cmd.Flag.String("o", "", "")
+	cmd.Flag.Parse(args)
+
+	conn, _, close := dialAPIServer()
+	defer close()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			base.Fatalf("failed to create %s: %s", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	client := captureService.NewCaptureServiceClient(conn)
+	r := &captureService.CaptureRequest{
+		RuleTags: splitList(*rules),
+		Users:    splitList(*users),
+		Duration: uint32(*duration),
+		MaxBytes: *maxBytes,
+	}
+	// The capture outlasts the timeout of calling the API.
+	stream, err := client.Capture(context.Background(), r)
+	if err != nil {
+		base.Fatalf("failed to capture: %s", err)
+	}
+	var dropped uint64
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			base.Fatalf("failed to capture: %s", err)
+		}
+		if _, err := w.Write(resp.Data); err != nil {
+			base.Fatalf("failed to write capture: %s", err)
+		}
+		dropped = resp.Dropped
+	}
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "%d packets dropped\n", dropped)
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -12,11 +12,14 @@ import (
 	"github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/infra/conf"
 	"github.com/xtls/xray-core/infra/conf/serial"
+	"github.com/xtls/xray-core/proxy/anytls"
+	"github.com/xtls/xray-core/proxy/naive"
 	"github.com/xtls/xray-core/proxy/shadowsocks"
 	"github.com/xtls/xray-core/proxy/shadowsocks_2022"
 	"github.com/xtls/xray-core/proxy/trojan"
 	vlessin "github.com/xtls/xray-core/proxy/vless/inbound"
 	vmessin "github.com/xtls/xray-core/proxy/vmess/inbound"
+	"github.com/xtls/xray-core/proxy/wireguard"
 
 	"github.com/xtls/xray-core/main/commands/base"
 )
@@ -88,6 +91,12 @@ func extractInboundUsers(inb *core.InboundHandlerConfig) []*protocol.User {
 		return ty.Users
 	case *shadowsocks_2022.MultiUserServerConfig:
 		return ty.Users
+	case *anytls.ServerConfig:
+		return ty.Users
+	case *naive.ServerConfig:
+		return ty.Users
+	case *wireguard.DeviceConfig:
+		return ty.Users
 	default:
 		fmt.Println("unsupported inbound type")
 	}
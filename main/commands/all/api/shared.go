@@ -3,6 +3,8 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/xtls/xray-core/common/buf"
@@ -28,6 +31,11 @@ var (
 	apiServerAddrPtr string
 	apiTimeout       int
 	apiJSON          bool
+	apiToken         string
+	apiTLS           bool
+	apiCA            string
+	apiCert          string
+	apiKey           string
 )
 
 func setSharedFlags(cmd *base.Command) {
@@ -36,11 +44,58 @@ func setSharedFlags(cmd *base.Command) {
 	cmd.Flag.IntVar(&apiTimeout, "t", 3, "")
 	cmd.Flag.IntVar(&apiTimeout, "timeout", 3, "")
 	cmd.Flag.BoolVar(&apiJSON, "json", false, "")
+	cmd.Flag.StringVar(&apiToken, "token", os.Getenv("XRAY_API_TOKEN"), "")
+	cmd.Flag.BoolVar(&apiTLS, "tls", false, "")
+	cmd.Flag.StringVar(&apiCA, "ca", "", "")
+	cmd.Flag.StringVar(&apiCert, "cert", "", "")
+	cmd.Flag.StringVar(&apiKey, "key", "", "")
+}
+
+// transportCredentials returns the credentials of the connection to the API
+// server, which is in TLS if any of the TLS flags is set.
+func transportCredentials() credentials.TransportCredentials {
+	if !apiTLS && apiCA == "" && apiCert == "" {
+		return insecure.NewCredentials()
+	}
+	config := &tls.Config{}
+	if apiCA != "" {
+		pem, err := os.ReadFile(apiCA)
+		if err != nil {
+			base.Fatalf("failed to read CA: %s", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			base.Fatalf("no certificate in %s", apiCA)
+		}
+	}
+	if apiCert != "" {
+		cert, err := tls.LoadX509KeyPair(apiCert, apiKey)
+		if err != nil {
+			base.Fatalf("failed to load client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config)
+}
+
+// bearerToken is the API token sent with every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
 }
 
 func dialAPIServer() (conn *grpc.ClientConn, ctx context.Context, close func()) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(apiTimeout)*time.Second)
-	conn, err := grpc.DialContext(ctx, apiServerAddrPtr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials()), grpc.WithBlock()}
+	if apiToken != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(apiToken)))
+	}
+	conn, err := grpc.DialContext(ctx, apiServerAddrPtr, opts...)
 	if err != nil {
 		base.Fatalf("failed to dial %s", apiServerAddrPtr)
 	}
@@ -0,0 +1,317 @@
+package convert
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/xtls/xray-core/main/commands/base"
+)
+
+var cmdClash = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} convert clash [-o file] [clash config]",
+	Short:       "Convert a Clash config to Xray config",
+	Long: `
+Convert the proxies, proxy-groups, rules and rule-providers of a Clash or
+Clash.Meta config to Xray outbounds, balancers, observatory and routing rules.
+
+Proxy groups are mapped as follows:
+
+	select        its first member
+	url-test      leastPing balancer with observatory
+	fallback      leastPing balancer with observatory
+	load-balance  random or roundRobin balancer
+
+Everything that cannot be converted is reported on stderr. Rule-providers
+are referenced as "clash:<path>", so the files must be copied to the Xray
+asset directory.
+
+Arguments:
+
+	-o file
+		Write the Xray config to the file instead of stdout.
+
+Examples:
+
+    {{.Exec}} convert clash -o config.json clash.yaml
+	`,
+	Run: executeConvertClash,
+}
+
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(b []byte) error {
+	n, err := strconv.Atoi(strings.Trim(string(b), `"`))
+	*i = flexInt(n)
+	return err
+}
+
+type clashConfig struct {
+	Proxies       []*clashProxy                 `json:"proxies"`
+	ProxyGroups   []*clashProxyGroup            `json:"proxy-groups"`
+	Rules         []string                      `json:"rules"`
+	RuleProviders map[string]*clashRuleProvider `json:"rule-providers"`
+}
+
+type clashProxy struct {
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Server            string   `json:"server"`
+	Port              flexInt  `json:"port"`
+	Ports             string   `json:"ports"`
+	UUID              string   `json:"uuid"`
+	Password          string   `json:"password"`
+	Username          string   `json:"username"`
+	Cipher            string   `json:"cipher"`
+	Flow              string   `json:"flow"`
+	Plugin            string   `json:"plugin"`
+	Network           string   `json:"network"`
+	TLS               bool     `json:"tls"`
+	SNI               string   `json:"sni"`
+	ServerName        string   `json:"servername"`
+	ALPN              []string `json:"alpn"`
+	ClientFingerprint string   `json:"client-fingerprint"`
+	SkipCertVerify    bool     `json:"skip-cert-verify"`
+	Obfs              string   `json:"obfs"`
+	ObfsPassword      string   `json:"obfs-password"`
+	RealityOpts       *struct {
+		PublicKey string `json:"public-key"`
+		ShortID   string `json:"short-id"`
+	} `json:"reality-opts"`
+	WSOpts *struct {
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers"`
+	} `json:"ws-opts"`
+	HTTPOpts *struct {
+		Path    []string            `json:"path"`
+		Headers map[string][]string `json:"headers"`
+	} `json:"http-opts"`
+	GRPCOpts *struct {
+		ServiceName string `json:"grpc-service-name"`
+	} `json:"grpc-opts"`
+}
+
+type clashProxyGroup struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Proxies  []string `json:"proxies"`
+	Use      []string `json:"use"`
+	URL      string   `json:"url"`
+	Interval flexInt  `json:"interval"`
+	Strategy string   `json:"strategy"`
+}
+
+type clashRuleProvider struct {
+	Behavior string `json:"behavior"`
+	Format   string `json:"format"`
+	Path     string `json:"path"`
+}
+
+func executeConvertClash(cmd *base.Command, args []string) {
+	var output string
+	cmd.Flag.StringVar(&output, "o", "", "")
+	cmd.Flag.Parse(args)
+
+	content := readInput(cmd.Flag.Args())
+	var c clashConfig
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		base.Fatalf("failed to parse Clash config: %s", err)
+	}
+	m := convertClash(&c)
+	writeMigration(m, m.config(clashFinal(c.Rules)), output)
+}
+
+func convertClash(c *clashConfig) *migration {
+	m := newMigration()
+	for _, p := range c.Proxies {
+		proxy, err := p.proxyServer()
+		if err != nil {
+			m.warnf("proxy %q: %s, skipped", p.Name, err)
+			continue
+		}
+		if p.SkipCertVerify {
+			m.warnf("proxy %q: skip-cert-verify is dropped, pin the certificate with pinnedPeerCertSha256", p.Name)
+		}
+		m.addProxy(proxy)
+	}
+	for _, g := range c.ProxyGroups {
+		if len(g.Use) > 0 {
+			m.warnf("group %q: proxy-providers %v are not supported", g.Name, g.Use)
+		}
+		kind := g.Type
+		if kind == "load-balance" && g.Strategy == "round-robin" {
+			kind = "round-robin"
+		}
+		interval := ""
+		if g.Interval > 0 {
+			interval = strconv.Itoa(int(g.Interval)) + "s"
+		}
+		m.addGroup(&proxyGroup{name: g.Name, kind: kind, members: g.Proxies, url: g.URL, interval: interval})
+	}
+	for _, rule := range c.Rules {
+		convertClashRule(m, rule, c.RuleProviders)
+	}
+	return m
+}
+
+func (p *clashProxy) proxyServer() (*proxyServer, error) {
+	s := &proxyServer{
+		name:        p.Name,
+		server:      p.Server,
+		port:        int(p.Port),
+		id:          p.UUID,
+		password:    p.Password,
+		username:    p.Username,
+		method:      p.Cipher,
+		flow:        p.Flow,
+		network:     p.Network,
+		tls:         p.TLS,
+		sni:         p.ServerName,
+		fingerprint: p.ClientFingerprint,
+		alpn:        p.ALPN,
+	}
+	if p.SNI != "" {
+		s.sni = p.SNI
+	}
+
+	switch p.Type {
+	case "vless", "vmess":
+		s.protocol = p.Type
+	case "trojan":
+		s.protocol = "trojan"
+		s.tls = true
+	case "ss":
+		if p.Plugin != "" {
+			return nil, fmt.Errorf("plugin %q is not supported", p.Plugin)
+		}
+		s.protocol = "shadowsocks"
+	case "hysteria2":
+		s.protocol = "hysteria2"
+		s.obfs, s.obfsPassword = p.Obfs, p.ObfsPassword
+		s.ports = p.Ports
+	case "socks5":
+		s.protocol = "socks"
+	case "http":
+		s.protocol = "http"
+	default:
+		return nil, fmt.Errorf("unsupported type %q", p.Type)
+	}
+	if p.RealityOpts != nil {
+		s.realityPublicKey, s.realityShortID = p.RealityOpts.PublicKey, p.RealityOpts.ShortID
+	}
+
+	switch p.Network {
+	case "", "tcp":
+		if p.HTTPOpts != nil {
+			s.headerType = "http"
+			s.path = strings.Join(p.HTTPOpts.Path, ",")
+			s.host = strings.Join(p.HTTPOpts.Headers["Host"], ",")
+		}
+	case "http":
+		s.network = "tcp"
+		s.headerType = "http"
+		if p.HTTPOpts != nil {
+			s.path = strings.Join(p.HTTPOpts.Path, ",")
+			s.host = strings.Join(p.HTTPOpts.Headers["Host"], ",")
+		}
+	case "ws":
+		if p.WSOpts != nil {
+			s.path = p.WSOpts.Path
+			s.host = p.WSOpts.Headers["Host"]
+		}
+	case "grpc":
+		if p.GRPCOpts != nil {
+			s.serviceName = p.GRPCOpts.ServiceName
+		}
+	default:
+		return nil, fmt.Errorf("unsupported network %q", p.Network)
+	}
+	return s, nil
+}
+
+// clashFinal returns the target of the MATCH rule.
+func clashFinal(rules []string) string {
+	for _, rule := range rules {
+		parts := strings.Split(rule, ",")
+		if len(parts) >= 2 && strings.TrimSpace(parts[0]) == "MATCH" {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
+
+func convertClashRule(m *migration, line string, providers map[string]*clashRuleProvider) {
+	parts := strings.Split(line, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	typ := strings.ToUpper(parts[0])
+	if typ == "MATCH" {
+		return
+	}
+	if len(parts) < 3 {
+		m.warnf("rule %q: invalid, skipped", line)
+		return
+	}
+	value, to := parts[1], parts[2]
+
+	var domains, ips []string
+	rule := map[string]interface{}{}
+	switch typ {
+	case "DOMAIN":
+		domains = []string{"full:" + value}
+	case "DOMAIN-SUFFIX":
+		domains = []string{"domain:" + strings.TrimPrefix(value, ".")}
+	case "DOMAIN-KEYWORD":
+		domains = []string{"keyword:" + value}
+	case "DOMAIN-REGEX":
+		domains = []string{"regexp:" + value}
+	case "GEOSITE":
+		domains = []string{"geosite:" + strings.ToLower(value)}
+	case "IP-CIDR", "IP-CIDR6":
+		ips = []string{value}
+	case "GEOIP":
+		ips = []string{"geoip:" + strings.ToLower(value)}
+	case "SRC-IP-CIDR":
+		rule["source"] = []string{value}
+	case "DST-PORT":
+		rule["port"] = strings.ReplaceAll(value, "/", ",")
+	case "SRC-PORT":
+		rule["sourcePort"] = strings.ReplaceAll(value, "/", ",")
+	case "NETWORK":
+		rule["network"] = strings.ToLower(value)
+	case "PROCESS-NAME":
+		rule["process"] = []string{value}
+	case "RULE-SET":
+		provider := providers[value]
+		if provider == nil || provider.Path == "" {
+			m.warnf("rule %q: rule-provider %q has no path, skipped", line, value)
+			return
+		}
+		if provider.Format == "mrs" {
+			m.warnf("rule %q: mrs rule-provider %q is not supported, skipped", line, value)
+			return
+		}
+		file := "clash:" + filepath.ToSlash(filepath.Clean(provider.Path))
+		switch provider.Behavior {
+		case "domain":
+			domains = []string{file}
+		case "ipcidr":
+			ips = []string{file}
+		default:
+			domains = []string{file}
+			ips = []string{file}
+			m.warnf("rule %q: only domain and IP rules of classical rule-provider %q are used", line, value)
+		}
+	default:
+		m.warnf("rule %q: unsupported rule type %s, skipped", line, typ)
+		return
+	}
+
+	for _, r := range splitRule(rule, domains, ips) {
+		m.addRule(r, to, line)
+	}
+}
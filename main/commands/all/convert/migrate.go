@@ -0,0 +1,526 @@
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/main/commands/base"
+	"github.com/xtls/xray-core/main/confloader"
+)
+
+const (
+	directTag = "direct"
+	blockTag  = "block"
+)
+
+// proxyServer is a proxy of a Clash or sing-box config, reduced to what an
+// Xray outbound can express.
+type proxyServer struct {
+	name     string
+	protocol string // vless, vmess, trojan, shadowsocks, hysteria2, socks or http
+	server   string
+	port     int
+	ports    string // hysteria2 port hopping, "443,20000-30000"
+
+	id       string
+	password string
+	method   string
+	flow     string
+	username string
+
+	network     string // tcp, ws, httpupgrade, grpc or xhttp
+	headerType  string
+	host        string
+	path        string
+	serviceName string
+	mode        string
+
+	tls              bool
+	sni              string
+	fingerprint      string
+	alpn             []string
+	realityPublicKey string
+	realityShortID   string
+
+	obfs         string
+	obfsPassword string
+}
+
+// proxyGroup is a Clash proxy-group or a sing-box selector/urltest outbound.
+type proxyGroup struct {
+	name     string
+	kind     string // select, url-test, fallback, load-balance or round-robin
+	members  []string
+	url      string
+	interval string
+}
+
+type target struct {
+	outbound string
+	balancer string
+}
+
+// migration collects the converted parts of a Clash or sing-box config and
+// everything that could not be converted.
+type migration struct {
+	outbounds []json.RawMessage
+	balancers []map[string]interface{}
+	rules     []map[string]interface{}
+
+	targets  map[string]*target
+	groups   map[string]*proxyGroup
+	order    []string
+	observed []string
+	probeURL string
+	interval string
+
+	report []string
+}
+
+func newMigration() *migration {
+	return &migration{
+		targets: map[string]*target{
+			"DIRECT":      {outbound: directTag},
+			"REJECT":      {outbound: blockTag},
+			"REJECT-DROP": {outbound: blockTag},
+		},
+		groups: make(map[string]*proxyGroup),
+	}
+}
+
+func (m *migration) warnf(format string, args ...interface{}) {
+	m.report = append(m.report, fmt.Sprintf(format, args...))
+}
+
+func (m *migration) addProxy(p *proxyServer) {
+	if _, found := m.targets[p.name]; found || m.groups[p.name] != nil || p.name == directTag || p.name == blockTag {
+		m.warnf("proxy %q: duplicate or reserved name, skipped", p.name)
+		return
+	}
+	outbound, err := p.outbound()
+	if err != nil {
+		m.warnf("proxy %q: %s, skipped", p.name, err)
+		return
+	}
+	m.outbounds = append(m.outbounds, outbound)
+	m.targets[p.name] = &target{outbound: p.name}
+	m.order = append(m.order, p.name)
+}
+
+// alias makes name stand for a built-in outbound, such as a sing-box direct
+// or block outbound.
+func (m *migration) alias(name, tag string) {
+	if _, found := m.targets[name]; !found {
+		m.targets[name] = &target{outbound: tag}
+	}
+}
+
+func (m *migration) addGroup(g *proxyGroup) {
+	if _, found := m.targets[g.name]; found || m.groups[g.name] != nil {
+		m.warnf("group %q: duplicate name, skipped", g.name)
+		return
+	}
+	m.groups[g.name] = g
+	m.order = append(m.order, g.name)
+}
+
+// resolve returns the outbound or balancer that stands for a proxy or group.
+// Groups are resolved lazily so that they can reference each other in any
+// order.
+func (m *migration) resolve(name string, visiting map[string]bool) *target {
+	if t, found := m.targets[name]; found {
+		return t
+	}
+	g := m.groups[name]
+	if g == nil || visiting[name] {
+		return nil
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var t *target
+	switch g.kind {
+	case "select":
+		for _, member := range g.members {
+			if t = m.resolve(member, visiting); t != nil {
+				m.warnf("group %q: selector has no Xray equivalent, using its first member %q", g.name, member)
+				break
+			}
+		}
+	case "url-test", "fallback", "load-balance", "round-robin":
+		var tags []string
+		for _, member := range g.members {
+			mt := m.resolve(member, visiting)
+			switch {
+			case mt == nil:
+				m.warnf("group %q: unknown member %q, skipped", g.name, member)
+			case mt.balancer != "":
+				tags = append(tags, m.balancerSelector(mt.balancer)...)
+			default:
+				tags = append(tags, mt.outbound)
+			}
+		}
+		if len(tags) == 0 {
+			break
+		}
+		strategy := "random"
+		switch g.kind {
+		case "url-test":
+			strategy = "leastPing"
+		case "fallback":
+			strategy = "leastPing"
+			m.warnf("group %q: fallback order is not kept, the member with the lowest latency is used", g.name)
+		case "round-robin":
+			strategy = "roundRobin"
+		}
+		if strategy == "leastPing" {
+			m.observe(tags, g.url, g.interval)
+		}
+		m.balancers = append(m.balancers, map[string]interface{}{
+			"tag":      g.name,
+			"selector": dedup(tags),
+			"strategy": map[string]interface{}{"type": strategy},
+		})
+		t = &target{balancer: g.name}
+	default:
+		m.warnf("group %q: unsupported type %q", g.name, g.kind)
+	}
+	if t == nil {
+		m.warnf("group %q: no usable member", g.name)
+		return nil
+	}
+	m.targets[name] = t
+	return t
+}
+
+func (m *migration) balancerSelector(tag string) []string {
+	for _, b := range m.balancers {
+		if b["tag"] == tag {
+			return b["selector"].([]string)
+		}
+	}
+	return nil
+}
+
+func (m *migration) observe(tags []string, probeURL, interval string) {
+	m.observed = dedup(append(m.observed, tags...))
+	if probeURL != "" && m.probeURL == "" {
+		m.probeURL = probeURL
+	} else if probeURL != "" && probeURL != m.probeURL {
+		m.warnf("probe URL %s is replaced by %s, Xray has a single observatory", probeURL, m.probeURL)
+	}
+	if interval != "" && m.interval == "" {
+		m.interval = interval
+	}
+}
+
+// addRule routes the matched traffic to the proxy or group named to.
+func (m *migration) addRule(rule map[string]interface{}, to, source string) {
+	t := m.resolve(to, map[string]bool{})
+	if t == nil {
+		m.warnf("rule %q: unknown target %q, skipped", source, to)
+		return
+	}
+	if t.balancer != "" {
+		rule["balancerTag"] = t.balancer
+	} else {
+		rule["outboundTag"] = t.outbound
+	}
+	m.rules = append(m.rules, rule)
+}
+
+// config assembles the Xray config. The first outbound is the default one,
+// and direct and block outbounds are appended for DIRECT and REJECT.
+func (m *migration) config(final string) map[string]interface{} {
+	for _, name := range m.order {
+		m.resolve(name, map[string]bool{})
+	}
+	if final != "" {
+		m.addRule(map[string]interface{}{"network": "tcp,udp"}, final, "final "+final)
+	}
+
+	outbounds := append([]json.RawMessage{}, m.outbounds...)
+	outbounds = append(outbounds,
+		json.RawMessage(`{"tag": "`+directTag+`", "protocol": "freedom"}`),
+		json.RawMessage(`{"tag": "`+blockTag+`", "protocol": "blackhole"}`),
+	)
+	m.checkPrefixes()
+
+	config := map[string]interface{}{
+		"outbounds": outbounds,
+		"routing": map[string]interface{}{
+			"domainStrategy": "IPIfNonMatch",
+			"rules":          m.rules,
+			"balancers":      m.balancers,
+		},
+	}
+	if len(m.observed) > 0 {
+		observatory := map[string]interface{}{
+			"subjectSelector":   m.observed,
+			"enableConcurrency": true,
+		}
+		if m.probeURL != "" {
+			observatory["probeURL"] = m.probeURL
+		}
+		if m.interval != "" {
+			observatory["probeInterval"] = m.interval
+		}
+		config["observatory"] = observatory
+	}
+	return config
+}
+
+// checkPrefixes reports balancer selectors that catch other outbounds, as
+// Xray matches selectors by tag prefix.
+func (m *migration) checkPrefixes() {
+	tags := []string{directTag, blockTag}
+	for _, name := range m.order {
+		if m.groups[name] == nil {
+			tags = append(tags, name)
+		}
+	}
+	for _, b := range m.balancers {
+		selector := b["selector"].([]string)
+		for _, s := range selector {
+			for _, tag := range tags {
+				if tag != s && strings.HasPrefix(tag, s) && !slices.Contains(selector, tag) {
+					m.warnf("balancer %q: selector %q also matches outbound %q", b["tag"], s, tag)
+				}
+			}
+		}
+	}
+}
+
+func (p *proxyServer) outbound() (json.RawMessage, error) {
+	if p.server == "" || p.port <= 0 || p.port > 65535 {
+		return nil, fmt.Errorf("invalid server %s:%d", p.server, p.port)
+	}
+	hostPort := net.JoinHostPort(p.server, strconv.Itoa(p.port))
+
+	var link string
+	switch p.protocol {
+	case "vless", "trojan":
+		q, err := p.query()
+		if err != nil {
+			return nil, err
+		}
+		user := p.password
+		if p.protocol == "vless" {
+			user = p.id
+			q.Set("encryption", "none")
+			if p.flow != "" {
+				q.Set("flow", p.flow)
+			}
+		}
+		link = (&url.URL{Scheme: p.protocol, User: url.User(user), Host: hostPort, RawQuery: q.Encode()}).String()
+	case "vmess":
+		q, err := p.query()
+		if err != nil {
+			return nil, err
+		}
+		if q.Get("security") == "reality" {
+			return nil, fmt.Errorf("REALITY is not supported for VMess")
+		}
+		path, typ := q.Get("path"), q.Get("headerType")
+		if p.network == "grpc" {
+			path, typ = q.Get("serviceName"), q.Get("mode")
+		}
+		tls := ""
+		if p.tls {
+			tls = "tls"
+		}
+		b, _ := json.Marshal(map[string]string{
+			"v": "2", "add": p.server, "port": strconv.Itoa(p.port), "id": p.id, "scy": p.method,
+			"net": q.Get("type"), "type": typ, "host": q.Get("host"), "path": path,
+			"tls": tls, "sni": q.Get("sni"), "alpn": q.Get("alpn"), "fp": q.Get("fp"),
+		})
+		link = "vmess://" + base64.StdEncoding.EncodeToString(b)
+	case "shadowsocks":
+		if p.network != "" && p.network != "tcp" || p.tls {
+			return nil, fmt.Errorf("Shadowsocks plugins are not supported")
+		}
+		link = "ss://" + url.PathEscape(p.method) + ":" + url.PathEscape(p.password) + "@" + hostPort
+	case "hysteria2":
+		q := url.Values{}
+		if p.sni != "" {
+			q.Set("sni", p.sni)
+		}
+		switch p.obfs {
+		case "":
+		case "salamander":
+			q.Set("obfs", p.obfs)
+			q.Set("obfs-password", p.obfsPassword)
+		default:
+			return nil, fmt.Errorf("unsupported obfs %q", p.obfs)
+		}
+		if p.ports != "" {
+			hostPort = net.JoinHostPort(p.server, strconv.Itoa(p.port)+","+p.ports)
+		}
+		link = (&url.URL{Scheme: "hysteria2", User: url.User(p.password), Host: hostPort, Path: "/", RawQuery: q.Encode()}).String()
+	case "socks", "http":
+		settings := map[string]interface{}{"address": p.server, "port": p.port}
+		if p.username != "" || p.password != "" {
+			settings["user"] = p.username
+			settings["pass"] = p.password
+		}
+		outbound := map[string]interface{}{"tag": p.name, "protocol": p.protocol, "settings": settings}
+		if p.tls {
+			tlsSettings := map[string]interface{}{}
+			if p.sni != "" {
+				tlsSettings["serverName"] = p.sni
+			}
+			outbound["streamSettings"] = map[string]interface{}{"security": "tls", "tlsSettings": tlsSettings}
+		}
+		return json.Marshal(outbound)
+	default:
+		return nil, fmt.Errorf("unsupported type %q", p.protocol)
+	}
+
+	raw, err := conf.ShareLinkToJSON(link)
+	if err != nil {
+		return nil, err
+	}
+	var outbound map[string]interface{}
+	if err := json.Unmarshal(raw, &outbound); err != nil {
+		return nil, err
+	}
+	outbound["tag"] = p.name
+	return json.Marshal(outbound)
+}
+
+// query encodes the transport and security of the proxy like a share link.
+func (p *proxyServer) query() (url.Values, error) {
+	q := url.Values{}
+	switch p.network {
+	case "", "tcp":
+		q.Set("type", "tcp")
+		if p.headerType == "http" {
+			q.Set("headerType", "http")
+		}
+	case "ws", "httpupgrade", "xhttp":
+		q.Set("type", p.network)
+		if p.mode != "" {
+			q.Set("mode", p.mode)
+		}
+	case "grpc":
+		q.Set("type", "grpc")
+		if p.serviceName != "" {
+			q.Set("serviceName", p.serviceName)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transport %q", p.network)
+	}
+	if p.host != "" {
+		q.Set("host", p.host)
+	}
+	if p.path != "" {
+		q.Set("path", p.path)
+	}
+
+	switch {
+	case p.realityPublicKey != "":
+		q.Set("security", "reality")
+		q.Set("pbk", p.realityPublicKey)
+		if p.realityShortID != "" {
+			q.Set("sid", p.realityShortID)
+		}
+	case p.tls:
+		q.Set("security", "tls")
+		if len(p.alpn) > 0 {
+			q.Set("alpn", strings.Join(p.alpn, ","))
+		}
+	default:
+		q.Set("security", "none")
+		return q, nil
+	}
+	if p.sni != "" {
+		q.Set("sni", p.sni)
+	}
+	if p.fingerprint != "" {
+		q.Set("fp", p.fingerprint)
+	}
+	return q, nil
+}
+
+// splitRule turns the destination conditions of a rule into Xray rules.
+// Domains and IPs are alternatives in Clash and sing-box, while the fields of
+// an Xray rule must all match, so they become separate rules that share the
+// other conditions.
+func splitRule(common map[string]interface{}, domains, ips []string) []map[string]interface{} {
+	if len(domains) == 0 && len(ips) == 0 {
+		return []map[string]interface{}{common}
+	}
+	var rules []map[string]interface{}
+	for key, values := range map[string][]string{"domain": domains, "ip": ips} {
+		if len(values) == 0 {
+			continue
+		}
+		rule := map[string]interface{}{key: values}
+		for k, v := range common {
+			rule[k] = v
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 2 && rules[0]["ip"] != nil {
+		rules[0], rules[1] = rules[1], rules[0]
+	}
+	return rules
+}
+
+func dedup(list []string) []string {
+	var out []string
+	for _, s := range list {
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func readInput(args []string) []byte {
+	input := "stdin:"
+	switch len(args) {
+	case 0:
+	case 1:
+		input = args[0]
+	default:
+		base.Fatalf("only one config can be converted at a time")
+	}
+	reader, err := confloader.LoadConfig(input)
+	if err != nil {
+		base.Fatalf("failed to load config: %s", err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		base.Fatalf("failed to read config: %s", err)
+	}
+	return content
+}
+
+// writeMigration prints the report to stderr and the config to output, or
+// stdout if output is empty.
+func writeMigration(m *migration, config map[string]interface{}, output string) {
+	for _, line := range m.report {
+		fmt.Fprintln(os.Stderr, "unsupported:", line)
+	}
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		base.Fatalf("failed to marshal config: %s", err)
+	}
+	if output == "" {
+		fmt.Println(string(b))
+		return
+	}
+	if err := os.WriteFile(output, append(b, '\n'), 0o644); err != nil {
+		base.Fatalf("failed to write config: %s", err)
+	}
+}
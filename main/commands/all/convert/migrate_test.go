@@ -0,0 +1,171 @@
+package convert
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/infra/conf"
+)
+
+const testRealityKey = "dZ6Ld7-kV0uiFmlQTjZQWMRBLeGvbbOYZbMrh_uMMEA"
+
+// writeAssets creates an empty Clash rule-provider and sing-box rule-set.
+func writeAssets(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("xray.location.asset", dir)
+	common.Must(os.WriteFile(filepath.Join(dir, "ads.yaml"), []byte("payload: []\n"), 0o644))
+
+	var srs bytes.Buffer
+	srs.WriteString("SRS\x01")
+	zw := zlib.NewWriter(&srs)
+	common.Must2(zw.Write([]byte{0}))
+	common.Must(zw.Close())
+	common.Must(os.WriteFile(filepath.Join(dir, "cn.srs"), srs.Bytes(), 0o644))
+}
+
+func buildMigration(t *testing.T, m *migration, final string) map[string]interface{} {
+	t.Helper()
+	config := m.config(final)
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xc := new(conf.Config)
+	if err := json.Unmarshal(b, xc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := xc.Build(); err != nil {
+		t.Fatalf("failed to build %s: %s", b, err)
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		t.Fatal(err)
+	}
+	return generic
+}
+
+func outboundTags(config map[string]interface{}) []string {
+	var tags []string
+	for _, o := range config["outbounds"].([]interface{}) {
+		tags = append(tags, o.(map[string]interface{})["tag"].(string))
+	}
+	return tags
+}
+
+func TestConvertClash(t *testing.T) {
+	clash := `
+proxies:
+  - {name: reality, type: vless, server: example.com, port: 443, uuid: 27848739-7e62-4138-9fd3-098a63964b6b, flow: xtls-rprx-vision, tls: true, servername: www.example.com, client-fingerprint: chrome, reality-opts: {public-key: ` + testRealityKey + `, short-id: 6ba8}}
+  - {name: ws, type: vmess, server: 1.2.3.4, port: "8443", uuid: 27848739-7e62-4138-9fd3-098a63964b6b, cipher: auto, network: ws, tls: true, ws-opts: {path: /ws, headers: {Host: cdn.example.com}}}
+  - {name: ss, type: ss, server: 1.2.3.4, port: 8388, cipher: aes-256-gcm, password: pass}
+  - {name: obfs, type: ss, server: 1.2.3.4, port: 8388, cipher: aes-256-gcm, password: pass, plugin: obfs}
+  - {name: hy, type: hysteria2, server: example.com, port: 443, ports: 20000-30000, password: auth, obfs: salamander, obfs-password: obfs}
+proxy-groups:
+  - {name: auto, type: url-test, proxies: [reality, ws], url: "https://www.gstatic.com/generate_204", interval: 300}
+  - {name: lb, type: load-balance, strategy: round-robin, proxies: [auto, ss]}
+  - {name: select, type: select, proxies: [lb, hy]}
+rule-providers:
+  ads: {type: http, behavior: domain, path: ./ads.yaml}
+rules:
+  - DOMAIN-SUFFIX,google.com,auto
+  - IP-CIDR,10.0.0.0/8,DIRECT,no-resolve
+  - RULE-SET,ads,REJECT
+  - DST-PORT,25/465,REJECT
+  - AND,((DOMAIN,a.com),(NETWORK,UDP)),DIRECT
+  - MATCH,select
+`
+	writeAssets(t)
+	var c clashConfig
+	if err := yaml.Unmarshal([]byte(clash), &c); err != nil {
+		t.Fatal(err)
+	}
+	m := convertClash(&c)
+	config := buildMigration(t, m, clashFinal(c.Rules))
+
+	if tags := outboundTags(config); !reflect.DeepEqual(tags, []string{"reality", "ws", "ss", "hy", "direct", "block"}) {
+		t.Errorf("unexpected outbounds %v", tags)
+	}
+
+	routing := config["routing"].(map[string]interface{})
+	balancers, _ := json.Marshal(routing["balancers"])
+	if want := `[{"selector":["reality","ws"],"strategy":{"type":"leastPing"},"tag":"auto"},{"selector":["reality","ws","ss"],"strategy":{"type":"roundRobin"},"tag":"lb"}]`; string(balancers) != want {
+		t.Errorf("balancers = %s", balancers)
+	}
+	rules, _ := json.Marshal(routing["rules"])
+	if want := `[{"balancerTag":"auto","domain":["domain:google.com"]},{"ip":["10.0.0.0/8"],"outboundTag":"direct"},{"domain":["clash:ads.yaml"],"outboundTag":"block"},{"outboundTag":"block","port":"25,465"},{"balancerTag":"lb","network":"tcp,udp"}]`; string(rules) != want {
+		t.Errorf("rules = %s", rules)
+	}
+	observatory, _ := json.Marshal(config["observatory"])
+	if want := `{"enableConcurrency":true,"probeInterval":"300s","probeURL":"https://www.gstatic.com/generate_204","subjectSelector":["reality","ws"]}`; string(observatory) != want {
+		t.Errorf("observatory = %s", observatory)
+	}
+
+	report := strings.Join(m.report, "\n")
+	for _, want := range []string{`proxy "obfs": plugin "obfs" is not supported`, `unsupported rule type AND`, `group "select": selector`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not mention %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestConvertSingBox(t *testing.T) {
+	singBox := `{
+		"outbounds": [
+			{"type": "selector", "tag": "proxy", "outbounds": ["auto", "tj"], "default": "tj"},
+			{"type": "urltest", "tag": "auto", "outbounds": ["tj", "socks"], "interval": "3m"},
+			{"type": "trojan", "tag": "tj", "server": "example.com", "server_port": 443, "password": "secret",
+				"tls": {"enabled": true, "server_name": "example.com", "utls": {"enabled": true}},
+				"transport": {"type": "grpc", "service_name": "svc"}},
+			{"type": "socks", "tag": "socks", "server": "127.0.0.1", "server_port": 1080},
+			{"type": "tuic", "tag": "tuic", "server": "example.com", "server_port": 443},
+			{"type": "direct", "tag": "out-direct"},
+			{"type": "block", "tag": "out-block"}
+		],
+		"route": {
+			"rule_set": [{"tag": "cn", "type": "local", "format": "binary", "path": "cn.srs"}],
+			"rules": [
+				{"action": "sniff"},
+				{"domain_suffix": [".example.org", "example.net"], "ip_cidr": "192.168.0.0/16", "port_range": ["1000:2000"], "outbound": "out-direct"},
+				{"rule_set": "cn", "action": "route", "outbound": "out-direct"},
+				{"protocol": "bittorrent", "action": "reject"},
+				{"type": "logical", "mode": "and", "rules": [], "outbound": "out-block"}
+			],
+			"final": "proxy"
+		}
+	}`
+	writeAssets(t)
+	var c singBoxConfig
+	if err := json.Unmarshal([]byte(singBox), &c); err != nil {
+		t.Fatal(err)
+	}
+	m := convertSingBox(&c)
+	config := buildMigration(t, m, c.Route.Final)
+
+	if tags := outboundTags(config); !reflect.DeepEqual(tags, []string{"tj", "socks", "direct", "block"}) {
+		t.Errorf("unexpected outbounds %v", tags)
+	}
+	routing := config["routing"].(map[string]interface{})
+	rules, _ := json.Marshal(routing["rules"])
+	if want := `[{"domain":["regexp:\\.example\\.org$","domain:example.net"],"outboundTag":"direct","port":"1000-2000"},{"ip":["192.168.0.0/16"],"outboundTag":"direct","port":"1000-2000"},{"domain":["srs:cn.srs"],"outboundTag":"direct"},{"ip":["srs:cn.srs"],"outboundTag":"direct"},{"outboundTag":"block","protocol":["bittorrent"]},{"network":"tcp,udp","outboundTag":"tj"}]`; string(rules) != want {
+		t.Errorf("rules = %s", rules)
+	}
+	balancers, _ := json.Marshal(routing["balancers"])
+	if want := `[{"selector":["tj","socks"],"strategy":{"type":"leastPing"},"tag":"auto"}]`; string(balancers) != want {
+		t.Errorf("balancers = %s", balancers)
+	}
+
+	report := strings.Join(m.report, "\n")
+	for _, want := range []string{`outbound "tuic": unsupported type`, `action "sniff"`, `logical rules`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not mention %q:\n%s", want, report)
+		}
+	}
+}
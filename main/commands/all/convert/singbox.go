@@ -0,0 +1,381 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	json_reader "github.com/xtls/xray-core/infra/conf/json"
+	"github.com/xtls/xray-core/main/commands/base"
+)
+
+var cmdSingBox = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} convert singbox [-o file] [sing-box config]",
+	Short:       "Convert a sing-box config to Xray config",
+	Long: `
+Convert the outbounds, route rules and rule-sets of a sing-box config to Xray
+outbounds, balancers, observatory and routing rules.
+
+Selector outbounds are replaced by their default (or first) member, urltest
+outbounds become leastPing balancers with observatory.
+
+Everything that cannot be converted is reported on stderr. Binary rule-sets
+are referenced as "srs:<path>", so the files must be copied to the Xray asset
+directory. Remote rule-sets are expected there as "<tag>.srs".
+
+Arguments:
+
+	-o file
+		Write the Xray config to the file instead of stdout.
+
+Examples:
+
+    {{.Exec}} convert singbox -o config.json sing-box.json
+	`,
+	Run: executeConvertSingBox,
+}
+
+// listable is a sing-box field that is either a single value or a list.
+type listable []string
+
+func (l *listable) UnmarshalJSON(b []byte) error {
+	var values []json.RawMessage
+	if err := json.Unmarshal(b, &values); err != nil {
+		values = []json.RawMessage{b}
+	}
+	for _, v := range values {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v)
+		}
+		*l = append(*l, s)
+	}
+	return nil
+}
+
+type singBoxConfig struct {
+	Outbounds []*singBoxOutbound `json:"outbounds"`
+	Route     struct {
+		Rules   []*singBoxRule `json:"rules"`
+		RuleSet []struct {
+			Tag    string `json:"tag"`
+			Type   string `json:"type"`
+			Format string `json:"format"`
+			Path   string `json:"path"`
+			URL    string `json:"url"`
+		} `json:"rule_set"`
+		Final string `json:"final"`
+	} `json:"route"`
+}
+
+type singBoxOutbound struct {
+	Type        string   `json:"type"`
+	Tag         string   `json:"tag"`
+	Server      string   `json:"server"`
+	ServerPort  int      `json:"server_port"`
+	ServerPorts listable `json:"server_ports"`
+	UUID        string   `json:"uuid"`
+	Flow        string   `json:"flow"`
+	Password    string   `json:"password"`
+	Username    string   `json:"username"`
+	Method      string   `json:"method"`
+	Security    string   `json:"security"`
+	Plugin      string   `json:"plugin"`
+	TLS         *struct {
+		Enabled    bool     `json:"enabled"`
+		ServerName string   `json:"server_name"`
+		Insecure   bool     `json:"insecure"`
+		ALPN       listable `json:"alpn"`
+		UTLS       *struct {
+			Enabled     bool   `json:"enabled"`
+			Fingerprint string `json:"fingerprint"`
+		} `json:"utls"`
+		Reality *struct {
+			Enabled   bool   `json:"enabled"`
+			PublicKey string `json:"public_key"`
+			ShortID   string `json:"short_id"`
+		} `json:"reality"`
+	} `json:"tls"`
+	Transport *struct {
+		Type        string            `json:"type"`
+		Host        listable          `json:"host"`
+		Path        string            `json:"path"`
+		Headers     map[string]string `json:"headers"`
+		ServiceName string            `json:"service_name"`
+	} `json:"transport"`
+	Obfs *struct {
+		Type     string `json:"type"`
+		Password string `json:"password"`
+	} `json:"obfs"`
+
+	Outbounds []string `json:"outbounds"`
+	Default   string   `json:"default"`
+	URL       string   `json:"url"`
+	Interval  string   `json:"interval"`
+}
+
+type singBoxRule struct {
+	Type     string `json:"type"`
+	Invert   bool   `json:"invert"`
+	Action   string `json:"action"`
+	Outbound string `json:"outbound"`
+
+	Domain          listable `json:"domain"`
+	DomainSuffix    listable `json:"domain_suffix"`
+	DomainKeyword   listable `json:"domain_keyword"`
+	DomainRegex     listable `json:"domain_regex"`
+	Geosite         listable `json:"geosite"`
+	GeoIP           listable `json:"geoip"`
+	IPCIDR          listable `json:"ip_cidr"`
+	IPIsPrivate     bool     `json:"ip_is_private"`
+	SourceIPCIDR    listable `json:"source_ip_cidr"`
+	Port            listable `json:"port"`
+	PortRange       listable `json:"port_range"`
+	SourcePort      listable `json:"source_port"`
+	SourcePortRange listable `json:"source_port_range"`
+	Network         listable `json:"network"`
+	Protocol        listable `json:"protocol"`
+	ProcessName     listable `json:"process_name"`
+	RuleSet         listable `json:"rule_set"`
+	Inbound         listable `json:"inbound"`
+}
+
+func executeConvertSingBox(cmd *base.Command, args []string) {
+	var output string
+	cmd.Flag.StringVar(&output, "o", "", "")
+	cmd.Flag.Parse(args)
+
+	content := readInput(cmd.Flag.Args())
+	var c singBoxConfig
+	// sing-box allows comments like Xray does
+	if err := json.NewDecoder(&json_reader.Reader{Reader: bytes.NewReader(content)}).Decode(&c); err != nil {
+		base.Fatalf("failed to parse sing-box config: %s", err)
+	}
+	m := convertSingBox(&c)
+	writeMigration(m, m.config(c.Route.Final), output)
+}
+
+func convertSingBox(c *singBoxConfig) *migration {
+	m := newMigration()
+	for _, o := range c.Outbounds {
+		switch o.Type {
+		case "direct":
+			m.alias(o.Tag, directTag)
+		case "block":
+			m.alias(o.Tag, blockTag)
+		case "selector":
+			members := o.Outbounds
+			if o.Default != "" {
+				members = append([]string{o.Default}, members...)
+			}
+			m.addGroup(&proxyGroup{name: o.Tag, kind: "select", members: members})
+		case "urltest":
+			m.addGroup(&proxyGroup{name: o.Tag, kind: "url-test", members: o.Outbounds, url: o.URL, interval: o.Interval})
+		default:
+			proxy, err := o.proxyServer()
+			if err != nil {
+				m.warnf("outbound %q: %s, skipped", o.Tag, err)
+				continue
+			}
+			if o.TLS != nil && o.TLS.Insecure {
+				m.warnf("outbound %q: insecure is dropped, pin the certificate with pinnedPeerCertSha256", o.Tag)
+			}
+			m.addProxy(proxy)
+		}
+	}
+
+	ruleSets := make(map[string]string)
+	for _, rs := range c.Route.RuleSet {
+		switch {
+		case rs.Format != "binary":
+			m.warnf("rule-set %q: only binary rule-sets are supported", rs.Tag)
+		case rs.Type == "remote":
+			ruleSets[rs.Tag] = "srs:" + rs.Tag + ".srs"
+			m.warnf("rule-set %q: download %s to the asset directory as %s.srs", rs.Tag, rs.URL, rs.Tag)
+		case rs.Path != "":
+			ruleSets[rs.Tag] = "srs:" + rs.Path
+		default:
+			m.warnf("rule-set %q: no path", rs.Tag)
+		}
+	}
+
+	for i, r := range c.Route.Rules {
+		convertSingBoxRule(m, r, "#"+strconv.Itoa(i), ruleSets)
+	}
+	return m
+}
+
+func (o *singBoxOutbound) proxyServer() (*proxyServer, error) {
+	s := &proxyServer{
+		name:     o.Tag,
+		server:   o.Server,
+		port:     o.ServerPort,
+		id:       o.UUID,
+		flow:     o.Flow,
+		password: o.Password,
+		username: o.Username,
+		method:   o.Method,
+	}
+	switch o.Type {
+	case "vless", "trojan", "shadowsocks", "socks", "http":
+		s.protocol = o.Type
+	case "vmess":
+		s.protocol = "vmess"
+		s.method = o.Security
+	case "hysteria2":
+		s.protocol = "hysteria2"
+		if o.Obfs != nil {
+			s.obfs, s.obfsPassword = o.Obfs.Type, o.Obfs.Password
+		}
+		for i, ports := range o.ServerPorts {
+			from, to, _ := strings.Cut(ports, ":")
+			if from == to || to == "" {
+				o.ServerPorts[i] = from
+			} else {
+				o.ServerPorts[i] = from + "-" + to
+			}
+		}
+		s.ports = strings.Join(o.ServerPorts, ",")
+	default:
+		return nil, fmt.Errorf("unsupported type %q", o.Type)
+	}
+	if o.Plugin != "" {
+		return nil, fmt.Errorf("plugin %q is not supported", o.Plugin)
+	}
+
+	if tls := o.TLS; tls != nil && tls.Enabled {
+		s.tls = true
+		s.sni = tls.ServerName
+		s.alpn = tls.ALPN
+		if tls.UTLS != nil && tls.UTLS.Enabled {
+			s.fingerprint = tls.UTLS.Fingerprint
+			if s.fingerprint == "" {
+				s.fingerprint = "chrome"
+			}
+		}
+		if tls.Reality != nil && tls.Reality.Enabled {
+			s.realityPublicKey, s.realityShortID = tls.Reality.PublicKey, tls.Reality.ShortID
+		}
+	}
+
+	if t := o.Transport; t != nil {
+		switch t.Type {
+		case "ws", "httpupgrade":
+			s.network = t.Type
+			s.path = t.Path
+			s.host = t.Headers["Host"]
+			if len(t.Host) > 0 {
+				s.host = t.Host[0]
+			}
+		case "grpc":
+			s.network = "grpc"
+			s.serviceName = t.ServiceName
+		default:
+			return nil, fmt.Errorf("unsupported transport %q", t.Type)
+		}
+	}
+	return s, nil
+}
+
+func convertSingBoxRule(m *migration, r *singBoxRule, name string, ruleSets map[string]string) {
+	to := r.Outbound
+	switch r.Action {
+	case "", "route":
+	case "reject":
+		to = "REJECT"
+	default:
+		m.warnf("rule %s: action %q is not supported, skipped", name, r.Action)
+		return
+	}
+	switch {
+	case r.Type == "logical":
+		m.warnf("rule %s: logical rules are not supported, skipped", name)
+		return
+	case r.Invert:
+		m.warnf("rule %s: invert is not supported, skipped", name)
+		return
+	case len(r.Inbound) > 0:
+		m.warnf("rule %s: inbound %v has no counterpart in the converted config, condition dropped", name, r.Inbound)
+	}
+
+	var domains, ips []string
+	for _, d := range r.Domain {
+		domains = append(domains, "full:"+d)
+	}
+	for _, d := range r.DomainSuffix {
+		if strings.HasPrefix(d, ".") {
+			domains = append(domains, `regexp:\.`+regexp.QuoteMeta(d[1:])+"$")
+		} else {
+			domains = append(domains, "domain:"+d)
+		}
+	}
+	for _, d := range r.DomainKeyword {
+		domains = append(domains, "keyword:"+d)
+	}
+	for _, d := range r.DomainRegex {
+		domains = append(domains, "regexp:"+d)
+	}
+	for _, d := range r.Geosite {
+		domains = append(domains, "geosite:"+d)
+	}
+	for _, ip := range r.GeoIP {
+		ips = append(ips, "geoip:"+ip)
+	}
+	ips = append(ips, r.IPCIDR...)
+	if r.IPIsPrivate {
+		ips = append(ips, "geoip:private")
+	}
+	for _, tag := range r.RuleSet {
+		rs, found := ruleSets[tag]
+		if !found {
+			m.warnf("rule %s: rule-set %q is not converted, skipped", name, tag)
+			return
+		}
+		domains = append(domains, rs)
+		ips = append(ips, rs)
+	}
+
+	rule := map[string]interface{}{}
+	if len(r.SourceIPCIDR) > 0 {
+		rule["source"] = []string(r.SourceIPCIDR)
+	}
+	if ports := singBoxPorts(r.Port, r.PortRange); ports != "" {
+		rule["port"] = ports
+	}
+	if ports := singBoxPorts(r.SourcePort, r.SourcePortRange); ports != "" {
+		rule["sourcePort"] = ports
+	}
+	if len(r.Network) > 0 {
+		rule["network"] = strings.Join(r.Network, ",")
+	}
+	if len(r.Protocol) > 0 {
+		rule["protocol"] = []string(r.Protocol)
+	}
+	if len(r.ProcessName) > 0 {
+		rule["process"] = []string(r.ProcessName)
+	}
+
+	for _, xr := range splitRule(rule, domains, ips) {
+		m.addRule(xr, to, "rule "+name)
+	}
+}
+
+// singBoxPorts joins ports and "from:to" port ranges into an Xray port list.
+func singBoxPorts(ports, ranges []string) string {
+	list := append([]string{}, ports...)
+	for _, r := range ranges {
+		from, to, _ := strings.Cut(r, ":")
+		if from == "" {
+			from = "1"
+		}
+		if to == "" {
+			to = "65535"
+		}
+		list = append(list, from+"-"+to)
+	}
+	return strings.Join(list, ",")
+}
@@ -0,0 +1,94 @@
+package link
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/infra/conf/serial"
+	"github.com/xtls/xray-core/main/commands/base"
+	"github.com/xtls/xray-core/main/confloader"
+)
+
+var cmdExport = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} link export [-tag tag] [config file] [config file] ...",
+	Short:       "Convert outbound configs to share links",
+	Long: `
+Print the outbounds of config files as share links, one per line. JSON, YAML
+and TOML can be used.
+
+VLESS (including REALITY and XHTTP), VMess, Trojan, Shadowsocks and
+Hysteria2 outbounds are exported, others are reported on stderr and skipped.
+Settings that share links cannot carry, such as sockopt and mux, are dropped.
+
+Arguments:
+
+	-tag tag
+		Only export the outbound with the tag. Can be given multiple times.
+
+Examples:
+
+    {{.Exec}} {{.LongName}} config.json
+    {{.Exec}} {{.LongName}} -tag proxy c1.json c2.json
+	`,
+	Run: executeExport,
+}
+
+type tagList []string
+
+func (l *tagList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *tagList) Set(tag string) error {
+	*l = append(*l, tag)
+	return nil
+}
+
+func executeExport(cmd *base.Command, args []string) {
+	var tags tagList
+	cmd.Flag.Var(&tags, "tag", "")
+	cmd.Flag.Parse(args)
+
+	if cmd.Flag.NArg() < 1 {
+		base.Fatalf("empty config list")
+	}
+
+	found := 0
+	for _, file := range cmd.Flag.Args() {
+		format := core.GetFormat(file)
+		if format == "" || file == "stdin:" {
+			format = "json"
+		}
+		decoder, ok := serial.ReaderDecoderByFormat[format]
+		if !ok {
+			base.Fatalf("unsupported config format: %s", file)
+		}
+		reader, err := confloader.LoadConfig(file)
+		if err != nil {
+			base.Fatalf("failed to load config %s: %s", file, err)
+		}
+		config, err := decoder(reader)
+		if err != nil {
+			base.Fatalf("failed to decode config %s: %s", file, err)
+		}
+
+		for _, outbound := range config.OutboundConfigs {
+			if len(tags) > 0 && !slices.Contains(tags, outbound.Tag) {
+				continue
+			}
+			found++
+			link, err := outbound.ShareLink()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+			fmt.Println(link)
+		}
+	}
+	if found == 0 {
+		base.Fatalf("no outbound found")
+	}
+}
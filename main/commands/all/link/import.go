@@ -0,0 +1,100 @@
+package link
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/main/commands/base"
+	"github.com/xtls/xray-core/main/confloader"
+)
+
+var cmdImport = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} link import [-prefix tag] [link|file] [link|file] ...",
+	Short:       "Convert share links to outbound configs",
+	Long: `
+Convert share links to JSON outbound configs.
+
+Arguments that contain "://" are share links, other arguments are files (or
+URLs) holding a list of links, plain or base64 encoded like a subscription.
+Links are read from stdin if no argument is given.
+
+Supported links: vless://, vmess://, trojan://, ss://, hysteria2:// (hy2://).
+Links that fail to parse are reported on stderr and skipped.
+
+Arguments:
+
+	-prefix tag
+		Prepend the tag prefix to the remark of every link.
+
+Examples:
+
+    {{.Exec}} {{.LongName}} "vless://uuid@example.com:443?security=tls#node"
+    {{.Exec}} {{.LongName}} sub.txt > outbounds.json
+	`,
+	Run: executeImport,
+}
+
+func executeImport(cmd *base.Command, args []string) {
+	var prefix string
+	cmd.Flag.StringVar(&prefix, "prefix", "", "")
+	cmd.Flag.Parse(args)
+
+	inputs := cmd.Flag.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"stdin:"}
+	}
+
+	var links []string
+	for _, input := range inputs {
+		if strings.Contains(input, "://") && !strings.HasPrefix(input, "http://") && !strings.HasPrefix(input, "https://") {
+			links = append(links, input)
+			continue
+		}
+		reader, err := confloader.LoadConfig(input)
+		if err != nil {
+			base.Fatalf("failed to load %s: %s", input, err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			base.Fatalf("failed to read %s: %s", input, err)
+		}
+		links = append(links, conf.DecodeSubscription(content)...)
+	}
+
+	outbounds := make([]json.RawMessage, 0, len(links))
+	for _, link := range links {
+		outbound, err := conf.ShareLinkToJSON(link)
+		if err == nil && prefix != "" {
+			outbound, err = prefixTag(outbound, prefix)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		outbounds = append(outbounds, outbound)
+	}
+	if len(outbounds) == 0 {
+		base.Fatalf("no valid share link")
+	}
+
+	b, err := json.MarshalIndent(map[string]interface{}{"outbounds": outbounds}, "", "  ")
+	if err != nil {
+		base.Fatalf("failed to marshal outbounds: %s", err)
+	}
+	fmt.Println(string(b))
+}
+
+func prefixTag(outbound json.RawMessage, prefix string) (json.RawMessage, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(outbound, &m); err != nil {
+		return nil, err
+	}
+	tag, _ := m["tag"].(string)
+	m["tag"] = prefix + tag
+	return json.Marshal(m)
+}
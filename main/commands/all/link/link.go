@@ -0,0 +1,17 @@
+package link
+
+import (
+	"github.com/xtls/xray-core/main/commands/base"
+)
+
+// CmdLink holds all share link sub commands
+var CmdLink = &base.Command{
+	UsageLine: "{{.Exec}} link",
+	Short:     "Share link tools",
+	Long: `{{.Exec}} {{.LongName}} converts between outbound configs and share links.
+`,
+	Commands: []*base.Command{
+		cmdImport,
+		cmdExport,
+	},
+}
@@ -0,0 +1,258 @@
+package anytls
+
+import (
+	"context"
+	"encoding/binary"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/common/signal"
+	"github.com/xtls/xray-core/common/task"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/policy"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+)
+
+// Client is an outbound connection handler for AnyTLS protocol. It reuses
+// the sessions whose streams have closed.
+type Client struct {
+	server        *protocol.ServerSpec
+	policyManager policy.Manager
+	padding       atomic.Pointer[PaddingScheme]
+
+	idleMutex   sync.Mutex
+	idle        []*Session
+	idleTimeout time.Duration
+	minIdle     int
+	idleChecker *task.Periodic
+}
+
+// NewClient creates a new AnyTLS outbound handler.
+func NewClient(ctx context.Context, config *ClientConfig) (*Client, error) {
+	if config.Server == nil {
+		return nil, errors.New(`no target server found`)
+	}
+	server, err := protocol.NewServerSpecFromPB(config.Server)
+	if err != nil {
+		return nil, errors.New("failed to get server spec").Base(err)
+	}
+	if server.User == nil {
+		return nil, errors.New("user is not specified")
+	}
+
+	v := core.MustFromContext(ctx)
+	client := &Client{
+		server:        server,
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		idleTimeout:   time.Duration(config.IdleSessionTimeout) * time.Second,
+		minIdle:       int(config.MinIdleSession),
+	}
+	if client.idleTimeout == 0 {
+		client.idleTimeout = 30 * time.Second
+	}
+	checkInterval := time.Duration(config.IdleSessionCheckInterval) * time.Second
+	if checkInterval == 0 {
+		checkInterval = 30 * time.Second
+	}
+	client.padding.Store(defaultPaddingScheme)
+	client.idleChecker = &task.Periodic{
+		Interval: checkInterval,
+		Execute:  client.cleanIdle,
+	}
+	common.Must(client.idleChecker.Start())
+	return client, nil
+}
+
+// Close implements common.Closable.Close().
+func (c *Client) Close() error {
+	c.idleChecker.Close()
+	c.idleMutex.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.idleMutex.Unlock()
+	for _, s := range idle {
+		s.Close()
+	}
+	return nil
+}
+
+// cleanIdle closes the sessions idle for too long, except the newest ones.
+func (c *Client) cleanIdle() error {
+	now := time.Now()
+	var expired []*Session
+
+	c.idleMutex.Lock()
+	c.idle = slices.DeleteFunc(c.idle, func(s *Session) bool {
+		return s.IsClosed()
+	})
+	for len(c.idle) > c.minIdle && now.Sub(c.idle[0].idleSince) > c.idleTimeout {
+		expired = append(expired, c.idle[0])
+		c.idle = c.idle[1:]
+	}
+	c.idleMutex.Unlock()
+
+	for _, s := range expired {
+		s.Close()
+	}
+	return nil
+}
+
+func (c *Client) getIdle() *Session {
+	c.idleMutex.Lock()
+	defer c.idleMutex.Unlock()
+
+	for len(c.idle) > 0 {
+		s := c.idle[len(c.idle)-1]
+		c.idle = c.idle[:len(c.idle)-1]
+		if !s.IsClosed() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *Client) putIdle(s *Session) {
+	if s.IsClosed() {
+		return
+	}
+	s.idleSince = time.Now()
+	c.idleMutex.Lock()
+	c.idle = append(c.idle, s)
+	c.idleMutex.Unlock()
+}
+
+// newSession dials a session and authenticates, with the first packet padded
+// as the padding scheme says.
+func (c *Client) newSession(ctx context.Context, dialer internet.Dialer) (*Session, error) {
+	conn, err := dialer.Dial(ctx, c.server.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	padding := c.padding.Load()
+	account := c.server.User.Account.(*MemoryAccount)
+	paddingLen := 0
+	if sizes := padding.RecordSizes(0); len(sizes) > 0 && sizes[0] > 0 {
+		paddingLen = sizes[0]
+	}
+	auth := make([]byte, len(account.Key)+2+paddingLen)
+	copy(auth, account.Key[:])
+	binary.BigEndian.PutUint16(auth[len(account.Key):], uint16(paddingLen))
+	if _, err := conn.Write(auth); err != nil {
+		conn.Close()
+		return nil, errors.New("failed to write authentication").Base(err)
+	}
+
+	s := newSession(conn, true, padding)
+	s.onPaddingScheme = func(p *PaddingScheme) {
+		c.padding.Store(p)
+	}
+	s.onStreamClose = c.putIdle
+	if err := s.sendSettings("xray/" + core.Version()); err != nil {
+		s.Close()
+		return nil, err
+	}
+	go func() {
+		if err := s.Run(context.Background()); err != nil {
+			errors.LogDebugInner(context.Background(), err, "anytls session ends")
+		}
+	}()
+	return s, nil
+}
+
+// Process implements proxy.Outbound.Process().
+func (c *Client) Process(ctx context.Context, link *transport.Link, dialer internet.Dialer) error {
+	outbounds := session.OutboundsFromContext(ctx)
+	ob := outbounds[len(outbounds)-1]
+	if !ob.Target.IsValid() {
+		return errors.New("target not specified")
+	}
+	ob.Name = "anytls"
+	ob.CanSpliceCopy = 3
+	destination := ob.Target
+
+	var stream *Stream
+	if s := c.getIdle(); s != nil {
+		stream, _ = s.OpenStream()
+	}
+	if stream == nil {
+		s, err := c.newSession(ctx, dialer)
+		if err != nil {
+			return errors.New("failed to find an available destination").Base(err).AtWarning()
+		}
+		if stream, err = s.OpenStream(); err != nil {
+			return errors.New("failed to open stream").Base(err).AtWarning()
+		}
+	}
+	defer stream.Close()
+	errors.LogInfo(ctx, "tunneling request to ", destination, " via ", c.server.Destination.NetAddr())
+
+	sessionPolicy := c.policyManager.ForLevel(c.server.User.Level)
+
+	var newCtx context.Context
+	var newCancel context.CancelFunc
+	if session.TimeoutOnlyFromContext(ctx) {
+		newCtx, newCancel = context.WithCancel(context.Background())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, func() {
+		cancel()
+		if newCancel != nil {
+			newCancel()
+		}
+	}, sessionPolicy.Timeouts.ConnectionIdle)
+
+	postRequest := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := WriteRequest(stream, destination); err != nil {
+			return errors.New("failed to write request").Base(err).AtWarning()
+		}
+
+		var writer buf.Writer = stream
+		if destination.Network == net.Network_UDP {
+			writer = &PacketWriter{Writer: stream, Target: destination}
+		}
+		if err := buf.Copy(link.Reader, writer, buf.UpdateActivity(timer)); err != nil {
+			return errors.New("failed to transfer request payload").Base(err).AtInfo()
+		}
+		return nil
+	}
+
+	getResponse := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		var reader buf.Reader = stream
+		if destination.Network == net.Network_UDP {
+			reader = &PacketReader{Reader: &buf.BufferedReader{Reader: stream}, Target: destination}
+		}
+		return buf.Copy(reader, link.Writer, buf.UpdateActivity(timer))
+	}
+
+	if newCtx != nil {
+		ctx = newCtx
+	}
+
+	responseDoneAndCloseWriter := task.OnSuccess(getResponse, task.Close(link.Writer))
+	if err := task.Run(ctx, postRequest, responseDoneAndCloseWriter); err != nil {
+		return errors.New("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*ClientConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewClient(ctx, config.(*ClientConfig))
+	}))
+}
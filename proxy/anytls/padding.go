@@ -0,0 +1,128 @@
+package anytls
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+)
+
+// DefaultPaddingScheme is the padding scheme of the reference implementation.
+const DefaultPaddingScheme = `stop=8
+0=30-30
+1=100-400
+2=400-500,c,500-1000,c,500-1000,c,500-1000,c,500-1000
+3=9-9,500-1000
+4=500-1000
+5=500-1000
+6=500-1000
+7=500-1000`
+
+// CheckMark in the record sizes stops the padding of a packet if no payload
+// is left.
+const CheckMark = -1
+
+type sizeRange struct {
+	min, max int
+}
+
+// PaddingScheme decides the sizes of the records of the first packets in a
+// session, which are filled with payload and padding.
+type PaddingScheme struct {
+	raw    []byte
+	md5    string
+	stop   uint32
+	ranges map[uint32][]sizeRange
+}
+
+// ParsePaddingScheme parses the lines of the scheme. "stop=N" ends the padding
+// at the N-th packet, and "i=min-max,c,..." gives the record sizes of the i-th
+// packet, where "c" is a CheckMark.
+func ParsePaddingScheme(raw string) (*PaddingScheme, error) {
+	p := &PaddingScheme{
+		raw:    []byte(raw),
+		ranges: make(map[uint32][]sizeRange),
+	}
+	sum := md5.Sum(p.raw)
+	p.md5 = hex.EncodeToString(sum[:])
+
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, errors.New("invalid padding scheme line: ", line)
+		}
+		if key == "stop" {
+			stop, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, errors.New("invalid padding scheme stop: ", value).Base(err)
+			}
+			p.stop = uint32(stop)
+			continue
+		}
+		pkt, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid padding scheme packet: ", key).Base(err)
+		}
+		var ranges []sizeRange
+		for _, s := range strings.Split(value, ",") {
+			if s == "c" {
+				ranges = append(ranges, sizeRange{CheckMark, CheckMark})
+				continue
+			}
+			lo, hi, ok := strings.Cut(s, "-")
+			if !ok {
+				return nil, errors.New("invalid padding scheme range: ", s)
+			}
+			min, err := strconv.Atoi(lo)
+			if err != nil {
+				return nil, errors.New("invalid padding scheme range: ", s).Base(err)
+			}
+			max, err := strconv.Atoi(hi)
+			if err != nil {
+				return nil, errors.New("invalid padding scheme range: ", s).Base(err)
+			}
+			if min < 0 || max < min || max > 0xFFFF {
+				return nil, errors.New("invalid padding scheme range: ", s)
+			}
+			ranges = append(ranges, sizeRange{min, max})
+		}
+		p.ranges[uint32(pkt)] = ranges
+	}
+	return p, nil
+}
+
+func (p *PaddingScheme) MD5() string {
+	return p.md5
+}
+
+func (p *PaddingScheme) Raw() []byte {
+	return p.raw
+}
+
+func (p *PaddingScheme) Stop() uint32 {
+	return p.stop
+}
+
+// RecordSizes generates the record sizes of the packet, which may include
+// CheckMark.
+func (p *PaddingScheme) RecordSizes(pkt uint32) []int {
+	ranges := p.ranges[pkt]
+	sizes := make([]int, 0, len(ranges))
+	for _, r := range ranges {
+		if r.min == CheckMark {
+			sizes = append(sizes, CheckMark)
+			continue
+		}
+		sizes = append(sizes, r.min+rand.Intn(r.max-r.min+1))
+	}
+	return sizes
+}
+
+var defaultPaddingScheme, _ = ParsePaddingScheme(DefaultPaddingScheme)
@@ -0,0 +1,45 @@
+package anytls_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/proxy/anytls"
+)
+
+func TestDefaultPaddingScheme(t *testing.T) {
+	p, err := ParsePaddingScheme(DefaultPaddingScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stop() != 8 {
+		t.Error("stop: ", p.Stop())
+	}
+
+	sizes := p.RecordSizes(2)
+	if len(sizes) != 9 {
+		t.Fatal("record sizes: ", sizes)
+	}
+	if sizes[0] < 400 || sizes[0] > 500 {
+		t.Error("record size: ", sizes[0])
+	}
+	if sizes[1] != CheckMark {
+		t.Error("check mark: ", sizes[1])
+	}
+	if sizes := p.RecordSizes(8); len(sizes) != 0 {
+		t.Error("record sizes after stop: ", sizes)
+	}
+}
+
+func TestInvalidPaddingScheme(t *testing.T) {
+	for _, raw := range []string{
+		"stop",
+		"stop=x",
+		"0=1",
+		"0=10-5",
+		"0=0-65536",
+	} {
+		if _, err := ParsePaddingScheme(raw); err == nil {
+			t.Error("expected error for ", raw)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package anytls
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+)
+
+var (
+	// addrParser serializes the destination of a stream, like SOCKS5.
+	addrParser = protocol.NewAddressParser(
+		protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+		protocol.AddressFamilyByte(0x04, net.AddressFamilyIPv6),
+		protocol.AddressFamilyByte(0x03, net.AddressFamilyDomain),
+	)
+
+	// packetAddrParser serializes the addresses of UDP-over-TCP packets.
+	packetAddrParser = protocol.NewAddressParser(
+		protocol.AddressFamilyByte(0x00, net.AddressFamilyIPv4),
+		protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv6),
+		protocol.AddressFamilyByte(0x02, net.AddressFamilyDomain),
+	)
+)
+
+// uotMagicAddress is the destination of the streams that carry UDP in
+// UDP-over-TCP version 2.
+const uotMagicAddress = "sp.v2.udp-over-tcp.arpa"
+
+// ReadRequest reads the destination of a stream. For UDP, connect tells
+// whether the packets carry no address and go to the destination.
+func ReadRequest(r io.Reader) (dest net.Destination, connect bool, err error) {
+	buffer := buf.StackNew()
+	defer buffer.Release()
+
+	addr, port, err := addrParser.ReadAddressPort(&buffer, r)
+	if err != nil {
+		return dest, false, err
+	}
+	if !addr.Family().IsDomain() || addr.Domain() != uotMagicAddress {
+		return net.TCPDestination(addr, port), false, nil
+	}
+
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return dest, false, err
+	}
+	buffer.Clear()
+	addr, port, err = addrParser.ReadAddressPort(&buffer, r)
+	if err != nil {
+		return dest, false, err
+	}
+	return net.UDPDestination(addr, port), b[0] != 0, nil
+}
+
+// WriteRequest writes the destination of a stream, and the UDP-over-TCP
+// request for UDP, whose packets carry their addresses.
+func WriteRequest(w io.Writer, dest net.Destination) error {
+	buffer := buf.StackNew()
+	defer buffer.Release()
+
+	if dest.Network == net.Network_UDP {
+		if err := addrParser.WriteAddressPort(&buffer, net.DomainAddress(uotMagicAddress), 0); err != nil {
+			return err
+		}
+		if err := buffer.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	if err := addrParser.WriteAddressPort(&buffer, dest.Address, dest.Port); err != nil {
+		return err
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// PacketWriter writes UDP-over-TCP packets, which carry their addresses
+// unless Connect is set.
+type PacketWriter struct {
+	io.Writer
+	Target  net.Destination
+	Connect bool
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *PacketWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, b := range mb {
+		target := w.Target
+		if b.UDP != nil && !w.Connect {
+			target = *b.UDP
+		}
+
+		header := buf.StackNew()
+		if !w.Connect {
+			if err := packetAddrParser.WriteAddressPort(&header, target.Address, target.Port); err != nil {
+				header.Release()
+				return err
+			}
+		}
+		binary.BigEndian.PutUint16(header.Extend(2), uint16(b.Len()))
+		packet := make([]byte, 0, header.Len()+b.Len())
+		packet = append(packet, header.Bytes()...)
+		packet = append(packet, b.Bytes()...)
+		header.Release()
+		if _, err := w.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PacketReader reads UDP-over-TCP packets, which carry their addresses
+// unless Connect is set.
+type PacketReader struct {
+	io.Reader
+	Target  net.Destination
+	Connect bool
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (r *PacketReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	dest := r.Target
+	if !r.Connect {
+		buffer := buf.StackNew()
+		addr, port, err := packetAddrParser.ReadAddressPort(&buffer, r.Reader)
+		buffer.Release()
+		if err != nil {
+			return nil, err
+		}
+		dest = net.UDPDestination(addr, port)
+	}
+
+	var lengthBuf [2]byte
+	if _, err := io.ReadFull(r.Reader, lengthBuf[:]); err != nil {
+		return nil, errors.New("failed to read packet length").Base(err)
+	}
+	length := int32(binary.BigEndian.Uint16(lengthBuf[:]))
+	if length > buf.Size {
+		// A buffer carries one packet, which can't be larger.
+		if _, err := io.CopyN(io.Discard, r.Reader, int64(length)); err != nil {
+			return nil, errors.New("failed to read packet payload").Base(err)
+		}
+		return nil, nil
+	}
+	b := buf.New()
+	if _, err := b.ReadFullFrom(r.Reader, length); err != nil {
+		b.Release()
+		return nil, errors.New("failed to read packet payload").Base(err)
+	}
+	b.UDP = &dest
+	return buf.MultiBuffer{b}, nil
+}
@@ -0,0 +1,61 @@
+package anytls_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+	. "github.com/xtls/xray-core/proxy/anytls"
+)
+
+func TestTCPRequest(t *testing.T) {
+	var buffer bytes.Buffer
+	destination := net.TCPDestination(net.DomainAddress("example.com"), 443)
+	common.Must(WriteRequest(&buffer, destination))
+
+	dest, connect, err := ReadRequest(&buffer)
+	common.Must(err)
+	if r := cmp.Diff(dest, destination); r != "" {
+		t.Error("destination: ", r)
+	}
+	if connect {
+		t.Error("unexpected connect")
+	}
+}
+
+func TestUDPRequest(t *testing.T) {
+	var buffer bytes.Buffer
+	destination := net.UDPDestination(net.LocalHostIP, 53)
+	common.Must(WriteRequest(&buffer, destination))
+
+	dest, connect, err := ReadRequest(&buffer)
+	common.Must(err)
+	if r := cmp.Diff(dest, destination); r != "" {
+		t.Error("destination: ", r)
+	}
+	if connect {
+		t.Error("unexpected connect")
+	}
+
+	payload := []byte("test string")
+	target := net.UDPDestination(net.IPAddress([]byte{8, 8, 8, 8}), 53)
+	data := buf.New()
+	common.Must2(data.Write(payload))
+	data.UDP = &target
+
+	writer := &PacketWriter{Writer: &buffer, Target: destination}
+	common.Must(writer.WriteMultiBuffer(buf.MultiBuffer{data}))
+
+	reader := &PacketReader{Reader: &buffer, Target: destination}
+	mb, err := reader.ReadMultiBuffer()
+	common.Must(err)
+	if r := cmp.Diff(mb[0].Bytes(), payload); r != "" {
+		t.Error("data: ", r)
+	}
+	if r := cmp.Diff(*mb[0].UDP, target); r != "" {
+		t.Error("packet destination: ", r)
+	}
+}
@@ -0,0 +1,181 @@
+package anytls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/log"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/policy"
+	"github.com/xtls/xray-core/features/routing"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet/stat"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles messages in AnyTLS
+// protocol.
+type Server struct {
+	policyManager policy.Manager
+	validator     *Validator
+	padding       *PaddingScheme
+}
+
+// NewServer creates a new AnyTLS inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	validator := new(Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, errors.New("failed to get anytls user").Base(err).AtError()
+		}
+
+		if err := validator.Add(u); err != nil {
+			return nil, errors.New("failed to add user").Base(err).AtError()
+		}
+	}
+
+	padding := defaultPaddingScheme
+	if config.PaddingScheme != "" {
+		var err error
+		padding, err = ParsePaddingScheme(config.PaddingScheme)
+		if err != nil {
+			return nil, errors.New("invalid padding scheme").Base(err).AtError()
+		}
+	}
+
+	v := core.MustFromContext(ctx)
+	return &Server{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     validator,
+		padding:       padding,
+	}, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// GetUser implements proxy.UserManager.GetUser().
+func (s *Server) GetUser(ctx context.Context, email string) *protocol.MemoryUser {
+	return s.validator.GetByEmail(email)
+}
+
+// GetUsers implements proxy.UserManager.GetUsers().
+func (s *Server) GetUsers(ctx context.Context) []*protocol.MemoryUser {
+	return s.validator.GetAll()
+}
+
+// GetUsersCount implements proxy.UserManager.GetUsersCount().
+func (s *Server) GetUsersCount(context.Context) int64 {
+	return s.validator.GetCount()
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP, net.Network_UNIX}
+}
+
+// Process implements proxy.Inbound.Process().
+func (s *Server) Process(ctx context.Context, network net.Network, conn stat.Connection, dispatcher routing.Dispatcher) error {
+	inbound := session.InboundFromContext(ctx)
+	inbound.Name = "anytls"
+	inbound.CanSpliceCopy = 3
+
+	sessionPolicy := s.policyManager.ForLevel(0)
+	if err := conn.SetReadDeadline(time.Now().Add(sessionPolicy.Timeouts.Handshake)); err != nil {
+		return errors.New("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	var auth [sha256.Size + 2]byte
+	if _, err := io.ReadFull(conn, auth[:]); err != nil {
+		return errors.New("failed to read authentication").Base(err)
+	}
+	user := s.validator.Get([sha256.Size]byte(auth[:sha256.Size]))
+	if user == nil {
+		log.Record(&log.AccessMessage{
+			From:   conn.RemoteAddr(),
+			To:     "",
+			Status: log.AccessRejected,
+			Reason: errors.New("invalid user"),
+		})
+		return errors.New("invalid user from ", conn.RemoteAddr()).AtWarning()
+	}
+	if _, err := io.CopyN(io.Discard, conn, int64(binary.BigEndian.Uint16(auth[sha256.Size:]))); err != nil {
+		return errors.New("failed to read padding").Base(err)
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return errors.New("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	inbound.User = user
+
+	sess := newSession(conn, false, s.padding)
+	sess.onNewStream = func(stream *Stream) {
+		if err := s.handleStream(ctx, stream, dispatcher); err != nil {
+			errors.LogInfoInner(ctx, err, "stream ends")
+		}
+	}
+	if err := sess.Run(ctx); err != nil && errors.Cause(err) != io.EOF {
+		return errors.New("session ends").Base(err)
+	}
+	return nil
+}
+
+func (s *Server) handleStream(ctx context.Context, stream *Stream, dispatcher routing.Dispatcher) error {
+	defer stream.Close()
+
+	ctx = session.SubContextFromMuxInbound(ctx)
+	inbound := session.InboundFromContext(ctx)
+
+	reader := &buf.BufferedReader{Reader: stream}
+	dest, connect, err := ReadRequest(reader)
+	if err != nil {
+		stream.synack(err)
+		return errors.New("failed to read request").Base(err)
+	}
+
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     dest,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  inbound.User.Email,
+	})
+	errors.LogInfo(ctx, "tunnelling request to ", dest)
+
+	if err := stream.synack(nil); err != nil {
+		return err
+	}
+
+	link := &transport.Link{
+		Reader: reader,
+		Writer: stream,
+	}
+	if dest.Network == net.Network_UDP {
+		link.Reader = &PacketReader{Reader: reader, Target: dest, Connect: connect}
+		link.Writer = &PacketWriter{Writer: stream, Target: dest, Connect: connect}
+	}
+	return dispatcher.DispatchLink(ctx, dest, link)
+}
@@ -0,0 +1,445 @@
+package anytls
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/signal/done"
+	"github.com/xtls/xray-core/transport/pipe"
+)
+
+// Frame format:
+// Command (byte)
+// Stream ID (uint32 BE)
+// Data length (uint16 BE)
+// Data...
+
+const (
+	cmdWaste               = 0
+	cmdSYN                 = 1
+	cmdPSH                 = 2
+	cmdFIN                 = 3
+	cmdSettings            = 4
+	cmdAlert               = 5
+	cmdUpdatePaddingScheme = 6
+	cmdSYNACK              = 7
+	cmdHeartRequest        = 8
+	cmdHeartResponse       = 9
+	cmdServerSettings      = 10
+
+	headerSize   = 1 + 4 + 2
+	maxFrameSize = 0xFFFF
+
+	// streamBufferSize is the most bytes buffered for a stream, beyond which
+	// the session stops reading.
+	streamBufferSize = 512 * 1024
+)
+
+// Session multiplexes the streams in a connection. Only the client pads the
+// first packets it writes.
+type Session struct {
+	conn   net.Conn
+	client bool
+
+	writeMutex sync.Mutex
+	// buffering holds the frames of the client until its first data frame,
+	// which are sent in one packet.
+	buffering bool
+	buffer    []byte
+	pkt       uint32
+	padding   *PaddingScheme
+
+	streamsMutex sync.Mutex
+	streams      map[uint32]*Stream
+	nextID       uint32
+
+	peerVersion atomic.Int32
+	done        *done.Instance
+
+	// onNewStream serves the streams opened by the client.
+	onNewStream func(*Stream)
+	// onPaddingScheme updates the padding scheme of the client.
+	onPaddingScheme func(*PaddingScheme)
+	// onStreamClose lets the client reuse the session.
+	onStreamClose func(*Session)
+	// idleSince is when the last stream of the client closed.
+	idleSince time.Time
+}
+
+func newSession(conn net.Conn, client bool, padding *PaddingScheme) *Session {
+	return &Session{
+		conn:      conn,
+		client:    client,
+		buffering: client,
+		padding:   padding,
+		streams:   make(map[uint32]*Stream),
+		done:      done.New(),
+	}
+}
+
+// IsClosed returns whether the session has been closed.
+func (s *Session) IsClosed() bool {
+	return s.done.Done()
+}
+
+func (s *Session) Close() error {
+	if s.done.Done() {
+		return nil
+	}
+	s.done.Close()
+
+	s.streamsMutex.Lock()
+	streams := s.streams
+	s.streams = make(map[uint32]*Stream)
+	s.streamsMutex.Unlock()
+	for _, stream := range streams {
+		stream.closeRemote(io.ErrClosedPipe)
+	}
+	return s.conn.Close()
+}
+
+// writeConn writes the packet, which the client splits into records of the
+// sizes in the padding scheme.
+func (s *Session) writeConn(b []byte) error {
+	if !s.client || s.pkt >= s.padding.Stop() {
+		_, err := s.conn.Write(b)
+		return err
+	}
+
+	s.pkt++
+	if s.pkt >= s.padding.Stop() {
+		_, err := s.conn.Write(b)
+		return err
+	}
+	for _, l := range s.padding.RecordSizes(s.pkt) {
+		if l == CheckMark {
+			if len(b) == 0 {
+				break
+			}
+			continue
+		}
+		switch {
+		case len(b) > l:
+			if _, err := s.conn.Write(b[:l]); err != nil {
+				return err
+			}
+			b = b[l:]
+		case len(b) > 0:
+			record := b
+			if l := l - len(b) - headerSize; l > 0 {
+				record = append(record, wasteFrame(l)...)
+			}
+			if _, err := s.conn.Write(record); err != nil {
+				return err
+			}
+			b = nil
+		default:
+			if _, err := s.conn.Write(wasteFrame(l)); err != nil {
+				return err
+			}
+		}
+	}
+	if len(b) > 0 {
+		_, err := s.conn.Write(b)
+		return err
+	}
+	return nil
+}
+
+func wasteFrame(l int) []byte {
+	b := make([]byte, headerSize+l)
+	b[0] = cmdWaste
+	binary.BigEndian.PutUint16(b[5:], uint16(l))
+	return b
+}
+
+// writeFrame writes the frame, or buffers it until a data frame if flush is
+// false.
+func (s *Session) writeFrame(cmd byte, id uint32, data []byte, flush bool) error {
+	if len(data) > maxFrameSize {
+		return errors.New("frame too large")
+	}
+	b := make([]byte, headerSize, headerSize+len(data))
+	b[0] = cmd
+	binary.BigEndian.PutUint32(b[1:], id)
+	binary.BigEndian.PutUint16(b[5:], uint16(len(data)))
+	b = append(b, data...)
+
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	if s.done.Done() {
+		return io.ErrClosedPipe
+	}
+	if s.buffering {
+		s.buffer = append(s.buffer, b...)
+		if !flush {
+			return nil
+		}
+		b = s.buffer
+		s.buffer = nil
+		s.buffering = false
+	}
+	if err := s.writeConn(b); err != nil {
+		s.Close()
+		return err
+	}
+	return nil
+}
+
+// sendSettings sends the settings of the client, with the MD5 of its padding
+// scheme, which the server updates if it differs.
+func (s *Session) sendSettings(client string) error {
+	settings := "v=2\nclient=" + client + "\npadding-md5=" + s.padding.MD5()
+	return s.writeFrame(cmdSettings, 0, []byte(settings), false)
+}
+
+// OpenStream opens a stream of the client.
+func (s *Session) OpenStream() (*Stream, error) {
+	s.streamsMutex.Lock()
+	if s.done.Done() {
+		s.streamsMutex.Unlock()
+		return nil, io.ErrClosedPipe
+	}
+	s.nextID++
+	stream := newStream(s, s.nextID)
+	s.streams[stream.id] = stream
+	s.streamsMutex.Unlock()
+
+	if err := s.writeFrame(cmdSYN, stream.id, nil, false); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *Session) removeStream(id uint32) {
+	s.streamsMutex.Lock()
+	delete(s.streams, id)
+	s.streamsMutex.Unlock()
+}
+
+func (s *Session) getStream(id uint32) *Stream {
+	s.streamsMutex.Lock()
+	defer s.streamsMutex.Unlock()
+	return s.streams[id]
+}
+
+func parseSettings(b []byte) map[string]string {
+	settings := make(map[string]string)
+	for _, line := range strings.Split(string(b), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// Run reads the frames until the session closes.
+func (s *Session) Run(ctx context.Context) error {
+	defer s.Close()
+
+	var header [headerSize]byte
+	for {
+		if _, err := io.ReadFull(s.conn, header[:]); err != nil {
+			return err
+		}
+		cmd := header[0]
+		id := binary.BigEndian.Uint32(header[1:])
+		length := int32(binary.BigEndian.Uint16(header[5:]))
+
+		if cmd == cmdPSH {
+			stream := s.getStream(id)
+			if stream == nil {
+				if _, err := io.CopyN(io.Discard, s.conn, int64(length)); err != nil {
+					return err
+				}
+				continue
+			}
+			for length > 0 {
+				b := buf.New()
+				n := min(length, buf.Size)
+				if _, err := b.ReadFullFrom(s.conn, n); err != nil {
+					b.Release()
+					return err
+				}
+				length -= n
+				if err := stream.writer.WriteMultiBuffer(buf.MultiBuffer{b}); err != nil {
+					continue
+				}
+			}
+			continue
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(s.conn, data); err != nil {
+			return err
+		}
+
+		switch cmd {
+		case cmdSYN:
+			if s.client || s.onNewStream == nil {
+				continue
+			}
+			s.streamsMutex.Lock()
+			if s.streams[id] != nil || s.done.Done() {
+				s.streamsMutex.Unlock()
+				continue
+			}
+			stream := newStream(s, id)
+			s.streams[id] = stream
+			s.streamsMutex.Unlock()
+			go s.onNewStream(stream)
+		case cmdSYNACK:
+			if stream := s.getStream(id); stream != nil && len(data) > 0 {
+				stream.closeRemote(errors.New("remote error: ", string(data)))
+			}
+		case cmdFIN:
+			if stream := s.getStream(id); stream != nil {
+				s.removeStream(id)
+				stream.closeRemote(nil)
+			}
+		case cmdSettings:
+			if s.client {
+				continue
+			}
+			settings := parseSettings(data)
+			if settings["padding-md5"] != s.padding.MD5() {
+				if err := s.writeFrame(cmdUpdatePaddingScheme, 0, s.padding.Raw(), true); err != nil {
+					return err
+				}
+			}
+			if v, _ := strconv.Atoi(settings["v"]); v >= 2 {
+				s.peerVersion.Store(int32(v))
+				if err := s.writeFrame(cmdServerSettings, 0, []byte("v=2"), true); err != nil {
+					return err
+				}
+			}
+		case cmdServerSettings:
+			if v, _ := strconv.Atoi(parseSettings(data)["v"]); v > 0 {
+				s.peerVersion.Store(int32(v))
+			}
+		case cmdUpdatePaddingScheme:
+			if !s.client || s.onPaddingScheme == nil {
+				continue
+			}
+			padding, err := ParsePaddingScheme(string(data))
+			if err != nil {
+				errors.LogInfoInner(ctx, err, "anytls: invalid padding scheme from the server")
+				continue
+			}
+			s.onPaddingScheme(padding)
+		case cmdAlert:
+			return errors.New("alert: ", string(data))
+		case cmdHeartRequest:
+			if err := s.writeFrame(cmdHeartResponse, id, nil, true); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Stream is a connection multiplexed in a session.
+type Stream struct {
+	id      uint32
+	session *Session
+	reader  *pipe.Reader
+	writer  *pipe.Writer
+
+	err       atomic.Pointer[error]
+	closeOnce sync.Once
+}
+
+func newStream(s *Session, id uint32) *Stream {
+	reader, writer := pipe.New(pipe.WithSizeLimit(streamBufferSize))
+	return &Stream{
+		id:      id,
+		session: s,
+		reader:  reader,
+		writer:  writer,
+	}
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (s *Stream) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := s.reader.ReadMultiBuffer()
+	if err == io.EOF {
+		if e := s.err.Load(); e != nil {
+			return nil, *e
+		}
+	}
+	return mb, err
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (s *Stream) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, b := range mb {
+		if _, err := s.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Stream) Write(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		l := min(len(b), maxFrameSize)
+		if err := s.session.writeFrame(cmdPSH, s.id, b[:l], true); err != nil {
+			return n, err
+		}
+		n += l
+		b = b[l:]
+	}
+	return n, nil
+}
+
+// synack replies the result of the connection to the client, whose version
+// is 2 or later.
+func (s *Stream) synack(err error) error {
+	if s.session.peerVersion.Load() < 2 {
+		return nil
+	}
+	var data []byte
+	if err != nil {
+		data = []byte(err.Error())
+		if len(data) > maxFrameSize {
+			data = data[:maxFrameSize]
+		}
+	}
+	return s.session.writeFrame(cmdSYNACK, s.id, data, true)
+}
+
+// closeRemote closes the stream that the peer closed.
+func (s *Stream) closeRemote(err error) {
+	if err != nil {
+		s.err.CompareAndSwap(nil, &err)
+	}
+	s.writer.Close()
+}
+
+// Close closes the stream, and tells the peer if the session is open.
+func (s *Stream) Close() error {
+	s.closeOnce.Do(func() {
+		s.writer.Close()
+		s.reader.Interrupt()
+		if s.session.getStream(s.id) == s {
+			s.session.removeStream(s.id)
+			s.session.writeFrame(cmdFIN, s.id, nil, true)
+		}
+		if s.session.onStreamClose != nil {
+			s.session.onStreamClose(s.session)
+		}
+	})
+	return nil
+}
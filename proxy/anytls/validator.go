@@ -0,0 +1,82 @@
+package anytls
+
+import (
+	"crypto/sha256"
+	"strings"
+	"sync"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+)
+
+// Validator stores valid AnyTLS users by the hash of their passwords.
+type Validator struct {
+	email sync.Map
+	users sync.Map
+}
+
+// Add an AnyTLS user, Email must be empty or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	if u.Email != "" {
+		_, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u)
+		if loaded {
+			return errors.New("User ", u.Email, " already exists.")
+		}
+	}
+	v.users.Store(u.Account.(*MemoryAccount).Key, u)
+	return nil
+}
+
+// Del an AnyTLS user with a non-empty Email.
+func (v *Validator) Del(e string) error {
+	if e == "" {
+		return errors.New("Email must not be empty.")
+	}
+	le := strings.ToLower(e)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return errors.New("User ", e, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(u.(*protocol.MemoryUser).Account.(*MemoryAccount).Key)
+	return nil
+}
+
+// Get an AnyTLS user with the hash of the password, nil if user doesn't
+// exist.
+func (v *Validator) Get(key [sha256.Size]byte) *protocol.MemoryUser {
+	u, _ := v.users.Load(key)
+	if u != nil {
+		return u.(*protocol.MemoryUser)
+	}
+	return nil
+}
+
+// GetByEmail gets an AnyTLS user with the Email, nil if user doesn't exist.
+func (v *Validator) GetByEmail(email string) *protocol.MemoryUser {
+	u, _ := v.email.Load(strings.ToLower(email))
+	if u != nil {
+		return u.(*protocol.MemoryUser)
+	}
+	return nil
+}
+
+// GetAll gets all users.
+func (v *Validator) GetAll() []*protocol.MemoryUser {
+	var u []*protocol.MemoryUser
+	v.users.Range(func(key, value interface{}) bool {
+		u = append(u, value.(*protocol.MemoryUser))
+		return true
+	})
+	return u
+}
+
+// GetCount gets the count of users.
+func (v *Validator) GetCount() int64 {
+	var c int64
+	v.users.Range(func(key, value interface{}) bool {
+		c++
+		return true
+	})
+	return c
+}
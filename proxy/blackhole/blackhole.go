@@ -37,6 +37,12 @@ func (h *Handler) Process(ctx context.Context, link *transport.Link, dialer inte
 	ob := outbounds[len(outbounds)-1]
 	ob.Name = "blackhole"
 
+	if responder, ok := h.response.(Responder); ok && responder.Respond(ctx, link) {
+		common.Interrupt(link.Writer)
+		common.Interrupt(link.Reader)
+		return nil
+	}
+
 	nBytes := h.response.WriteTo(link.Writer)
 	if nBytes > 0 {
 		// Sleep a little here to make sure the response is sent to client.
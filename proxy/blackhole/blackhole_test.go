@@ -2,10 +2,13 @@ package blackhole_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/xtls/xray-core/common"
 	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/proxy/blackhole"
@@ -40,3 +43,75 @@ func TestBlackholeHTTPResponse(t *testing.T) {
 		t.Error("expect http response, but nothing")
 	}
 }
+
+func TestBlackholeTLSAlert(t *testing.T) {
+	ctx := session.ContextWithOutbounds(context.Background(), []*session.Outbound{{}})
+	ctx = session.ContextWithContent(ctx, &session.Content{Protocol: "tls"})
+	handler, err := blackhole.New(ctx, &blackhole.Config{
+		Response: serial.ToTypedMessage(&blackhole.TLSAlertResponse{}),
+	})
+	common.Must(err)
+
+	reader, writer := pipe.New(pipe.WithoutSizeLimit())
+	alert := make(chan buf.MultiBuffer, 1)
+	go func() {
+		mb, _ := reader.ReadMultiBuffer()
+		alert <- mb
+	}()
+	common.Must(handler.Process(ctx, &transport.Link{Reader: reader, Writer: writer}, nil))
+	if b := (<-alert).String(); b != "\x15\x03\x03\x00\x02\x02\x28" {
+		t.Errorf("unexpected alert %x", b)
+	}
+}
+
+func TestBlackholeReset(t *testing.T) {
+	rejected := false
+	ctx := session.ContextWithOutbounds(context.Background(), []*session.Outbound{{Target: net.UDPDestination(net.LocalHostIP, 53)}})
+	ctx = session.ContextWithRejecter(ctx, func() error {
+		rejected = true
+		return nil
+	})
+	handler, err := blackhole.New(ctx, &blackhole.Config{
+		Response: serial.ToTypedMessage(&blackhole.ResetResponse{}),
+	})
+	common.Must(err)
+
+	reader, writer := pipe.New(pipe.WithoutSizeLimit())
+	common.Must(handler.Process(ctx, &transport.Link{Reader: reader, Writer: writer}, nil))
+	if !rejected {
+		t.Error("connection is not rejected")
+	}
+}
+
+func TestBlackholeTarpit(t *testing.T) {
+	ctx := session.ContextWithOutbounds(context.Background(), []*session.Outbound{{Target: net.TCPDestination(net.LocalHostIP, 80)}})
+	handler, err := blackhole.New(ctx, &blackhole.Config{
+		Response: serial.ToTypedMessage(&blackhole.TarpitResponse{Interval: 1, Timeout: 3}),
+	})
+	common.Must(err)
+
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	defer uplinkWriter.Close()
+
+	var n atomic.Int32
+	go func() {
+		for {
+			mb, err := downlinkReader.ReadMultiBuffer()
+			n.Add(mb.Len())
+			buf.ReleaseMulti(mb)
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	common.Must(handler.Process(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, nil))
+	if elapsed := time.Since(start); elapsed < 3*time.Second {
+		t.Error("tarpit ended after ", elapsed)
+	}
+	if n := n.Load(); n < 2 || n > 3 {
+		t.Error("expected 2 or 3 bytes, but got ", n)
+	}
+}
@@ -1,8 +1,23 @@
 package blackhole
 
 import (
+	"context"
+	"crypto/rand"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/xtls/xray-core/common"
 	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/proxy"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet/stat"
 )
 
 const (
@@ -24,15 +39,147 @@ type ResponseConfig interface {
 // WriteTo implements ResponseConfig.WriteTo().
 func (*NoneResponse) WriteTo(buf.Writer) int32 { return 0 }
 
+// Responder is a response that handles the connection by itself.
+type Responder interface {
+	// Respond reports whether it handled the connection. Otherwise the
+	// connection is blackholed as with NoneResponse.
+	Respond(ctx context.Context, link *transport.Link) bool
+}
+
 // WriteTo implements ResponseConfig.WriteTo().
-func (*HTTPResponse) WriteTo(writer buf.Writer) int32 {
-	b := buf.New()
-	common.Must2(b.WriteString(http403response))
-	n := b.Len()
-	writer.WriteMultiBuffer(buf.MultiBuffer{b})
+func (r *HTTPResponse) WriteTo(writer buf.Writer) int32 {
+	var response string
+	if r.Status == 0 && len(r.Header) == 0 && r.Body == "" {
+		response = http403response
+	} else {
+		response = r.build()
+	}
+	mb := buf.MergeBytes(nil, []byte(response))
+	n := mb.Len()
+	writer.WriteMultiBuffer(mb)
 	return n
 }
 
+func (r *HTTPResponse) build() string {
+	status := int(r.Status)
+	if status == 0 {
+		status = http.StatusForbidden
+	}
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 " + strconv.Itoa(status) + " " + http.StatusText(status) + "\r\n")
+	b.WriteString("Connection: close\r\n")
+	b.WriteString("Content-Length: " + strconv.Itoa(len(r.Body)) + "\r\n")
+	for _, key := range slices.Sorted(maps.Keys(r.Header)) {
+		switch http.CanonicalHeaderKey(key) {
+		case "Connection", "Content-Length":
+			continue
+		}
+		b.WriteString(key + ": " + r.Header[key] + "\r\n")
+	}
+	b.WriteString("\r\n")
+	b.WriteString(r.Body)
+	return b.String()
+}
+
+// WriteTo implements ResponseConfig.WriteTo().
+func (*ResetResponse) WriteTo(buf.Writer) int32 { return 0 }
+
+// Respond implements Responder.
+func (*ResetResponse) Respond(ctx context.Context, link *transport.Link) bool {
+	if reject := session.RejecterFromContext(ctx); reject != nil {
+		if err := reject(); err != nil {
+			errors.LogInfoInner(ctx, err, "failed to reject connection")
+			return false
+		}
+		return true
+	}
+
+	// The inbound connection may only be reset if it is not shared with
+	// other requests, such as by mux.
+	outbounds := session.OutboundsFromContext(ctx)
+	inbound := session.InboundFromContext(ctx)
+	if outbounds[len(outbounds)-1].Target.Network != net.Network_TCP || inbound == nil || inbound.Conn == nil || inbound.CanSpliceCopy == 3 {
+		return false
+	}
+	if !proxy.IsRAWTransportWithoutSecurity(inbound.Conn) {
+		return false
+	}
+	tcpConn, ok := stat.TryUnwrapStatsConn(inbound.Conn).(*net.TCPConn)
+	if !ok {
+		return false
+	}
+	// Closing with a zero linger sends RST instead of FIN.
+	if err := tcpConn.SetLinger(0); err != nil {
+		return false
+	}
+	tcpConn.Close()
+	return true
+}
+
+// tlsHandshakeFailure is a fatal handshake_failure alert record.
+var tlsHandshakeFailure = []byte{21, 3, 3, 0, 2, 2, 40}
+
+// WriteTo implements ResponseConfig.WriteTo().
+func (*TLSAlertResponse) WriteTo(buf.Writer) int32 { return 0 }
+
+// Respond implements Responder.
+func (*TLSAlertResponse) Respond(ctx context.Context, link *transport.Link) bool {
+	content := session.ContentFromContext(ctx)
+	if content == nil || content.Protocol != "tls" {
+		return false
+	}
+	link.Writer.WriteMultiBuffer(buf.MergeBytes(nil, tlsHandshakeFailure))
+	// Sleep a little here to make sure the alert is sent to client.
+	time.Sleep(time.Second)
+	return true
+}
+
+// WriteTo implements ResponseConfig.WriteTo().
+func (*TarpitResponse) WriteTo(buf.Writer) int32 { return 0 }
+
+// Respond implements Responder.
+func (r *TarpitResponse) Respond(ctx context.Context, link *transport.Link) bool {
+	outbounds := session.OutboundsFromContext(ctx)
+	if outbounds[len(outbounds)-1].Target.Network != net.Network_TCP {
+		return false
+	}
+
+	interval := time.Duration(r.Interval) * time.Second
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(r.Timeout)*time.Second)
+		defer cancel()
+	}
+	// The client hanging up ends the tarpit.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		buf.Copy(link.Reader, buf.Discard)
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-ticker.C:
+		}
+		// A random printable byte, which keeps HTTP clients waiting for the
+		// rest of a header line.
+		var b [1]byte
+		common.Must2(rand.Read(b[:]))
+		b[0] = 'a' + b[0]%26
+		if err := link.Writer.WriteMultiBuffer(buf.MergeBytes(nil, b[:])); err != nil {
+			return true
+		}
+	}
+}
+
 // GetInternalResponse converts response settings from proto to internal data structure.
 func (c *Config) GetInternalResponse() (ResponseConfig, error) {
 	if c.GetResponse() == nil {
@@ -2,6 +2,7 @@ package blackhole_test
 
 import (
 	"bufio"
+	"io"
 	"net/http"
 	"testing"
 
@@ -24,3 +25,28 @@ func TestHTTPResponse(t *testing.T) {
 		t.Error("expected status code 403, but got ", response.StatusCode)
 	}
 }
+
+func TestCustomHTTPResponse(t *testing.T) {
+	buffer := buf.New()
+
+	httpResponse := &HTTPResponse{
+		Status: 451,
+		Header: map[string]string{"Content-Type": "text/plain"},
+		Body:   "blocked",
+	}
+	httpResponse.WriteTo(buf.NewWriter(buffer))
+
+	response, err := http.ReadResponse(bufio.NewReader(buffer), nil)
+	common.Must(err)
+	if response.StatusCode != 451 {
+		t.Error("expected status code 451, but got ", response.StatusCode)
+	}
+	if v := response.Header.Get("Content-Type"); v != "text/plain" {
+		t.Error("unexpected Content-Type ", v)
+	}
+	body, err := io.ReadAll(response.Body)
+	common.Must(err)
+	if string(body) != "blocked" {
+		t.Error("unexpected body ", string(body))
+	}
+}
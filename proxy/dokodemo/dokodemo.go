@@ -150,6 +150,14 @@ func (d *DokodemoDoor) Process(ctx context.Context, network net.Network, conn st
 	})
 	errors.LogInfo(ctx, "received request for ", conn.RemoteAddr())
 
+	// Rejecting a UDP flow quotes the destination the client sent to, which
+	// is only known with TPROXY.
+	if dest.Network == net.Network_TCP || destinationOverridden {
+		if r := rejecter(conn, conn.RemoteAddr(), dest); r != nil {
+			ctx = session.ContextWithRejecter(ctx, r)
+		}
+	}
+
 	var reader buf.Reader
 	if dest.Network == net.Network_TCP {
 		reader = buf.NewReader(conn)
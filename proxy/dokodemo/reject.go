@@ -0,0 +1,100 @@
+package dokodemo
+
+import (
+	"encoding/binary"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/transport/internet/stat"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// rejecter returns how to reject a connection from src to dest the way the
+// network does, or nil if it cannot be rejected.
+func rejecter(conn stat.Connection, src net.Addr, dest net.Destination) session.Rejecter {
+	switch dest.Network {
+	case net.Network_TCP:
+		tcpConn, ok := stat.TryUnwrapStatsConn(conn).(*net.TCPConn)
+		if !ok {
+			return nil
+		}
+		return func() error {
+			// Closing with a zero linger sends RST instead of FIN.
+			if err := tcpConn.SetLinger(0); err != nil {
+				return err
+			}
+			return tcpConn.Close()
+		}
+	case net.Network_UDP:
+		back, ok := src.(*net.UDPAddr)
+		if !ok || !dest.Address.Family().IsIP() {
+			return nil
+		}
+		return func() error {
+			return writePortUnreachable(back, &net.UDPAddr{IP: dest.Address.IP(), Port: int(dest.Port)})
+		}
+	}
+	return nil
+}
+
+// writePortUnreachable sends an ICMP port unreachable for a datagram from src
+// to dst back to src. It requires the privilege to open raw sockets.
+func writePortUnreachable(src, dst *net.UDPAddr) error {
+	udpHeader := make([]byte, 8)
+	binary.BigEndian.PutUint16(udpHeader, uint16(src.Port))
+	binary.BigEndian.PutUint16(udpHeader[2:], uint16(dst.Port))
+	binary.BigEndian.PutUint16(udpHeader[4:], 8)
+
+	var network, address string
+	var message icmp.Message
+	if ip4 := src.IP.To4(); ip4 != nil {
+		ipHeader, err := (&ipv4.Header{
+			Version:  ipv4.Version,
+			Len:      ipv4.HeaderLen,
+			TotalLen: ipv4.HeaderLen + 8,
+			TTL:      64,
+			Protocol: 17,
+			Src:      ip4,
+			Dst:      dst.IP.To4(),
+		}).Marshal()
+		if err != nil {
+			return err
+		}
+		network, address = "ip4:icmp", "0.0.0.0"
+		message = icmp.Message{
+			Type: ipv4.ICMPTypeDestinationUnreachable,
+			Code: 3,
+			Body: &icmp.DstUnreach{Data: append(ipHeader, udpHeader...)},
+		}
+	} else {
+		ipHeader := make([]byte, ipv6.HeaderLen)
+		ipHeader[0] = 6 << 4
+		binary.BigEndian.PutUint16(ipHeader[4:], 8)
+		ipHeader[6] = 17
+		ipHeader[7] = 64
+		copy(ipHeader[8:], src.IP.To16())
+		copy(ipHeader[24:], dst.IP.To16())
+		network, address = "ip6:ipv6-icmp", "::"
+		message = icmp.Message{
+			Type: ipv6.ICMPTypeDestinationUnreachable,
+			Code: 4,
+			Body: &icmp.DstUnreach{Data: append(ipHeader, udpHeader...)},
+		}
+	}
+
+	// The kernel computes the checksum of ICMPv6.
+	b, err := message.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return errors.New("failed to open raw socket").Base(err)
+	}
+	defer conn.Close()
+	_, err = conn.WriteTo(b, &net.IPAddr{IP: src.IP})
+	return err
+}
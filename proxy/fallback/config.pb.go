@@ -0,0 +1,203 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: proxy/fallback/config.proto
+
+package fallback
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Destination is one of the servers a fallback forwards to.
+type Destination struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Dest  string                 `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`
+	// Relative share of the connections. 0 means 1.
+	Weight        uint32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Destination) Reset() {
+	*x = Destination{}
+	mi := &file_proxy_fallback_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Destination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Destination) ProtoMessage() {}
+
+func (x *Destination) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_fallback_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Destination.ProtoReflect.Descriptor instead.
+func (*Destination) Descriptor() ([]byte, []int) {
+	return file_proxy_fallback_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Destination) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Destination) GetDest() string {
+	if x != nil {
+		return x.Dest
+	}
+	return ""
+}
+
+func (x *Destination) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// HealthCheck probes the destinations of a fallback by connecting to them.
+type HealthCheck struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In seconds. 0 disables probing, failed destinations are then only retried
+	// after a while.
+	Interval uint32 `protobuf:"varint,1,opt,name=interval,proto3" json:"interval,omitempty"`
+	// In seconds.
+	Timeout       uint32 `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheck) Reset() {
+	*x = HealthCheck{}
+	mi := &file_proxy_fallback_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheck) ProtoMessage() {}
+
+func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_fallback_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return file_proxy_fallback_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HealthCheck) GetInterval() uint32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetTimeout() uint32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+var File_proxy_fallback_config_proto protoreflect.FileDescriptor
+
+const file_proxy_fallback_config_proto_rawDesc = "" +
+	"\n" +
+	"\x1bproxy/fallback/config.proto\x12\x13xray.proxy.fallback\"M\n" +
+	"\vDestination\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04dest\x18\x02 \x01(\tR\x04dest\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\rR\x06weight\"C\n" +
+	"\vHealthCheck\x12\x1a\n" +
+	"\binterval\x18\x01 \x01(\rR\binterval\x12\x18\n" +
+	"\atimeout\x18\x02 \x01(\rR\atimeoutB[\n" +
+	"\x17com.xray.proxy.fallbackP\x01Z(github.com/xtls/xray-core/proxy/fallback\xaa\x02\x13Xray.Proxy.Fallbackb\x06proto3"
+
+var (
+	file_proxy_fallback_config_proto_rawDescOnce sync.Once
+	file_proxy_fallback_config_proto_rawDescData []byte
+)
+
+func file_proxy_fallback_config_proto_rawDescGZIP() []byte {
+	file_proxy_fallback_config_proto_rawDescOnce.Do(func() {
+		file_proxy_fallback_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proxy_fallback_config_proto_rawDesc), len(file_proxy_fallback_config_proto_rawDesc)))
+	})
+	return file_proxy_fallback_config_proto_rawDescData
+}
+
+var file_proxy_fallback_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proxy_fallback_config_proto_goTypes = []any{
+	(*Destination)(nil), // 0: xray.proxy.fallback.Destination
+	(*HealthCheck)(nil), // 1: xray.proxy.fallback.HealthCheck
+}
+var file_proxy_fallback_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proxy_fallback_config_proto_init() }
+func file_proxy_fallback_config_proto_init() {
+	if File_proxy_fallback_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_fallback_config_proto_rawDesc), len(file_proxy_fallback_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proxy_fallback_config_proto_goTypes,
+		DependencyIndexes: file_proxy_fallback_config_proto_depIdxs,
+		MessageInfos:      file_proxy_fallback_config_proto_msgTypes,
+	}.Build()
+	File_proxy_fallback_config_proto = out.File
+	file_proxy_fallback_config_proto_goTypes = nil
+	file_proxy_fallback_config_proto_depIdxs = nil
+}
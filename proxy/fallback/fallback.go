@@ -0,0 +1,184 @@
+// Package fallback implements the fallback matching and destinations shared by
+// the VLESS and Trojan inbounds.
+package fallback
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol/bittorrent"
+	"github.com/xtls/xray-core/common/protocol/http"
+	"github.com/xtls/xray-core/common/protocol/rdp"
+	"github.com/xtls/xray-core/common/protocol/ssh"
+	"github.com/xtls/xray-core/common/protocol/tls"
+	"github.com/xtls/xray-core/common/session"
+)
+
+// Config is the configuration of a fallback, implemented by the Fallback
+// messages of VLESS and Trojan.
+type Config interface {
+	GetName() string
+	GetAlpn() string
+	GetPath() string
+	GetType() string
+	GetDest() string
+	GetHost() string
+	GetPathRegexp() string
+	GetMethods() []string
+	GetProtocol() string
+	GetDests() []*Destination
+	GetHealthCheck() *HealthCheck
+}
+
+// IsAdvanced returns true if c matches on more than the name, alpn and path,
+// which the inbounds look up in their maps.
+func IsAdvanced(c Config) bool {
+	return c.GetHost() != "" || c.GetPathRegexp() != "" || len(c.GetMethods()) > 0 || c.GetProtocol() != ""
+}
+
+// Request is what is known about a connection that falls back.
+type Request struct {
+	// Name and Alpn are negotiated in the TLS handshake.
+	Name string
+	Alpn string
+	// Host, Method and Path are from the first HTTP request, without the port
+	// and the query.
+	Host   string
+	Method string
+	Path   string
+	// Protocol is the protocol sniffed from the first bytes, such as "http1",
+	// "http2", "tls" or "ssh".
+	Protocol string
+}
+
+type sniffer func(b []byte) (interface{ Protocol() string }, error)
+
+var sniffers = []sniffer{
+	func(b []byte) (interface{ Protocol() string }, error) { return tls.SniffTLS(b) },
+	func(b []byte) (interface{ Protocol() string }, error) { return ssh.SniffSSH(b) },
+	func(b []byte) (interface{ Protocol() string }, error) { return rdp.SniffRDP(b) },
+	func(b []byte) (interface{ Protocol() string }, error) { return bittorrent.SniffBittorrent(b) },
+}
+
+// Parse fills in the HTTP request and the protocol from the first bytes of a
+// connection.
+func (r *Request) Parse(b []byte) {
+	content := &session.Content{}
+	header, _ := http.SniffHTTP(b, session.ContextWithContent(context.Background(), content))
+	switch {
+	case header != nil:
+		r.Protocol = header.Protocol()
+		r.Host = header.Domain()
+	case bytes.HasPrefix(b, []byte("PRI * HTTP/2.0")):
+		r.Protocol = "http2"
+	case content.Attribute(":method") != "":
+		r.Protocol = "http1"
+	}
+	if r.Protocol != "" {
+		r.Method = strings.ToUpper(content.Attribute(":method"))
+		r.Path, _, _ = strings.Cut(content.Attribute(":path"), "?")
+		return
+	}
+	for _, sniff := range sniffers {
+		if header, err := sniff(b); err == nil {
+			r.Protocol = header.Protocol()
+			return
+		}
+	}
+}
+
+// Matcher matches requests against the conditions of a fallback.
+type Matcher struct {
+	name     string
+	alpn     string
+	path     string
+	host     string
+	regexp   *regexp.Regexp
+	methods  []string
+	protocol string
+}
+
+// NewMatcher creates a Matcher for the conditions of c.
+func NewMatcher(c Config) (*Matcher, error) {
+	m := &Matcher{
+		name:     strings.ToLower(c.GetName()),
+		alpn:     strings.ToLower(c.GetAlpn()),
+		path:     c.GetPath(),
+		host:     strings.ToLower(c.GetHost()),
+		protocol: strings.ToLower(c.GetProtocol()),
+	}
+	if c.GetPathRegexp() != "" {
+		r, err := regexp.Compile(c.GetPathRegexp())
+		if err != nil {
+			return nil, errors.New("invalid path regexp").Base(err)
+		}
+		m.regexp = r
+	}
+	for _, method := range c.GetMethods() {
+		m.methods = append(m.methods, strings.ToUpper(method))
+	}
+	return m, nil
+}
+
+// Match returns true if r meets all conditions of m.
+func (m *Matcher) Match(r *Request) bool {
+	if m.name != "" && !strings.Contains(r.Name, m.name) {
+		return false
+	}
+	if m.alpn != "" && m.alpn != r.Alpn {
+		return false
+	}
+	if m.path != "" && m.path != r.Path {
+		return false
+	}
+	if m.host != "" && !matchHost(m.host, r.Host) {
+		return false
+	}
+	if m.regexp != nil && (r.Path == "" || !m.regexp.MatchString(r.Path)) {
+		return false
+	}
+	if len(m.methods) > 0 && !contains(m.methods, r.Method) {
+		return false
+	}
+	if m.protocol != "" && m.protocol != r.Protocol && (m.protocol != "http" || !strings.HasPrefix(r.Protocol, "http")) {
+		return false
+	}
+	return true
+}
+
+// matchHost matches host against pattern, where "*.example.com" matches the
+// subdomains of example.com.
+func matchHost(pattern, host string) bool {
+	if suffix, found := strings.CutPrefix(pattern, "*"); found {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return pattern == host
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule is a fallback with advanced conditions.
+type Rule struct {
+	*Matcher
+	Config Config
+}
+
+// Match returns the config of the first rule that matches r, or nil.
+func Match(rules []*Rule, r *Request) Config {
+	for _, rule := range rules {
+		if rule.Match(r) {
+			return rule.Config
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package fallback_test
+
+import (
+	"testing"
+
+	. "github.com/xtls/xray-core/proxy/fallback"
+	"github.com/xtls/xray-core/proxy/trojan"
+)
+
+func TestRequestParse(t *testing.T) {
+	cases := []struct {
+		input   string
+		request Request
+	}{
+		{
+			input: "GET /api/v1?id=1 HTTP/1.1\r\nHost: www.example.com:8443\r\n\r\n",
+			request: Request{
+				Host:     "www.example.com",
+				Method:   "GET",
+				Path:     "/api/v1",
+				Protocol: "http1",
+			},
+		},
+		{
+			input: "post /upload HTTP/1.1\r\n\r\n",
+			request: Request{
+				Method:   "POST",
+				Path:     "/upload",
+				Protocol: "http1",
+			},
+		},
+		{
+			input:   "SSH-2.0-OpenSSH_9.6\r\n",
+			request: Request{Protocol: "ssh"},
+		},
+		{
+			input:   "\x00\x01\x02\x03",
+			request: Request{},
+		},
+	}
+	for _, c := range cases {
+		var r Request
+		r.Parse([]byte(c.input))
+		if r != c.request {
+			t.Errorf("%q: expected %+v, got %+v", c.input, c.request, r)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	cases := []struct {
+		fallback *trojan.Fallback
+		request  Request
+		match    bool
+	}{
+		{
+			fallback: &trojan.Fallback{Host: "*.example.com"},
+			request:  Request{Host: "www.example.com"},
+			match:    true,
+		},
+		{
+			fallback: &trojan.Fallback{Host: "*.example.com"},
+			request:  Request{Host: "example.com"},
+			match:    false,
+		},
+		{
+			fallback: &trojan.Fallback{Host: "Example.com"},
+			request:  Request{Host: "example.com"},
+			match:    true,
+		},
+		{
+			fallback: &trojan.Fallback{PathRegexp: "^/api/v[0-9]+/"},
+			request:  Request{Path: "/api/v2/users"},
+			match:    true,
+		},
+		{
+			fallback: &trojan.Fallback{PathRegexp: "^/api/"},
+			request:  Request{Path: "/static/app.js"},
+			match:    false,
+		},
+		{
+			fallback: &trojan.Fallback{Methods: []string{"get", "head"}},
+			request:  Request{Method: "HEAD"},
+			match:    true,
+		},
+		{
+			fallback: &trojan.Fallback{Methods: []string{"GET"}},
+			request:  Request{Method: "POST"},
+			match:    false,
+		},
+		{
+			fallback: &trojan.Fallback{Protocol: "http"},
+			request:  Request{Protocol: "http2"},
+			match:    true,
+		},
+		{
+			fallback: &trojan.Fallback{Protocol: "ssh"},
+			request:  Request{Protocol: "http1"},
+			match:    false,
+		},
+		{
+			fallback: &trojan.Fallback{Name: "example.com", Alpn: "h2", Host: "app.example.com"},
+			request:  Request{Name: "www.example.com", Alpn: "http/1.1", Host: "app.example.com"},
+			match:    false,
+		},
+	}
+	for i, c := range cases {
+		m, err := NewMatcher(c.fallback)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Match(&c.request) != c.match {
+			t.Errorf("case %d: expected match %v", i, c.match)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package fallback
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common/dice"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/task"
+)
+
+const (
+	// retryAfter is how long a destination that failed is skipped if it is
+	// not probed.
+	retryAfter = 10 * time.Second
+	// defaultTimeout is the timeout of probes.
+	defaultTimeout = 5 * time.Second
+)
+
+type target struct {
+	network string
+	address string
+	weight  int
+	// failedAt is the time of the last failure in unix nanoseconds, 0 if
+	// the destination is healthy.
+	failedAt atomic.Int64
+}
+
+func (t *target) healthy(probed bool) bool {
+	failedAt := t.failedAt.Load()
+	if failedAt == 0 {
+		return true
+	}
+	return !probed && time.Since(time.Unix(0, failedAt)) > retryAfter
+}
+
+// Pool dials the destinations of a fallback in proportion to their weights,
+// skipping those that are down.
+type Pool struct {
+	targets []*target
+	timeout time.Duration
+	checker *task.Periodic
+}
+
+// NewPool creates a Pool for the dest and dests of c, and starts probing them
+// if configured.
+func NewPool(c Config) *Pool {
+	p := &Pool{
+		timeout: defaultTimeout,
+	}
+	if c.GetDest() != "" {
+		p.targets = append(p.targets, &target{network: c.GetType(), address: c.GetDest(), weight: 1})
+	}
+	for _, d := range c.GetDests() {
+		p.targets = append(p.targets, &target{network: d.Type, address: d.Dest, weight: max(int(d.Weight), 1)})
+	}
+	if check := c.GetHealthCheck(); check != nil {
+		if check.Timeout > 0 {
+			p.timeout = time.Duration(check.Timeout) * time.Second
+		}
+		if check.Interval > 0 && len(p.targets) > 1 {
+			p.checker = &task.Periodic{
+				Interval: time.Duration(check.Interval) * time.Second,
+				Execute:  p.check,
+			}
+			p.checker.Start()
+		}
+	}
+	return p
+}
+
+// String returns the addresses of the destinations.
+func (p *Pool) String() string {
+	addresses := make([]string, len(p.targets))
+	for i, t := range p.targets {
+		addresses[i] = t.address
+	}
+	return strings.Join(addresses, ",")
+}
+
+func (p *Pool) check() error {
+	for _, t := range p.targets {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+			defer cancel()
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, t.network, t.address)
+			if err != nil {
+				if t.failedAt.CompareAndSwap(0, time.Now().UnixNano()) {
+					errors.LogInfoInner(context.Background(), err, "fallback destination ", t.address, " is down")
+				}
+				return
+			}
+			conn.Close()
+			if t.failedAt.Swap(0) != 0 {
+				errors.LogInfo(context.Background(), "fallback destination ", t.address, " is up")
+			}
+		}()
+	}
+	return nil
+}
+
+// pick returns a random destination of those not tried yet, preferring
+// healthy ones.
+func (p *Pool) pick(tried []bool) int {
+	probed := p.checker != nil
+	for _, healthy := range []bool{true, false} {
+		var candidates []int
+		total := 0
+		for i, t := range p.targets {
+			if !tried[i] && t.healthy(probed) == healthy {
+				candidates = append(candidates, i)
+				total += t.weight
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		n := dice.Roll(total)
+		for _, i := range candidates {
+			if n < p.targets[i].weight {
+				return i
+			}
+			n -= p.targets[i].weight
+		}
+	}
+	return -1
+}
+
+// Dial connects to a destination, trying the others if it fails.
+func (p *Pool) Dial(ctx context.Context) (net.Conn, error) {
+	tried := make([]bool, len(p.targets))
+	var err error = errors.New("no fallback destination")
+	for {
+		i := p.pick(tried)
+		if i < 0 {
+			return nil, err
+		}
+		t := p.targets[i]
+		var dialer net.Dialer
+		conn, dialErr := dialer.DialContext(ctx, t.network, t.address)
+		if dialErr == nil {
+			if p.checker == nil {
+				t.failedAt.Store(0)
+			}
+			return conn, nil
+		}
+		t.failedAt.CompareAndSwap(0, time.Now().UnixNano())
+		tried[i] = true
+		err = dialErr
+	}
+}
+
+// Close implements common.Closable.
+func (p *Pool) Close() error {
+	if p.checker != nil {
+		return p.checker.Close()
+	}
+	return nil
+}
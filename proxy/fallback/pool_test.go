@@ -0,0 +1,88 @@
+package fallback_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/net"
+	. "github.com/xtls/xray-core/proxy/fallback"
+	"github.com/xtls/xray-core/proxy/trojan"
+)
+
+func listen(t *testing.T) (net.Listener, chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	accepted := make(chan struct{}, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+			accepted <- struct{}{}
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return listener, accepted
+}
+
+func closedAddress() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	listener.Close()
+	return listener.Addr().String()
+}
+
+func TestPoolSkipsDownDestination(t *testing.T) {
+	up, accepted := listen(t)
+	pool := NewPool(&trojan.Fallback{
+		Dests: []*Destination{
+			{Type: "tcp", Dest: closedAddress(), Weight: 100},
+			{Type: "tcp", Dest: up.Addr().String()},
+		},
+	})
+	defer pool.Close()
+
+	for range 10 {
+		conn, err := pool.Dial(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+		<-accepted
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	up, accepted := listen(t)
+	down := closedAddress()
+	pool := NewPool(&trojan.Fallback{
+		Type: "tcp",
+		Dest: down,
+		Dests: []*Destination{
+			{Type: "tcp", Dest: up.Addr().String()},
+		},
+		HealthCheck: &HealthCheck{Interval: 60, Timeout: 1},
+	})
+	defer pool.Close()
+
+	// The probe of the destination that is up is accepted as well.
+	<-accepted
+	time.Sleep(100 * time.Millisecond)
+
+	for range 10 {
+		conn, err := pool.Dial(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+		select {
+		case <-accepted:
+		case <-time.After(time.Second):
+			t.Fatal("dialed the destination that is down")
+		}
+	}
+}
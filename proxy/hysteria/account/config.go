@@ -18,12 +18,18 @@ func (a *Account) AsAccount() (protocol.Account, error) {
 	return &MemoryAccount{
 		Auth: a.Auth,
 		VR:   VR,
+		Up:   a.Up,
+		Down: a.Down,
 	}, nil
 }
 
 type MemoryAccount struct {
 	Auth string
 	VR   net.Port
+	// Up and Down are the most bytes per second the user may upload and
+	// download, or 0 for those of its level.
+	Up   uint64
+	Down uint64
 }
 
 func (a *MemoryAccount) Equals(other protocol.Account) bool {
@@ -36,6 +42,8 @@ func (a *MemoryAccount) Equals(other protocol.Account) bool {
 func (a *MemoryAccount) ToProto() proto.Message {
 	return &Account{
 		Auth: a.Auth,
+		Up:   a.Up,
+		Down: a.Down,
 	}
 }
 
@@ -43,6 +51,8 @@ type Validator struct {
 	users sync.Map
 	ids   sync.Map
 	mu    sync.Mutex
+
+	levelBandwidth map[uint32]*Bandwidth
 }
 
 func NewValidator() *Validator {
@@ -87,6 +97,8 @@ func (v *Validator) Get(auth string) (user *protocol.MemoryUser) {
 					Account: &MemoryAccount{
 						Auth: auth,
 						VR:   VR,
+						Up:   user.Account.(*MemoryAccount).Up,
+						Down: user.Account.(*MemoryAccount).Down,
 					},
 				}
 			}
@@ -99,6 +111,28 @@ func (v *Validator) Get(auth string) (user *protocol.MemoryUser) {
 	return
 }
 
+// SetLevelBandwidth sets the bandwidth of the users of each level. It is
+// called before the validator is used.
+func (v *Validator) SetLevelBandwidth(levels map[uint32]*Bandwidth) {
+	v.levelBandwidth = levels
+}
+
+// Bandwidth returns the most bytes per second the user may upload and
+// download, or 0 if unlimited.
+func (v *Validator) Bandwidth(user *protocol.MemoryUser) (up uint64, down uint64) {
+	account := user.Account.(*MemoryAccount)
+	up, down = account.Up, account.Down
+	if level := v.levelBandwidth[user.Level]; level != nil {
+		if up == 0 {
+			up = level.Up
+		}
+		if down == 0 {
+			down = level.Down
+		}
+	}
+	return
+}
+
 func (v *Validator) GetByID(id uuid.UUID) (user *protocol.MemoryUser) {
 	id[6] = 0
 	id[7] = 0
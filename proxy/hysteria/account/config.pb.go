@@ -22,8 +22,12 @@ const (
 )
 
 type Account struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Auth          string                 `protobuf:"bytes,1,opt,name=auth,proto3" json:"auth,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Auth  string                 `protobuf:"bytes,1,opt,name=auth,proto3" json:"auth,omitempty"`
+	// Most bytes per second the user may upload and download. Those of the
+	// level of the user apply if 0.
+	Up            uint64 `protobuf:"varint,2,opt,name=up,proto3" json:"up,omitempty"`
+	Down          uint64 `protobuf:"varint,3,opt,name=down,proto3" json:"down,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,13 +69,84 @@ func (x *Account) GetAuth() string {
 	return ""
 }
 
+func (x *Account) GetUp() uint64 {
+	if x != nil {
+		return x.Up
+	}
+	return 0
+}
+
+func (x *Account) GetDown() uint64 {
+	if x != nil {
+		return x.Down
+	}
+	return 0
+}
+
+type Bandwidth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Up            uint64                 `protobuf:"varint,1,opt,name=up,proto3" json:"up,omitempty"`
+	Down          uint64                 `protobuf:"varint,2,opt,name=down,proto3" json:"down,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bandwidth) Reset() {
+	*x = Bandwidth{}
+	mi := &file_proxy_hysteria_account_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bandwidth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bandwidth) ProtoMessage() {}
+
+func (x *Bandwidth) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_hysteria_account_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bandwidth.ProtoReflect.Descriptor instead.
+func (*Bandwidth) Descriptor() ([]byte, []int) {
+	return file_proxy_hysteria_account_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Bandwidth) GetUp() uint64 {
+	if x != nil {
+		return x.Up
+	}
+	return 0
+}
+
+func (x *Bandwidth) GetDown() uint64 {
+	if x != nil {
+		return x.Down
+	}
+	return 0
+}
+
 var File_proxy_hysteria_account_config_proto protoreflect.FileDescriptor
 
 const file_proxy_hysteria_account_config_proto_rawDesc = "" +
 	"\n" +
-	"#proxy/hysteria/account/config.proto\x12\x1bxray.proxy.hysteria.account\"\x1d\n" +
+	"#proxy/hysteria/account/config.proto\x12\x1bxray.proxy.hysteria.account\"A\n" +
 	"\aAccount\x12\x12\n" +
-	"\x04auth\x18\x01 \x01(\tR\x04authBs\n" +
+	"\x04auth\x18\x01 \x01(\tR\x04auth\x12\x0e\n" +
+	"\x02up\x18\x02 \x01(\x04R\x02up\x12\x12\n" +
+	"\x04down\x18\x03 \x01(\x04R\x04down\"/\n" +
+	"\tBandwidth\x12\x0e\n" +
+	"\x02up\x18\x01 \x01(\x04R\x02up\x12\x12\n" +
+	"\x04down\x18\x02 \x01(\x04R\x04downBs\n" +
 	"\x1fcom.xray.proxy.hysteria.accountP\x01Z0github.com/xtls/xray-core/proxy/hysteria/account\xaa\x02\x1bXray.Proxy.Hysteria.Accountb\x06proto3"
 
 var (
@@ -86,9 +161,10 @@ func file_proxy_hysteria_account_config_proto_rawDescGZIP() []byte {
 	return file_proxy_hysteria_account_config_proto_rawDescData
 }
 
-var file_proxy_hysteria_account_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_proxy_hysteria_account_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_proxy_hysteria_account_config_proto_goTypes = []any{
-	(*Account)(nil), // 0: xray.proxy.hysteria.account.Account
+	(*Account)(nil),   // 0: xray.proxy.hysteria.account.Account
+	(*Bandwidth)(nil), // 1: xray.proxy.hysteria.account.Bandwidth
 }
 var file_proxy_hysteria_account_config_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
@@ -109,7 +185,7 @@ func file_proxy_hysteria_account_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_hysteria_account_config_proto_rawDesc), len(file_proxy_hysteria_account_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
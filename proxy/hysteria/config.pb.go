@@ -8,6 +8,7 @@ package hysteria
 
 import (
 	protocol "github.com/xtls/xray-core/common/protocol"
+	account "github.com/xtls/xray-core/proxy/hysteria/account"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -67,10 +68,12 @@ func (x *ClientConfig) GetServer() *protocol.ServerEndpoint {
 }
 
 type ServerConfig struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Users         []*protocol.User       `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Users []*protocol.User       `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Most bytes per second the users of each level may upload and download.
+	LevelBandwidth map[uint32]*account.Bandwidth `protobuf:"bytes,2,rep,name=level_bandwidth,json=levelBandwidth,proto3" json:"level_bandwidth,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ServerConfig) Reset() {
@@ -110,15 +113,26 @@ func (x *ServerConfig) GetUsers() []*protocol.User {
 	return nil
 }
 
+func (x *ServerConfig) GetLevelBandwidth() map[uint32]*account.Bandwidth {
+	if x != nil {
+		return x.LevelBandwidth
+	}
+	return nil
+}
+
 var File_proxy_hysteria_config_proto protoreflect.FileDescriptor
 
 const file_proxy_hysteria_config_proto_rawDesc = "" +
 	"\n" +
-	"\x1bproxy/hysteria/config.proto\x12\x13xray.proxy.hysteria\x1a!common/protocol/server_spec.proto\x1a\x1acommon/protocol/user.proto\"L\n" +
+	"\x1bproxy/hysteria/config.proto\x12\x13xray.proxy.hysteria\x1a!common/protocol/server_spec.proto\x1a\x1acommon/protocol/user.proto\x1a#proxy/hysteria/account/config.proto\"L\n" +
 	"\fClientConfig\x12<\n" +
-	"\x06server\x18\x01 \x01(\v2$.xray.common.protocol.ServerEndpointR\x06server\"@\n" +
+	"\x06server\x18\x01 \x01(\v2$.xray.common.protocol.ServerEndpointR\x06server\"\x8b\x02\n" +
 	"\fServerConfig\x120\n" +
-	"\x05users\x18\x01 \x03(\v2\x1a.xray.common.protocol.UserR\x05usersB[\n" +
+	"\x05users\x18\x01 \x03(\v2\x1a.xray.common.protocol.UserR\x05users\x12^\n" +
+	"\x0flevel_bandwidth\x18\x02 \x03(\v25.xray.proxy.hysteria.ServerConfig.LevelBandwidthEntryR\x0elevelBandwidth\x1ai\n" +
+	"\x13LevelBandwidthEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\rR\x03key\x12<\n" +
+	"\x05value\x18\x02 \x01(\v2&.xray.proxy.hysteria.account.BandwidthR\x05value:\x028\x01B[\n" +
 	"\x17com.xray.proxy.hysteriaP\x01Z(github.com/xtls/xray-core/proxy/hysteria\xaa\x02\x13Xray.Proxy.Hysteriab\x06proto3"
 
 var (
@@ -133,21 +147,25 @@ func file_proxy_hysteria_config_proto_rawDescGZIP() []byte {
 	return file_proxy_hysteria_config_proto_rawDescData
 }
 
-var file_proxy_hysteria_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proxy_hysteria_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_proxy_hysteria_config_proto_goTypes = []any{
 	(*ClientConfig)(nil),            // 0: xray.proxy.hysteria.ClientConfig
 	(*ServerConfig)(nil),            // 1: xray.proxy.hysteria.ServerConfig
-	(*protocol.ServerEndpoint)(nil), // 2: xray.common.protocol.ServerEndpoint
-	(*protocol.User)(nil),           // 3: xray.common.protocol.User
+	nil,                             // 2: xray.proxy.hysteria.ServerConfig.LevelBandwidthEntry
+	(*protocol.ServerEndpoint)(nil), // 3: xray.common.protocol.ServerEndpoint
+	(*protocol.User)(nil),           // 4: xray.common.protocol.User
+	(*account.Bandwidth)(nil),       // 5: xray.proxy.hysteria.account.Bandwidth
 }
 var file_proxy_hysteria_config_proto_depIdxs = []int32{
-	2, // 0: xray.proxy.hysteria.ClientConfig.server:type_name -> xray.common.protocol.ServerEndpoint
-	3, // 1: xray.proxy.hysteria.ServerConfig.users:type_name -> xray.common.protocol.User
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	3, // 0: xray.proxy.hysteria.ClientConfig.server:type_name -> xray.common.protocol.ServerEndpoint
+	4, // 1: xray.proxy.hysteria.ServerConfig.users:type_name -> xray.common.protocol.User
+	2, // 2: xray.proxy.hysteria.ServerConfig.level_bandwidth:type_name -> xray.proxy.hysteria.ServerConfig.LevelBandwidthEntry
+	5, // 3: xray.proxy.hysteria.ServerConfig.LevelBandwidthEntry.value:type_name -> xray.proxy.hysteria.account.Bandwidth
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_proxy_hysteria_config_proto_init() }
@@ -161,7 +179,7 @@ func file_proxy_hysteria_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_hysteria_config_proto_rawDesc), len(file_proxy_hysteria_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
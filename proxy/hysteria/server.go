@@ -37,6 +37,7 @@ func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
 	}
 
 	validator := account.NewValidator()
+	validator.SetLevelBandwidth(config.LevelBandwidth)
 	for _, user := range config.Users {
 		u, err := user.ToMemoryUser()
 		if err != nil {
@@ -0,0 +1,290 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: proxy/mirror/config.proto
+
+package mirror
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Sink_Type int32
+
+const (
+	// TCP sends every flow in a connection to a collector at address.
+	Sink_TCP Sink_Type = 0
+	// PCAP writes the flows to a pcapng file at path, with synthesized
+	// headers.
+	Sink_PCAP Sink_Type = 1
+	// OUTBOUND sends the uplink of every flow through the outbound of tag.
+	Sink_OUTBOUND Sink_Type = 2
+)
+
+// Enum value maps for Sink_Type.
+var (
+	Sink_Type_name = map[int32]string{
+		0: "TCP",
+		1: "PCAP",
+		2: "OUTBOUND",
+	}
+	Sink_Type_value = map[string]int32{
+		"TCP":      0,
+		"PCAP":     1,
+		"OUTBOUND": 2,
+	}
+)
+
+func (x Sink_Type) Enum() *Sink_Type {
+	p := new(Sink_Type)
+	*p = x
+	return p
+}
+
+func (x Sink_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sink_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_proxy_mirror_config_proto_enumTypes[0].Descriptor()
+}
+
+func (Sink_Type) Type() protoreflect.EnumType {
+	return &file_proxy_mirror_config_proto_enumTypes[0]
+}
+
+func (x Sink_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sink_Type.Descriptor instead.
+func (Sink_Type) EnumDescriptor() ([]byte, []int) {
+	return file_proxy_mirror_config_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type Sink struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  Sink_Type              `protobuf:"varint,1,opt,name=type,proto3,enum=xray.proxy.mirror.Sink_Type" json:"type,omitempty"`
+	// host:port of the collector.
+	Address       string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Path          string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Tag           string `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sink) Reset() {
+	*x = Sink{}
+	mi := &file_proxy_mirror_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sink) ProtoMessage() {}
+
+func (x *Sink) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_mirror_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sink.ProtoReflect.Descriptor instead.
+func (*Sink) Descriptor() ([]byte, []int) {
+	return file_proxy_mirror_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Sink) GetType() Sink_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Sink_TCP
+}
+
+func (x *Sink) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Sink) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Sink) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type Config struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tag of the outbound that the flows are sent through.
+	OutboundTag string  `protobuf:"bytes,1,opt,name=outbound_tag,json=outboundTag,proto3" json:"outbound_tag,omitempty"`
+	Sinks       []*Sink `protobuf:"bytes,2,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	// Fraction of the flows mirrored, all flows if 0.
+	Sampling float32 `protobuf:"fixed32,3,opt,name=sampling,proto3" json:"sampling,omitempty"`
+	// Bytes mirrored at most in a flow, unlimited if 0.
+	MaxBytes      uint64 `protobuf:"varint,4,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_proxy_mirror_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_mirror_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_proxy_mirror_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Config) GetOutboundTag() string {
+	if x != nil {
+		return x.OutboundTag
+	}
+	return ""
+}
+
+func (x *Config) GetSinks() []*Sink {
+	if x != nil {
+		return x.Sinks
+	}
+	return nil
+}
+
+func (x *Config) GetSampling() float32 {
+	if x != nil {
+		return x.Sampling
+	}
+	return 0
+}
+
+func (x *Config) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+var File_proxy_mirror_config_proto protoreflect.FileDescriptor
+
+const file_proxy_mirror_config_proto_rawDesc = "" +
+	"\n" +
+	"\x19proxy/mirror/config.proto\x12\x11xray.proxy.mirror\"\xa1\x01\n" +
+	"\x04Sink\x120\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1c.xray.proxy.mirror.Sink.TypeR\x04type\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x10\n" +
+	"\x03tag\x18\x04 \x01(\tR\x03tag\"'\n" +
+	"\x04Type\x12\a\n" +
+	"\x03TCP\x10\x00\x12\b\n" +
+	"\x04PCAP\x10\x01\x12\f\n" +
+	"\bOUTBOUND\x10\x02\"\x93\x01\n" +
+	"\x06Config\x12!\n" +
+	"\foutbound_tag\x18\x01 \x01(\tR\voutboundTag\x12-\n" +
+	"\x05sinks\x18\x02 \x03(\v2\x17.xray.proxy.mirror.SinkR\x05sinks\x12\x1a\n" +
+	"\bsampling\x18\x03 \x01(\x02R\bsampling\x12\x1b\n" +
+	"\tmax_bytes\x18\x04 \x01(\x04R\bmaxBytesBU\n" +
+	"\x15com.xray.proxy.mirrorP\x01Z&github.com/xtls/xray-core/proxy/mirror\xaa\x02\x11Xray.Proxy.Mirrorb\x06proto3"
+
+var (
+	file_proxy_mirror_config_proto_rawDescOnce sync.Once
+	file_proxy_mirror_config_proto_rawDescData []byte
+)
+
+func file_proxy_mirror_config_proto_rawDescGZIP() []byte {
+	file_proxy_mirror_config_proto_rawDescOnce.Do(func() {
+		file_proxy_mirror_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proxy_mirror_config_proto_rawDesc), len(file_proxy_mirror_config_proto_rawDesc)))
+	})
+	return file_proxy_mirror_config_proto_rawDescData
+}
+
+var file_proxy_mirror_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proxy_mirror_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proxy_mirror_config_proto_goTypes = []any{
+	(Sink_Type)(0), // 0: xray.proxy.mirror.Sink.Type
+	(*Sink)(nil),   // 1: xray.proxy.mirror.Sink
+	(*Config)(nil), // 2: xray.proxy.mirror.Config
+}
+var file_proxy_mirror_config_proto_depIdxs = []int32{
+	0, // 0: xray.proxy.mirror.Sink.type:type_name -> xray.proxy.mirror.Sink.Type
+	1, // 1: xray.proxy.mirror.Config.sinks:type_name -> xray.proxy.mirror.Sink
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proxy_mirror_config_proto_init() }
+func file_proxy_mirror_config_proto_init() {
+	if File_proxy_mirror_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_mirror_config_proto_rawDesc), len(file_proxy_mirror_config_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proxy_mirror_config_proto_goTypes,
+		DependencyIndexes: file_proxy_mirror_config_proto_depIdxs,
+		EnumInfos:         file_proxy_mirror_config_proto_enumTypes,
+		MessageInfos:      file_proxy_mirror_config_proto_msgTypes,
+	}.Build()
+	File_proxy_mirror_config_proto = out.File
+	file_proxy_mirror_config_proto_goTypes = nil
+	file_proxy_mirror_config_proto_depIdxs = nil
+}
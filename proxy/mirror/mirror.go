@@ -0,0 +1,200 @@
+// Package mirror is an outbound handler that sends connections through
+// another outbound, and copies their traffic to sinks such as a collector, a
+// pcapng file or another outbound, for debugging and intrusion detection.
+package mirror
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/outbound"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+)
+
+// Handler is an outbound connection that mirrors the traffic of another.
+type Handler struct {
+	outboundManager outbound.Manager
+	tag             string
+	sinks           []sink
+	sampling        float64
+	maxBytes        int64
+}
+
+// New creates a new mirror handler.
+func New(ctx context.Context, config *Config) (*Handler, error) {
+	if config.OutboundTag == "" {
+		return nil, errors.New("outbound tag not specified")
+	}
+	h := &Handler{
+		tag:      config.OutboundTag,
+		sampling: float64(config.Sampling),
+		maxBytes: int64(config.MaxBytes),
+	}
+	if err := core.RequireFeatures(ctx, func(om outbound.Manager) {
+		h.outboundManager = om
+	}); err != nil {
+		return nil, err
+	}
+	for _, c := range config.Sinks {
+		s, err := newSink(h, c)
+		if err != nil {
+			h.Close()
+			return nil, err
+		}
+		h.sinks = append(h.sinks, s)
+	}
+	return h, nil
+}
+
+// Process implements proxy.Outbound.
+func (h *Handler) Process(ctx context.Context, link *transport.Link, _ internet.Dialer) error {
+	outbounds := session.OutboundsFromContext(ctx)
+	ob := outbounds[len(outbounds)-1]
+	ob.Name = "mirror"
+
+	handler := h.outboundManager.GetHandler(h.tag)
+	if handler == nil {
+		return errors.New("outbound ", h.tag, " not found")
+	}
+
+	if h.sampling > 0 && h.sampling < 1 && rand.Float64() >= h.sampling {
+		handler.Dispatch(ctx, link)
+		return nil
+	}
+
+	// Spliced data would bypass the mirror.
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		inbound.CanSpliceCopy = 3
+	}
+	m := &mirror{
+		flows: make([]*flow, 0, len(h.sinks)),
+		left:  h.maxBytes,
+	}
+	info := newFlowInfo(ctx, ob.Target)
+	for _, s := range h.sinks {
+		m.flows = append(m.flows, newFlow(ctx, s, info))
+	}
+	defer m.close()
+
+	errors.LogInfo(ctx, "mirroring connection to ", ob.Target, " through ", h.tag)
+	handler.Dispatch(ctx, &transport.Link{
+		Reader: &buf.TeeReader{
+			Reader: link.Reader,
+			Tee:    func(mb buf.MultiBuffer) { m.copy(uplink, mb) },
+		},
+		Writer: &buf.TeeWriter{
+			Writer: link.Writer,
+			Tee:    func(mb buf.MultiBuffer) { m.copy(downlink, mb) },
+		},
+	})
+	return nil
+}
+
+// Close implements common.Closable.
+func (h *Handler) Close() error {
+	for _, s := range h.sinks {
+		common.Close(s)
+	}
+	return nil
+}
+
+// direction is the direction of mirrored data, which is also the type of
+// its records in a tcp sink, and matches pcapng.Direction.
+type direction byte
+
+const (
+	uplink   direction = 1
+	downlink direction = 2
+)
+
+// flowInfo describes a mirrored connection.
+type flowInfo struct {
+	Source      net.Destination
+	Destination net.Destination
+	Inbound     string
+	User        string
+	Time        time.Time
+}
+
+func newFlowInfo(ctx context.Context, dest net.Destination) *flowInfo {
+	info := &flowInfo{
+		Destination: dest,
+		Time:        time.Now(),
+	}
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		info.Source = inbound.Source
+		info.Inbound = inbound.Tag
+		if inbound.User != nil {
+			info.User = inbound.User.Email
+		}
+	}
+	return info
+}
+
+// mirror copies the traffic of a connection to the flows in the sinks, until
+// the bytes left run out.
+type mirror struct {
+	flows []*flow
+	left  int64
+	used  atomic.Int64
+}
+
+func (m *mirror) copy(dir direction, mb buf.MultiBuffer) {
+	size := int64(mb.Len())
+	if size == 0 {
+		return
+	}
+	if m.left > 0 {
+		used := m.used.Add(size)
+		if used-size >= m.left {
+			return
+		}
+		if used > m.left {
+			size -= used - m.left
+		}
+	}
+	for _, f := range m.flows {
+		f.send(dir, copyMultiBuffer(mb, size))
+	}
+}
+
+func (m *mirror) close() {
+	for _, f := range m.flows {
+		f.close()
+	}
+}
+
+// copyMultiBuffer returns a copy of the first size bytes in mb.
+func copyMultiBuffer(mb buf.MultiBuffer, size int64) buf.MultiBuffer {
+	copied := make(buf.MultiBuffer, 0, len(mb))
+	for _, b := range mb {
+		if size <= 0 {
+			break
+		}
+		data := b.Bytes()
+		if int64(len(data)) > size {
+			data = data[:size]
+		}
+		size -= int64(len(data))
+		c := buf.New()
+		c.Write(data)
+		c.UDP = b.UDP
+		copied = append(copied, c)
+	}
+	return copied
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return New(ctx, config.(*Config))
+	}))
+}
@@ -0,0 +1,218 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/features/outbound"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/pipe"
+)
+
+type echoHandler struct {
+	outbound.Handler
+}
+
+func (echoHandler) Dispatch(ctx context.Context, link *transport.Link) {
+	buf.Copy(link.Reader, link.Writer)
+	common.Close(link.Writer)
+}
+
+type testManager struct {
+	outbound.Manager
+}
+
+func (testManager) GetHandler(tag string) outbound.Handler {
+	if tag == "echo" {
+		return echoHandler{}
+	}
+	return nil
+}
+
+// mirrorEcho sends data through h to the echo outbound, and returns the echo.
+func mirrorEcho(t *testing.T, h *Handler, data string) string {
+	ctx := session.ContextWithInbound(context.Background(), &session.Inbound{
+		Source: net.TCPDestination(net.ParseAddress("10.0.0.1"), 40000),
+		Tag:    "in",
+	})
+	ctx = session.ContextWithOutbounds(ctx, []*session.Outbound{{
+		Target: net.TCPDestination(net.DomainAddress("www.example.com"), 80),
+	}})
+	uplinkReader, uplinkWriter := pipe.New()
+	downlinkReader, downlinkWriter := pipe.New()
+	b := buf.New()
+	b.WriteString(data)
+	common.Must(uplinkWriter.WriteMultiBuffer(buf.MultiBuffer{b}))
+	common.Must(uplinkWriter.Close())
+	common.Must(h.Process(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, nil))
+
+	var echo string
+	for {
+		mb, err := downlinkReader.ReadMultiBuffer()
+		echo += mb.String()
+		buf.ReleaseMulti(mb)
+		if err != nil {
+			return echo
+		}
+	}
+}
+
+func TestMirrorToCollector(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	h := &Handler{
+		outboundManager: testManager{},
+		tag:             "echo",
+		maxBytes:        10,
+	}
+	s, err := newSink(h, &Sink{Type: Sink_TCP, Address: listener.Addr().String()})
+	common.Must(err)
+	h.sinks = []sink{s}
+
+	if echo := mirrorEcho(t, h, "0123456789abcdef"); echo != "0123456789abcdef" {
+		t.Fatal("unexpected echo ", echo)
+	}
+
+	conn, err := listener.Accept()
+	common.Must(err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	var records []string
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			break
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		common.Must2(io.ReadFull(conn, payload))
+		records = append(records, string(rune('0'+header[0]))+":"+string(payload))
+	}
+	if len(records) != 2 || !bytes.Contains([]byte(records[0]), []byte(`"source":"tcp:10.0.0.1:40000"`)) {
+		t.Fatal("unexpected records ", records)
+	}
+	// The flow is capped at 10 bytes, so the downlink is not mirrored.
+	if records[1] != "1:0123456789" {
+		t.Error("unexpected uplink ", records[1])
+	}
+}
+
+func TestMirrorToPcap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mirror.pcapng")
+	h := &Handler{
+		outboundManager: testManager{},
+		tag:             "echo",
+	}
+	s, err := newSink(h, &Sink{Type: Sink_PCAP, Path: path})
+	common.Must(err)
+	h.sinks = []sink{s}
+
+	if echo := mirrorEcho(t, h, "hello"); echo != "hello" {
+		t.Fatal("unexpected echo ", echo)
+	}
+
+	// The handshake, the data in both directions and the FINs.
+	var packets [][]byte
+	for deadline := time.Now().Add(5 * time.Second); len(packets) < 8 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		b, err := os.ReadFile(path)
+		common.Must(err)
+		packets = parsePcapng(b)
+	}
+	common.Must(h.Close())
+	if len(packets) != 8 {
+		t.Fatal("unexpected number of packets ", len(packets))
+	}
+	if string(packets[3][40:]) != "hello" || string(packets[4][40:]) != "hello" {
+		t.Error("unexpected payloads")
+	}
+}
+
+// parsePcapng returns the packets in the enhanced packet blocks of b.
+func parsePcapng(b []byte) [][]byte {
+	var packets [][]byte
+	for b = b[48:]; len(b) >= 12; {
+		size := binary.LittleEndian.Uint32(b[4:])
+		if int(size) > len(b) {
+			break
+		}
+		if binary.LittleEndian.Uint32(b) == 6 {
+			n := binary.LittleEndian.Uint32(b[20:])
+			packets = append(packets, b[28:28+n])
+		}
+		b = b[size:]
+	}
+	return packets
+}
+
+type stuckHandler struct {
+	outbound.Handler
+	release  chan struct{}
+	received chan int32
+}
+
+func (h stuckHandler) Dispatch(ctx context.Context, link *transport.Link) {
+	<-h.release
+	var n int32
+	for {
+		mb, err := link.Reader.ReadMultiBuffer()
+		n += mb.Len()
+		buf.ReleaseMulti(mb)
+		if err != nil {
+			h.received <- n
+			return
+		}
+	}
+}
+
+type stuckManager struct {
+	outbound.Manager
+	handler outbound.Handler
+}
+
+func (m stuckManager) GetHandler(tag string) outbound.Handler {
+	return m.handler
+}
+
+func TestMirrorToSlowOutbound(t *testing.T) {
+	handler := stuckHandler{release: make(chan struct{}), received: make(chan int32, 1)}
+	h := &Handler{outboundManager: stuckManager{handler: handler}}
+	dest := net.TCPDestination(net.DomainAddress("www.example.com"), 80)
+	f := newFlow(context.Background(), &outboundSink{handler: h, tag: "stuck"}, newFlowInfo(context.Background(), dest))
+
+	paced := true
+	for range 1024 {
+		b := buf.New()
+		b.Extend(buf.Size)
+		f.send(uplink, buf.MultiBuffer{b})
+		// The connection is slower than the flow until the outbound blocks it.
+		deadline := time.Now().Add(100 * time.Millisecond)
+		for paced && len(f.records) > 0 {
+			if time.Now().After(deadline) {
+				paced = false
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	f.close()
+	close(handler.release)
+
+	// What is left is the queue of the flow and the buffer of the sink.
+	if n := <-handler.received; n > 2*1024*1024 {
+		t.Error("the slow outbound buffered ", n, " bytes")
+	}
+	if f.dropped.Load() == 0 {
+		t.Error("expected the writes to the slow outbound to be dropped")
+	}
+}
@@ -0,0 +1,61 @@
+package mirror
+
+import (
+	"context"
+	"os"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/pcapng"
+)
+
+// pcapSink writes the flows to a pcapng file as raw IP packets, with
+// synthesized headers.
+type pcapSink struct {
+	file   *os.File
+	writer *pcapng.Writer
+}
+
+func newPcapSink(path string) (*pcapSink, error) {
+	if path == "" {
+		return nil, errors.New("path of pcap sink not specified")
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, errors.New("failed to create pcap file ", path).Base(err)
+	}
+	writer, err := pcapng.NewWriter(file)
+	if err != nil {
+		file.Close()
+		return nil, errors.New("failed to write pcap file ", path).Base(err)
+	}
+	return &pcapSink{file: file, writer: writer}, nil
+}
+
+func (s *pcapSink) open(_ context.Context, info *flowInfo) (flowWriter, error) {
+	f, err := pcapng.NewFlow(s.writer, info.Source, info.Destination, info.Time)
+	if err != nil {
+		return nil, err
+	}
+	return &pcapFlowWriter{flow: f}, nil
+}
+
+// Close implements common.Closable.
+func (s *pcapSink) Close() error {
+	s.writer.Lock()
+	defer s.writer.Unlock()
+	return s.file.Close()
+}
+
+type pcapFlowWriter struct {
+	flow *pcapng.Flow
+}
+
+func (w *pcapFlowWriter) write(dir direction, mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+	return w.flow.Write(pcapng.Direction(dir), mb)
+}
+
+func (w *pcapFlowWriter) close() {
+	w.flow.Close()
+}
@@ -0,0 +1,212 @@
+package mirror
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+	"github.com/xtls/xray-core/transport/pipe"
+)
+
+// flowQueueSize is how many writes a flow queues for a sink, beyond which
+// they are dropped rather than slowing down the connection.
+const flowQueueSize = 64
+
+// outboundBufferSize is how many bytes an outbound sink buffers for a flow.
+const outboundBufferSize = 64 * 1024
+
+// sink receives the traffic of mirrored connections.
+type sink interface {
+	common.Closable
+	// open starts mirroring a connection, which is described by info.
+	open(ctx context.Context, info *flowInfo) (flowWriter, error)
+}
+
+// flowWriter writes the traffic of a connection to a sink.
+type flowWriter interface {
+	write(dir direction, mb buf.MultiBuffer) error
+	close()
+}
+
+func newSink(h *Handler, c *Sink) (sink, error) {
+	switch c.Type {
+	case Sink_TCP:
+		dest, err := net.ParseDestination("tcp:" + c.Address)
+		if err != nil {
+			return nil, errors.New("invalid collector address ", c.Address).Base(err)
+		}
+		return &tcpSink{dest: dest}, nil
+	case Sink_PCAP:
+		return newPcapSink(c.Path)
+	case Sink_OUTBOUND:
+		if c.Tag == "" {
+			return nil, errors.New("outbound tag of sink not specified")
+		}
+		return &outboundSink{handler: h, tag: c.Tag}, nil
+	default:
+		return nil, errors.New("unknown sink type ", c.Type)
+	}
+}
+
+type record struct {
+	dir direction
+	mb  buf.MultiBuffer
+}
+
+// flow is a connection mirrored to a sink. The sink is written in the
+// background, so that a slow sink never slows down the connection.
+type flow struct {
+	records chan record
+	dropped atomic.Uint64
+}
+
+func newFlow(ctx context.Context, s sink, info *flowInfo) *flow {
+	f := &flow{
+		records: make(chan record, flowQueueSize),
+	}
+	go func() {
+		w, err := s.open(ctx, info)
+		if err != nil {
+			errors.LogInfoInner(ctx, err, "failed to open mirror sink")
+		}
+		for r := range f.records {
+			if w == nil {
+				buf.ReleaseMulti(r.mb)
+				continue
+			}
+			if err := w.write(r.dir, r.mb); err != nil {
+				errors.LogInfoInner(ctx, err, "failed to write to mirror sink")
+				w.close()
+				w = nil
+			}
+		}
+		if w != nil {
+			w.close()
+		}
+		if n := f.dropped.Load(); n > 0 {
+			errors.LogInfo(ctx, "mirror sink dropped ", n, " writes")
+		}
+	}()
+	return f
+}
+
+func (f *flow) send(dir direction, mb buf.MultiBuffer) {
+	select {
+	case f.records <- record{dir: dir, mb: mb}:
+	default:
+		f.dropped.Add(1)
+		buf.ReleaseMulti(mb)
+	}
+}
+
+func (f *flow) close() {
+	close(f.records)
+}
+
+// tcpSink sends every flow in a connection to a collector. A flow is a
+// sequence of records, each of 1 byte of type, 4 bytes of length and the
+// payload: first the flow described in JSON as type 0, then the data of the
+// uplink as type 1 and of the downlink as type 2.
+type tcpSink struct {
+	dest net.Destination
+}
+
+func (s *tcpSink) open(ctx context.Context, info *flowInfo) (flowWriter, error) {
+	conn, err := internet.DialSystem(context.WithoutCancel(ctx), s.dest, nil)
+	if err != nil {
+		return nil, errors.New("failed to dial collector ", s.dest).Base(err)
+	}
+	w := &tcpFlowWriter{
+		conn:   conn,
+		writer: buf.NewWriter(conn),
+	}
+	header, _ := json.Marshal(map[string]interface{}{
+		"source":      info.Source.String(),
+		"destination": info.Destination.String(),
+		"inbound":     info.Inbound,
+		"user":        info.User,
+		"time":        info.Time,
+	})
+	b := buf.New()
+	b.Write(header)
+	if err := w.write(0, buf.MultiBuffer{b}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close implements common.Closable.
+func (s *tcpSink) Close() error {
+	return nil
+}
+
+type tcpFlowWriter struct {
+	conn   net.Conn
+	writer buf.Writer
+}
+
+func (w *tcpFlowWriter) write(dir direction, mb buf.MultiBuffer) error {
+	b := buf.New()
+	b.WriteByte(byte(dir))
+	binary.BigEndian.PutUint32(b.Extend(4), uint32(mb.Len()))
+	return w.writer.WriteMultiBuffer(append(buf.MultiBuffer{b}, mb...))
+}
+
+func (w *tcpFlowWriter) close() {
+	w.conn.Close()
+}
+
+// outboundSink sends the uplink of every flow through an outbound, such as
+// to a shadow server. The downlink of the outbound is discarded.
+type outboundSink struct {
+	handler *Handler
+	tag     string
+}
+
+func (s *outboundSink) open(ctx context.Context, info *flowInfo) (flowWriter, error) {
+	handler := s.handler.outboundManager.GetHandler(s.tag)
+	if handler == nil {
+		return nil, errors.New("outbound ", s.tag, " not found")
+	}
+	ctx = session.ContextWithOutbounds(context.WithoutCancel(ctx), []*session.Outbound{{
+		Target: info.Destination,
+		Tag:    s.tag,
+	}})
+	// A slow outbound blocks the flow like a slow collector does, so that
+	// the flow drops the writes beyond its queue instead of buffering them.
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(outboundBufferSize))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(outboundBufferSize))
+	go handler.Dispatch(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter})
+	go buf.Copy(downlinkReader, buf.Discard)
+	return &outboundFlowWriter{writer: uplinkWriter}, nil
+}
+
+// Close implements common.Closable.
+func (s *outboundSink) Close() error {
+	return nil
+}
+
+type outboundFlowWriter struct {
+	writer *pipe.Writer
+}
+
+func (w *outboundFlowWriter) write(dir direction, mb buf.MultiBuffer) error {
+	if dir != uplink {
+		buf.ReleaseMulti(mb)
+		return nil
+	}
+	return w.writer.WriteMultiBuffer(mb)
+}
+
+func (w *outboundFlowWriter) close() {
+	w.writer.Close()
+}
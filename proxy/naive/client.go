@@ -0,0 +1,259 @@
+package naive
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http2"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/common/signal"
+	"github.com/xtls/xray-core/common/task"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/policy"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+	"github.com/xtls/xray-core/transport/internet/reality"
+	"github.com/xtls/xray-core/transport/internet/stat"
+	"github.com/xtls/xray-core/transport/internet/tls"
+)
+
+// Client is an outbound connection handler for naive. The tunnels share an
+// HTTP/2 connection while it takes new requests.
+type Client struct {
+	server        *protocol.ServerSpec
+	policyManager policy.Manager
+
+	h2Mutex sync.Mutex
+	h2Conn  *http2.ClientConn
+}
+
+// NewClient creates a new naive outbound handler.
+func NewClient(ctx context.Context, config *ClientConfig) (*Client, error) {
+	if config.Server == nil {
+		return nil, errors.New(`no target server found`)
+	}
+	server, err := protocol.NewServerSpecFromPB(config.Server)
+	if err != nil {
+		return nil, errors.New("failed to get server spec").Base(err)
+	}
+	if server.User == nil {
+		return nil, errors.New("user is not specified")
+	}
+
+	v := core.MustFromContext(ctx)
+	return &Client{
+		server:        server,
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+	}, nil
+}
+
+// Close implements common.Closable.Close().
+func (c *Client) Close() error {
+	c.h2Mutex.Lock()
+	defer c.h2Mutex.Unlock()
+	if c.h2Conn != nil {
+		return c.h2Conn.Close()
+	}
+	return nil
+}
+
+// tunnelConn is a CONNECT tunnel, whose streams may have the padding.
+type tunnelConn struct {
+	io.Reader
+	io.Writer
+	close func() error
+}
+
+func (c *tunnelConn) Close() error {
+	return c.close()
+}
+
+// Process implements proxy.Outbound.Process().
+func (c *Client) Process(ctx context.Context, link *transport.Link, dialer internet.Dialer) error {
+	outbounds := session.OutboundsFromContext(ctx)
+	ob := outbounds[len(outbounds)-1]
+	if !ob.Target.IsValid() {
+		return errors.New("target not specified")
+	}
+	ob.Name = "naive"
+	ob.CanSpliceCopy = 3
+	destination := ob.Target
+	if destination.Network == net.Network_UDP {
+		return errors.New("UDP is not supported by naive")
+	}
+
+	conn, err := c.connect(ctx, dialer, destination.NetAddr())
+	if err != nil {
+		return errors.New("failed to find an available destination").Base(err).AtWarning()
+	}
+	defer conn.Close()
+	errors.LogInfo(ctx, "tunneling request to ", destination, " via ", c.server.Destination.NetAddr())
+
+	sessionPolicy := c.policyManager.ForLevel(c.server.User.Level)
+
+	var newCtx context.Context
+	var newCancel context.CancelFunc
+	if session.TimeoutOnlyFromContext(ctx) {
+		newCtx, newCancel = context.WithCancel(context.Background())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, func() {
+		cancel()
+		if newCancel != nil {
+			newCancel()
+		}
+	}, sessionPolicy.Timeouts.ConnectionIdle)
+
+	postRequest := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+		if err := buf.Copy(link.Reader, buf.NewWriter(conn), buf.UpdateActivity(timer)); err != nil {
+			return errors.New("failed to transfer request payload").Base(err).AtInfo()
+		}
+		return nil
+	}
+
+	getResponse := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+		return buf.Copy(buf.NewReader(conn), link.Writer, buf.UpdateActivity(timer))
+	}
+
+	if newCtx != nil {
+		ctx = newCtx
+	}
+
+	responseDoneAndCloseWriter := task.OnSuccess(getResponse, task.Close(link.Writer))
+	if err := task.Run(ctx, postRequest, responseDoneAndCloseWriter); err != nil {
+		return errors.New("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+// connect opens a CONNECT tunnel to the target, over the cached HTTP/2
+// connection if it takes new requests.
+func (c *Client) connect(ctx context.Context, dialer internet.Dialer, target string) (*tunnelConn, error) {
+	account := c.server.User.Account.(*Account)
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: target},
+		Header: make(http.Header),
+		Host:   target,
+	}
+	auth := account.Username + ":" + account.Password
+	req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	req.Header.Set(paddingHeader, paddingHeaderValue())
+
+	c.h2Mutex.Lock()
+	h2Conn := c.h2Conn
+	c.h2Mutex.Unlock()
+	if h2Conn != nil && h2Conn.CanTakeNewRequest() {
+		return connectHTTP2(req, h2Conn)
+	}
+
+	rawConn, err := dialer.Dial(ctx, c.server.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	nextProto := ""
+	switch conn := stat.TryUnwrapStatsConn(rawConn).(type) {
+	case *tls.Conn:
+		if err := conn.HandshakeContext(ctx); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		nextProto = conn.ConnectionState().NegotiatedProtocol
+	case *tls.UConn:
+		if err := conn.HandshakeContext(ctx); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		nextProto = conn.ConnectionState().NegotiatedProtocol
+	case *reality.UConn:
+		nextProto = conn.ConnectionState().NegotiatedProtocol
+	}
+
+	switch nextProto {
+	case "", "http/1.1":
+		return connectHTTP1(req, rawConn)
+	case "h2":
+		t := &http2.Transport{}
+		h2Conn, err := t.NewClientConn(rawConn)
+		if err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		c.h2Mutex.Lock()
+		c.h2Conn = h2Conn
+		c.h2Mutex.Unlock()
+		return connectHTTP2(req, h2Conn)
+	default:
+		rawConn.Close()
+		return nil, errors.New("negotiated unsupported application layer protocol: " + nextProto)
+	}
+}
+
+func connectHTTP1(req *http.Request, rawConn net.Conn) (*tunnelConn, error) {
+	if err := req.Write(rawConn); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReaderSize(rawConn, buf.Size)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		rawConn.Close()
+		return nil, errors.New("proxy responded with non 200 code: " + resp.Status)
+	}
+	return newTunnelConn(resp, reader, rawConn, rawConn.Close), nil
+}
+
+func connectHTTP2(req *http.Request, h2Conn *http2.ClientConn) (*tunnelConn, error) {
+	pr, pw := io.Pipe()
+	req.Body = pr
+	resp, err := h2Conn.RoundTrip(req)
+	if err != nil {
+		pw.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		pw.Close()
+		resp.Body.Close()
+		return nil, errors.New("proxy responded with non 200 code: " + resp.Status)
+	}
+	return newTunnelConn(resp, resp.Body, pw, func() error {
+		pw.Close()
+		return resp.Body.Close()
+	}), nil
+}
+
+// newTunnelConn pads the streams of the tunnel if the server pads them.
+func newTunnelConn(resp *http.Response, reader io.Reader, writer io.Writer, close func() error) *tunnelConn {
+	if resp.Header.Get(paddingHeader) != "" {
+		reader = &PaddingReader{Reader: reader}
+		writer = &PaddingWriter{Writer: writer}
+	}
+	return &tunnelConn{Reader: reader, Writer: writer, close: close}
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*ClientConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewClient(ctx, config.(*ClientConfig))
+	}))
+}
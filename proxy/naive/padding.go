@@ -0,0 +1,104 @@
+package naive
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// The first frames of a stream in both directions are padded if both the
+// request and the response carry the padding header. Frame format:
+// Payload length (uint16 BE)
+// Padding length (byte)
+// Payload...
+// Padding (zeros)...
+
+const (
+	paddingHeader = "Padding"
+
+	// paddingFrames is the number of padded frames in each direction.
+	paddingFrames = 8
+
+	maxPayloadSize = 0xFFFF
+	maxPaddingSize = 0xFF
+)
+
+// paddingChars are the characters of the padding header that HPACK can't
+// compress with its Huffman table.
+const paddingChars = "!#$()+<>?@[]^`{}"
+
+// paddingHeaderValue generates a padding header value of 30 to 62 characters.
+func paddingHeaderValue() string {
+	n := 30 + rand.Intn(32)
+	var b strings.Builder
+	b.Grow(n)
+	for i := 0; i < 16; i++ {
+		b.WriteByte(paddingChars[rand.Intn(len(paddingChars))])
+	}
+	b.WriteString(strings.Repeat("~", n-16))
+	return b.String()
+}
+
+// PaddingWriter pads the first frames written to the writer.
+type PaddingWriter struct {
+	io.Writer
+	frames int
+}
+
+func (w *PaddingWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 && w.frames < paddingFrames {
+		payload := p[:min(len(p), maxPayloadSize)]
+		padding := rand.Intn(maxPaddingSize + 1)
+		frame := make([]byte, 3+len(payload)+padding)
+		binary.BigEndian.PutUint16(frame, uint16(len(payload)))
+		frame[2] = byte(padding)
+		copy(frame[3:], payload)
+		if _, err := w.Writer.Write(frame); err != nil {
+			return n, err
+		}
+		w.frames++
+		n += len(payload)
+		p = p[len(payload):]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+	m, err := w.Writer.Write(p)
+	return n + m, err
+}
+
+// PaddingReader removes the padding of the first frames read from the reader.
+type PaddingReader struct {
+	io.Reader
+	frames  int
+	payload int
+	padding int
+}
+
+func (r *PaddingReader) Read(p []byte) (int, error) {
+	for {
+		if r.payload > 0 {
+			n, err := r.Reader.Read(p[:min(len(p), r.payload)])
+			r.payload -= n
+			return n, err
+		}
+		if r.padding > 0 {
+			if _, err := io.CopyN(io.Discard, r.Reader, int64(r.padding)); err != nil {
+				return 0, err
+			}
+			r.padding = 0
+		}
+		if r.frames >= paddingFrames {
+			return r.Reader.Read(p)
+		}
+		var header [3]byte
+		if _, err := io.ReadFull(r.Reader, header[:]); err != nil {
+			return 0, err
+		}
+		r.frames++
+		r.payload = int(binary.BigEndian.Uint16(header[:]))
+		r.padding = int(header[2])
+	}
+}
@@ -0,0 +1,32 @@
+package naive_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/xtls/xray-core/common"
+	. "github.com/xtls/xray-core/proxy/naive"
+)
+
+func TestPadding(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := &PaddingWriter{Writer: &buffer}
+
+	var payload []byte
+	for i := range 10 {
+		b := bytes.Repeat([]byte{byte(i)}, 100*(i+1))
+		common.Must2(writer.Write(b))
+		payload = append(payload, b...)
+	}
+	if buffer.Len() <= len(payload) {
+		t.Error("not padded: ", buffer.Len())
+	}
+
+	data, err := io.ReadAll(&PaddingReader{Reader: &buffer})
+	common.Must(err)
+	if r := cmp.Diff(data, payload); r != "" {
+		t.Error("data: ", r)
+	}
+}
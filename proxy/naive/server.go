@@ -0,0 +1,264 @@
+package naive
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/log"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	http_proto "github.com/xtls/xray-core/common/protocol/http"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/policy"
+	"github.com/xtls/xray-core/features/routing"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet/stat"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler for naive, which tunnels the CONNECT
+// requests over HTTP/2, or HTTP/1.1 if the client doesn't speak HTTP/2.
+type Server struct {
+	policyManager policy.Manager
+	validator     *Validator
+}
+
+// NewServer creates a new naive inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	validator := new(Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, errors.New("failed to get naive user").Base(err).AtError()
+		}
+
+		if err := validator.Add(u); err != nil {
+			return nil, errors.New("failed to add user").Base(err).AtError()
+		}
+	}
+
+	v := core.MustFromContext(ctx)
+	return &Server{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     validator,
+	}, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// GetUser implements proxy.UserManager.GetUser().
+func (s *Server) GetUser(ctx context.Context, email string) *protocol.MemoryUser {
+	return s.validator.GetByEmail(email)
+}
+
+// GetUsers implements proxy.UserManager.GetUsers().
+func (s *Server) GetUsers(ctx context.Context) []*protocol.MemoryUser {
+	return s.validator.GetAll()
+}
+
+// GetUsersCount implements proxy.UserManager.GetUsersCount().
+func (s *Server) GetUsersCount(context.Context) int64 {
+	return s.validator.GetCount()
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP, net.Network_UNIX}
+}
+
+type bufferedConn struct {
+	stat.Connection
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// Process implements proxy.Inbound.Process().
+func (s *Server) Process(ctx context.Context, network net.Network, conn stat.Connection, dispatcher routing.Dispatcher) error {
+	inbound := session.InboundFromContext(ctx)
+	inbound.Name = "naive"
+	inbound.CanSpliceCopy = 3
+
+	sessionPolicy := s.policyManager.ForLevel(0)
+	if err := conn.SetReadDeadline(time.Now().Add(sessionPolicy.Timeouts.Handshake)); err != nil {
+		return errors.New("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	reader := bufio.NewReaderSize(conn, buf.Size)
+	preface, err := reader.Peek(len(http2.ClientPreface))
+	if err != nil {
+		return errors.New("failed to read request").Base(err)
+	}
+
+	if string(preface) == http2.ClientPreface {
+		if err := conn.SetReadDeadline(time.Time{}); err != nil {
+			return errors.New("unable to set read deadline").Base(err).AtWarning()
+		}
+		server := &http2.Server{}
+		server.ServeConn(&bufferedConn{Connection: conn, reader: reader}, &http2.ServeConnOpts{
+			Context: ctx,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				s.serveHTTP2(w, r, conn, dispatcher)
+			}),
+		})
+		return nil
+	}
+
+	request, err := http.ReadRequest(reader)
+	if err != nil {
+		return errors.New("failed to read http request").Base(err)
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return errors.New("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	user, dest, err := s.parseRequest(request)
+	if err != nil {
+		s.reject(conn, request, err)
+		return common.Error2(conn.Write([]byte("HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n")))
+	}
+
+	response := "HTTP/1.1 200 OK\r\n\r\n"
+	padding := request.Header.Get(paddingHeader) != ""
+	if padding {
+		response = "HTTP/1.1 200 OK\r\n" + paddingHeader + ": " + paddingHeaderValue() + "\r\n\r\n"
+	}
+	if _, err := conn.Write([]byte(response)); err != nil {
+		return errors.New("failed to write back OK response").Base(err)
+	}
+	return s.tunnel(ctx, user, dest, reader, conn, padding, dispatcher)
+}
+
+func (s *Server) serveHTTP2(w http.ResponseWriter, r *http.Request, conn stat.Connection, dispatcher routing.Dispatcher) {
+	ctx := r.Context()
+
+	user, dest, err := s.parseRequest(r)
+	if err != nil {
+		s.reject(conn, r, err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	padding := r.Header.Get(paddingHeader) != ""
+	if padding {
+		w.Header().Set(paddingHeader, paddingHeaderValue())
+	}
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
+	if err := s.tunnel(ctx, user, dest, r.Body, &flushWriter{w}, padding, dispatcher); err != nil {
+		errors.LogInfoInner(ctx, err, "stream ends")
+	}
+}
+
+// parseRequest authenticates a CONNECT request and gets its destination.
+func (s *Server) parseRequest(r *http.Request) (*protocol.MemoryUser, net.Destination, error) {
+	if r.Method != http.MethodConnect {
+		return nil, net.Destination{}, errors.New("unexpected method ", r.Method)
+	}
+	username, password, ok := parseBasicAuth(r.Header.Get("Proxy-Authorization"))
+	if !ok {
+		return nil, net.Destination{}, errors.New("no proxy authorization")
+	}
+	user := s.validator.Get(username, password)
+	if user == nil {
+		return nil, net.Destination{}, errors.New("invalid user")
+	}
+	dest, err := http_proto.ParseHost(r.Host, net.Port(443))
+	if err != nil {
+		return nil, net.Destination{}, errors.New("malformed proxy host: ", r.Host).Base(err)
+	}
+	return user, dest, nil
+}
+
+// reject logs the rejected request, which gets a 404 as if the server were a
+// plain web server.
+func (s *Server) reject(conn stat.Connection, r *http.Request, reason error) {
+	log.Record(&log.AccessMessage{
+		From:   conn.RemoteAddr(),
+		To:     r.Host,
+		Status: log.AccessRejected,
+		Reason: reason,
+	})
+}
+
+// tunnel dispatches a CONNECT tunnel of the user, whose streams may have the
+// padding.
+func (s *Server) tunnel(ctx context.Context, user *protocol.MemoryUser, dest net.Destination, reader io.Reader, writer io.Writer, padding bool, dispatcher routing.Dispatcher) error {
+	ctx = session.SubContextFromMuxInbound(ctx)
+	newInbound := *session.InboundFromContext(ctx)
+	newInbound.User = user
+	inbound := &newInbound
+	ctx = session.ContextWithInbound(ctx, inbound)
+
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     dest,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  user.Email,
+	})
+	errors.LogInfo(ctx, "tunnelling request to ", dest)
+
+	if padding {
+		reader = &PaddingReader{Reader: reader}
+		writer = &PaddingWriter{Writer: writer}
+	}
+	if err := dispatcher.DispatchLink(ctx, dest, &transport.Link{
+		Reader: buf.NewReader(reader),
+		Writer: buf.NewWriter(writer),
+	}); err != nil {
+		return errors.New("failed to dispatch request").Base(err)
+	}
+	return nil
+}
+
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (w *flushWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	if err == nil {
+		w.w.(http.Flusher).Flush()
+	}
+	return n, err
+}
+
+func parseBasicAuth(auth string) (username, password string, ok bool) {
+	const prefix = "Basic "
+	if !strings.HasPrefix(auth, prefix) {
+		return
+	}
+	c, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return
+	}
+	return strings.Cut(string(c), ":")
+}
@@ -0,0 +1,92 @@
+package naive
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+)
+
+// Validator stores valid naive users by their usernames.
+type Validator struct {
+	email sync.Map
+	users sync.Map
+}
+
+// Add a naive user, Email must be empty or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	account := u.Account.(*Account)
+	if u.Email != "" {
+		_, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u)
+		if loaded {
+			return errors.New("User ", u.Email, " already exists.")
+		}
+	}
+	if _, loaded := v.users.LoadOrStore(account.Username, u); loaded {
+		if u.Email != "" {
+			v.email.Delete(strings.ToLower(u.Email))
+		}
+		return errors.New("User ", account.Username, " already exists.")
+	}
+	return nil
+}
+
+// Del a naive user with a non-empty Email.
+func (v *Validator) Del(e string) error {
+	if e == "" {
+		return errors.New("Email must not be empty.")
+	}
+	le := strings.ToLower(e)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return errors.New("User ", e, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(u.(*protocol.MemoryUser).Account.(*Account).Username)
+	return nil
+}
+
+// Get a naive user with the username and password, nil if user doesn't
+// exist or the password doesn't match.
+func (v *Validator) Get(username, password string) *protocol.MemoryUser {
+	u, _ := v.users.Load(username)
+	if u == nil {
+		return nil
+	}
+	user := u.(*protocol.MemoryUser)
+	if subtle.ConstantTimeCompare([]byte(user.Account.(*Account).Password), []byte(password)) != 1 {
+		return nil
+	}
+	return user
+}
+
+// GetByEmail gets a naive user with the Email, nil if user doesn't exist.
+func (v *Validator) GetByEmail(email string) *protocol.MemoryUser {
+	u, _ := v.email.Load(strings.ToLower(email))
+	if u != nil {
+		return u.(*protocol.MemoryUser)
+	}
+	return nil
+}
+
+// GetAll gets all users.
+func (v *Validator) GetAll() []*protocol.MemoryUser {
+	var u []*protocol.MemoryUser
+	v.users.Range(func(key, value interface{}) bool {
+		u = append(u, value.(*protocol.MemoryUser))
+		return true
+	})
+	return u
+}
+
+// GetCount gets the count of users.
+func (v *Validator) GetCount() int64 {
+	var c int64
+	v.users.Range(func(key, value interface{}) bool {
+		c++
+		return true
+	})
+	return c
+}
@@ -0,0 +1,314 @@
+package ssh
+
+import (
+	"context"
+	gonet "net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/common/signal"
+	"github.com/xtls/xray-core/common/task"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/policy"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+)
+
+// Client is an outbound connection handler that tunnels the TCP connections
+// in direct-tcpip channels of an SSH connection, which it keeps for the later
+// connections.
+type Client struct {
+	config        *Config
+	server        net.Destination
+	policyManager policy.Manager
+
+	signers           []gossh.Signer
+	hostKeyCallback   gossh.HostKeyCallback
+	hostKeyAlgorithms []string
+
+	access sync.Mutex
+	client *gossh.Client
+}
+
+// NewClient creates a new SSH outbound handler.
+func NewClient(ctx context.Context, config *Config) (*Client, error) {
+	if config.Address == nil {
+		return nil, errors.New("SSH server address is not set")
+	}
+	if config.User == "" {
+		return nil, errors.New("SSH user is not set")
+	}
+	if config.Password == "" && len(config.PrivateKey) == 0 && !config.UseAgent {
+		return nil, errors.New("no SSH authentication method is set")
+	}
+	port := config.Port
+	if port == 0 {
+		port = 22
+	}
+
+	signers, err := config.signers()
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, hostKeyAlgorithms, err := config.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+	if len(config.HostKey) == 0 {
+		errors.LogWarning(ctx, "SSH host key of ", config.Address.AsAddress(), " is not pinned, any host key is accepted")
+	}
+
+	v := core.MustFromContext(ctx)
+	return &Client{
+		config:            config,
+		server:            net.TCPDestination(config.Address.AsAddress(), net.Port(port)),
+		policyManager:     v.GetFeature(policy.ManagerType()).(policy.Manager),
+		signers:           signers,
+		hostKeyCallback:   hostKeyCallback,
+		hostKeyAlgorithms: hostKeyAlgorithms,
+	}, nil
+}
+
+// Close implements common.Closable.Close().
+func (c *Client) Close() error {
+	c.access.Lock()
+	defer c.access.Unlock()
+	if c.client != nil {
+		return c.client.Close()
+	}
+	return nil
+}
+
+// Process implements proxy.Outbound.Process().
+func (c *Client) Process(ctx context.Context, link *transport.Link, dialer internet.Dialer) error {
+	outbounds := session.OutboundsFromContext(ctx)
+	ob := outbounds[len(outbounds)-1]
+	if !ob.Target.IsValid() {
+		return errors.New("target not specified")
+	}
+	ob.Name = "ssh"
+	ob.CanSpliceCopy = 3
+	destination := ob.Target
+	if destination.Network != net.Network_TCP {
+		return errors.New("only TCP is supported by SSH outbound")
+	}
+
+	sessionPolicy := c.policyManager.ForLevel(0)
+
+	conn, err := c.openChannel(ctx, dialer, ob.Tag, destination, sessionPolicy.Timeouts.Handshake)
+	if err != nil {
+		return errors.New("failed to open SSH channel to ", destination).Base(err).AtWarning()
+	}
+	defer conn.Close()
+	errors.LogInfo(ctx, "tunneling request to ", destination, " via ", c.server.NetAddr())
+
+	var newCtx context.Context
+	var newCancel context.CancelFunc
+	if session.TimeoutOnlyFromContext(ctx) {
+		newCtx, newCancel = context.WithCancel(context.Background())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, func() {
+		cancel()
+		if newCancel != nil {
+			newCancel()
+		}
+	}, sessionPolicy.Timeouts.ConnectionIdle)
+
+	postRequest := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+		if err := buf.Copy(link.Reader, buf.NewWriter(conn), buf.UpdateActivity(timer)); err != nil {
+			return errors.New("failed to transfer request payload").Base(err).AtInfo()
+		}
+		return nil
+	}
+
+	getResponse := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+		return buf.Copy(buf.NewReader(conn), link.Writer, buf.UpdateActivity(timer))
+	}
+
+	if newCtx != nil {
+		ctx = newCtx
+	}
+
+	responseDoneAndCloseWriter := task.OnSuccess(getResponse, task.Close(link.Writer))
+	if err := task.Run(ctx, postRequest, responseDoneAndCloseWriter); err != nil {
+		return errors.New("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+// openChannel opens a direct-tcpip channel to the destination, in a new SSH
+// connection if the kept one is broken.
+func (c *Client) openChannel(ctx context.Context, dialer internet.Dialer, tag string, destination net.Destination, timeout time.Duration) (gonet.Conn, error) {
+	for range 2 {
+		client, reused, err := c.getClient(ctx, dialer, tag, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		dialCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			dialCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		conn, err := client.DialContext(dialCtx, "tcp", destination.NetAddr())
+		if err == nil {
+			return conn, nil
+		}
+		if _, ok := err.(*gossh.OpenChannelError); ok || !reused || ctx.Err() != nil {
+			return nil, err
+		}
+		errors.LogInfoInner(ctx, err, "SSH connection to ", c.server.NetAddr(), " is broken, reconnecting")
+		c.drop(client)
+	}
+	return nil, errors.New("failed to reconnect")
+}
+
+// getClient returns the kept SSH connection, or a new one. reused tells
+// whether the connection is the kept one.
+func (c *Client) getClient(ctx context.Context, dialer internet.Dialer, tag string, timeout time.Duration) (*gossh.Client, bool, error) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if c.client != nil {
+		return c.client, true, nil
+	}
+
+	// The connection outlives the request that dials it.
+	dialCtx := session.ContextWithOutbounds(core.ToBackgroundDetachedContext(ctx), []*session.Outbound{{
+		Target: c.server,
+		Tag:    tag,
+	}})
+	client, err := c.dial(dialCtx, dialer, timeout)
+	if err != nil {
+		return nil, false, err
+	}
+	errors.LogInfo(ctx, "established SSH connection to ", c.server.NetAddr())
+	c.client = client
+
+	closed := make(chan struct{})
+	go func() {
+		err := client.Wait()
+		close(closed)
+		errors.LogInfoInner(dialCtx, err, "SSH connection to ", c.server.NetAddr(), " closed")
+		c.drop(client)
+	}()
+	if c.config.KeepAlive > 0 {
+		go c.keepAlive(dialCtx, client, closed, time.Duration(c.config.KeepAlive)*time.Second)
+	}
+	return client, false, nil
+}
+
+// drop closes the SSH connection and forgets it if it's the kept one.
+func (c *Client) drop(client *gossh.Client) {
+	c.access.Lock()
+	if c.client == client {
+		c.client = nil
+	}
+	c.access.Unlock()
+	client.Close()
+}
+
+func (c *Client) dial(ctx context.Context, dialer internet.Dialer, timeout time.Duration) (*gossh.Client, error) {
+	conn, err := dialer.Dial(ctx, c.server)
+	if err != nil {
+		return nil, err
+	}
+
+	var agentConn gonet.Conn
+	if c.config.UseAgent {
+		socket := c.config.AgentSocket
+		if socket == "" {
+			socket = os.Getenv("SSH_AUTH_SOCK")
+		}
+		if socket == "" {
+			conn.Close()
+			return nil, errors.New("SSH agent socket is not set")
+		}
+		agentConn, err = gonet.Dial("unix", socket)
+		if err != nil {
+			conn.Close()
+			return nil, errors.New("failed to connect to SSH agent").Base(err)
+		}
+		defer agentConn.Close()
+	}
+
+	clientConfig := &gossh.ClientConfig{
+		User:              c.config.User,
+		Auth:              c.config.authMethods(c.signers, agentConn),
+		HostKeyCallback:   c.hostKeyCallback,
+		HostKeyAlgorithms: c.hostKeyAlgorithms,
+		ClientVersion:     c.config.ClientVersion,
+	}
+	if clientConfig.ClientVersion != "" && !strings.HasPrefix(clientConfig.ClientVersion, "SSH-2.0-") {
+		clientConfig.ClientVersion = "SSH-2.0-" + clientConfig.ClientVersion
+	}
+
+	// The connection may not support deadlines if it's proxied.
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			conn.Close()
+		})
+		defer timer.Stop()
+	}
+	sshConn, chans, reqs, err := gossh.NewClientConn(conn, c.server.NetAddr(), clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, errors.New("SSH handshake failed").Base(err)
+	}
+	return gossh.NewClient(sshConn, chans, reqs), nil
+}
+
+// keepAlive sends keepalive requests, and closes the connection if one isn't
+// replied in the interval.
+func (c *Client) keepAlive(ctx context.Context, client *gossh.Client, closed <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+
+		replied := make(chan error, 1)
+		go func() {
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			replied <- err
+		}()
+		select {
+		case err := <-replied:
+			if err == nil {
+				continue
+			}
+			errors.LogInfoInner(ctx, err, "SSH keepalive failed")
+		case <-closed:
+			return
+		case <-time.After(interval):
+			errors.LogInfo(ctx, "SSH keepalive timed out")
+		}
+		c.drop(client)
+		return
+	}
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewClient(ctx, config.(*Config))
+	}))
+}
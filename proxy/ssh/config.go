@@ -0,0 +1,106 @@
+package ssh
+
+import (
+	"bytes"
+	gonet "net"
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/xtls/xray-core/common/errors"
+)
+
+// signers parses the private key of the config.
+func (c *Config) signers() ([]gossh.Signer, error) {
+	if len(c.PrivateKey) == 0 {
+		return nil, nil
+	}
+	var signer gossh.Signer
+	var err error
+	if c.PrivateKeyPassphrase != "" {
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(c.PrivateKey, []byte(c.PrivateKeyPassphrase))
+	} else {
+		signer, err = gossh.ParsePrivateKey(c.PrivateKey)
+	}
+	if err != nil {
+		return nil, errors.New("failed to parse private key").Base(err)
+	}
+	return []gossh.Signer{signer}, nil
+}
+
+// authMethods returns the auth methods of the config, tried in the order of
+// public keys and password. agentConn is the connection to the agent if it's
+// used.
+func (c *Config) authMethods(signers []gossh.Signer, agentConn gonet.Conn) []gossh.AuthMethod {
+	var methods []gossh.AuthMethod
+	if agentConn != nil {
+		agentClient := agent.NewClient(agentConn)
+		methods = append(methods, gossh.PublicKeysCallback(func() ([]gossh.Signer, error) {
+			agentSigners, err := agentClient.Signers()
+			if err != nil {
+				return nil, err
+			}
+			return append(signers, agentSigners...), nil
+		}))
+	} else if len(signers) > 0 {
+		methods = append(methods, gossh.PublicKeys(signers...))
+	}
+	if c.Password != "" {
+		methods = append(methods, gossh.Password(c.Password))
+	}
+	return methods
+}
+
+// hostKeyCallback checks the host key against the pinned keys, and returns
+// the algorithms of the pinned keys to negotiate.
+func (c *Config) hostKeyCallback() (gossh.HostKeyCallback, []string, error) {
+	if len(c.HostKey) == 0 {
+		return gossh.InsecureIgnoreHostKey(), nil, nil
+	}
+
+	var keys [][]byte
+	var fingerprints []string
+	var algorithms []string
+	for _, s := range c.HostKey {
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "SHA256:") {
+			fingerprints = append(fingerprints, s)
+			continue
+		}
+		key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(s))
+		if err != nil {
+			return nil, nil, errors.New("failed to parse host key: ", s).Base(err)
+		}
+		keys = append(keys, key.Marshal())
+		algorithms = append(algorithms, hostKeyAlgorithms(key.Type())...)
+	}
+	if len(fingerprints) > 0 {
+		// Fingerprints don't tell the algorithms.
+		algorithms = nil
+	}
+
+	return func(hostname string, remote gonet.Addr, key gossh.PublicKey) error {
+		b := key.Marshal()
+		for _, k := range keys {
+			if bytes.Equal(k, b) {
+				return nil
+			}
+		}
+		fingerprint := gossh.FingerprintSHA256(key)
+		for _, f := range fingerprints {
+			if f == fingerprint {
+				return nil
+			}
+		}
+		return errors.New("host key mismatch: ", fingerprint)
+	}, algorithms, nil
+}
+
+// hostKeyAlgorithms returns the signature algorithms of the key type.
+func hostKeyAlgorithms(keyType string) []string {
+	if keyType == gossh.KeyAlgoRSA {
+		return []string{gossh.KeyAlgoRSASHA512, gossh.KeyAlgoRSASHA256, gossh.KeyAlgoRSA}
+	}
+	return []string{keyType}
+}
@@ -0,0 +1,225 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: proxy/ssh/config.proto
+
+package ssh
+
+import (
+	net "github.com/xtls/xray-core/common/net"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Config struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Address  *net.IPOrDomain        `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Port     uint32                 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	User     string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Password string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	// Private key in PEM or OpenSSH format.
+	PrivateKey           []byte `protobuf:"bytes,5,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
+	PrivateKeyPassphrase string `protobuf:"bytes,6,opt,name=private_key_passphrase,json=privateKeyPassphrase,proto3" json:"private_key_passphrase,omitempty"`
+	// Authenticates with the keys of the agent at the socket, or at
+	// SSH_AUTH_SOCK if it's empty.
+	UseAgent    bool   `protobuf:"varint,7,opt,name=use_agent,json=useAgent,proto3" json:"use_agent,omitempty"`
+	AgentSocket string `protobuf:"bytes,8,opt,name=agent_socket,json=agentSocket,proto3" json:"agent_socket,omitempty"`
+	// Pinned host keys, in authorized_keys format or as SHA256 fingerprints.
+	// Any host key is accepted if empty.
+	HostKey []string `protobuf:"bytes,9,rep,name=host_key,json=hostKey,proto3" json:"host_key,omitempty"`
+	// Seconds between keepalive requests, 0 to disable.
+	KeepAlive     uint32 `protobuf:"varint,10,opt,name=keep_alive,json=keepAlive,proto3" json:"keep_alive,omitempty"`
+	ClientVersion string `protobuf:"bytes,11,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_proxy_ssh_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_ssh_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_proxy_ssh_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Config) GetAddress() *net.IPOrDomain {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *Config) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Config) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Config) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Config) GetPrivateKey() []byte {
+	if x != nil {
+		return x.PrivateKey
+	}
+	return nil
+}
+
+func (x *Config) GetPrivateKeyPassphrase() string {
+	if x != nil {
+		return x.PrivateKeyPassphrase
+	}
+	return ""
+}
+
+func (x *Config) GetUseAgent() bool {
+	if x != nil {
+		return x.UseAgent
+	}
+	return false
+}
+
+func (x *Config) GetAgentSocket() string {
+	if x != nil {
+		return x.AgentSocket
+	}
+	return ""
+}
+
+func (x *Config) GetHostKey() []string {
+	if x != nil {
+		return x.HostKey
+	}
+	return nil
+}
+
+func (x *Config) GetKeepAlive() uint32 {
+	if x != nil {
+		return x.KeepAlive
+	}
+	return 0
+}
+
+func (x *Config) GetClientVersion() string {
+	if x != nil {
+		return x.ClientVersion
+	}
+	return ""
+}
+
+var File_proxy_ssh_config_proto protoreflect.FileDescriptor
+
+const file_proxy_ssh_config_proto_rawDesc = "" +
+	"\n" +
+	"\x16proxy/ssh/config.proto\x12\x0exray.proxy.ssh\x1a\x18common/net/address.proto\"\xfb\x02\n" +
+	"\x06Config\x125\n" +
+	"\aaddress\x18\x01 \x01(\v2\x1b.xray.common.net.IPOrDomainR\aaddress\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\rR\x04port\x12\x12\n" +
+	"\x04user\x18\x03 \x01(\tR\x04user\x12\x1a\n" +
+	"\bpassword\x18\x04 \x01(\tR\bpassword\x12\x1f\n" +
+	"\vprivate_key\x18\x05 \x01(\fR\n" +
+	"privateKey\x124\n" +
+	"\x16private_key_passphrase\x18\x06 \x01(\tR\x14privateKeyPassphrase\x12\x1b\n" +
+	"\tuse_agent\x18\a \x01(\bR\buseAgent\x12!\n" +
+	"\fagent_socket\x18\b \x01(\tR\vagentSocket\x12\x19\n" +
+	"\bhost_key\x18\t \x03(\tR\ahostKey\x12\x1d\n" +
+	"\n" +
+	"keep_alive\x18\n" +
+	" \x01(\rR\tkeepAlive\x12%\n" +
+	"\x0eclient_version\x18\v \x01(\tR\rclientVersionBL\n" +
+	"\x12com.xray.proxy.sshP\x01Z#github.com/xtls/xray-core/proxy/ssh\xaa\x02\x0eXray.Proxy.Sshb\x06proto3"
+
+var (
+	file_proxy_ssh_config_proto_rawDescOnce sync.Once
+	file_proxy_ssh_config_proto_rawDescData []byte
+)
+
+func file_proxy_ssh_config_proto_rawDescGZIP() []byte {
+	file_proxy_ssh_config_proto_rawDescOnce.Do(func() {
+		file_proxy_ssh_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proxy_ssh_config_proto_rawDesc), len(file_proxy_ssh_config_proto_rawDesc)))
+	})
+	return file_proxy_ssh_config_proto_rawDescData
+}
+
+var file_proxy_ssh_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_proxy_ssh_config_proto_goTypes = []any{
+	(*Config)(nil),         // 0: xray.proxy.ssh.Config
+	(*net.IPOrDomain)(nil), // 1: xray.common.net.IPOrDomain
+}
+var file_proxy_ssh_config_proto_depIdxs = []int32{
+	1, // 0: xray.proxy.ssh.Config.address:type_name -> xray.common.net.IPOrDomain
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proxy_ssh_config_proto_init() }
+func file_proxy_ssh_config_proto_init() {
+	if File_proxy_ssh_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_ssh_config_proto_rawDesc), len(file_proxy_ssh_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proxy_ssh_config_proto_goTypes,
+		DependencyIndexes: file_proxy_ssh_config_proto_depIdxs,
+		MessageInfos:      file_proxy_ssh_config_proto_msgTypes,
+	}.Build()
+	File_proxy_ssh_config_proto = out.File
+	file_proxy_ssh_config_proto_goTypes = nil
+	file_proxy_ssh_config_proto_depIdxs = nil
+}
@@ -14,13 +14,13 @@ import (
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
 	udp_proto "github.com/xtls/xray-core/common/protocol/udp"
-	"github.com/xtls/xray-core/common/retry"
 	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/common/signal"
 	"github.com/xtls/xray-core/common/task"
 	"github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/features/policy"
 	"github.com/xtls/xray-core/features/routing"
+	"github.com/xtls/xray-core/proxy/fallback"
 	"github.com/xtls/xray-core/transport/internet/reality"
 	"github.com/xtls/xray-core/transport/internet/stat"
 	"github.com/xtls/xray-core/transport/internet/tls"
@@ -38,6 +38,8 @@ type Server struct {
 	policyManager policy.Manager
 	validator     *Validator
 	fallbacks     map[string]map[string]map[string]*Fallback // or nil
+	fallbackRules []*fallback.Rule
+	fallbackPools map[*Fallback]*fallback.Pool
 	cone          bool
 }
 
@@ -64,7 +66,17 @@ func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
 
 	if config.Fallbacks != nil {
 		server.fallbacks = make(map[string]map[string]map[string]*Fallback)
+		server.fallbackPools = make(map[*Fallback]*fallback.Pool)
 		for _, fb := range config.Fallbacks {
+			server.fallbackPools[fb] = fallback.NewPool(fb)
+			if fallback.IsAdvanced(fb) {
+				matcher, err := fallback.NewMatcher(fb)
+				if err != nil {
+					return nil, errors.New("invalid fallback").Base(err).AtError()
+				}
+				server.fallbackRules = append(server.fallbackRules, &fallback.Rule{Matcher: matcher, Config: fb})
+				continue
+			}
 			if server.fallbacks[fb.Name] == nil {
 				server.fallbacks[fb.Name] = make(map[string]map[string]*Fallback)
 			}
@@ -115,6 +127,15 @@ func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
 	return server, nil
 }
 
+// Close implements common.Closable.Close().
+func (s *Server) Close() error {
+	var errs []error
+	for _, pool := range s.fallbackPools {
+		errs = append(errs, pool.Close())
+	}
+	return errors.Combine(errs...)
+}
+
 // AddUser implements proxy.UserManager.AddUser().
 func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
 	return s.validator.Add(u)
@@ -385,82 +406,28 @@ func (s *Server) fallback(ctx context.Context, err error, sessionPolicy policy.S
 	name = strings.ToLower(name)
 	alpn = strings.ToLower(alpn)
 
-	if len(napfb) > 1 || napfb[""] == nil {
-		if name != "" && napfb[name] == nil {
-			match := ""
-			for n := range napfb {
-				if n != "" && strings.Contains(name, n) && len(n) > len(match) {
-					match = n
-				}
-			}
-			name = match
-		}
-	}
-
-	if napfb[name] == nil {
-		name = ""
-	}
-	apfb := napfb[name]
-	if apfb == nil {
-		return errors.New(`failed to find the default "name" config`).AtWarning()
-	}
-
-	if apfb[alpn] == nil {
-		alpn = ""
-	}
-	pfb := apfb[alpn]
-	if pfb == nil {
-		return errors.New(`failed to find the default "alpn" config`).AtWarning()
-	}
-
-	path := ""
-	if len(pfb) > 1 || pfb[""] == nil {
-		if firstLen >= 18 && first.Byte(4) != '*' { // not h2c
-			firstBytes := first.Bytes()
-			for i := 4; i <= 8; i++ { // 5 -> 9
-				if firstBytes[i] == '/' && firstBytes[i-1] == ' ' {
-					search := len(firstBytes)
-					if search > 64 {
-						search = 64 // up to about 60
-					}
-					for j := i + 1; j < search; j++ {
-						k := firstBytes[j]
-						if k == '\r' || k == '\n' { // avoid logging \r or \n
-							break
-						}
-						if k == '?' || k == ' ' {
-							path = string(firstBytes[i:j])
-							errors.LogInfo(ctx, "realPath = "+path)
-							if pfb[path] == nil {
-								path = ""
-							}
-							break
-						}
-					}
-					break
-				}
-			}
+	var fb *Fallback
+	if len(s.fallbackRules) > 0 {
+		request := &fallback.Request{Name: name, Alpn: alpn}
+		request.Parse(first.Bytes())
+		if c := fallback.Match(s.fallbackRules, request); c != nil {
+			fb = c.(*Fallback)
 		}
 	}
-	fb := pfb[path]
 	if fb == nil {
-		return errors.New(`failed to find the default "path" config`).AtWarning()
+		if fb, err = lookupFallback(ctx, napfb, name, alpn, first, firstLen); err != nil {
+			return err
+		}
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
 	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
 
-	var conn net.Conn
-	if err := retry.ExponentialBackoff(5, 100).On(func() error {
-		var dialer net.Dialer
-		conn, err = dialer.DialContext(ctx, fb.Type, fb.Dest)
-		if err != nil {
-			return err
-		}
-		return nil
-	}); err != nil {
-		return errors.New("failed to dial to " + fb.Dest).Base(err).AtWarning()
+	pool := s.fallbackPools[fb]
+	conn, err := pool.Dial(ctx)
+	if err != nil {
+		return errors.New("failed to dial to " + pool.String()).Base(err).AtWarning()
 	}
 	defer conn.Close()
 
@@ -547,3 +514,68 @@ func (s *Server) fallback(ctx context.Context, err error, sessionPolicy policy.S
 
 	return nil
 }
+
+// lookupFallback finds the fallback by name, alpn and path.
+func lookupFallback(ctx context.Context, napfb map[string]map[string]map[string]*Fallback, name, alpn string, first *buf.Buffer, firstLen int64) (*Fallback, error) {
+	if len(napfb) > 1 || napfb[""] == nil {
+		if name != "" && napfb[name] == nil {
+			match := ""
+			for n := range napfb {
+				if n != "" && strings.Contains(name, n) && len(n) > len(match) {
+					match = n
+				}
+			}
+			name = match
+		}
+	}
+
+	if napfb[name] == nil {
+		name = ""
+	}
+	apfb := napfb[name]
+	if apfb == nil {
+		return nil, errors.New(`failed to find the default "name" config`).AtWarning()
+	}
+
+	if apfb[alpn] == nil {
+		alpn = ""
+	}
+	pfb := apfb[alpn]
+	if pfb == nil {
+		return nil, errors.New(`failed to find the default "alpn" config`).AtWarning()
+	}
+
+	path := ""
+	if len(pfb) > 1 || pfb[""] == nil {
+		if firstLen >= 18 && first.Byte(4) != '*' { // not h2c
+			firstBytes := first.Bytes()
+			for i := 4; i <= 8; i++ { // 5 -> 9
+				if firstBytes[i] == '/' && firstBytes[i-1] == ' ' {
+					search := len(firstBytes)
+					if search > 64 {
+						search = 64 // up to about 60
+					}
+					for j := i + 1; j < search; j++ {
+						k := firstBytes[j]
+						if k == '\r' || k == '\n' { // avoid logging \r or \n
+							break
+						}
+						if k == '?' || k == ' ' {
+							path = string(firstBytes[i:j])
+							errors.LogInfo(ctx, "realPath = "+path)
+							if pfb[path] == nil {
+								path = ""
+							}
+							break
+						}
+					}
+					break
+				}
+			}
+		}
+	}
+	if fb := pfb[path]; fb != nil {
+		return fb, nil
+	}
+	return nil, errors.New(`failed to find the default "path" config`).AtWarning()
+}
@@ -0,0 +1,113 @@
+package account
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+func (a *Account) AsAccount() (protocol.Account, error) {
+	id, err := uuid.ParseString(a.Id)
+	if err != nil {
+		return nil, errors.New("failed to parse ID").Base(err).AtError()
+	}
+	return &MemoryAccount{
+		ID:       id,
+		Password: a.Password,
+	}, nil
+}
+
+type MemoryAccount struct {
+	ID       uuid.UUID
+	Password string
+}
+
+func (a *MemoryAccount) Equals(other protocol.Account) bool {
+	if b, ok := other.(*MemoryAccount); ok {
+		return a.ID == b.ID
+	}
+	return false
+}
+
+func (a *MemoryAccount) ToProto() proto.Message {
+	return &Account{
+		Id:       a.ID.String(),
+		Password: a.Password,
+	}
+}
+
+// Validator stores the TUIC users by UUID.
+type Validator struct {
+	email sync.Map
+	users sync.Map
+}
+
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// Add adds a user, whose UUID must be unique, and whose Email must be empty
+// or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	id := u.Account.(*MemoryAccount).ID
+	if _, loaded := v.users.LoadOrStore(id, u); loaded {
+		return errors.New("User ", id.String(), " already exists.")
+	}
+	if u.Email != "" {
+		if _, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u); loaded {
+			v.users.Delete(id)
+			return errors.New("User ", u.Email, " already exists.")
+		}
+	}
+	return nil
+}
+
+// DelByEmail deletes the user with a non-empty Email.
+func (v *Validator) DelByEmail(email string) error {
+	if email == "" {
+		return errors.New("Email must not be empty.")
+	}
+	le := strings.ToLower(email)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return errors.New("User ", email, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(u.(*protocol.MemoryUser).Account.(*MemoryAccount).ID)
+	return nil
+}
+
+// Get returns the user of the UUID, or nil if it doesn't exist.
+func (v *Validator) Get(id uuid.UUID) *protocol.MemoryUser {
+	if u, ok := v.users.Load(id); ok {
+		return u.(*protocol.MemoryUser)
+	}
+	return nil
+}
+
+func (v *Validator) GetByEmail(email string) *protocol.MemoryUser {
+	if u, ok := v.email.Load(strings.ToLower(email)); ok {
+		return u.(*protocol.MemoryUser)
+	}
+	return nil
+}
+
+func (v *Validator) GetAll() (users []*protocol.MemoryUser) {
+	v.users.Range(func(key, value any) bool {
+		users = append(users, value.(*protocol.MemoryUser))
+		return true
+	})
+	return
+}
+
+func (v *Validator) GetCount() (count int64) {
+	v.users.Range(func(key, value any) bool {
+		count++
+		return true
+	})
+	return
+}
@@ -0,0 +1,263 @@
+package tuic
+
+import (
+	"context"
+	go_errors "errors"
+	"io"
+
+	"github.com/apernet/quic-go"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/common/signal"
+	"github.com/xtls/xray-core/common/task"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/policy"
+	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/internet"
+	"github.com/xtls/xray-core/transport/internet/stat"
+	"github.com/xtls/xray-core/transport/internet/tuic"
+)
+
+type Client struct {
+	server        *protocol.ServerSpec
+	policyManager policy.Manager
+}
+
+func NewClient(ctx context.Context, config *ClientConfig) (*Client, error) {
+	v := core.MustFromContext(ctx)
+	p := v.GetFeature(policy.ManagerType()).(policy.Manager)
+
+	streamSettings := session.StreamSettingsFromContext(ctx).(*internet.MemoryStreamConfig)
+	if _, ok := streamSettings.ProtocolSettings.(*tuic.Config); !ok {
+		return nil, errors.New("not tuic transport")
+	}
+	if config.Server == nil {
+		return nil, errors.New(`no target server found`)
+	}
+	server, err := protocol.NewServerSpecFromPB(config.Server)
+	if err != nil {
+		return nil, errors.New("failed to get server spec").Base(err)
+	}
+
+	return &Client{
+		server:        server,
+		policyManager: p,
+	}, nil
+}
+
+func (c *Client) Process(ctx context.Context, link *transport.Link, dialer internet.Dialer) error {
+	outbounds := session.OutboundsFromContext(ctx)
+	ob := outbounds[len(outbounds)-1]
+	if !ob.Target.IsValid() {
+		return errors.New("target not specified")
+	}
+	ob.Name = "tuic"
+	ob.CanSpliceCopy = 3
+	target := ob.Target
+
+	conn, err := dialer.Dial(tuic.ContextWithDatagram(ctx, target.Network == net.Network_UDP), c.server.Destination)
+	if err != nil {
+		return errors.New("failed to find an available destination").AtWarning().Base(err)
+	}
+	defer conn.Close()
+	errors.LogInfo(ctx, "tunneling request to ", target, " via ", target.Network, ":", c.server.Destination.NetAddr())
+
+	var newCtx context.Context
+	var newCancel context.CancelFunc
+	if session.TimeoutOnlyFromContext(ctx) {
+		newCtx, newCancel = context.WithCancel(context.Background())
+	}
+
+	sessionPolicy := c.policyManager.ForLevel(0)
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, func() {
+		cancel()
+		if newCancel != nil {
+			newCancel()
+		}
+	}, sessionPolicy.Timeouts.ConnectionIdle)
+
+	if newCtx != nil {
+		ctx = newCtx
+	}
+
+	var requestDone, responseDone func() error
+
+	if target.Network == net.Network_TCP {
+		requestDone = func() error {
+			defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+			bufferedWriter := buf.NewBufferedWriter(buf.NewWriter(conn))
+			if err := WriteTCPRequest(bufferedWriter, target); err != nil {
+				return errors.New("failed to write request").Base(err)
+			}
+			if err := bufferedWriter.SetBuffered(false); err != nil {
+				return err
+			}
+			return buf.Copy(link.Reader, bufferedWriter, buf.UpdateActivity(timer))
+		}
+
+		responseDone = func() error {
+			defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+			return buf.Copy(buf.NewReader(conn), link.Writer, buf.UpdateActivity(timer))
+		}
+	} else {
+		iConn := stat.TryUnwrapStatsConn(conn)
+		if _, ok := iConn.(*tuic.InterConn); !ok {
+			return errors.New("udp requires tuic udp transport")
+		}
+
+		requestDone = func() error {
+			defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+			writer := &UDPWriter{
+				writer: conn,
+				addr:   target,
+			}
+
+			if err := buf.Copy(link.Reader, writer, buf.UpdateActivity(timer)); err != nil {
+				return errors.New("failed to transport all UDP request").Base(err)
+			}
+
+			return nil
+		}
+
+		responseDone = func() error {
+			defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+			reader := &UDPReader{
+				reader: conn,
+				df:     &Defragger{},
+			}
+
+			if err := buf.Copy(reader, link.Writer, buf.UpdateActivity(timer)); err != nil {
+				return errors.New("failed to transport all UDP response").Base(err)
+			}
+
+			return nil
+		}
+	}
+
+	responseDoneAndCloseWriter := task.OnSuccess(responseDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDone, responseDoneAndCloseWriter); err != nil {
+		return errors.New("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*ClientConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewClient(ctx, config.(*ClientConfig))
+	}))
+}
+
+// UDPWriter writes packet commands to a UDP association, and fragments the
+// packets that don't fit in a datagram.
+type UDPWriter struct {
+	writer   io.Writer
+	addr     net.Destination
+	packetID uint16
+}
+
+func (w *UDPWriter) sendPacket(p *Packet) error {
+	b := buf.NewWithSize(int32(p.Size()))
+	defer b.Release()
+	if err := p.Serialize(b); err != nil {
+		return err
+	}
+	_, err := w.writer.Write(b.Bytes())
+	return err
+}
+
+func (w *UDPWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	for i, b := range mb {
+		addr := w.addr
+		if b.UDP != nil {
+			addr = *b.UDP
+		}
+		addr.Network = net.Network_UDP
+
+		w.packetID++
+		p := &Packet{
+			PacketID:  w.packetID,
+			FragCount: 1,
+			Addr:      &addr,
+			Data:      b.Bytes(),
+		}
+
+		err := w.sendPacket(p)
+		var errTooLarge *quic.DatagramTooLargeError
+		if go_errors.As(err, &errTooLarge) {
+			for _, frag := range FragPacket(p, int(errTooLarge.MaxDatagramPayloadSize)) {
+				if err := w.sendPacket(frag); err != nil {
+					buf.ReleaseMulti(mb[i:])
+					return err
+				}
+			}
+		} else if err != nil {
+			buf.ReleaseMulti(mb[i:])
+			return err
+		}
+
+		b.Release()
+	}
+
+	return nil
+}
+
+type UDPReader struct {
+	reader   io.Reader
+	df       *Defragger
+	firstBuf *buf.Buffer
+	buf      []byte
+}
+
+func (r *UDPReader) ReadFrom(p []byte) (n int, addr *net.Destination, err error) {
+	if r.buf == nil {
+		r.buf = make([]byte, tuic.MaxPacketSize)
+	}
+	for {
+		n, err := r.reader.Read(r.buf)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		packet, err := ParsePacket(r.buf[:n])
+		if err != nil {
+			continue
+		}
+
+		packet = r.df.Feed(packet)
+		if packet == nil || packet.Addr == nil {
+			continue
+		}
+
+		if len(p) < len(packet.Data) {
+			continue
+		}
+
+		return copy(p, packet.Data), packet.Addr, nil
+	}
+}
+
+func (r *UDPReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	if r.firstBuf != nil {
+		mb := buf.MultiBuffer{r.firstBuf}
+		r.firstBuf = nil
+		return mb, nil
+	}
+	b := buf.New()
+	b.Resize(0, buf.Size)
+	n, addr, err := r.ReadFrom(b.Bytes())
+	if err != nil {
+		b.Release()
+		return nil, err
+	}
+	b.Resize(0, int32(n))
+	b.UDP = addr
+	return buf.MultiBuffer{b}, nil
+}
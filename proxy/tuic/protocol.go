@@ -0,0 +1,223 @@
+package tuic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/transport/internet/tuic"
+)
+
+var addrParser = protocol.NewAddressParser(
+	protocol.AddressFamilyByte(0x00, net.AddressFamilyDomain),
+	protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+	protocol.AddressFamilyByte(0x02, net.AddressFamilyIPv6),
+)
+
+// addrTypeNone is the address type of the fragments after the first one,
+// which carry no address.
+const addrTypeNone = 0xff
+
+// ReadTCPRequest reads the address of the connect command, after its version
+// and type.
+func ReadTCPRequest(r io.Reader) (net.Destination, error) {
+	buffer := buf.StackNew()
+	defer buffer.Release()
+
+	addr, port, err := addrParser.ReadAddressPort(&buffer, r)
+	if err != nil {
+		return net.Destination{}, err
+	}
+	return net.TCPDestination(addr, port), nil
+}
+
+func WriteTCPRequest(w io.Writer, dest net.Destination) error {
+	buffer := buf.StackNew()
+	defer buffer.Release()
+
+	if err := addrParser.WriteAddressPort(&buffer, dest.Address, dest.Port); err != nil {
+		return err
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// Packet format:
+// Version (byte)
+// Type (byte)
+// Association ID (uint16 BE)
+// Packet ID (uint16 BE)
+// Fragment count (uint8)
+// Fragment ID (uint8)
+// Data size (uint16 BE)
+// Address type (byte), address and port (uint16 BE)
+// Data...
+
+type Packet struct {
+	PacketID  uint16
+	FragCount uint8
+	FragID    uint8
+	// Addr is nil in the fragments after the first one.
+	Addr *net.Destination
+	Data []byte
+}
+
+func (p *Packet) HeaderSize() int {
+	n := 10 + 1
+	if p.Addr != nil {
+		switch p.Addr.Address.Family() {
+		case net.AddressFamilyIPv4:
+			n += 4 + 2
+		case net.AddressFamilyIPv6:
+			n += 16 + 2
+		default:
+			n += 1 + len(p.Addr.Address.Domain()) + 2
+		}
+	}
+	return n
+}
+
+func (p *Packet) Size() int {
+	return p.HeaderSize() + len(p.Data)
+}
+
+// Serialize writes the packet command into the buffer. The transport sets its
+// association ID.
+func (p *Packet) Serialize(b *buf.Buffer) error {
+	header := b.Extend(10)
+	header[0] = tuic.Version
+	header[1] = tuic.CommandPacket
+	binary.BigEndian.PutUint16(header[4:], p.PacketID)
+	header[6] = p.FragCount
+	header[7] = p.FragID
+	binary.BigEndian.PutUint16(header[8:], uint16(len(p.Data)))
+	if p.Addr == nil {
+		if err := b.WriteByte(addrTypeNone); err != nil {
+			return err
+		}
+	} else if err := addrParser.WriteAddressPort(b, p.Addr.Address, p.Addr.Port); err != nil {
+		return err
+	}
+	_, err := b.Write(p.Data)
+	return err
+}
+
+func ParsePacket(d []byte) (*Packet, error) {
+	if len(d) < 11 || d[0] != tuic.Version || d[1] != tuic.CommandPacket {
+		return nil, errors.New("invalid packet")
+	}
+	p := &Packet{
+		PacketID:  binary.BigEndian.Uint16(d[4:]),
+		FragCount: d[6],
+		FragID:    d[7],
+	}
+	size := int(binary.BigEndian.Uint16(d[8:]))
+	r := bytes.NewReader(d[10:])
+	if d[10] == addrTypeNone {
+		r.Seek(1, io.SeekCurrent)
+	} else {
+		buffer := buf.StackNew()
+		addr, port, err := addrParser.ReadAddressPort(&buffer, r)
+		buffer.Release()
+		if err != nil {
+			return nil, err
+		}
+		dest := net.UDPDestination(addr, port)
+		p.Addr = &dest
+	}
+	if r.Len() != size {
+		return nil, errors.New("invalid data size")
+	}
+	p.Data = d[len(d)-size:]
+	return p, nil
+}
+
+// FragPacket splits the packet into fragments of at most maxSize bytes, of
+// which only the first one carries the address.
+func FragPacket(p *Packet, maxSize int) []*Packet {
+	if p.Size() <= maxSize {
+		return []*Packet{p}
+	}
+	first := maxSize - p.HeaderSize()
+	rest := maxSize - (&Packet{}).HeaderSize()
+	if first <= 0 {
+		return nil
+	}
+	count := 1 + (len(p.Data)-first+rest-1)/rest
+	if count > 0xFF {
+		return nil
+	}
+	frags := make([]*Packet, 0, count)
+	off := 0
+	for i := 0; off < len(p.Data); i++ {
+		size := rest
+		frag := &Packet{
+			PacketID:  p.PacketID,
+			FragCount: uint8(count),
+			FragID:    uint8(i),
+		}
+		if i == 0 {
+			size = first
+			frag.Addr = p.Addr
+		}
+		size = min(size, len(p.Data)-off)
+		frag.Data = p.Data[off : off+size]
+		frags = append(frags, frag)
+		off += size
+	}
+	return frags
+}
+
+// Defragger handles the defragmentation of packets.
+// Like the one of Hysteria, it only handles one packet ID at a time, and
+// discards the previous fragments on a new one.
+type Defragger struct {
+	pktID uint16
+	frags []*Packet
+	count uint8
+	size  int
+}
+
+func (d *Defragger) Feed(p *Packet) *Packet {
+	if p.FragCount <= 1 {
+		return p
+	}
+	if p.FragID >= p.FragCount {
+		return nil
+	}
+	// The fragments are kept across reads, which reuse the buffer.
+	p.Data = bytes.Clone(p.Data)
+	if p.PacketID != d.pktID || p.FragCount != uint8(len(d.frags)) {
+		d.pktID = p.PacketID
+		d.frags = make([]*Packet, p.FragCount)
+		d.frags[p.FragID] = p
+		d.count = 1
+		d.size = len(p.Data)
+	} else if d.frags[p.FragID] == nil {
+		d.frags[p.FragID] = p
+		d.count++
+		d.size += len(p.Data)
+		if int(d.count) == len(d.frags) {
+			addr := d.frags[0].Addr
+			if addr == nil {
+				return nil
+			}
+			data := make([]byte, 0, d.size)
+			for _, frag := range d.frags {
+				data = append(data, frag.Data...)
+			}
+			d.frags = nil
+			return &Packet{
+				PacketID:  p.PacketID,
+				FragCount: 1,
+				Addr:      addr,
+				Data:      data,
+			}
+		}
+	}
+	return nil
+}
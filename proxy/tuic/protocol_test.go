@@ -0,0 +1,71 @@
+package tuic_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+	. "github.com/xtls/xray-core/proxy/tuic"
+)
+
+func TestTCPRequest(t *testing.T) {
+	dest := net.TCPDestination(net.DomainAddress("example.com"), 443)
+
+	b := buf.New()
+	defer b.Release()
+	common.Must(WriteTCPRequest(b, dest))
+	if r := cmp.Diff(b.Bytes(), append([]byte{0x00, 11}, append([]byte("example.com"), 0x01, 0xbb)...)); r != "" {
+		t.Error(r)
+	}
+
+	actual, err := ReadTCPRequest(b)
+	common.Must(err)
+	if r := cmp.Diff(actual, dest); r != "" {
+		t.Error(r)
+	}
+}
+
+func TestPacketFragment(t *testing.T) {
+	dest := net.UDPDestination(net.IPAddress([]byte{1, 2, 3, 4}), 53)
+	data := bytes.Repeat([]byte{1, 2, 3, 4, 5, 6, 7}, 300)
+
+	packet := &Packet{
+		PacketID:  7,
+		FragCount: 1,
+		Addr:      &dest,
+		Data:      data,
+	}
+	frags := FragPacket(packet, 1000)
+	if len(frags) != 3 {
+		t.Fatal("expected 3 fragments, got ", len(frags))
+	}
+
+	df := &Defragger{}
+	var actual *Packet
+	for i, frag := range frags {
+		b := buf.New()
+		common.Must(frag.Serialize(b))
+		if b.Len() > 1000 {
+			t.Error("fragment ", i, " is too large: ", b.Len())
+		}
+		parsed, err := ParsePacket(b.Bytes())
+		common.Must(err)
+		if (i == 0) != (parsed.Addr != nil) {
+			t.Error("unexpected address of fragment ", i)
+		}
+		actual = df.Feed(parsed)
+		b.Release()
+	}
+	if actual == nil {
+		t.Fatal("failed to defragment")
+	}
+	if r := cmp.Diff(*actual.Addr, dest); r != "" {
+		t.Error(r)
+	}
+	if r := cmp.Diff(actual.Data, data); r != "" {
+		t.Error(r)
+	}
+}
@@ -7,12 +7,11 @@
 package tun
 
 import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
-
-	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
-	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 )
 
 const (
@@ -22,6 +21,55 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type DNSHijack int32
+
+const (
+	DNSHijack_None       DNSHijack = 0
+	DNSHijack_Configured DNSHijack = 1
+	DNSHijack_All        DNSHijack = 2
+)
+
+// Enum value maps for DNSHijack.
+var (
+	DNSHijack_name = map[int32]string{
+		0: "None",
+		1: "Configured",
+		2: "All",
+	}
+	DNSHijack_value = map[string]int32{
+		"None":       0,
+		"Configured": 1,
+		"All":        2,
+	}
+)
+
+func (x DNSHijack) Enum() *DNSHijack {
+	p := new(DNSHijack)
+	*p = x
+	return p
+}
+
+func (x DNSHijack) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DNSHijack) Descriptor() protoreflect.EnumDescriptor {
+	return file_proxy_tun_config_proto_enumTypes[0].Descriptor()
+}
+
+func (DNSHijack) Type() protoreflect.EnumType {
+	return &file_proxy_tun_config_proto_enumTypes[0]
+}
+
+func (x DNSHijack) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DNSHijack.Descriptor instead.
+func (DNSHijack) EnumDescriptor() ([]byte, []int) {
+	return file_proxy_tun_config_proto_rawDescGZIP(), []int{0}
+}
+
 type Config struct {
 	state                  protoimpl.MessageState `protogen:"open.v1"`
 	Name                   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -32,6 +80,8 @@ type Config struct {
 	AutoSystemRoutingTable []string               `protobuf:"bytes,6,rep,name=auto_system_routing_table,json=autoSystemRoutingTable,proto3" json:"auto_system_routing_table,omitempty"`
 	AutoOutboundsInterface string                 `protobuf:"bytes,7,opt,name=auto_outbounds_interface,json=autoOutboundsInterface,proto3" json:"auto_outbounds_interface,omitempty"`
 	Desc                   string                 `protobuf:"bytes,8,opt,name=desc,proto3" json:"desc,omitempty"`
+	DnsHijack              DNSHijack              `protobuf:"varint,9,opt,name=dns_hijack,json=dnsHijack,proto3,enum=xray.proxy.tun.DNSHijack" json:"dns_hijack,omitempty"`
+	FakeDns                bool                   `protobuf:"varint,10,opt,name=fake_dns,json=fakeDns,proto3" json:"fake_dns,omitempty"`
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
@@ -122,11 +172,25 @@ func (x *Config) GetDesc() string {
 	return ""
 }
 
+func (x *Config) GetDnsHijack() DNSHijack {
+	if x != nil {
+		return x.DnsHijack
+	}
+	return DNSHijack_None
+}
+
+func (x *Config) GetFakeDns() bool {
+	if x != nil {
+		return x.FakeDns
+	}
+	return false
+}
+
 var File_proxy_tun_config_proto protoreflect.FileDescriptor
 
 const file_proxy_tun_config_proto_rawDesc = "" +
 	"\n" +
-	"\x16proxy/tun/config.proto\x12\x0exray.proxy.tun\"\x82\x02\n" +
+	"\x16proxy/tun/config.proto\x12\x0exray.proxy.tun\"\xd7\x02\n" +
 	"\x06Config\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
 	"\x03MTU\x18\x02 \x01(\rR\x03MTU\x12\x18\n" +
@@ -136,7 +200,16 @@ const file_proxy_tun_config_proto_rawDesc = "" +
 	"user_level\x18\x05 \x01(\rR\tuserLevel\x129\n" +
 	"\x19auto_system_routing_table\x18\x06 \x03(\tR\x16autoSystemRoutingTable\x128\n" +
 	"\x18auto_outbounds_interface\x18\a \x01(\tR\x16autoOutboundsInterface\x12\x12\n" +
-	"\x04desc\x18\b \x01(\tR\x04descBL\n" +
+	"\x04desc\x18\b \x01(\tR\x04desc\x128\n" +
+	"\n" +
+	"dns_hijack\x18\t \x01(\x0e2\x19.xray.proxy.tun.DNSHijackR\tdnsHijack\x12\x19\n" +
+	"\bfake_dns\x18\n" +
+	" \x01(\bR\afakeDns*.\n" +
+	"\tDNSHijack\x12\b\n" +
+	"\x04None\x10\x00\x12\x0e\n" +
+	"\n" +
+	"Configured\x10\x01\x12\a\n" +
+	"\x03All\x10\x02BL\n" +
 	"\x12com.xray.proxy.tunP\x01Z#github.com/xtls/xray-core/proxy/tun\xaa\x02\x0eXray.Proxy.Tunb\x06proto3"
 
 var (
@@ -151,16 +224,19 @@ func file_proxy_tun_config_proto_rawDescGZIP() []byte {
 	return file_proxy_tun_config_proto_rawDescData
 }
 
+var file_proxy_tun_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_proxy_tun_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_proxy_tun_config_proto_goTypes = []any{
-	(*Config)(nil), // 0: xray.proxy.tun.Config
+	(DNSHijack)(0), // 0: xray.proxy.tun.DNSHijack
+	(*Config)(nil), // 1: xray.proxy.tun.Config
 }
 var file_proxy_tun_config_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: xray.proxy.tun.Config.dns_hijack:type_name -> xray.proxy.tun.DNSHijack
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_proxy_tun_config_proto_init() }
@@ -173,13 +249,14 @@ func file_proxy_tun_config_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_tun_config_proto_rawDesc), len(file_proxy_tun_config_proto_rawDesc)),
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   1,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_proxy_tun_config_proto_goTypes,
 		DependencyIndexes: file_proxy_tun_config_proto_depIdxs,
+		EnumInfos:         file_proxy_tun_config_proto_enumTypes,
 		MessageInfos:      file_proxy_tun_config_proto_msgTypes,
 	}.Build()
 	File_proxy_tun_config_proto = out.File
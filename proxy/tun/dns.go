@@ -0,0 +1,293 @@
+package tun
+
+import (
+	"context"
+	go_errors "errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	dns_proto "github.com/xtls/xray-core/common/protocol/dns"
+	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/common/signal"
+	"github.com/xtls/xray-core/common/task"
+	"github.com/xtls/xray-core/features/dns"
+	"github.com/xtls/xray-core/transport"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsForwardTimeout is how long a query forwarded to its original destination
+// waits for the answer.
+const dnsForwardTimeout = 5 * time.Second
+
+// isDNSHijack reports whether a connection to destination should be answered by the built-in DNS
+// instead of being dispatched.
+func (t *Handler) isDNSHijack(destination net.Destination) bool {
+	if t.dnsClient == nil || destination.Port != 53 || !destination.Address.Family().IsIP() {
+		return false
+	}
+	switch t.config.DnsHijack {
+	case DNSHijack_All:
+		return true
+	case DNSHijack_Configured:
+		ip := destination.Address.IP()
+		for _, server := range t.dnsServers {
+			if server.Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleDNS answers the queries coming over link from app/dns, until the connection is closed or stays idle.
+func (t *Handler) handleDNS(ctx context.Context, destination net.Destination, link *transport.Link) error {
+	var reader dns_proto.MessageReader
+	var writer dns_proto.MessageWriter
+	if destination.Network == net.Network_TCP {
+		reader = dns_proto.NewTCPReader(link.Reader)
+		writer = &dns_proto.TCPWriter{
+			Writer: link.Writer,
+		}
+	} else {
+		reader = &dns_proto.UDPReader{
+			Reader: link.Reader,
+		}
+		writer = &dns_proto.UDPWriter{
+			Writer: link.Writer,
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, t.policyManager.ForLevel(t.config.UserLevel).Timeouts.ConnectionIdle)
+	defer timer.SetTimeout(0)
+
+	request := func() error {
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		for {
+			b, err := reader.ReadMessage()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			timer.Update()
+
+			// a full cone udp connection may also carry packets to other destinations
+			if b.UDP != nil && !t.isDNSHijack(*b.UDP) {
+				errors.LogDebug(ctx, "drop non-DNS packet to ", b.UDP, " on hijacked connection")
+				b.Release()
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := t.answerDNS(ctx, destination, b, writer); err != nil {
+					errors.LogInfoInner(ctx, err, "write DNS answer")
+					timer.SetTimeout(0)
+					return
+				}
+				timer.Update()
+			}()
+		}
+	}
+
+	if err := task.Run(ctx, request); err != nil {
+		return errors.New("connection ends").Base(err)
+	}
+	return nil
+}
+
+// answerDNS resolves the query in b and writes back the response. Only A and AAAA queries are resolved,
+// the others are forwarded to destination, where they were sent to.
+func (t *Handler) answerDNS(ctx context.Context, destination net.Destination, b *buf.Buffer, writer dns_proto.MessageWriter) error {
+	defer b.Release()
+
+	var parser dnsmessage.Parser
+	header, err := parser.Start(b.Bytes())
+	if err != nil {
+		errors.LogInfoInner(ctx, err, "parse DNS query")
+		return nil
+	}
+	q, err := parser.Question()
+	if err != nil {
+		errors.LogInfoInner(ctx, err, "parse DNS question")
+		return nil
+	}
+
+	msg := &dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 header.ID,
+			Response:           true,
+			RecursionDesired:   header.RecursionDesired,
+			RecursionAvailable: true,
+		},
+		Questions: []dnsmessage.Question{q},
+	}
+
+	if q.Class == dnsmessage.ClassINET && (q.Type == dnsmessage.TypeA || q.Type == dnsmessage.TypeAAAA) {
+		domain := strings.TrimSuffix(q.Name.String(), ".")
+		ips, ttl, err := t.dnsClient.LookupIP(domain, dns.IPOption{
+			IPv4Enable: q.Type == dnsmessage.TypeA,
+			IPv6Enable: q.Type == dnsmessage.TypeAAAA,
+			FakeEnable: t.config.FakeDns,
+		})
+		rCode := dns.RCodeFromError(err)
+		if rCode == 0 && len(ips) == 0 && !go_errors.Is(err, dns.ErrEmptyResponse) {
+			errors.LogInfoInner(ctx, err, "lookup ", domain)
+			msg.Header.RCode = dnsmessage.RCodeServerFailure
+		} else {
+			msg.Header.RCode = dnsmessage.RCode(rCode)
+		}
+		errors.LogInfo(ctx, "hijacked type ", q.Type, " query for domain ", domain, " -> ", ips)
+
+		rHeader := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+		for _, ip := range ips {
+			if ip4 := ip.To4(); q.Type == dnsmessage.TypeA && ip4 != nil {
+				var r dnsmessage.AResource
+				copy(r.A[:], ip4)
+				msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: rHeader, Body: &r})
+			} else if q.Type == dnsmessage.TypeAAAA && ip4 == nil && len(ip) == net.IPv6len {
+				var r dnsmessage.AAAAResource
+				copy(r.AAAA[:], ip)
+				msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: rHeader, Body: &r})
+			}
+		}
+	} else {
+		answer, err := t.forwardDNS(ctx, destination, b)
+		if err == nil {
+			errors.LogInfo(ctx, "forwarded type ", q.Type, " query for domain ", q.Name.String())
+			answer.UDP = b.UDP
+			return writer.WriteMessage(answer)
+		}
+		errors.LogInfoInner(ctx, err, "forward type ", q.Type, " query for domain ", q.Name.String())
+		msg.Header.RCode = dnsmessage.RCodeServerFailure
+	}
+
+	answer, err := dns_proto.PackMessage(msg)
+	if err != nil {
+		errors.LogInfoInner(ctx, err, "pack DNS answer")
+		return nil
+	}
+	// reply from the address the query was sent to
+	answer.UDP = b.UDP
+	return writer.WriteMessage(answer)
+}
+
+// forwardDNS sends the query in b through the dispatcher to destination, or to the destination of the packet
+// in a full cone udp connection, and returns the answer.
+func (t *Handler) forwardDNS(ctx context.Context, destination net.Destination, b *buf.Buffer) (*buf.Buffer, error) {
+	if b.UDP != nil {
+		destination = *b.UDP
+	}
+	ctx, cancel := context.WithTimeout(ctx, dnsForwardTimeout)
+	defer cancel()
+	// the queries are forwarded concurrently, and the content is written by the dispatcher
+	ctx = session.ContextWithContent(ctx, &session.Content{})
+
+	link, err := t.dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		return nil, errors.New("failed to dispatch to ", destination).Base(err)
+	}
+	defer common.Close(link.Writer)
+	defer common.Interrupt(link.Reader)
+	stop := context.AfterFunc(ctx, func() {
+		common.Interrupt(link.Reader)
+	})
+	defer stop()
+
+	var reader dns_proto.MessageReader
+	var writer dns_proto.MessageWriter
+	if destination.Network == net.Network_TCP {
+		reader = dns_proto.NewTCPReader(link.Reader)
+		writer = &dns_proto.TCPWriter{
+			Writer: link.Writer,
+		}
+	} else {
+		reader = &dns_proto.UDPReader{
+			Reader: link.Reader,
+		}
+		writer = &dns_proto.UDPWriter{
+			Writer: link.Writer,
+		}
+	}
+
+	query := buf.New()
+	query.Write(b.Bytes())
+	if err := writer.WriteMessage(query); err != nil {
+		return nil, errors.New("failed to send query").Base(err)
+	}
+	answer, err := reader.ReadMessage()
+	if err != nil {
+		return nil, errors.New("failed to read answer").Base(err)
+	}
+	return answer, nil
+}
+
+// fakeDomain returns the domain a fake ip was handed out for, or an empty string.
+func (t *Handler) fakeDomain(address net.Address) string {
+	if t.fakeDNS == nil || !address.Family().IsIP() {
+		return ""
+	}
+	if fkr0, ok := t.fakeDNS.(dns.FakeDNSEngineRev0); ok && !fkr0.IsIPInIPPool(address) {
+		return ""
+	}
+	return t.fakeDNS.GetDomainFromFakeDNS(address)
+}
+
+// fakeDNSReader maps fake ip destinations of udp packets back to their domains, so they can be routed without sniffing.
+type fakeDNSReader struct {
+	buf.Reader
+	handler *Handler
+	fakeIPs *sync.Map
+}
+
+func (r *fakeDNSReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	for _, b := range mb {
+		if b.UDP == nil {
+			continue
+		}
+		if domain := r.handler.fakeDomain(b.UDP.Address); domain != "" {
+			domainAddress := net.DomainAddress(domain)
+			r.fakeIPs.Store(domainAddress.Domain(), b.UDP.Address)
+			b.UDP = &net.Destination{
+				Network: b.UDP.Network,
+				Address: domainAddress,
+				Port:    b.UDP.Port,
+			}
+		}
+	}
+	return mb, err
+}
+
+// fakeDNSWriter reverts fakeDNSReader for the returning packets, so they come from the fake ip the client used.
+type fakeDNSWriter struct {
+	buf.Writer
+	fakeIPs *sync.Map
+}
+
+func (w *fakeDNSWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	for _, b := range mb {
+		if b.UDP == nil || !b.UDP.Address.Family().IsDomain() {
+			continue
+		}
+		if ip, ok := w.fakeIPs.Load(b.UDP.Address.Domain()); ok {
+			b.UDP = &net.Destination{
+				Network: b.UDP.Network,
+				Address: ip.(net.Address),
+				Port:    b.UDP.Port,
+			}
+		}
+	}
+	return w.Writer.WriteMultiBuffer(mb)
+}
@@ -0,0 +1,210 @@
+package tun
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	xnet "github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/features/dns"
+	"github.com/xtls/xray-core/features/policy"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type testDNSClient struct {
+	option dns.IPOption
+}
+
+func (c *testDNSClient) Type() interface{} {
+	return dns.ClientType()
+}
+
+func (c *testDNSClient) Start() error {
+	return nil
+}
+
+func (c *testDNSClient) Close() error {
+	return nil
+}
+
+func (c *testDNSClient) LookupIP(domain string, option dns.IPOption) ([]xnet.IP, uint32, error) {
+	c.option = option
+	if domain != "example.com" {
+		return nil, 0, dns.RCodeError(dnsmessage.RCodeNameError)
+	}
+	return []xnet.IP{xnet.ParseIP("198.18.0.1")}, 60, nil
+}
+
+type testFakeDNS struct{}
+
+func (testFakeDNS) Type() interface{} {
+	return (*dns.FakeDNSEngine)(nil)
+}
+
+func (testFakeDNS) Start() error {
+	return nil
+}
+
+func (testFakeDNS) Close() error {
+	return nil
+}
+
+func (testFakeDNS) GetFakeIPForDomain(domain string) []xnet.Address {
+	return nil
+}
+
+func (testFakeDNS) GetDomainFromFakeDNS(ip xnet.Address) string {
+	if ip.String() == "198.18.0.1" {
+		return "example.com"
+	}
+	return ""
+}
+
+func packTCPQuery(t *testing.T, name string, qType dnsmessage.Type) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  qType,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	b, err := msg.AppendPack(make([]byte, 2, 514))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint16(b, uint16(len(b)-2))
+	return b
+}
+
+func unpackTCPAnswer(t *testing.T, b []byte) *dnsmessage.Message {
+	t.Helper()
+	if len(b) < 2 || int(binary.BigEndian.Uint16(b)) != len(b)-2 {
+		t.Fatalf("malformed tcp answer: %x", b)
+	}
+	msg := new(dnsmessage.Message)
+	if err := msg.Unpack(b[2:]); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func newDNSTestHandler(hijack DNSHijack) (*Handler, *testDNSClient, *testDispatcher) {
+	client := new(testDNSClient)
+	dispatcher := &testDispatcher{}
+	return &Handler{
+		ctx:           context.Background(),
+		config:        &Config{DNS: []string{"10.0.0.53"}, DnsHijack: hijack, FakeDns: true},
+		policyManager: policy.DefaultManager{},
+		dispatcher:    dispatcher,
+		dnsClient:     client,
+		dnsServers:    []xnet.IP{xnet.ParseIP("10.0.0.53")},
+	}, client, dispatcher
+}
+
+func TestHandlerHijacksConfiguredDNS(t *testing.T) {
+	handler, client, dispatcher := newDNSTestHandler(DNSHijack_Configured)
+
+	conn := newTestConn(packTCPQuery(t, "example.com.", dnsmessage.TypeA))
+	handler.HandleConnection(conn, xnet.TCPDestination(xnet.ParseAddress("10.0.0.53"), 53))
+
+	if dispatcher.destination.IsValid() {
+		t.Fatalf("hijacked query was dispatched to %v", dispatcher.destination)
+	}
+	if !client.option.IPv4Enable || client.option.IPv6Enable || !client.option.FakeEnable {
+		t.Fatalf("unexpected lookup option: %+v", client.option)
+	}
+	msg := unpackTCPAnswer(t, conn.writer.Bytes())
+	if msg.ID != 42 || !msg.Response || msg.RCode != dnsmessage.RCodeSuccess {
+		t.Fatalf("unexpected answer header: %+v", msg.Header)
+	}
+	if len(msg.Answers) != 1 {
+		t.Fatalf("unexpected answers: %v", msg.Answers)
+	}
+	if a, ok := msg.Answers[0].Body.(*dnsmessage.AResource); !ok || a.A != [4]byte{198, 18, 0, 1} || msg.Answers[0].Header.TTL != 60 {
+		t.Fatalf("unexpected answer: %v", msg.Answers[0])
+	}
+
+	conn = newTestConn(packTCPQuery(t, "missing.example.", dnsmessage.TypeAAAA))
+	handler.HandleConnection(conn, xnet.TCPDestination(xnet.ParseAddress("10.0.0.53"), 53))
+	if msg := unpackTCPAnswer(t, conn.writer.Bytes()); msg.RCode != dnsmessage.RCodeNameError || len(msg.Answers) != 0 {
+		t.Fatalf("unexpected answer for missing domain: %+v", msg)
+	}
+
+}
+
+func TestHandlerForwardsNonIPQuery(t *testing.T) {
+	handler, _, dispatcher := newDNSTestHandler(DNSHijack_Configured)
+	answer := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, Response: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName("example.com."),
+			Type:  dnsmessage.TypeTXT,
+			Class: dnsmessage.ClassINET,
+		}},
+		Answers: []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("example.com."), Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.TXTResource{TXT: []string{"upstream"}},
+		}},
+	}
+	b, err := answer.AppendPack(make([]byte, 2, 514))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint16(b, uint16(len(b)-2))
+	dispatcher.writePayload = b
+
+	server := xnet.TCPDestination(xnet.ParseAddress("10.0.0.53"), 53)
+	conn := newTestConn(packTCPQuery(t, "example.com.", dnsmessage.TypeTXT))
+	handler.HandleConnection(conn, server)
+
+	if dispatcher.destination != server {
+		t.Fatalf("TXT query was dispatched to %v", dispatcher.destination)
+	}
+	msg := unpackTCPAnswer(t, conn.writer.Bytes())
+	if msg.ID != 42 || msg.RCode != dnsmessage.RCodeSuccess || len(msg.Answers) != 1 {
+		t.Fatalf("unexpected answer for TXT query: %+v", msg)
+	}
+	if txt, ok := msg.Answers[0].Body.(*dnsmessage.TXTResource); !ok || len(txt.TXT) != 1 || txt.TXT[0] != "upstream" {
+		t.Fatalf("unexpected TXT answer: %v", msg.Answers[0])
+	}
+}
+
+func TestHandlerDNSHijackModes(t *testing.T) {
+	other := xnet.TCPDestination(xnet.ParseAddress("8.8.8.8"), 53)
+
+	handler, _, dispatcher := newDNSTestHandler(DNSHijack_Configured)
+	handler.HandleConnection(newTestConn(packTCPQuery(t, "example.com.", dnsmessage.TypeA)), other)
+	if dispatcher.destination != other {
+		t.Fatalf("query to unlisted server should be dispatched, got %v", dispatcher.destination)
+	}
+
+	handler, _, dispatcher = newDNSTestHandler(DNSHijack_All)
+	handler.HandleConnection(newTestConn(packTCPQuery(t, "example.com.", dnsmessage.TypeA)), other)
+	if dispatcher.destination.IsValid() {
+		t.Fatalf("query to %v should be hijacked", other)
+	}
+
+	handler, _, dispatcher = newDNSTestHandler(DNSHijack_None)
+	handler.dnsClient = nil
+	handler.HandleConnection(newTestConn([]byte("payload")), other)
+	if dispatcher.destination != other {
+		t.Fatalf("query should be dispatched without hijack, got %v", dispatcher.destination)
+	}
+}
+
+func TestHandlerMapsFakeIPToDomain(t *testing.T) {
+	handler, _, dispatcher := newDNSTestHandler(DNSHijack_None)
+	handler.fakeDNS = testFakeDNS{}
+
+	handler.HandleConnection(newTestConn([]byte("payload")), xnet.TCPDestination(xnet.ParseAddress("198.18.0.1"), 443))
+	if want := xnet.TCPDestination(xnet.DomainAddress("example.com"), 443); dispatcher.destination != want {
+		t.Fatalf("unexpected destination: got %v, want %v", dispatcher.destination, want)
+	}
+
+	handler.HandleConnection(newTestConn([]byte("payload")), xnet.TCPDestination(xnet.ParseAddress("198.18.0.2"), 443))
+	if want := xnet.TCPDestination(xnet.ParseAddress("198.18.0.2"), 443); dispatcher.destination != want {
+		t.Fatalf("unexpected destination: got %v, want %v", dispatcher.destination, want)
+	}
+}
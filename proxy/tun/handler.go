@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/netip"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/xtls/xray-core/common"
@@ -15,6 +16,7 @@ import (
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/dns"
 	"github.com/xtls/xray-core/features/policy"
 	"github.com/xtls/xray-core/features/routing"
 	"github.com/xtls/xray-core/features/stats"
@@ -31,6 +33,9 @@ type Handler struct {
 	tun             Tun
 	policyManager   policy.Manager
 	dispatcher      routing.Dispatcher
+	dnsClient       dns.Client
+	fakeDNS         dns.FakeDNSEngine
+	dnsServers      []net.IP
 	tag             string
 	sniffingRequest session.SniffingRequest
 	uplinkCounter   stats.Counter
@@ -49,7 +54,7 @@ var _ ConnectionHandler = (*Handler)(nil)
 var _ common.Runnable = (*Handler)(nil)
 
 // Init the Handler instance with necessary parameters
-func (t *Handler) Init(ctx context.Context, pm policy.Manager, dispatcher routing.Dispatcher) error {
+func (t *Handler) Init(ctx context.Context, pm policy.Manager, dispatcher routing.Dispatcher, dnsClient dns.Client) error {
 	// Retrieve tag and sniffing config from context (set by AlwaysOnInboundHandler)
 	if inbound := session.InboundFromContext(ctx); inbound != nil {
 		t.tag = inbound.Tag
@@ -62,6 +67,25 @@ func (t *Handler) Init(ctx context.Context, pm policy.Manager, dispatcher routin
 	t.policyManager = pm
 	t.dispatcher = dispatcher
 
+	if t.config.DnsHijack != DNSHijack_None {
+		t.dnsClient = dnsClient
+		for _, server := range t.config.DNS {
+			ip := net.ParseIP(server)
+			if ip == nil {
+				return errors.New("invalid DNS address: ", server)
+			}
+			t.dnsServers = append(t.dnsServers, ip)
+		}
+	}
+	if t.config.FakeDns {
+		core.OptionalFeatures(ctx, func(fdns dns.FakeDNSEngine) {
+			t.fakeDNS = fdns
+		})
+		if t.fakeDNS == nil {
+			return errors.New("fakeDns is enabled but FakeDNS is not configured")
+		}
+	}
+
 	if len(t.tag) > 0 && pm.ForSystem().Stats.InboundUplink {
 		statsManager := core.MustFromContext(ctx).GetFeature(stats.ManagerType()).(stats.Manager)
 		name := "inbound>>>" + t.tag + ">>>traffic>>>uplink"
@@ -153,6 +177,12 @@ func (t *Handler) Start() error {
 	return nil
 }
 
+// rejecter is implemented by connections of the stack which can be rejected
+// the way the network does.
+type rejecter interface {
+	Reject() error
+}
+
 // HandleConnection pass the connection coming from the ip stack to the routing dispatcher
 func (t *Handler) HandleConnection(conn net.Conn, destination net.Destination) {
 	// when handling is done with any outcome, always signal back to the incoming connection
@@ -171,6 +201,9 @@ func (t *Handler) HandleConnection(conn net.Conn, destination net.Destination) {
 		return
 	}
 	source := net.DestinationFromAddr(remote)
+	if r, ok := conn.(rejecter); ok {
+		ctx = session.ContextWithRejecter(ctx, r.Reject)
+	}
 	if t.uplinkCounter != nil || t.downlinkCounter != nil {
 		conn = &stat.CounterConnection{
 			Connection:   conn,
@@ -195,6 +228,12 @@ func (t *Handler) HandleConnection(conn net.Conn, destination net.Destination) {
 	})
 	ctx = session.SubContextFromMuxInbound(ctx)
 
+	// map fake ips back to their domains, so routing does not depend on sniffing
+	if domain := t.fakeDomain(destination.Address); domain != "" {
+		errors.LogInfo(ctx, "fake ip ", destination.Address, " is mapped to ", domain)
+		destination.Address = net.DomainAddress(domain)
+	}
+
 	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
 		From:   inbound.Source,
 		To:     destination,
@@ -203,10 +242,26 @@ func (t *Handler) HandleConnection(conn net.Conn, destination net.Destination) {
 	})
 	errors.LogInfo(ctx, "processing from ", source, " to ", destination)
 
+	reader := buf.NewReader(conn)
+	writer := buf.NewWriter(conn)
+	if t.fakeDNS != nil && destination.Network == net.Network_UDP {
+		fakeIPs := new(sync.Map)
+		reader = &fakeDNSReader{Reader: reader, handler: t, fakeIPs: fakeIPs}
+		writer = &fakeDNSWriter{Writer: writer, fakeIPs: fakeIPs}
+	}
 	link := &transport.Link{
-		Reader: &buf.TimeoutWrapperReader{Reader: buf.NewReader(conn)},
-		Writer: buf.NewWriter(conn),
+		Reader: &buf.TimeoutWrapperReader{Reader: reader},
+		Writer: writer,
 	}
+
+	if t.isDNSHijack(destination) {
+		errors.LogInfo(ctx, "hijacking DNS from ", source, " to ", destination)
+		if err := t.handleDNS(ctx, destination, link); err != nil {
+			errors.LogInfoInner(ctx, err, "DNS hijack ends")
+		}
+		return
+	}
+
 	if err := t.dispatcher.DispatchLink(ctx, destination, link); err != nil {
 		errors.LogError(ctx, errors.New("connection closed").Base(err))
 	}
@@ -233,8 +288,8 @@ func (t *Handler) Process(ctx context.Context, network net.Network, conn stat.Co
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		t := &Handler{config: config.(*Config)}
-		err := core.RequireFeatures(ctx, func(pm policy.Manager, dispatcher routing.Dispatcher) error {
-			return t.Init(ctx, pm, dispatcher)
+		err := core.RequireFeatures(ctx, func(pm policy.Manager, dispatcher routing.Dispatcher, dnsClient dns.Client) error {
+			return t.Init(ctx, pm, dispatcher, dnsClient)
 		})
 		return t, err
 	}))
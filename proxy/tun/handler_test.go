@@ -12,6 +12,7 @@ import (
 	xnet "github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/features/routing"
 	"github.com/xtls/xray-core/transport"
+	"github.com/xtls/xray-core/transport/pipe"
 )
 
 type testCounter struct {
@@ -74,6 +75,7 @@ func (c *testConn) SetWriteDeadline(time.Time) error {
 type testDispatcher struct {
 	writePayload []byte
 	readBytes    int32
+	destination  xnet.Destination
 }
 
 func (d *testDispatcher) Type() interface{} {
@@ -88,11 +90,15 @@ func (d *testDispatcher) Close() error {
 	return nil
 }
 
-func (d *testDispatcher) Dispatch(context.Context, xnet.Destination) (*transport.Link, error) {
-	return nil, nil
+func (d *testDispatcher) Dispatch(ctx context.Context, dest xnet.Destination) (*transport.Link, error) {
+	uplinkReader, uplinkWriter := pipe.New()
+	downlinkReader, downlinkWriter := pipe.New()
+	go d.DispatchLink(ctx, dest, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter})
+	return &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, nil
 }
 
 func (d *testDispatcher) DispatchLink(ctx context.Context, dest xnet.Destination, link *transport.Link) error {
+	d.destination = dest
 	mb, err := link.Reader.ReadMultiBuffer()
 	if err != nil {
 		return err
@@ -104,3 +104,53 @@ func BuildLocalEchoReply(netProto tcpip.NetworkProtocolNumber, request []byte, s
 
 	return reply, nil
 }
+
+// BuildPortUnreachable builds the ICMP port unreachable message for a UDP
+// datagram from src to dst, to be sent back from dst to src. The datagram
+// quoted in the message only carries the IP and UDP headers.
+func BuildPortUnreachable(netProto tcpip.NetworkProtocolNumber, srcIP, dstIP tcpip.Address, srcPort, dstPort uint16) ([]byte, error) {
+	var message []byte
+	switch netProto {
+	case header.IPv4ProtocolNumber:
+		message = make([]byte, header.ICMPv4MinimumSize+header.IPv4MinimumSize+header.UDPMinimumSize)
+		icmpHdr := header.ICMPv4(message)
+		icmpHdr.SetType(header.ICMPv4DstUnreachable)
+		icmpHdr.SetCode(header.ICMPv4PortUnreachable)
+		ipHdr := header.IPv4(message[header.ICMPv4MinimumSize:])
+		ipHdr.Encode(&header.IPv4Fields{
+			TotalLength: header.IPv4MinimumSize + header.UDPMinimumSize,
+			TTL:         64,
+			Protocol:    uint8(header.UDPProtocolNumber),
+			SrcAddr:     srcIP,
+			DstAddr:     dstIP,
+		})
+		ipHdr.SetChecksum(^ipHdr.CalculateChecksum())
+	case header.IPv6ProtocolNumber:
+		message = make([]byte, header.ICMPv6MinimumSize+header.IPv6MinimumSize+header.UDPMinimumSize)
+		icmpHdr := header.ICMPv6(message)
+		icmpHdr.SetType(header.ICMPv6DstUnreachable)
+		icmpHdr.SetCode(header.ICMPv6PortUnreachable)
+		ipHdr := header.IPv6(message[header.ICMPv6MinimumSize:])
+		ipHdr.Encode(&header.IPv6Fields{
+			PayloadLength:     header.UDPMinimumSize,
+			TransportProtocol: header.UDPProtocolNumber,
+			HopLimit:          64,
+			SrcAddr:           srcIP,
+			DstAddr:           dstIP,
+		})
+	default:
+		return nil, errors.New("unsupported icmp network protocol")
+	}
+
+	udpHdr := header.UDP(message[len(message)-header.UDPMinimumSize:])
+	udpHdr.Encode(&header.UDPFields{
+		SrcPort: srcPort,
+		DstPort: dstPort,
+		Length:  header.UDPMinimumSize,
+	})
+
+	if err := RewriteChecksum(netProto, message, dstIP, srcIP); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
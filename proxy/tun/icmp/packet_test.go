@@ -165,6 +165,44 @@ func TestBuildLocalEchoReply(t *testing.T) {
 	})
 }
 
+func TestBuildPortUnreachable(t *testing.T) {
+	src := tcpip.AddrFromSlice([]byte{10, 0, 0, 2})
+	dst := tcpip.AddrFromSlice([]byte{8, 8, 8, 8})
+	message, err := BuildPortUnreachable(header.IPv4ProtocolNumber, src, dst, 50000, 443)
+	if err != nil {
+		t.Fatal(err)
+	}
+	icmpHdr := header.ICMPv4(message)
+	if icmpHdr.Type() != header.ICMPv4DstUnreachable || icmpHdr.Code() != header.ICMPv4PortUnreachable {
+		t.Fatalf("unexpected ipv4 type/code: %d/%d", icmpHdr.Type(), icmpHdr.Code())
+	}
+	if checksum.Checksum(message, 0) != 0xffff {
+		t.Fatal("invalid ipv4 checksum")
+	}
+	quoted := header.IPv4(icmpHdr.Payload())
+	if !quoted.IsValid(len(quoted)) || quoted.SourceAddress() != src || quoted.DestinationAddress() != dst {
+		t.Fatal("unexpected quoted ipv4 header")
+	}
+	udpHdr := header.UDP(quoted.Payload())
+	if udpHdr.SourcePort() != 50000 || udpHdr.DestinationPort() != 443 {
+		t.Fatalf("unexpected quoted ports: %d/%d", udpHdr.SourcePort(), udpHdr.DestinationPort())
+	}
+
+	src6 := tcpip.AddrFromSlice([]byte{0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2})
+	dst6 := tcpip.AddrFromSlice([]byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1})
+	message, err = BuildPortUnreachable(header.IPv6ProtocolNumber, src6, dst6, 50000, 443)
+	if err != nil {
+		t.Fatal(err)
+	}
+	icmp6Hdr := header.ICMPv6(message)
+	if icmp6Hdr.Type() != header.ICMPv6DstUnreachable || icmp6Hdr.Code() != header.ICMPv6PortUnreachable {
+		t.Fatalf("unexpected ipv6 type/code: %d/%d", icmp6Hdr.Type(), icmp6Hdr.Code())
+	}
+	if header.IPv6(icmp6Hdr.Payload()).SourceAddress() != src6 {
+		t.Fatal("unexpected quoted ipv6 header")
+	}
+}
+
 func checksumPayloadV4(payload []byte) uint16 {
 	return checksum.Checksum(payload, 0)
 }
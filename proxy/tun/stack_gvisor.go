@@ -6,6 +6,7 @@ import (
 
 	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/common/net"
+	tunicmp "github.com/xtls/xray-core/proxy/tun/icmp"
 	"gvisor.dev/gvisor/pkg/buffer"
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
@@ -85,7 +86,7 @@ func (t *stackGVisor) Start() error {
 			options.SetReusePort(true)
 
 			t.handler.HandleConnection(
-				gonet.NewTCPConn(&wq, ep),
+				&tcpConn{TCPConn: gonet.NewTCPConn(&wq, ep), ep: ep},
 				// local address on the gVisor side is connection destination
 				net.TCPDestination(net.IPAddress(id.LocalAddress.AsSlice()), net.Port(id.LocalPort)),
 			)
@@ -99,7 +100,7 @@ func (t *stackGVisor) Start() error {
 	ipStack.SetTransportProtocolHandler(tcp.ProtocolNumber, tcpForwarder.HandlePacket)
 
 	// Use custom UDP packet handler, instead of strict gVisor forwarder, for FullCone NAT support
-	udpForwarder := newUdpConnectionHandler(t.handler.HandleConnection, t.writeRawUDPPacket)
+	udpForwarder := newUdpConnectionHandler(t.handler.HandleConnection, t.writeRawUDPPacket, t.writeUDPUnreachable)
 	ipStack.SetTransportProtocolHandler(udp.ProtocolNumber, func(id stack.TransportEndpointID, pkt *stack.PacketBuffer) bool {
 		data := pkt.Clone().Data().AsRange().ToSlice()
 		// if len(data) == 0 {
@@ -190,6 +191,34 @@ func (t *stackGVisor) writeRawUDPPacket(payload []byte, src net.Destination, dst
 	return nil
 }
 
+// writeUDPUnreachable sends an ICMP port unreachable for a datagram from src
+// to dst back to src.
+func (t *stackGVisor) writeUDPUnreachable(src net.Destination, dst net.Destination) error {
+	srcIP := tcpip.AddrFromSlice(src.Address.IP())
+	dstIP := tcpip.AddrFromSlice(dst.Address.IP())
+	netProto := header.IPv6ProtocolNumber
+	if dst.Address.Family().IsIPv4() {
+		netProto = header.IPv4ProtocolNumber
+	}
+	message, err := tunicmp.BuildPortUnreachable(netProto, srcIP, dstIP, uint16(src.Port), uint16(dst.Port))
+	if err != nil {
+		return err
+	}
+	return t.writeRawICMPPacket(netProto, message, dstIP, srcIP)
+}
+
+// tcpConn is a TCP connection of the stack which can be reset.
+type tcpConn struct {
+	*gonet.TCPConn
+	ep tcpip.Endpoint
+}
+
+// Reject aborts the connection with a RST.
+func (c *tcpConn) Reject() error {
+	c.ep.Abort()
+	return nil
+}
+
 // Close is called by Handler to shut down the stack
 func (t *stackGVisor) Close() error {
 	if t.stack == nil {
@@ -24,13 +24,15 @@ type udpConnectionHandler struct {
 
 	handleConnection func(conn net.Conn, dest net.Destination)
 	writePacket      func(data []byte, src net.Destination, dst net.Destination) error
+	writeUnreachable func(src net.Destination, dst net.Destination) error
 }
 
-func newUdpConnectionHandler(handleConnection func(conn net.Conn, dest net.Destination), writePacket func(data []byte, src net.Destination, dst net.Destination) error) *udpConnectionHandler {
+func newUdpConnectionHandler(handleConnection func(conn net.Conn, dest net.Destination), writePacket func(data []byte, src net.Destination, dst net.Destination) error, writeUnreachable func(src net.Destination, dst net.Destination) error) *udpConnectionHandler {
 	handler := &udpConnectionHandler{
 		udpConns:         make(map[net.Destination]*udpConn),
 		handleConnection: handleConnection,
 		writePacket:      writePacket,
+		writeUnreachable: writeUnreachable,
 	}
 
 	return handler
@@ -116,6 +118,12 @@ func (c *udpConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
 	}
 }
 
+// Reject sends an ICMP port unreachable for the original destination back to
+// the source.
+func (c *udpConn) Reject() error {
+	return c.handler.writeUnreachable(c.src, c.dst)
+}
+
 // Read packets from the connection
 func (c *udpConn) Read(p []byte) (int, error) {
 	e, ok := <-c.egress
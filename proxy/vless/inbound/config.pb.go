@@ -8,6 +8,7 @@ package inbound
 
 import (
 	protocol "github.com/xtls/xray-core/common/protocol"
+	fallback "github.com/xtls/xray-core/proxy/fallback"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -23,13 +24,19 @@ const (
 )
 
 type Fallback struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Alpn          string                 `protobuf:"bytes,2,opt,name=alpn,proto3" json:"alpn,omitempty"`
-	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
-	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
-	Dest          string                 `protobuf:"bytes,5,opt,name=dest,proto3" json:"dest,omitempty"`
-	Xver          uint64                 `protobuf:"varint,6,opt,name=xver,proto3" json:"xver,omitempty"`
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Name          string                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Alpn          string                  `protobuf:"bytes,2,opt,name=alpn,proto3" json:"alpn,omitempty"`
+	Path          string                  `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Type          string                  `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Dest          string                  `protobuf:"bytes,5,opt,name=dest,proto3" json:"dest,omitempty"`
+	Xver          uint64                  `protobuf:"varint,6,opt,name=xver,proto3" json:"xver,omitempty"`
+	Host          string                  `protobuf:"bytes,7,opt,name=host,proto3" json:"host,omitempty"`
+	PathRegexp    string                  `protobuf:"bytes,8,opt,name=path_regexp,json=pathRegexp,proto3" json:"path_regexp,omitempty"`
+	Methods       []string                `protobuf:"bytes,9,rep,name=methods,proto3" json:"methods,omitempty"`
+	Protocol      string                  `protobuf:"bytes,10,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Dests         []*fallback.Destination `protobuf:"bytes,11,rep,name=dests,proto3" json:"dests,omitempty"`
+	HealthCheck   *fallback.HealthCheck   `protobuf:"bytes,12,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -106,6 +113,48 @@ func (x *Fallback) GetXver() uint64 {
 	return 0
 }
 
+func (x *Fallback) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Fallback) GetPathRegexp() string {
+	if x != nil {
+		return x.PathRegexp
+	}
+	return ""
+}
+
+func (x *Fallback) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *Fallback) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *Fallback) GetDests() []*fallback.Destination {
+	if x != nil {
+		return x.Dests
+	}
+	return nil
+}
+
+func (x *Fallback) GetHealthCheck() *fallback.HealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
 type Config struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Users         []*protocol.User       `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
@@ -202,14 +251,22 @@ var File_proxy_vless_inbound_config_proto protoreflect.FileDescriptor
 
 const file_proxy_vless_inbound_config_proto_rawDesc = "" +
 	"\n" +
-	" proxy/vless/inbound/config.proto\x12\x18xray.proxy.vless.inbound\x1a\x1acommon/protocol/user.proto\"\x82\x01\n" +
+	" proxy/vless/inbound/config.proto\x12\x18xray.proxy.vless.inbound\x1a\x1acommon/protocol/user.proto\x1a\x1bproxy/fallback/config.proto\"\xea\x02\n" +
 	"\bFallback\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04alpn\x18\x02 \x01(\tR\x04alpn\x12\x12\n" +
 	"\x04path\x18\x03 \x01(\tR\x04path\x12\x12\n" +
 	"\x04type\x18\x04 \x01(\tR\x04type\x12\x12\n" +
 	"\x04dest\x18\x05 \x01(\tR\x04dest\x12\x12\n" +
-	"\x04xver\x18\x06 \x01(\x04R\x04xver\"\x92\x02\n" +
+	"\x04xver\x18\x06 \x01(\x04R\x04xver\x12\x12\n" +
+	"\x04host\x18\a \x01(\tR\x04host\x12\x1f\n" +
+	"\vpath_regexp\x18\b \x01(\tR\n" +
+	"pathRegexp\x12\x18\n" +
+	"\amethods\x18\t \x03(\tR\amethods\x12\x1a\n" +
+	"\bprotocol\x18\n" +
+	" \x01(\tR\bprotocol\x126\n" +
+	"\x05dests\x18\v \x03(\v2 .xray.proxy.fallback.DestinationR\x05dests\x12C\n" +
+	"\fhealth_check\x18\f \x01(\v2 .xray.proxy.fallback.HealthCheckR\vhealthCheck\"\x92\x02\n" +
 	"\x06Config\x120\n" +
 	"\x05users\x18\x01 \x03(\v2\x1a.xray.common.protocol.UserR\x05users\x12@\n" +
 	"\tfallbacks\x18\x02 \x03(\v2\".xray.proxy.vless.inbound.FallbackR\tfallbacks\x12\x1e\n" +
@@ -237,18 +294,22 @@ func file_proxy_vless_inbound_config_proto_rawDescGZIP() []byte {
 
 var file_proxy_vless_inbound_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_proxy_vless_inbound_config_proto_goTypes = []any{
-	(*Fallback)(nil),      // 0: xray.proxy.vless.inbound.Fallback
-	(*Config)(nil),        // 1: xray.proxy.vless.inbound.Config
-	(*protocol.User)(nil), // 2: xray.common.protocol.User
+	(*Fallback)(nil),             // 0: xray.proxy.vless.inbound.Fallback
+	(*Config)(nil),               // 1: xray.proxy.vless.inbound.Config
+	(*fallback.Destination)(nil), // 2: xray.proxy.fallback.Destination
+	(*fallback.HealthCheck)(nil), // 3: xray.proxy.fallback.HealthCheck
+	(*protocol.User)(nil),        // 4: xray.common.protocol.User
 }
 var file_proxy_vless_inbound_config_proto_depIdxs = []int32{
-	2, // 0: xray.proxy.vless.inbound.Config.users:type_name -> xray.common.protocol.User
-	0, // 1: xray.proxy.vless.inbound.Config.fallbacks:type_name -> xray.proxy.vless.inbound.Fallback
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2, // 0: xray.proxy.vless.inbound.Fallback.dests:type_name -> xray.proxy.fallback.Destination
+	3, // 1: xray.proxy.vless.inbound.Fallback.health_check:type_name -> xray.proxy.fallback.HealthCheck
+	4, // 2: xray.proxy.vless.inbound.Config.users:type_name -> xray.common.protocol.User
+	0, // 3: xray.proxy.vless.inbound.Config.fallbacks:type_name -> xray.proxy.vless.inbound.Fallback
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_proxy_vless_inbound_config_proto_init() }
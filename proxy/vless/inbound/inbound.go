@@ -21,7 +21,6 @@ import (
 	"github.com/xtls/xray-core/common/mux"
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
-	"github.com/xtls/xray-core/common/retry"
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/common/signal"
@@ -36,6 +35,7 @@ import (
 	"github.com/xtls/xray-core/features/routing"
 	"github.com/xtls/xray-core/features/stats"
 	"github.com/xtls/xray-core/proxy"
+	"github.com/xtls/xray-core/proxy/fallback"
 	"github.com/xtls/xray-core/proxy/vless"
 	"github.com/xtls/xray-core/proxy/vless/encoding"
 	"github.com/xtls/xray-core/proxy/vless/encryption"
@@ -84,6 +84,8 @@ type Handler struct {
 	defaultDispatcher      routing.Dispatcher
 	ctx                    context.Context
 	fallbacks              map[string]map[string]map[string]*Fallback // or nil
+	fallbackRules          []*fallback.Rule
+	fallbackPools          map[*Fallback]*fallback.Pool
 	// regexps               map[string]*regexp.Regexp       // or nil
 }
 
@@ -117,7 +119,17 @@ func New(ctx context.Context, config *Config, dc dns.Client, validator vless.Val
 	if config.Fallbacks != nil {
 		handler.fallbacks = make(map[string]map[string]map[string]*Fallback)
 		// handler.regexps = make(map[string]*regexp.Regexp)
+		handler.fallbackPools = make(map[*Fallback]*fallback.Pool)
 		for _, fb := range config.Fallbacks {
+			handler.fallbackPools[fb] = fallback.NewPool(fb)
+			if fallback.IsAdvanced(fb) {
+				matcher, err := fallback.NewMatcher(fb)
+				if err != nil {
+					return nil, errors.New("invalid fallback").Base(err).AtError()
+				}
+				handler.fallbackRules = append(handler.fallbackRules, &fallback.Rule{Matcher: matcher, Config: fb})
+				continue
+			}
 			if handler.fallbacks[fb.Name] == nil {
 				handler.fallbacks[fb.Name] = make(map[string]map[string]*Fallback)
 			}
@@ -233,7 +245,11 @@ func (h *Handler) Close() error {
 	for _, u := range h.validator.GetAll() {
 		h.RemoveReverse(u)
 	}
-	return errors.Combine(common.Close(h.validator))
+	errs := []error{common.Close(h.validator)}
+	for _, pool := range h.fallbackPools {
+		errs = append(errs, pool.Close())
+	}
+	return errors.Combine(errs...)
 }
 
 // AddUser implements proxy.UserManager.AddUser().
@@ -335,97 +351,28 @@ func (h *Handler) Process(ctx context.Context, network net.Network, connection s
 			name = strings.ToLower(name)
 			alpn = strings.ToLower(alpn)
 
-			if len(napfb) > 1 || napfb[""] == nil {
-				if name != "" && napfb[name] == nil {
-					match := ""
-					for n := range napfb {
-						if n != "" && strings.Contains(name, n) && len(n) > len(match) {
-							match = n
-						}
-					}
-					name = match
+			var fb *Fallback
+			if len(h.fallbackRules) > 0 {
+				request := &fallback.Request{Name: name, Alpn: alpn}
+				request.Parse(first.Bytes())
+				if c := fallback.Match(h.fallbackRules, request); c != nil {
+					fb = c.(*Fallback)
 				}
 			}
-
-			if napfb[name] == nil {
-				name = ""
-			}
-			apfb := napfb[name]
-			if apfb == nil {
-				return errors.New(`failed to find the default "name" config`).AtWarning()
-			}
-
-			if apfb[alpn] == nil {
-				alpn = ""
-			}
-			pfb := apfb[alpn]
-			if pfb == nil {
-				return errors.New(`failed to find the default "alpn" config`).AtWarning()
-			}
-
-			path := ""
-			if len(pfb) > 1 || pfb[""] == nil {
-				/*
-					if lines := bytes.Split(firstBytes, []byte{'\r', '\n'}); len(lines) > 1 {
-						if s := bytes.Split(lines[0], []byte{' '}); len(s) == 3 {
-							if len(s[0]) < 8 && len(s[1]) > 0 && len(s[2]) == 8 {
-								errors.New("realPath = " + string(s[1])).AtInfo().WriteToLog(sid)
-								for _, fb := range pfb {
-									if fb.Path != "" && h.regexps[fb.Path].Match(s[1]) {
-										path = fb.Path
-										break
-									}
-								}
-							}
-						}
-					}
-				*/
-				if firstLen >= 18 && first.Byte(4) != '*' { // not h2c
-					firstBytes := first.Bytes()
-					for i := 4; i <= 8; i++ { // 5 -> 9
-						if firstBytes[i] == '/' && firstBytes[i-1] == ' ' {
-							search := len(firstBytes)
-							if search > 64 {
-								search = 64 // up to about 60
-							}
-							for j := i + 1; j < search; j++ {
-								k := firstBytes[j]
-								if k == '\r' || k == '\n' { // avoid logging \r or \n
-									break
-								}
-								if k == '?' || k == ' ' {
-									path = string(firstBytes[i:j])
-									errors.LogInfo(ctx, "realPath = "+path)
-									if pfb[path] == nil {
-										path = ""
-									}
-									break
-								}
-							}
-							break
-						}
-					}
-				}
-			}
-			fb := pfb[path]
 			if fb == nil {
-				return errors.New(`failed to find the default "path" config`).AtWarning()
+				if fb, err = lookupFallback(ctx, napfb, name, alpn, first, firstLen); err != nil {
+					return err
+				}
 			}
 
 			ctx, cancel := context.WithCancel(ctx)
 			timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
 			ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
 
-			var conn net.Conn
-			if err := retry.ExponentialBackoff(5, 100).On(func() error {
-				var dialer net.Dialer
-				conn, err = dialer.DialContext(ctx, fb.Type, fb.Dest)
-				if err != nil {
-					return err
-				}
-				return nil
-			}); err != nil {
-				return errors.New("failed to dial to " + fb.Dest).Base(err).AtWarning()
+			pool := h.fallbackPools[fb]
+			conn, err := pool.Dial(ctx)
+			if err != nil {
+				return errors.New("failed to dial to " + pool.String()).Base(err).AtWarning()
 			}
 			defer conn.Close()
 
@@ -698,3 +645,83 @@ func (r *Reverse) SenderSettings() *serial.TypedMessage {
 func (r *Reverse) ProxySettings() *serial.TypedMessage {
 	return nil
 }
+
+// lookupFallback finds the fallback by name, alpn and path.
+func lookupFallback(ctx context.Context, napfb map[string]map[string]map[string]*Fallback, name, alpn string, first *buf.Buffer, firstLen int64) (*Fallback, error) {
+	if len(napfb) > 1 || napfb[""] == nil {
+		if name != "" && napfb[name] == nil {
+			match := ""
+			for n := range napfb {
+				if n != "" && strings.Contains(name, n) && len(n) > len(match) {
+					match = n
+				}
+			}
+			name = match
+		}
+	}
+
+	if napfb[name] == nil {
+		name = ""
+	}
+	apfb := napfb[name]
+	if apfb == nil {
+		return nil, errors.New(`failed to find the default "name" config`).AtWarning()
+	}
+
+	if apfb[alpn] == nil {
+		alpn = ""
+	}
+	pfb := apfb[alpn]
+	if pfb == nil {
+		return nil, errors.New(`failed to find the default "alpn" config`).AtWarning()
+	}
+
+	path := ""
+	if len(pfb) > 1 || pfb[""] == nil {
+		/*
+			if lines := bytes.Split(firstBytes, []byte{'\r', '\n'}); len(lines) > 1 {
+				if s := bytes.Split(lines[0], []byte{' '}); len(s) == 3 {
+					if len(s[0]) < 8 && len(s[1]) > 0 && len(s[2]) == 8 {
+						errors.New("realPath = " + string(s[1])).AtInfo().WriteToLog(sid)
+						for _, fb := range pfb {
+							if fb.Path != "" && h.regexps[fb.Path].Match(s[1]) {
+								path = fb.Path
+								break
+							}
+						}
+					}
+				}
+			}
+		*/
+		if firstLen >= 18 && first.Byte(4) != '*' { // not h2c
+			firstBytes := first.Bytes()
+			for i := 4; i <= 8; i++ { // 5 -> 9
+				if firstBytes[i] == '/' && firstBytes[i-1] == ' ' {
+					search := len(firstBytes)
+					if search > 64 {
+						search = 64 // up to about 60
+					}
+					for j := i + 1; j < search; j++ {
+						k := firstBytes[j]
+						if k == '\r' || k == '\n' { // avoid logging \r or \n
+							break
+						}
+						if k == '?' || k == ' ' {
+							path = string(firstBytes[i:j])
+							errors.LogInfo(ctx, "realPath = "+path)
+							if pfb[path] == nil {
+								path = ""
+							}
+							break
+						}
+					}
+					break
+				}
+			}
+		}
+	}
+	if fb := pfb[path]; fb != nil {
+		return fb, nil
+	}
+	return nil, errors.New(`failed to find the default "path" config`).AtWarning()
+}
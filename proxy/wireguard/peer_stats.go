@@ -0,0 +1,122 @@
+package wireguard
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/protocol"
+)
+
+// peerStatsInterval is how often the traffic and handshakes of the peers are
+// read from the device.
+const peerStatsInterval = 10 * time.Second
+
+type peerStats struct {
+	rx, tx uint64
+}
+
+type peerStatus struct {
+	pub       [32]byte
+	rx, tx    uint64
+	handshake int64
+}
+
+// parsePeerStatus parses the peers in the output of the UAPI get operation.
+func parsePeerStatus(ipc string) []*peerStatus {
+	var peers []*peerStatus
+	var peer *peerStatus
+	for _, line := range strings.Split(ipc, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if key == "public_key" {
+			peer = nil
+			if pub, err := hex.DecodeString(value); err == nil && len(pub) == 32 {
+				peer = &peerStatus{pub: [32]byte(pub)}
+				peers = append(peers, peer)
+			}
+			continue
+		}
+		if peer == nil {
+			continue
+		}
+		switch key {
+		case "rx_bytes":
+			peer.rx, _ = strconv.ParseUint(value, 10, 64)
+		case "tx_bytes":
+			peer.tx, _ = strconv.ParseUint(value, 10, 64)
+		case "last_handshake_time_sec":
+			peer.handshake, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return peers
+}
+
+// updatePeerStats adds the traffic of the peers since the last update to the
+// counters of their users, and sets the unix time of their last handshakes.
+//
+// The traffic is what the device sends and receives, including the WireGuard
+// and IP overhead and the packets that are never dispatched. It is counted in
+// user>>>EMAIL>>>wireguard>>>uplink and downlink, alongside the
+// user>>>EMAIL>>>traffic>>>uplink and downlink counters of the connections
+// dispatched for the peer, which are counted as for any other inbound. Adding
+// the device traffic to them would count the same payload twice.
+//
+// The caller holds s.mu.
+func (s *Server) updatePeerStats() {
+	if s.dev == nil {
+		return
+	}
+	ipc, err := s.dev.IpcGet()
+	if err != nil {
+		errors.LogDebugInner(s.ctx, err, "failed to get WireGuard peers")
+		return
+	}
+
+	for _, peer := range parsePeerStatus(ipc) {
+		last := s.peerStats[peer.pub]
+		if last == nil {
+			last = &peerStats{}
+			s.peerStats[peer.pub] = last
+		}
+		// The traffic restarts from zero if the peer is added again.
+		rx, tx := peer.rx, peer.tx
+		if rx >= last.rx {
+			rx -= last.rx
+		}
+		if tx >= last.tx {
+			tx -= last.tx
+		}
+		last.rx, last.tx = peer.rx, peer.tx
+
+		u, _ := s.users.Load(peer.pub)
+		if u == nil {
+			continue
+		}
+		user := u.(*protocol.MemoryUser)
+		if user.Email == "" {
+			continue
+		}
+		p := s.policyManager.ForLevel(user.Level)
+		prefix := "user>>>" + user.Email + ">>>wireguard>>>"
+		if p.Stats.UserUplink && rx > 0 {
+			if c, _ := s.statsManager.GetOrRegisterCounter(prefix + "uplink"); c != nil {
+				c.Add(int64(rx))
+			}
+		}
+		if p.Stats.UserDownlink && tx > 0 {
+			if c, _ := s.statsManager.GetOrRegisterCounter(prefix + "downlink"); c != nil {
+				c.Add(int64(tx))
+			}
+		}
+		if p.Stats.UserHandshake && peer.handshake > 0 {
+			if c, _ := s.statsManager.GetOrRegisterCounter(prefix + "handshake"); c != nil {
+				c.Set(peer.handshake)
+			}
+		}
+	}
+}
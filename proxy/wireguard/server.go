@@ -17,6 +17,7 @@ import (
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/session"
+	"github.com/xtls/xray-core/common/task"
 	"github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/features/policy"
 	"github.com/xtls/xray-core/features/routing"
@@ -42,6 +43,7 @@ type Server struct {
 	streamSettings  *internet.MemoryStreamConfig
 	uplinkCounter   stats.Counter
 	downlinkCounter stats.Counter
+	statsManager    stats.Manager
 
 	tun   tun.Device
 	stack *stack.Stack
@@ -50,6 +52,10 @@ type Server struct {
 
 	pub   [32]byte
 	users *sync.Map
+
+	// peerStats is the traffic of the peers last read from the device.
+	peerStats     map[[32]byte]*peerStats
+	peerStatsTask *task.Periodic
 }
 
 func NewServer(ctx context.Context, conf *DeviceConfig) (*Server, error) {
@@ -104,16 +110,7 @@ func NewServer(ctx context.Context, conf *DeviceConfig) (*Server, error) {
 	var pub [32]byte
 	curve25519.ScalarBaseMult(&pub, pri)
 
-	users := &sync.Map{}
-	for _, u := range conf.Users {
-		user, err := u.ToMemoryUser()
-		if err != nil {
-			return nil, err
-		}
-		users.Store(user.Account.(*MemoryAccount).Pub, user)
-	}
-
-	return &Server{
+	server := &Server{
 		conf:          conf,
 		ctx:           core.ToBackgroundDetachedContext(ctx),
 		policyManager: p,
@@ -125,25 +122,60 @@ func NewServer(ctx context.Context, conf *DeviceConfig) (*Server, error) {
 		streamSettings:  streamSettings,
 		uplinkCounter:   uplinkCounter,
 		downlinkCounter: downlinkCounter,
+		statsManager:    v.GetFeature(stats.ManagerType()).(stats.Manager),
 
 		tun:   tun,
 		stack: stack,
 
-		pub:   pub,
-		users: users,
-	}, nil
+		pub:       pub,
+		users:     &sync.Map{},
+		peerStats: make(map[[32]byte]*peerStats),
+	}
+	server.peerStatsTask = &task.Periodic{
+		Interval: peerStatsInterval,
+		Execute: func() error {
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			server.updatePeerStats()
+			return nil
+		},
+	}
+	for _, u := range conf.Users {
+		user, err := u.ToMemoryUser()
+		if err != nil {
+			return nil, err
+		}
+		if err := server.addUser(user); err != nil {
+			return nil, err
+		}
+	}
+	return server, nil
 }
 
+// AddUser implements proxy.UserManager.AddUser. The peer is added to the
+// device if it's up, or when it starts.
 func (s *Server) AddUser(ctx context.Context, user *protocol.MemoryUser) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.dev == nil {
-		return errors.New("too early")
-	}
+	return s.addUser(user)
+}
+
+func (s *Server) addUser(user *protocol.MemoryUser) error {
 	peer := user.Account.(*MemoryAccount)
 	if peer.Pub == s.pub {
 		return errors.New("invalid public key")
 	}
+	if _, found := s.users.Load(peer.Pub); found {
+		return errors.New("peer ", hex.EncodeToString(peer.Pub[:]), " already exists")
+	}
+	if user.Email != "" && s.GetUser(context.Background(), user.Email) != nil {
+		return errors.New("User ", user.Email, " already exists.")
+	}
+	if s.dev == nil {
+		s.users.Store(peer.Pub, user)
+		return nil
+	}
+
 	var sb strings.Builder
 	sb.WriteString("public_key=" + hex.EncodeToString(peer.Pub[:]) + "\n")
 	sb.WriteString("replace_allowed_ips=true\n")
@@ -164,26 +196,34 @@ func (s *Server) AddUser(ctx context.Context, user *protocol.MemoryUser) error {
 	return nil
 }
 
+// RemoveUser implements proxy.UserManager.RemoveUser. The traffic of the
+// peer is counted before it's removed from the device.
 func (s *Server) RemoveUser(ctx context.Context, email string) error {
+	if email == "" {
+		return errors.New("Email must not be empty.")
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.dev == nil {
-		return errors.New("too early")
+	user := s.GetUser(ctx, email)
+	if user == nil {
+		return errors.New("User ", email, " not found.")
 	}
-	if user := s.GetUser(ctx, email); user != nil {
-		peer := user.Account.(*MemoryAccount)
+	peer := user.Account.(*MemoryAccount)
+	if s.dev != nil {
+		s.updatePeerStats()
 		err := s.dev.IpcSet("public_key=" + hex.EncodeToString(peer.Pub[:]) + "\nremove=true\n")
 		if err != nil {
 			return err
 		}
-		s.users.Delete(peer.Pub)
 	}
+	s.users.Delete(peer.Pub)
+	delete(s.peerStats, peer.Pub)
 	return nil
 }
 
 func (s *Server) GetUser(ctx context.Context, email string) (user *protocol.MemoryUser) {
 	s.users.Range(func(key, value any) bool {
-		if value.(*protocol.MemoryUser).Email == email {
+		if strings.EqualFold(value.(*protocol.MemoryUser).Email, email) {
 			user = value.(*protocol.MemoryUser)
 			return false
 		}
@@ -234,6 +274,7 @@ func (s *Server) Process(ctx context.Context, network net.Network, conn stat.Con
 
 // Close implements common.Closable.Close.
 func (s *Server) Close() error {
+	s.peerStatsTask.Close()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.dev != nil {
@@ -249,6 +290,13 @@ func (s *Server) Close() error {
 
 // Start implements common.Runnable.Start.
 func (s *Server) Start() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	return s.peerStatsTask.Start()
+}
+
+func (s *Server) start() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.dev != nil {
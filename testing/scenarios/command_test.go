@@ -2,14 +2,21 @@ package scenarios
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/xtls/xray-core/app/capture"
+	capturecmd "github.com/xtls/xray-core/app/capture/command"
 	"github.com/xtls/xray-core/app/commander"
 	"github.com/xtls/xray-core/app/policy"
 	"github.com/xtls/xray-core/app/proxyman"
@@ -20,6 +27,7 @@ import (
 	"github.com/xtls/xray-core/common"
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/protocol/tls/cert"
 	"github.com/xtls/xray-core/common/serial"
 	"github.com/xtls/xray-core/common/uuid"
 	core "github.com/xtls/xray-core/core"
@@ -30,7 +38,12 @@ import (
 	"github.com/xtls/xray-core/proxy/vmess/outbound"
 	"github.com/xtls/xray-core/testing/servers/tcp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/testing/protocmp"
 )
 
@@ -669,3 +682,220 @@ func TestCommanderStats(t *testing.T) {
 		t.Error("value < 10240*1024: ", sresp.Stat.Value)
 	}
 }
+
+func TestCommanderAuth(t *testing.T) {
+	for _, rest := range []bool{false, true} {
+		testCommanderAuth(t, rest)
+	}
+}
+
+func testCommanderAuth(t *testing.T, rest bool) {
+	clientAuth := func(c *x509.Certificate) {
+		c.ExtKeyUsage = append(c.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+	ca, _ := cert.MustGenerate(nil, cert.Authority(true), cert.KeyUsage(x509.KeyUsageCertSign|x509.KeyUsageDigitalSignature), clientAuth)
+	serverCert, _ := cert.MustGenerate(ca, cert.DNSNames("localhost"))
+	clientCert, _ := cert.MustGenerate(ca, clientAuth)
+	dir := t.TempDir()
+	writePEM := func(name string, c *cert.Certificate) (string, string) {
+		certPEM, keyPEM := c.ToPEM()
+		certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+		common.Must(os.WriteFile(certFile, certPEM, 0o600))
+		common.Must(os.WriteFile(keyFile, keyPEM, 0o600))
+		return certFile, keyFile
+	}
+	caFile, _ := writePEM("ca", ca)
+	serverCertFile, serverKeyFile := writePEM("server", serverCert)
+	clientCertFile, clientKeyFile := writePEM("client", clientCert)
+
+	cmdPort := tcp.PickPort()
+	config := &core.Config{
+		App: []*serial.TypedMessage{
+			serial.ToTypedMessage(&commander.Config{
+				Tag:    "api",
+				Listen: fmt.Sprintf("127.0.0.1:%d", cmdPort),
+				Service: []*serial.TypedMessage{
+					serial.ToTypedMessage(&command.Config{}),
+				},
+				Tls: &commander.TLSConfig{
+					CertificateFile: serverCertFile,
+					KeyFile:         serverKeyFile,
+					ClientCaFile:    caFile,
+				},
+				Token: []*commander.Token{
+					{Name: "reader", Secret: "r", Method: []string{"/xray.app.proxyman.command.HandlerService/ListInbounds"}},
+					{Name: "admin", Secret: "a", Method: []string{"*"}},
+				},
+				Rest: rest,
+			}),
+		},
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				Tag: "d",
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(tcp.PickPort())}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+					RewriteAddress:  net.NewIPOrDomain(net.LocalHostIP),
+					RewritePort:     80,
+					AllowedNetworks: []net.Network{net.Network_TCP},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	}
+
+	servers, err := InitializeServerConfigs(config)
+	common.Must(err)
+	defer CloseAllServers(servers)
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(common.Must2(os.ReadFile(caFile)))
+	keyPair := common.Must2(tls.LoadX509KeyPair(clientCertFile, clientKeyFile))
+	cmdConn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", cmdPort), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{keyPair},
+	})))
+	common.Must(err)
+	defer cmdConn.Close()
+	hsClient := command.NewHandlerServiceClient(cmdConn)
+
+	call := func(token string, f func(ctx context.Context) error) codes.Code {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		return status.Code(f(ctx))
+	}
+	list := func(ctx context.Context) error {
+		_, err := hsClient.ListInbounds(ctx, &command.ListInboundsRequest{})
+		return err
+	}
+	remove := func(ctx context.Context) error {
+		_, err := hsClient.RemoveInbound(ctx, &command.RemoveInboundRequest{Tag: "d"})
+		return err
+	}
+
+	if code := call("", list); code != codes.Unauthenticated {
+		t.Error("unexpected code without token ", code)
+	}
+	if code := call("x", list); code != codes.Unauthenticated {
+		t.Error("unexpected code of invalid token ", code)
+	}
+	if code := call("r", list); code != codes.OK {
+		t.Error("unexpected code of reader ", code)
+	}
+	if code := call("r", remove); code != codes.PermissionDenied {
+		t.Error("unexpected code of reader removing inbound ", code)
+	}
+	if code := call("a", remove); code != codes.OK {
+		t.Error("unexpected code of admin ", code)
+	}
+
+	// Clients without certificates are rejected in mutual TLS.
+	plainConn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", cmdPort), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		RootCAs: roots,
+	})))
+	common.Must(err)
+	defer plainConn.Close()
+	if _, err := command.NewHandlerServiceClient(plainConn).ListInbounds(metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer a"), &command.ListInboundsRequest{}); err == nil {
+		t.Error("expected error of client without certificate")
+	}
+}
+
+func TestCommanderREST(t *testing.T) {
+	cmdPort := tcp.PickPort()
+	config := &core.Config{
+		App: []*serial.TypedMessage{
+			serial.ToTypedMessage(&capture.Config{}),
+			serial.ToTypedMessage(&commander.Config{
+				Tag:    "api",
+				Listen: fmt.Sprintf("127.0.0.1:%d", cmdPort),
+				Service: []*serial.TypedMessage{
+					serial.ToTypedMessage(&command.Config{}),
+					serial.ToTypedMessage(&capturecmd.Config{}),
+				},
+				Token: []*commander.Token{
+					{Name: "admin", Secret: "a", Method: []string{"*"}},
+				},
+				Rest: true,
+			}),
+		},
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				Tag: "d",
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(tcp.PickPort())}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+					RewriteAddress:  net.NewIPOrDomain(net.LocalHostIP),
+					RewritePort:     80,
+					AllowedNetworks: []net.Network{net.Network_TCP},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	}
+
+	servers, err := InitializeServerConfigs(config)
+	common.Must(err)
+	defer CloseAllServers(servers)
+
+	post := func(path, token, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://127.0.0.1:%d%s", cmdPort, path), strings.NewReader(body))
+		common.Must(err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		common.Must(err)
+		return resp
+	}
+
+	resp := post("/HandlerService/ListInbounds", "a", "{}")
+	body := common.Must2(io.ReadAll(resp.Body))
+	resp.Body.Close()
+	var inbounds command.ListInboundsResponse
+	common.Must(protojson.Unmarshal(body, &inbounds))
+	if resp.StatusCode != http.StatusOK || len(inbounds.Inbounds) != 1 || inbounds.Inbounds[0].Tag != "d" {
+		t.Error("unexpected response ", resp.StatusCode, " ", string(body))
+	}
+
+	resp = post("/xray.app.proxyman.command.HandlerService/ListInbounds", "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Error("unexpected status without token ", resp.StatusCode)
+	}
+	resp = post("/HandlerService/Unknown", "a", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Error("unexpected status of unknown method ", resp.StatusCode)
+	}
+
+	// Streams are sent as server-sent events.
+	resp = post("/CaptureService/Capture", "a", `{"duration": 1}`)
+	body = common.Must2(io.ReadAll(resp.Body))
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" || !strings.HasPrefix(string(body), "data: ") {
+		t.Error("unexpected stream ", resp.Header, " ", string(body))
+	}
+
+	// gRPC is served on the same listener.
+	cmdConn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", cmdPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	common.Must(err)
+	defer cmdConn.Close()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer a")
+	if _, err := command.NewHandlerServiceClient(cmdConn).ListInbounds(ctx, &command.ListInboundsRequest{}); err != nil {
+		t.Error(err)
+	}
+}
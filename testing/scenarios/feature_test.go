@@ -489,6 +489,79 @@ func TestForward(t *testing.T) {
 	}
 }
 
+func TestRoutingChain(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: xor,
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	serverPort := tcp.PickPort()
+	// Nothing listens on the target, which is only reachable through the hop.
+	unusedPort := tcp.PickPort()
+	allow := []*freedom.FinalRuleConfig{{Action: freedom.RuleAction_Allow}}
+	serverConfig := &core.Config{
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				Tag: "in",
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(serverPort)}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+					RewriteAddress:  net.NewIPOrDomain(net.LocalHostIP),
+					RewritePort:     uint32(unusedPort),
+					AllowedNetworks: []net.Network{net.Network_TCP},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				Tag:           "blocked",
+				ProxySettings: serial.ToTypedMessage(&blackhole.Config{}),
+			},
+			{
+				Tag:           "exit",
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{FinalRules: allow}),
+			},
+			{
+				Tag: "hop",
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{
+					DestinationOverride: &freedom.DestinationOverride{
+						Server: &protocol.ServerEndpoint{
+							Address: net.NewIPOrDomain(net.LocalHostIP),
+							Port:    uint32(dest.Port),
+						},
+					},
+					FinalRules: allow,
+				}),
+			},
+		},
+		App: []*serial.TypedMessage{
+			serial.ToTypedMessage(&router.Config{
+				Rule: []*router.RoutingRule{
+					{
+						TargetTag: &router.RoutingRule_Tag{
+							Tag: "exit",
+						},
+						InboundTag: []string{"in"},
+						Chain:      []string{"hop"},
+					},
+				},
+			}),
+		},
+	}
+
+	servers, err := InitializeServerConfigs(serverConfig)
+	common.Must(err)
+	defer CloseAllServers(servers)
+
+	if err := testTCPConn(serverPort, 1024, time.Second*5)(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestUDPConnection(t *testing.T) {
 	udpServer := udp.Server{
 		MsgProcessor: xor,
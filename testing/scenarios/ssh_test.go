@@ -0,0 +1,247 @@
+package scenarios
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	gonet "net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/app/proxyman"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/serial"
+	core "github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/proxy/dokodemo"
+	"github.com/xtls/xray-core/proxy/freedom"
+	"github.com/xtls/xray-core/proxy/ssh"
+	"github.com/xtls/xray-core/testing/servers/tcp"
+	"github.com/xtls/xray-core/transport/internet"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/sync/errgroup"
+)
+
+// sshServer is an SSH server that only serves direct-tcpip channels.
+type sshServer struct {
+	config      *gossh.ServerConfig
+	listener    gonet.Listener
+	connections atomic.Int32
+}
+
+func (s *sshServer) Start() (net.Port, error) {
+	port := tcp.PickPort()
+	listener, err := gonet.Listen("tcp", "127.0.0.1:"+port.String())
+	if err != nil {
+		return 0, err
+	}
+	s.listener = listener
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return port, nil
+}
+
+func (s *sshServer) handle(conn gonet.Conn) {
+	defer conn.Close()
+	_, chans, reqs, err := gossh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	s.connections.Add(1)
+	go gossh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(gossh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		var payload struct {
+			Host     string
+			Port     uint32
+			OrigHost string
+			OrigPort uint32
+		}
+		if err := gossh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+			newChannel.Reject(gossh.ConnectionFailed, err.Error())
+			continue
+		}
+		target, err := gonet.Dial("tcp", gonet.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+		if err != nil {
+			newChannel.Reject(gossh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go gossh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			defer target.Close()
+			go io.Copy(target, channel)
+			io.Copy(channel, target)
+		}()
+	}
+}
+
+func (s *sshServer) Close() error {
+	return s.listener.Close()
+}
+
+func newSSHKey() (ed25519.PrivateKey, gossh.Signer) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	common.Must(err)
+	signer, err := gossh.NewSignerFromKey(key)
+	common.Must(err)
+	return key, signer
+}
+
+func testSSH(t *testing.T, config *ssh.Config, server *sshServer, dialerProxy bool) error {
+	tcpServer := tcp.Server{
+		MsgProcessor: xor,
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	serverPort, err := server.Start()
+	common.Must(err)
+	defer server.Close()
+
+	config.Address = net.NewIPOrDomain(net.LocalHostIP)
+	config.Port = uint32(serverPort)
+
+	senderSettings := &proxyman.SenderConfig{}
+	if dialerProxy {
+		senderSettings.StreamSettings = &internet.StreamConfig{
+			SocketSettings: &internet.SocketConfig{
+				DialerProxy: "direct",
+			},
+		}
+	}
+
+	clientPort := tcp.PickPort()
+	clientConfig := &core.Config{
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(clientPort)}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+					RewriteAddress:  net.NewIPOrDomain(dest.Address),
+					RewritePort:     uint32(dest.Port),
+					AllowedNetworks: []net.Network{net.Network_TCP},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings:  serial.ToTypedMessage(config),
+				SenderSettings: serial.ToTypedMessage(senderSettings),
+			},
+			{
+				Tag: "direct",
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{
+					FinalRules: []*freedom.FinalRuleConfig{{Action: freedom.RuleAction_Allow}},
+				}),
+			},
+		},
+	}
+
+	servers, err := InitializeServerConfigs(clientConfig)
+	common.Must(err)
+	defer CloseAllServers(servers)
+
+	var errg errgroup.Group
+	for range 3 {
+		errg.Go(testTCPConn(clientPort, 1024*1024, time.Second*20))
+	}
+	return errg.Wait()
+}
+
+func TestSSHPassword(t *testing.T) {
+	_, hostKey := newSSHKey()
+	serverConfig := &gossh.ServerConfig{
+		PasswordCallback: func(c gossh.ConnMetadata, password []byte) (*gossh.Permissions, error) {
+			if c.User() == "user" && string(password) == "password" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+	server := &sshServer{config: serverConfig}
+
+	config := &ssh.Config{
+		User:     "user",
+		Password: "password",
+		HostKey:  []string{string(gossh.MarshalAuthorizedKey(hostKey.PublicKey()))},
+	}
+	if err := testSSH(t, config, server, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.connections.Load(); n != 1 {
+		t.Error("SSH connections: ", n)
+	}
+}
+
+func TestSSHPrivateKeyWithDialerProxy(t *testing.T) {
+	_, hostKey := newSSHKey()
+	clientKey, clientSigner := newSSHKey()
+	serverConfig := &gossh.ServerConfig{
+		PublicKeyCallback: func(c gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			if string(key.Marshal()) == string(clientSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+	server := &sshServer{config: serverConfig}
+
+	block, err := gossh.MarshalPrivateKey(clientKey, "")
+	common.Must(err)
+	config := &ssh.Config{
+		User:       "user",
+		PrivateKey: pem.EncodeToMemory(block),
+		HostKey:    []string{gossh.FingerprintSHA256(hostKey.PublicKey())},
+		KeepAlive:  1,
+	}
+	if err := testSSH(t, config, server, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSSHHostKeyMismatch(t *testing.T) {
+	_, hostKey := newSSHKey()
+	_, otherKey := newSSHKey()
+	serverConfig := &gossh.ServerConfig{
+		NoClientAuth: true,
+	}
+	serverConfig.AddHostKey(hostKey)
+	server := &sshServer{config: serverConfig}
+
+	config := &ssh.Config{
+		User:     "user",
+		Password: "password",
+		HostKey:  []string{string(gossh.MarshalAuthorizedKey(otherKey.PublicKey()))},
+	}
+	if err := testSSH(t, config, server, false); err == nil {
+		t.Error("expected error for mismatched host key")
+	}
+	if n := server.connections.Load(); n != 0 {
+		t.Error("SSH connections: ", n)
+	}
+}
@@ -1,14 +1,23 @@
 package scenarios
 
 import (
+	"context"
+	"fmt"
 	"testing"
-	//"time"
+	"time"
 
+	"github.com/xtls/xray-core/app/commander"
 	"github.com/xtls/xray-core/app/log"
+	"github.com/xtls/xray-core/app/policy"
 	"github.com/xtls/xray-core/app/proxyman"
+	"github.com/xtls/xray-core/app/proxyman/command"
+	"github.com/xtls/xray-core/app/router"
+	"github.com/xtls/xray-core/app/stats"
+	statscmd "github.com/xtls/xray-core/app/stats/command"
 	"github.com/xtls/xray-core/common"
 	clog "github.com/xtls/xray-core/common/log"
 	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/serial"
 	core "github.com/xtls/xray-core/core"
 	"github.com/xtls/xray-core/infra/conf"
@@ -17,7 +26,8 @@ import (
 	"github.com/xtls/xray-core/proxy/wireguard"
 	"github.com/xtls/xray-core/testing/servers/tcp"
 	"github.com/xtls/xray-core/testing/servers/udp"
-	//"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func TestWireguard(t *testing.T) {
@@ -122,3 +132,190 @@ func TestWireguard(t *testing.T) {
 	// 	t.Error(err)
 	// }
 }
+
+func TestWireguardAddRemovePeer(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: xor,
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	serverPrivate, _ := conf.ParseWireGuardKey("EGs4lTSJPmgELx6YiJAmPR2meWi6bY+e9rTdCipSj10=")
+	serverPublic, _ := conf.ParseWireGuardKey("osAMIyil18HeZXGGBDC9KpZoM+L2iGyXWVSYivuM9B0=")
+	clientPrivate, _ := conf.ParseWireGuardKey("CPQSpgxgdQRZa5SUbT3HLv+mmDVHLW5YR/rQlzum/2I=")
+	clientPublic, _ := conf.ParseWireGuardKey("MmLJ5iHFVVBp7VsB0hxfpQ0wEzAbT2KQnpQpj0+RtBw=")
+
+	cmdPort := tcp.PickPort()
+	serverPort := udp.PickPort()
+	serverConfig := &core.Config{
+		App: []*serial.TypedMessage{
+			serial.ToTypedMessage(&stats.Config{}),
+			serial.ToTypedMessage(&commander.Config{
+				Tag: "api",
+				Service: []*serial.TypedMessage{
+					serial.ToTypedMessage(&command.Config{}),
+					serial.ToTypedMessage(&statscmd.Config{}),
+				},
+			}),
+			serial.ToTypedMessage(&router.Config{
+				Rule: []*router.RoutingRule{
+					{
+						InboundTag: []string{"api"},
+						TargetTag: &router.RoutingRule_Tag{
+							Tag: "api",
+						},
+					},
+				},
+			}),
+			serial.ToTypedMessage(&policy.Config{
+				Level: map[uint32]*policy.Policy{
+					1: {
+						Stats: &policy.Policy_Stats{
+							UserUplink:    true,
+							UserDownlink:  true,
+							UserHandshake: true,
+						},
+					},
+				},
+			}),
+		},
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				Tag: "wg",
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(serverPort)}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&wireguard.DeviceConfig{
+					Endpoint:  []string{"10.0.0.1"},
+					Mtu:       1420,
+					SecretKey: serverPrivate,
+				}),
+			},
+			{
+				Tag: "api",
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(cmdPort)}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+					RewriteAddress:  net.NewIPOrDomain(dest.Address),
+					RewritePort:     uint32(dest.Port),
+					AllowedNetworks: []net.Network{net.Network_TCP},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				// The netstack drops the loopback destinations from the tunnel.
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{
+					DestinationOverride: &freedom.DestinationOverride{
+						Server: &protocol.ServerEndpoint{
+							Address: net.NewIPOrDomain(dest.Address),
+							Port:    uint32(dest.Port),
+						},
+					},
+					FinalRules: []*freedom.FinalRuleConfig{{Action: freedom.RuleAction_Allow}},
+				}),
+			},
+		},
+	}
+
+	clientPort := tcp.PickPort()
+	clientConfig := &core.Config{
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortList: &net.PortList{Range: []*net.PortRange{net.SinglePortRange(clientPort)}},
+					Listen:   net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+					RewriteAddress:  net.NewIPOrDomain(net.ParseAddress("10.0.0.3")),
+					RewritePort:     uint32(dest.Port),
+					AllowedNetworks: []net.Network{net.Network_TCP},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{}),
+				ProxySettings: serial.ToTypedMessage(&wireguard.DeviceConfig{
+					IsClient:    true,
+					NoKernelTun: true,
+					Endpoint:    []string{"10.0.0.2"},
+					Mtu:         1420,
+					SecretKey:   clientPrivate,
+					Peers: []*wireguard.PeerConfig{{
+						Endpoint:   "127.0.0.1:" + serverPort.String(),
+						PublicKey:  clientPublic,
+						AllowedIps: []string{"0.0.0.0/0", "::0/0"},
+					}},
+				}),
+			},
+		},
+	}
+
+	servers, err := InitializeServerConfigs(serverConfig, clientConfig)
+	common.Must(err)
+	defer CloseAllServers(servers)
+
+	cmdConn, err := grpc.Dial(fmt.Sprintf("127.0.0.1:%d", cmdPort), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	common.Must(err)
+	defer cmdConn.Close()
+
+	hsClient := command.NewHandlerServiceClient(cmdConn)
+	resp, err := hsClient.AlterInbound(context.Background(), &command.AlterInboundRequest{
+		Tag: "wg",
+		Operation: serial.ToTypedMessage(&command.AddUserOperation{
+			User: &protocol.User{
+				Email: "peer@example.com",
+				Level: 1,
+				Account: serial.ToTypedMessage(&wireguard.PeerConfig{
+					PublicKey:  serverPublic,
+					AllowedIps: []string{"10.0.0.2/32"},
+				}),
+			},
+		}),
+	})
+	common.Must(err)
+	if resp == nil {
+		t.Fatal("unexpected nil response")
+	}
+
+	if err := testTCPConn(clientPort, 10240, time.Second*10)(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = hsClient.AlterInbound(context.Background(), &command.AlterInboundRequest{
+		Tag: "wg",
+		Operation: serial.ToTypedMessage(&command.RemoveUserOperation{
+			Email: "peer@example.com",
+		}),
+	})
+	common.Must(err)
+	if resp == nil {
+		t.Fatal("unexpected nil response")
+	}
+
+	// The traffic of the peer is counted when it's removed, alongside the
+	// traffic of its connections.
+	sClient := statscmd.NewStatsServiceClient(cmdConn)
+	for _, name := range []string{
+		"user>>>peer@example.com>>>traffic>>>uplink",
+		"user>>>peer@example.com>>>traffic>>>downlink",
+		"user>>>peer@example.com>>>wireguard>>>uplink",
+		"user>>>peer@example.com>>>wireguard>>>downlink",
+		"user>>>peer@example.com>>>wireguard>>>handshake",
+	} {
+		sresp, err := sClient.GetStats(context.Background(), &statscmd.GetStatsRequest{Name: name})
+		common.Must(err)
+		if sresp.Stat.Value <= 0 {
+			t.Error(name, ": ", sresp.Stat.Value)
+		}
+	}
+
+	if err := testTCPConn(clientPort, 1024, time.Second*2)(); err == nil {
+		t.Error("expected error after the peer is removed")
+	}
+}
@@ -224,6 +224,12 @@ func checkAddressPortStrategy(ctx context.Context, dest net.Destination, sockopt
 
 // DialSystem calls system dialer to create a network connection.
 func DialSystem(ctx context.Context, dest net.Destination, sockopt *SocketConfig) (net.Conn, error) {
+	// A chain from routing replaces the dialerProxy, and leaves the domain to
+	// be resolved by the last hop.
+	if chain := session.ChainFromContext(ctx); len(chain) > 0 {
+		return dialChain(ctx, dest, chain)
+	}
+
 	var src net.Address
 	outbounds := session.OutboundsFromContext(ctx)
 	var outboundName string
@@ -281,6 +287,20 @@ func DialSystem(ctx context.Context, dest net.Destination, sockopt *SocketConfig
 	return effectiveSystemDialer.Dial(ctx, src, dest, sockopt)
 }
 
+// dialChain dials dest through the last outbound of chain, which in turn
+// dials through the rest.
+func dialChain(ctx context.Context, dest net.Destination, chain []string) (net.Conn, error) {
+	if obm == nil {
+		return nil, errors.New("there is no outbound manager for chain").AtError()
+	}
+	tag := chain[len(chain)-1]
+	h := obm.GetHandler(tag)
+	if h == nil {
+		return nil, errors.New("there is no outbound handler for chain hop ", tag).AtError()
+	}
+	return redirect(session.ContextWithChain(ctx, chain[:len(chain)-1]), dest, tag, h), nil
+}
+
 func InitSystemDialer(dc dns.Client, om outbound.Manager) {
 	dnsClient = dc
 	obm = om
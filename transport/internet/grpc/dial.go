@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -41,6 +42,8 @@ func init() {
 type dialerConf struct {
 	net.Destination
 	*internet.MemoryStreamConfig
+	// Connections dialed through different chains from routing are not shared.
+	chain string
 }
 
 var (
@@ -86,7 +89,9 @@ func getGrpcClient(ctx context.Context, dest net.Destination, streamSettings *in
 	sockopt := streamSettings.SocketSettings
 	grpcSettings := streamSettings.ProtocolSettings.(*Config)
 
-	if client, found := globalDialerMap[dialerConf{dest, streamSettings}]; found && client.GetState() != connectivity.Shutdown {
+	chain := session.ChainFromContext(ctx)
+	key := dialerConf{dest, streamSettings, strings.Join(chain, "\x00")}
+	if client, found := globalDialerMap[key]; found && client.GetState() != connectivity.Shutdown {
 		return client, nil
 	}
 
@@ -123,6 +128,7 @@ func getGrpcClient(ctx context.Context, dest net.Destination, streamSettings *in
 			gctx = c.ContextWithID(gctx, c.IDFromContext(ctx))
 			gctx = session.ContextWithOutbounds(gctx, session.OutboundsFromContext(ctx))
 			gctx = session.ContextWithTimeoutOnly(gctx, true)
+			gctx = session.ContextWithChain(gctx, chain)
 
 			c, err := internet.DialSystem(gctx, net.TCPDestination(address, port), sockopt)
 			if err == nil {
@@ -202,7 +208,7 @@ func getGrpcClient(ctx context.Context, dest net.Destination, streamSettings *in
 		setUserAgent(conn, userAgent)
 		conn.Connect()
 	}
-	globalDialerMap[dialerConf{dest, streamSettings}] = conn
+	globalDialerMap[key] = conn
 	return conn, err
 }
 
@@ -20,8 +20,9 @@ type interConn struct {
 	local  net.Addr
 	remote net.Addr
 
-	client bool
-	user   *protocol.MemoryUser
+	client  bool
+	user    *protocol.MemoryUser
+	limiter *rxLimiter
 }
 
 func (c *interConn) User() *protocol.MemoryUser {
@@ -29,7 +30,9 @@ func (c *interConn) User() *protocol.MemoryUser {
 }
 
 func (c *interConn) Read(b []byte) (int, error) {
-	return c.stream.Read(b)
+	n, err := c.stream.Read(b)
+	c.limiter.wait(n)
+	return n, err
 }
 
 func (c *interConn) Write(b []byte) (int, error) {
@@ -161,6 +164,7 @@ type udpSessionManager struct {
 	addConn        internet.ConnHandler
 	udpIdleTimeout time.Duration
 	user           *protocol.MemoryUser
+	limiter        *rxLimiter
 }
 
 func (m *udpSessionManager) close(udpConn *InterConn) {
@@ -208,6 +212,7 @@ func (m *udpSessionManager) run() {
 		if len(d) < 4 {
 			continue
 		}
+		m.limiter.wait(len(d))
 		id := binary.BigEndian.Uint32(d[:4])
 
 		m.feed(id, d)
@@ -19,6 +19,8 @@ import (
 	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/core"
+	"github.com/xtls/xray-core/features/stats"
 	"github.com/xtls/xray-core/proxy/hysteria/account"
 	"github.com/xtls/xray-core/transport/internet"
 	"github.com/xtls/xray-core/transport/internet/hysteria/congestion"
@@ -35,9 +37,73 @@ type httpHandler struct {
 	quicParams  *internet.QuicParams
 	addConn     internet.ConnHandler
 	conn        *quic.Conn
+	stats       stats.Manager
 
 	auth bool
 	user *protocol.MemoryUser
+	// rx is the most bytes per second the client may send, which the server
+	// tells it and enforces with rxLimiter.
+	rx        uint64
+	rxLimiter *rxLimiter
+}
+
+// liveRates holds the effective rates of the live connections of each rate
+// counter, whose value is the highest of them.
+var liveRates = struct {
+	sync.Mutex
+	m map[string]map[*httpHandler]uint64
+}{m: make(map[string]map[*httpHandler]uint64)}
+
+// setLiveRate sets the rate of h for the counter, or removes it if rate is
+// 0, and updates the counter.
+func setLiveRate(name string, c stats.Counter, h *httpHandler, rate uint64) {
+	liveRates.Lock()
+	defer liveRates.Unlock()
+
+	rates := liveRates.m[name]
+	if rate != 0 {
+		if rates == nil {
+			rates = make(map[*httpHandler]uint64)
+			liveRates.m[name] = rates
+		}
+		rates[h] = rate
+	} else {
+		delete(rates, h)
+		if len(rates) == 0 {
+			delete(liveRates.m, name)
+		}
+	}
+	var highest uint64
+	for _, r := range rates {
+		highest = max(highest, r)
+	}
+	c.Set(int64(highest))
+}
+
+// minRate returns the lower of the rates, where 0 is unlimited.
+func minRate(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// setRate sets the effective rate of the connection in the counter of the
+// user, which holds the highest rate of the live connections, if stats are
+// enabled.
+func (h *httpHandler) setRate(direction string, rate uint64) {
+	if h.stats == nil || h.user == nil || h.user.Email == "" || rate == 0 {
+		return
+	}
+	name := "user>>>" + h.user.Email + ">>>brutal>>>" + direction
+	c, _ := h.stats.GetOrRegisterCounter(name)
+	if c == nil {
+		return
+	}
+	setLiveRate(name, c, h, rate)
+	context.AfterFunc(h.conn.Context(), func() {
+		setLiveRate(name, c, h, 0)
+	})
 }
 
 func (h *httpHandler) AuthHTTP(w http.ResponseWriter, r *http.Request) bool {
@@ -47,7 +113,7 @@ func (h *httpHandler) AuthHTTP(w http.ResponseWriter, r *http.Request) bool {
 
 		if h.auth {
 			w.Header().Set(ResponseHeaderUDPEnabled, strconv.FormatBool(h.validator != nil))
-			w.Header().Set(CommonHeaderCCRX, strconv.FormatUint(h.quicParams.BrutalDown, 10))
+			w.Header().Set(CommonHeaderCCRX, strconv.FormatUint(h.rx, 10))
 			w.Header().Set(CommonHeaderPadding, AuthResponsePadding.String())
 			w.WriteHeader(StatusAuthOK)
 			return true
@@ -68,23 +134,36 @@ func (h *httpHandler) AuthHTTP(w http.ResponseWriter, r *http.Request) bool {
 			h.auth = true
 			h.user = user
 
+			// The bandwidth of the user clamps the rates of the server.
+			var maxUp, maxDown uint64
+			if user != nil {
+				maxUp, maxDown = h.validator.Bandwidth(user)
+			}
+			h.rx = minRate(h.quicParams.BrutalDown, maxUp)
+			h.rxLimiter = newRxLimiter(h.rx)
+
 			conn := h.conn
 			quicParams := h.quicParams
+			var tx uint64
 			switch quicParams.Congestion {
 			case "reno":
 			case "bbr":
 				congestion.UseBBR(conn, bbr.Profile(quicParams.BbrProfile))
 			case "", "brutal":
-				if quicParams.BrutalUp == 0 || down == 0 {
+				if up := minRate(quicParams.BrutalUp, maxDown); up == 0 || down == 0 {
 					congestion.UseBBR(conn, bbr.Profile(quicParams.BbrProfile))
 				} else {
-					congestion.UseBrutal(conn, min(quicParams.BrutalUp, down))
+					tx = min(up, down)
+					congestion.UseBrutal(conn, tx)
 				}
 			case "force-brutal":
-				congestion.UseBrutal(conn, quicParams.BrutalUp)
+				tx = minRate(quicParams.BrutalUp, maxDown)
+				congestion.UseBrutal(conn, tx)
 			default:
 				panic(quicParams.Congestion)
 			}
+			h.setRate("uplink", h.rx)
+			h.setRate("downlink", tx)
 
 			if h.validator != nil {
 				udpSM := &udpSessionManager{
@@ -94,13 +173,14 @@ func (h *httpHandler) AuthHTTP(w http.ResponseWriter, r *http.Request) bool {
 					addConn:        h.addConn,
 					udpIdleTimeout: time.Duration(h.config.UdpIdleTimeout) * time.Second,
 					user:           h.user,
+					limiter:        h.rxLimiter,
 				}
 				go udpSM.clean()
 				go udpSM.run()
 			}
 
 			w.Header().Set(ResponseHeaderUDPEnabled, strconv.FormatBool(h.validator != nil))
-			w.Header().Set(CommonHeaderCCRX, strconv.FormatUint(h.quicParams.BrutalDown, 10))
+			w.Header().Set(CommonHeaderCCRX, strconv.FormatUint(h.rx, 10))
 			w.Header().Set(CommonHeaderPadding, AuthResponsePadding.String())
 			w.WriteHeader(StatusAuthOK)
 			return true
@@ -132,7 +212,8 @@ func (h *httpHandler) StreamDispatcher(ft http3.FrameType, stream *quic.Stream,
 			local:  h.conn.LocalAddr(),
 			remote: h.conn.RemoteAddr(),
 
-			user: h.user,
+			user:    h.user,
+			limiter: h.rxLimiter,
 		})
 		return true, nil
 	default:
@@ -146,6 +227,7 @@ type Listener struct {
 	masqHandler http.Handler
 	quicParams  *internet.QuicParams
 	addConn     internet.ConnHandler
+	stats       stats.Manager
 
 	pktConn  net.PacketConn
 	tr       *quic.Transport
@@ -160,6 +242,7 @@ func (l *Listener) handleClient(conn *quic.Conn) {
 		quicParams:  l.quicParams,
 		addConn:     l.addConn,
 		conn:        conn,
+		stats:       l.stats,
 	}
 	h3s := http3.Server{
 		Handler:          handler,
@@ -328,6 +411,10 @@ func Listen(ctx context.Context, address net.Address, port net.Port, streamSetti
 		listener: listener,
 	}
 
+	if v := core.FromContext(ctx); v != nil {
+		l.stats, _ = v.GetFeature(stats.ManagerType()).(stats.Manager)
+	}
+
 	go l.keepAccepting()
 
 	return l, nil
@@ -0,0 +1,45 @@
+package hysteria
+
+import (
+	"sync"
+	"time"
+)
+
+// rxBurst is how long the client may send faster than its rate after being
+// idle.
+const rxBurst = 100 * time.Millisecond
+
+// rxLimiter holds back the reads of a connection to the rate the server told
+// the client, so that a client ignoring it is slowed down by flow control and
+// its datagrams are dropped. A nil rxLimiter is unlimited.
+type rxLimiter struct {
+	sync.Mutex
+	rate uint64
+	// next is when the bytes read so far may have arrived at the rate.
+	next time.Time
+}
+
+func newRxLimiter(rate uint64) *rxLimiter {
+	if rate == 0 {
+		return nil
+	}
+	return &rxLimiter{rate: rate}
+}
+
+// wait blocks until n more bytes may be read.
+func (l *rxLimiter) wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	l.Lock()
+	now := time.Now()
+	if start := now.Add(-rxBurst); l.next.Before(start) {
+		l.next = start
+	}
+	l.next = l.next.Add(time.Duration(uint64(n) * uint64(time.Second) / l.rate))
+	d := l.next.Sub(now)
+	l.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
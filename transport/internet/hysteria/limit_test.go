@@ -0,0 +1,42 @@
+package hysteria
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/app/stats"
+)
+
+func TestRxLimiter(t *testing.T) {
+	var unlimited *rxLimiter
+	unlimited.wait(1 << 30)
+
+	l := newRxLimiter(1 << 20)
+	start := time.Now()
+	for range 40 {
+		l.wait(8 * 1024)
+	}
+	// 320 KiB at 1 MiB/s, less the burst
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > time.Second {
+		t.Error("unexpected time to read at the rate: ", elapsed)
+	}
+}
+
+func TestLiveRates(t *testing.T) {
+	c := new(stats.Counter)
+	a, b := new(httpHandler), new(httpHandler)
+
+	setLiveRate("rate", c, a, 100)
+	setLiveRate("rate", c, b, 200)
+	if c.Value() != 200 {
+		t.Error("expected the highest rate, got ", c.Value())
+	}
+	setLiveRate("rate", c, b, 0)
+	if c.Value() != 100 {
+		t.Error("expected the rate of the live connection, got ", c.Value())
+	}
+	setLiveRate("rate", c, a, 0)
+	if c.Value() != 0 || len(liveRates.m) != 0 {
+		t.Error("expected no live connections, got ", c.Value())
+	}
+}
@@ -25,6 +25,14 @@ func (c *Config) GetReceivingInFlightSize() uint32 {
 	return size
 }
 
+// GetFECOverhead returns the bytes FEC adds to each packet.
+func (c *Config) GetFECOverhead() uint32 {
+	if !c.FECEnabled() {
+		return 0
+	}
+	return FECOverhead
+}
+
 func init() {
 	common.Must(internet.RegisterProtocolConfigCreator(ProtocolName, func() interface{} {
 		return &Config{
@@ -29,8 +29,15 @@ type Config struct {
 	DownlinkCapacity uint32                 `protobuf:"varint,4,opt,name=downlink_capacity,json=downlinkCapacity,proto3" json:"downlink_capacity,omitempty"`
 	CwndMultiplier   uint32                 `protobuf:"varint,5,opt,name=cwnd_multiplier,json=cwndMultiplier,proto3" json:"cwnd_multiplier,omitempty"`
 	MaxSendingWindow uint32                 `protobuf:"varint,6,opt,name=max_sending_window,json=maxSendingWindow,proto3" json:"max_sending_window,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// Reed-Solomon forward error correction, enabled when both shard counts
+	// are positive. Both sides must use the same settings.
+	DataShards   uint32 `protobuf:"varint,7,opt,name=data_shards,json=dataShards,proto3" json:"data_shards,omitempty"`
+	ParityShards uint32 `protobuf:"varint,8,opt,name=parity_shards,json=parityShards,proto3" json:"parity_shards,omitempty"`
+	// Adapt the parity shards sent per group to the loss reported by the peer,
+	// with parity_shards as the upper bound.
+	AdaptiveParity bool `protobuf:"varint,9,opt,name=adaptive_parity,json=adaptiveParity,proto3" json:"adaptive_parity,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Config) Reset() {
@@ -105,18 +112,43 @@ func (x *Config) GetMaxSendingWindow() uint32 {
 	return 0
 }
 
+func (x *Config) GetDataShards() uint32 {
+	if x != nil {
+		return x.DataShards
+	}
+	return 0
+}
+
+func (x *Config) GetParityShards() uint32 {
+	if x != nil {
+		return x.ParityShards
+	}
+	return 0
+}
+
+func (x *Config) GetAdaptiveParity() bool {
+	if x != nil {
+		return x.AdaptiveParity
+	}
+	return false
+}
+
 var File_transport_internet_kcp_config_proto protoreflect.FileDescriptor
 
 const file_transport_internet_kcp_config_proto_rawDesc = "" +
 	"\n" +
-	"#transport/internet/kcp/config.proto\x12\x1bxray.transport.internet.kcp\"\xd9\x01\n" +
+	"#transport/internet/kcp/config.proto\x12\x1bxray.transport.internet.kcp\"\xc8\x02\n" +
 	"\x06Config\x12\x10\n" +
 	"\x03mtu\x18\x01 \x01(\rR\x03mtu\x12\x10\n" +
 	"\x03tti\x18\x02 \x01(\rR\x03tti\x12'\n" +
 	"\x0fuplink_capacity\x18\x03 \x01(\rR\x0euplinkCapacity\x12+\n" +
 	"\x11downlink_capacity\x18\x04 \x01(\rR\x10downlinkCapacity\x12'\n" +
 	"\x0fcwnd_multiplier\x18\x05 \x01(\rR\x0ecwndMultiplier\x12,\n" +
-	"\x12max_sending_window\x18\x06 \x01(\rR\x10maxSendingWindowBs\n" +
+	"\x12max_sending_window\x18\x06 \x01(\rR\x10maxSendingWindow\x12\x1f\n" +
+	"\vdata_shards\x18\a \x01(\rR\n" +
+	"dataShards\x12#\n" +
+	"\rparity_shards\x18\b \x01(\rR\fparityShards\x12'\n" +
+	"\x0fadaptive_parity\x18\t \x01(\bR\x0eadaptiveParityBs\n" +
 	"\x1fcom.xray.transport.internet.kcpP\x01Z0github.com/xtls/xray-core/transport/internet/kcp\xaa\x02\x1bXray.Transport.Internet.Kcpb\x06proto3"
 
 var (
@@ -215,7 +215,7 @@ func NewConnection(meta ConnMetadata, writer io.Writer, closer io.Closer, config
 		dataOutput: signal.NewNotifier(),
 		Config:     config,
 		output:     NewRetryableWriter(NewSegmentWriter(writer)),
-		mss:        config.Mtu - DataSegmentOverhead,
+		mss:        config.Mtu - DataSegmentOverhead - config.GetFECOverhead(),
 		roundTrip: &RoundTripInfo{
 			rto:    100,
 			minRtt: config.Tti,
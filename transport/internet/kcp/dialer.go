@@ -83,14 +83,19 @@ func DialKCP(ctx context.Context, dest net.Destination, streamSettings *internet
 
 	kcpSettings := streamSettings.ProtocolSettings.(*Config)
 
-	reader := &KCPPacketReader{}
+	var reader PacketReader = &KCPPacketReader{}
+	var writer io.Writer = conn
+	if fec := NewFEC(kcpSettings); fec != nil {
+		reader = fec
+		writer = fec.Writer(conn)
+	}
 
 	conv := uint16(atomic.AddUint32(&globalConv, 1))
 	session := NewConnection(ConnMetadata{
 		LocalAddr:    conn.LocalAddr(),
 		RemoteAddr:   conn.RemoteAddr(),
 		Conversation: conv,
-	}, conn, conn, kcpSettings)
+	}, writer, conn, kcpSettings)
 
 	go fetchInput(ctx, conn, reader, session)
 
@@ -0,0 +1,347 @@
+package kcp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xtls/xray-core/common/errors"
+)
+
+const (
+	// fecHeaderSize is the size of the FEC header before every packet:
+	// conversation (2), group (4), index (1), data shards (1),
+	// parity shards (1) and observed loss in percent (1).
+	fecHeaderSize = 10
+	// FECOverhead is the number of bytes FEC adds to a data packet, the FEC
+	// header and the size of the packet.
+	FECOverhead = fecHeaderSize + 2
+	// fecWindow is the number of groups kept for recovery.
+	fecWindow = 32
+)
+
+// FECEnabled returns true if Reed-Solomon forward error correction is
+// configured.
+func (c *Config) FECEnabled() bool {
+	return c.DataShards > 0 && c.ParityShards > 0
+}
+
+type fecHeader struct {
+	conv   uint16
+	group  uint32
+	index  int
+	data   int
+	parity int
+	loss   uint32
+}
+
+func (h *fecHeader) serialize(b []byte) {
+	binary.BigEndian.PutUint16(b, h.conv)
+	binary.BigEndian.PutUint32(b[2:], h.group)
+	b[6] = byte(h.index)
+	b[7] = byte(h.data)
+	b[8] = byte(h.parity)
+	b[9] = byte(h.loss)
+}
+
+func parseFECHeader(b []byte) (*fecHeader, []byte, bool) {
+	if len(b) < fecHeaderSize {
+		return nil, nil, false
+	}
+	h := &fecHeader{
+		conv:   binary.BigEndian.Uint16(b),
+		group:  binary.BigEndian.Uint32(b[2:]),
+		index:  int(b[6]),
+		data:   int(b[7]),
+		parity: int(b[8]),
+		loss:   uint32(b[9]),
+	}
+	if h.data == 0 || h.index >= h.data+h.parity || h.loss > 100 {
+		return nil, nil, false
+	}
+	return h, b[fecHeaderSize:], true
+}
+
+// FECConversation returns the conversation of an FEC packet.
+func FECConversation(b []byte) (uint16, bool) {
+	h, _, ok := parseFECHeader(b)
+	if !ok {
+		return 0, false
+	}
+	return h.conv, true
+}
+
+type fecGroup struct {
+	parity   int
+	shards   [][]byte
+	received int
+	// complete means that all data shards are either received or
+	// recovered.
+	complete bool
+}
+
+// FEC adds Reed-Solomon forward error correction to the packets of a
+// connection. Every dataShards packets sent are followed by parity packets,
+// from which the receiver recovers lost packets without waiting for a
+// retransmission.
+//
+// Both sides measure the loss of the groups they receive and report it in
+// every packet they send. With adaptive parity, the number of parity shards
+// follows the loss reported by the peer.
+type FEC struct {
+	sync.Mutex
+	config   *Config
+	codec    *reedSolomon
+	reader   KCPPacketReader
+	groups   map[uint32]*fecGroup
+	newest   uint32
+	started  bool
+	loss     atomic.Uint32
+	peerLoss atomic.Uint32
+}
+
+// NewFEC creates a new FEC for one connection, or returns nil if FEC is not
+// enabled in config.
+func NewFEC(config *Config) *FEC {
+	if !config.FECEnabled() {
+		return nil
+	}
+	return &FEC{
+		config: config,
+		codec:  &reedSolomon{dataShards: int(config.DataShards)},
+		groups: make(map[uint32]*fecGroup),
+	}
+}
+
+// Loss returns the loss of received packets in percent.
+func (f *FEC) Loss() uint32 {
+	return f.loss.Load()
+}
+
+// parityShards returns the number of parity shards for the next group.
+func (f *FEC) parityShards() int {
+	limit := int(f.config.ParityShards)
+	if !f.config.AdaptiveParity {
+		return limit
+	}
+	// Send enough parity to recover twice the loss seen by the peer.
+	n := (int(f.config.DataShards)*int(f.peerLoss.Load())*2 + 99) / 100
+	return min(max(n, 1), limit)
+}
+
+// Read implements PacketReader.
+func (f *FEC) Read(b []byte) []Segment {
+	h, body, ok := parseFECHeader(b)
+	if !ok || h.data != int(f.config.DataShards) {
+		return nil
+	}
+	f.peerLoss.Store(h.loss)
+
+	var packets [][]byte
+	if h.index < h.data {
+		packets = append(packets, body)
+	}
+
+	f.Lock()
+	if g := f.group(h); g != nil && g.shards[h.index] == nil {
+		g.shards[h.index] = append([]byte(nil), body...)
+		g.received++
+		packets = append(packets, f.recover(g)...)
+	}
+	f.Unlock()
+
+	var segments []Segment
+	for _, packet := range packets {
+		if len(packet) < 2 {
+			continue
+		}
+		size := int(binary.BigEndian.Uint16(packet))
+		if size > len(packet)-2 {
+			continue
+		}
+		segments = append(segments, f.reader.Read(packet[2:2+size])...)
+	}
+	return segments
+}
+
+// group returns the group of a shard, or nil if it is too old.
+func (f *FEC) group(h *fecHeader) *fecGroup {
+	if !f.started {
+		f.started = true
+		f.newest = h.group
+	}
+	if int32(h.group-f.newest) > 0 {
+		// Skipped groups may still arrive out of order, unless they are
+		// already outside of the window.
+		gap := h.group - f.newest - 1
+		kept := min(gap, fecWindow-1)
+		for range min(gap-kept, fecWindow) {
+			f.account(h.data, 0)
+		}
+		for i := range kept {
+			f.groups[h.group-1-i] = &fecGroup{shards: make([][]byte, h.data)}
+		}
+		f.newest = h.group
+		for id, g := range f.groups {
+			if int32(f.newest-id) >= fecWindow {
+				f.account(len(g.shards), g.received)
+				delete(f.groups, id)
+			}
+		}
+	} else if int32(f.newest-h.group) >= fecWindow {
+		return nil
+	}
+
+	g := f.groups[h.group]
+	if g == nil {
+		g = &fecGroup{}
+		f.groups[h.group] = g
+	}
+	if g.shards == nil || (g.parity == 0 && h.parity > 0) {
+		shards := make([][]byte, h.data+h.parity)
+		copy(shards, g.shards)
+		g.shards = shards
+		g.parity = h.parity
+	}
+	if h.index >= len(g.shards) {
+		return nil
+	}
+	return g
+}
+
+// account updates the loss with a group of which received of expected
+// shards arrived.
+func (f *FEC) account(expected, received int) {
+	if expected == 0 {
+		return
+	}
+	sample := uint32((expected - received) * 100 / expected)
+	f.loss.Store((f.loss.Load()*7 + sample + 4) / 8)
+}
+
+// recover returns the data packets of g recovered from its parity shards.
+func (f *FEC) recover(g *fecGroup) [][]byte {
+	data := int(f.config.DataShards)
+	if g.complete || g.received < data {
+		return nil
+	}
+	var missing []int
+	size := 0
+	for i, shard := range g.shards {
+		if shard == nil {
+			if i < data {
+				missing = append(missing, i)
+			}
+		} else if i >= data {
+			size = len(shard)
+		}
+	}
+	g.complete = true
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// Data shards are sent without the padding to the size of the group.
+	shards := make([][]byte, len(g.shards))
+	for i, shard := range g.shards {
+		if shard != nil && len(shard) > size {
+			return nil
+		}
+		if shard != nil && len(shard) < size {
+			padded := make([]byte, size)
+			copy(padded, shard)
+			shard = padded
+		}
+		shards[i] = shard
+	}
+	if err := f.codec.reconstruct(shards); err != nil {
+		errors.LogInfoInner(context.Background(), err, "failed to recover mKCP packets")
+		return nil
+	}
+	packets := make([][]byte, 0, len(missing))
+	for _, i := range missing {
+		g.shards[i] = shards[i]
+		packets = append(packets, shards[i])
+	}
+	return packets
+}
+
+// Writer returns a writer that sends the packets written to it with FEC.
+func (f *FEC) Writer(writer io.Writer) io.Writer {
+	return &fecWriter{
+		fec:    f,
+		writer: writer,
+	}
+}
+
+type fecWriter struct {
+	sync.Mutex
+	fec    *FEC
+	writer io.Writer
+	group  uint32
+	parity int
+	shards [][]byte
+	size   int
+	buffer []byte
+}
+
+func (w *fecWriter) write(h *fecHeader, shard []byte) error {
+	w.buffer = append(w.buffer[:0], make([]byte, fecHeaderSize)...)
+	h.serialize(w.buffer)
+	w.buffer = append(w.buffer, shard...)
+	_, err := w.writer.Write(w.buffer)
+	return err
+}
+
+// Write implements io.Writer. b must be a serialized segment.
+func (w *fecWriter) Write(b []byte) (int, error) {
+	if len(b) < 2 {
+		return 0, errors.New("invalid mKCP packet")
+	}
+	w.Lock()
+	defer w.Unlock()
+
+	data := int(w.fec.config.DataShards)
+	if len(w.shards) == 0 {
+		w.parity = w.fec.parityShards()
+		w.size = 0
+	}
+	h := &fecHeader{
+		conv:   binary.BigEndian.Uint16(b),
+		group:  w.group,
+		index:  len(w.shards),
+		data:   data,
+		parity: w.parity,
+		loss:   w.fec.Loss(),
+	}
+	shard := binary.BigEndian.AppendUint16(make([]byte, 0, len(b)+2), uint16(len(b)))
+	shard = append(shard, b...)
+	w.shards = append(w.shards, shard)
+	w.size = max(w.size, len(shard))
+	if err := w.write(h, shard); err != nil {
+		return 0, err
+	}
+
+	if len(w.shards) == data {
+		for i := range w.shards {
+			if len(w.shards[i]) < w.size {
+				padded := make([]byte, w.size)
+				copy(padded, w.shards[i])
+				w.shards[i] = padded
+			}
+		}
+		parity := make([]byte, w.size)
+		for i := range w.parity {
+			w.fec.codec.encode(w.shards, i, parity)
+			h.index = data + i
+			if err := w.write(h, parity); err != nil {
+				break
+			}
+		}
+		w.shards = w.shards[:0]
+		w.group++
+	}
+	return len(b), nil
+}
@@ -0,0 +1,172 @@
+package kcp_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/buf"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/transport/internet"
+	. "github.com/xtls/xray-core/transport/internet/kcp"
+	"github.com/xtls/xray-core/transport/internet/stat"
+)
+
+type packetRecorder struct {
+	packets [][]byte
+}
+
+func (r *packetRecorder) Write(b []byte) (int, error) {
+	r.packets = append(r.packets, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func writeDataSegments(t *testing.T, writer io.Writer, n int) [][]byte {
+	var payloads [][]byte
+	for i := 0; i < n; i++ {
+		seg := NewDataSegment()
+		seg.Conv = 7
+		seg.Number = uint32(i)
+		payload := make([]byte, 10+i*13)
+		common.Must2(rand.Read(payload))
+		seg.Data().Write(payload)
+		b := make([]byte, seg.ByteSize())
+		seg.Serialize(b)
+		common.Must2(writer.Write(b))
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func TestFECRecovery(t *testing.T) {
+	config := &Config{DataShards: 4, ParityShards: 2}
+	recorder := &packetRecorder{}
+	payloads := writeDataSegments(t, NewFEC(config).Writer(recorder), 8)
+	if len(recorder.packets) != 12 {
+		t.Fatal("expected 12 packets, got ", len(recorder.packets))
+	}
+
+	// Lose two data packets of the first group and one of the second.
+	lost := map[int]bool{0: true, 2: true, 7: true}
+	reader := NewFEC(config)
+	received := make(map[uint32][]byte)
+	for i, packet := range recorder.packets {
+		if lost[i] {
+			continue
+		}
+		for _, seg := range reader.Read(packet) {
+			data := seg.(*DataSegment)
+			received[data.Number] = append([]byte(nil), data.Data().Bytes()...)
+		}
+	}
+	for i, payload := range payloads {
+		if !bytes.Equal(received[uint32(i)], payload) {
+			t.Error("segment ", i, " not recovered")
+		}
+	}
+}
+
+func TestFECTooManyLost(t *testing.T) {
+	config := &Config{DataShards: 4, ParityShards: 1}
+	recorder := &packetRecorder{}
+	writeDataSegments(t, NewFEC(config).Writer(recorder), 4)
+
+	reader := NewFEC(config)
+	var segments []Segment
+	for i, packet := range recorder.packets {
+		if i == 1 || i == 3 {
+			continue
+		}
+		segments = append(segments, reader.Read(packet)...)
+	}
+	if len(segments) != 2 {
+		t.Error("expected 2 segments, got ", len(segments))
+	}
+}
+
+func TestFECAdaptiveParity(t *testing.T) {
+	config := &Config{DataShards: 10, ParityShards: 5, AdaptiveParity: true}
+
+	// Lose a fifth of the packets of the groups received from the peer.
+	peer := &packetRecorder{}
+	peerWriter := NewFEC(config).Writer(peer)
+	writeDataSegments(t, peerWriter, 10*(32+64))
+	local := NewFEC(config)
+	for i, packet := range peer.packets {
+		if i%5 != 0 {
+			local.Read(packet)
+		}
+	}
+	if loss := local.Loss(); loss < 12 || loss > 25 {
+		t.Fatal("unexpected loss ", loss)
+	}
+
+	// The peer has not reported any loss yet.
+	recorder := &packetRecorder{}
+	writeDataSegments(t, local.Writer(recorder), 10)
+	if n := len(recorder.packets) - 10; n != 1 {
+		t.Fatal("expected 1 parity shard, got ", n)
+	}
+
+	// Once it receives the loss in the packets, it sends enough parity to
+	// recover twice the loss.
+	remote := NewFEC(config)
+	for _, packet := range recorder.packets {
+		remote.Read(packet)
+	}
+	recorder = &packetRecorder{}
+	writeDataSegments(t, remote.Writer(recorder), 10)
+	if n := len(recorder.packets) - 10; n < 3 || n > 5 {
+		t.Error("unexpected parity shards ", n)
+	}
+}
+
+func TestDialAndListenWithFEC(t *testing.T) {
+	config := &Config{
+		Mtu:              1350,
+		Tti:              20,
+		UplinkCapacity:   5,
+		DownlinkCapacity: 20,
+		CwndMultiplier:   1,
+		MaxSendingWindow: 2 * 1024 * 1024,
+		DataShards:       10,
+		ParityShards:     3,
+		AdaptiveParity:   true,
+	}
+	listener, err := NewListener(context.Background(), net.LocalHostIP, net.Port(0), &internet.MemoryStreamConfig{
+		ProtocolName:     "mkcp",
+		ProtocolSettings: config,
+	}, func(conn stat.Connection) {
+		go func() {
+			defer conn.Close()
+			buf.Copy(buf.NewReader(conn), buf.NewWriter(conn))
+		}()
+	})
+	common.Must(err)
+	defer listener.Close()
+
+	port := net.Port(listener.Addr().(*net.UDPAddr).Port)
+	conn, err := DialKCP(context.Background(), net.UDPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
+		ProtocolName:     "mkcp",
+		ProtocolSettings: config,
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	payload := make([]byte, 64*1024)
+	common.Must2(rand.Read(payload))
+	go conn.Write(payload)
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	response := make([]byte, len(payload))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(response, payload) {
+		t.Error("corrupted response")
+	}
+}
@@ -3,6 +3,7 @@ package kcp
 import (
 	"context"
 	gotls "crypto/tls"
+	"io"
 	"sync"
 
 	"github.com/xtls/xray-core/common"
@@ -25,6 +26,7 @@ type ConnectionID struct {
 type Listener struct {
 	sync.Mutex
 	sessions  map[ConnectionID]*Connection
+	fec       map[ConnectionID]*FEC
 	hub       *udp.Hub
 	tlsConfig *gotls.Config
 	config    *Config
@@ -38,6 +40,7 @@ func NewListener(ctx context.Context, address net.Address, port net.Port, stream
 	l := &Listener{
 		reader:   &KCPPacketReader{},
 		sessions: make(map[ConnectionID]*Connection),
+		fec:      make(map[ConnectionID]*FEC),
 		config:   kcpSettings,
 		addConn:  addConn,
 	}
@@ -68,6 +71,11 @@ func (l *Listener) handlePackets() {
 }
 
 func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
+	if l.config.FECEnabled() {
+		l.onReceiveFEC(payload, src)
+		return
+	}
+
 	segments := l.reader.Read(payload.Bytes())
 	payload.Release()
 
@@ -76,9 +84,28 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 		return
 	}
 
-	conv := segments[0].Conversation()
-	cmd := segments[0].Command()
+	id := ConnectionID{
+		Remote: src.Address,
+		Port:   src.Port,
+		Conv:   segments[0].Conversation(),
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	l.input(id, src, segments, nil)
+}
+
+// onReceiveFEC decodes the payload with the FEC of its connection, as parity
+// packets recover segments of the same connection only.
+func (l *Listener) onReceiveFEC(payload *buf.Buffer, src net.Destination) {
+	defer payload.Release()
 
+	conv, ok := FECConversation(payload.Bytes())
+	if !ok {
+		errors.LogInfo(context.Background(), "discarding invalid payload from ", src)
+		return
+	}
 	id := ConnectionID{
 		Remote: src.Address,
 		Port:   src.Port,
@@ -88,10 +115,22 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 	l.Lock()
 	defer l.Unlock()
 
+	fec, found := l.fec[id]
+	if !found {
+		fec = NewFEC(l.config)
+	}
+	segments := fec.Read(payload.Bytes())
+	if len(segments) == 0 {
+		return
+	}
+	l.input(id, src, segments, fec)
+}
+
+func (l *Listener) input(id ConnectionID, src net.Destination, segments []Segment, fec *FEC) {
 	conn, found := l.sessions[id]
 
 	if !found {
-		if cmd == CommandTerminate {
+		if segments[0].Command() == CommandTerminate {
 			return
 		}
 		writer := &Writer{
@@ -100,6 +139,11 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 			dest:     src,
 			listener: l,
 		}
+		var output io.Writer = writer
+		if fec != nil {
+			output = fec.Writer(writer)
+			l.fec[id] = fec
+		}
 		remoteAddr := &net.UDPAddr{
 			IP:   src.Address.IP(),
 			Port: int(src.Port),
@@ -108,8 +152,8 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 		conn = NewConnection(ConnMetadata{
 			LocalAddr:    localAddr,
 			RemoteAddr:   remoteAddr,
-			Conversation: conv,
-		}, writer, writer, l.config)
+			Conversation: id.Conv,
+		}, output, writer, l.config)
 		var netConn stat.Connection = conn
 		if l.tlsConfig != nil {
 			netConn = tls.Server(conn, l.tlsConfig)
@@ -124,6 +168,7 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 func (l *Listener) Remove(id ConnectionID) {
 	l.Lock()
 	delete(l.sessions, id)
+	delete(l.fec, id)
 	l.Unlock()
 }
 
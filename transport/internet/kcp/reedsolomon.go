@@ -0,0 +1,146 @@
+package kcp
+
+import (
+	"github.com/xtls/xray-core/common/errors"
+)
+
+// Arithmetic in GF(2^8) with the polynomial x^8 + x^4 + x^3 + x^2 + 1.
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		exp[i+255] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	return
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// gfMulAdd computes dst ^= c * src.
+func gfMulAdd(dst, src []byte, c byte) {
+	switch c {
+	case 0:
+		return
+	case 1:
+		for i, b := range src {
+			dst[i] ^= b
+		}
+		return
+	}
+	logC := int(gfLog[c])
+	for i, b := range src {
+		if b != 0 {
+			dst[i] ^= gfExp[logC+int(gfLog[b])]
+		}
+	}
+}
+
+// reedSolomon is a systematic Reed-Solomon code over GF(2^8). Parity shard i
+// is row i of a Cauchy matrix, so the code of a group does not depend on how
+// many parity shards are sent for it, and any dataShards of the shards
+// recover the data.
+type reedSolomon struct {
+	dataShards int
+}
+
+func (r *reedSolomon) coefficient(row, col int) byte {
+	if row < r.dataShards {
+		if row == col {
+			return 1
+		}
+		return 0
+	}
+	return gfInv(byte(row ^ col))
+}
+
+// encode computes parity shard i of data, whose shards must have equal size.
+func (r *reedSolomon) encode(data [][]byte, i int, parity []byte) {
+	clear(parity)
+	for col, shard := range data {
+		gfMulAdd(parity, shard, r.coefficient(r.dataShards+i, col))
+	}
+}
+
+// reconstruct fills in the nil data shards of shards, which is indexed by
+// data shards followed by parity shards. All present shards must have equal
+// size and at least dataShards must be present.
+func (r *reedSolomon) reconstruct(shards [][]byte) error {
+	k := r.dataShards
+	rows := make([]int, 0, k)
+	size := 0
+	for i, shard := range shards {
+		if shard != nil {
+			rows = append(rows, i)
+			size = len(shard)
+			if len(rows) == k {
+				break
+			}
+		}
+	}
+	if len(rows) < k {
+		return errors.New("too few shards to reconstruct")
+	}
+
+	// Invert the rows of the generator matrix for the present shards with
+	// Gauss-Jordan elimination.
+	m := make([][]byte, k)
+	inv := make([][]byte, k)
+	for i, row := range rows {
+		m[i] = make([]byte, k)
+		inv[i] = make([]byte, k)
+		inv[i][i] = 1
+		for col := range k {
+			m[i][col] = r.coefficient(row, col)
+		}
+	}
+	for col := range k {
+		pivot := col
+		for pivot < k && m[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == k {
+			return errors.New("singular matrix")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+		if c := gfInv(m[col][col]); c != 1 {
+			for j := range k {
+				m[col][j] = gfMul(m[col][j], c)
+				inv[col][j] = gfMul(inv[col][j], c)
+			}
+		}
+		for i := range k {
+			if i != col && m[i][col] != 0 {
+				c := m[i][col]
+				gfMulAdd(m[i], m[col], c)
+				gfMulAdd(inv[i], inv[col], c)
+			}
+		}
+	}
+
+	for i := range k {
+		if shards[i] != nil {
+			continue
+		}
+		shard := make([]byte, size)
+		for j, row := range rows {
+			gfMulAdd(shard, shards[row], inv[i][j])
+		}
+		shards[i] = shard
+	}
+	return nil
+}
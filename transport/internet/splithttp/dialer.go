@@ -12,6 +12,7 @@ import (
 	reflect "reflect"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/xtls/xray-core/common/errors"
 	"github.com/xtls/xray-core/common/net"
 	"github.com/xtls/xray-core/common/net/cnc"
+	"github.com/xtls/xray-core/common/session"
 	"github.com/xtls/xray-core/common/signal/done"
 	"github.com/xtls/xray-core/transport/internet"
 	"github.com/xtls/xray-core/transport/internet/browser_dialer"
@@ -39,6 +41,8 @@ import (
 type dialerConf struct {
 	net.Destination
 	*internet.MemoryStreamConfig
+	// Connections dialed through different chains from routing are not shared.
+	chain string
 }
 
 var (
@@ -60,7 +64,7 @@ func getHTTPClient(ctx context.Context, dest net.Destination, streamSettings *in
 		globalDialerMap = make(map[dialerConf]*XmuxManager)
 	}
 
-	key := dialerConf{dest, streamSettings}
+	key := dialerConf{dest, streamSettings, strings.Join(session.ChainFromContext(ctx), "\x00")}
 
 	xmuxManager, found := globalDialerMap[key]
 
@@ -0,0 +1,80 @@
+package tuic
+
+import (
+	"context"
+	"time"
+
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/proxy/tuic/account"
+	"github.com/xtls/xray-core/transport/internet"
+)
+
+// Version is the version of TUIC.
+const Version = 0x05
+
+// Types of the commands, which start with the version and the type.
+const (
+	CommandAuthenticate = 0x00
+	CommandConnect      = 0x01
+	CommandPacket       = 0x02
+	CommandDissociate   = 0x03
+	CommandHeartbeat    = 0x04
+)
+
+const (
+	closeErrCodeOK            = 0x00
+	closeErrCodeProtocolError = 0x01
+	closeErrCodeAuthFailed    = 0x02
+	closeErrCodeAuthTimeout   = 0x03
+
+	// MaxDatagramFrameSize is the largest QUIC datagram, beyond which UDP
+	// packets are fragmented in native mode.
+	MaxDatagramFrameSize = 1200
+	// MaxPacketSize is the largest packet command in a unidirectional stream.
+	MaxPacketSize       = 65535 + 512
+	udpMessageChanSize  = 1024
+	idleCleanupInterval = 1 * time.Second
+)
+
+type datagramKey struct{}
+
+func ContextWithDatagram(ctx context.Context, v bool) context.Context {
+	return context.WithValue(ctx, datagramKey{}, v)
+}
+
+func DatagramFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(datagramKey{}).(bool)
+	return v
+}
+
+type validatorKey struct{}
+
+func ContextWithValidator(ctx context.Context, v *account.Validator) context.Context {
+	return context.WithValue(ctx, validatorKey{}, v)
+}
+
+func ValidatorFromContext(ctx context.Context) *account.Validator {
+	v, _ := ctx.Value(validatorKey{}).(*account.Validator)
+	return v
+}
+
+type status int
+
+const (
+	StatusNull status = iota
+	StatusActive
+	StatusInactive
+)
+
+const protocolName = "tuic"
+
+func init() {
+	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
+		return &Config{
+			UdpRelayMode:   "native",
+			UdpIdleTimeout: 60,
+			AuthTimeout:    3,
+			Heartbeat:      10,
+		}
+	}))
+}
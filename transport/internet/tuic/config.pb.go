@@ -0,0 +1,185 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.5
+// source: transport/internet/tuic/config.proto
+
+package tuic
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Config struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID and password the client authenticates with.
+	Uuid     string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	// How the client relays UDP: "native" in QUIC datagrams, or "quic" in
+	// unidirectional streams.
+	UdpRelayMode string `protobuf:"bytes,3,opt,name=udp_relay_mode,json=udpRelayMode,proto3" json:"udp_relay_mode,omitempty"`
+	// Whether the client sends before the handshake completes, and the server
+	// accepts it. It requires TLS session resumption.
+	ZeroRtt bool `protobuf:"varint,4,opt,name=zero_rtt,json=zeroRtt,proto3" json:"zero_rtt,omitempty"`
+	// Seconds before an idle UDP association is closed, before a connection
+	// that doesn't authenticate is closed, and between the heartbeats of the
+	// client.
+	UdpIdleTimeout int64 `protobuf:"varint,5,opt,name=udp_idle_timeout,json=udpIdleTimeout,proto3" json:"udp_idle_timeout,omitempty"`
+	AuthTimeout    int64 `protobuf:"varint,6,opt,name=auth_timeout,json=authTimeout,proto3" json:"auth_timeout,omitempty"`
+	Heartbeat      int64 `protobuf:"varint,7,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_transport_internet_tuic_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_internet_tuic_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_transport_internet_tuic_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Config) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Config) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Config) GetUdpRelayMode() string {
+	if x != nil {
+		return x.UdpRelayMode
+	}
+	return ""
+}
+
+func (x *Config) GetZeroRtt() bool {
+	if x != nil {
+		return x.ZeroRtt
+	}
+	return false
+}
+
+func (x *Config) GetUdpIdleTimeout() int64 {
+	if x != nil {
+		return x.UdpIdleTimeout
+	}
+	return 0
+}
+
+func (x *Config) GetAuthTimeout() int64 {
+	if x != nil {
+		return x.AuthTimeout
+	}
+	return 0
+}
+
+func (x *Config) GetHeartbeat() int64 {
+	if x != nil {
+		return x.Heartbeat
+	}
+	return 0
+}
+
+var File_transport_internet_tuic_config_proto protoreflect.FileDescriptor
+
+const file_transport_internet_tuic_config_proto_rawDesc = "" +
+	"\n" +
+	"$transport/internet/tuic/config.proto\x12\x1cxray.transport.internet.tuic\"\xe4\x01\n" +
+	"\x06Config\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12$\n" +
+	"\x0eudp_relay_mode\x18\x03 \x01(\tR\fudpRelayMode\x12\x19\n" +
+	"\bzero_rtt\x18\x04 \x01(\bR\azeroRtt\x12(\n" +
+	"\x10udp_idle_timeout\x18\x05 \x01(\x03R\x0eudpIdleTimeout\x12!\n" +
+	"\fauth_timeout\x18\x06 \x01(\x03R\vauthTimeout\x12\x1c\n" +
+	"\theartbeat\x18\a \x01(\x03R\theartbeatBv\n" +
+	" com.xray.transport.internet.tuicP\x01Z1github.com/xtls/xray-core/transport/internet/tuic\xaa\x02\x1cXray.Transport.Internet.Tuicb\x06proto3"
+
+var (
+	file_transport_internet_tuic_config_proto_rawDescOnce sync.Once
+	file_transport_internet_tuic_config_proto_rawDescData []byte
+)
+
+func file_transport_internet_tuic_config_proto_rawDescGZIP() []byte {
+	file_transport_internet_tuic_config_proto_rawDescOnce.Do(func() {
+		file_transport_internet_tuic_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transport_internet_tuic_config_proto_rawDesc), len(file_transport_internet_tuic_config_proto_rawDesc)))
+	})
+	return file_transport_internet_tuic_config_proto_rawDescData
+}
+
+var file_transport_internet_tuic_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_transport_internet_tuic_config_proto_goTypes = []any{
+	(*Config)(nil), // 0: xray.transport.internet.tuic.Config
+}
+var file_transport_internet_tuic_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_transport_internet_tuic_config_proto_init() }
+func file_transport_internet_tuic_config_proto_init() {
+	if File_transport_internet_tuic_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transport_internet_tuic_config_proto_rawDesc), len(file_transport_internet_tuic_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_transport_internet_tuic_config_proto_goTypes,
+		DependencyIndexes: file_transport_internet_tuic_config_proto_depIdxs,
+		MessageInfos:      file_transport_internet_tuic_config_proto_msgTypes,
+	}.Build()
+	File_transport_internet_tuic_config_proto = out.File
+	file_transport_internet_tuic_config_proto_goTypes = nil
+	file_transport_internet_tuic_config_proto_depIdxs = nil
+}
@@ -0,0 +1,392 @@
+package tuic
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apernet/quic-go"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/transport/internet"
+)
+
+// readCommand reads the version and the type of a command.
+func readCommand(r io.Reader) (byte, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	if b[0] != Version {
+		return 0, errors.New("unsupported version ", b[0])
+	}
+	return b[1], nil
+}
+
+// readPacket reads the packet command in a unidirectional stream, after its
+// version and type.
+func readPacket(stream *quic.ReceiveStream) ([]byte, error) {
+	p, err := io.ReadAll(io.LimitReader(stream, MaxPacketSize))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{Version, CommandPacket}, p...), nil
+}
+
+// sendUni sends the command in a new unidirectional stream.
+func sendUni(conn *quic.Conn, b []byte) error {
+	stream, err := conn.OpenUniStream()
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Write(b); err != nil {
+		stream.CancelWrite(0)
+		return err
+	}
+	return stream.Close()
+}
+
+// interConn is a relayed TCP connection in a bidirectional stream. The
+// client starts it with the connect command.
+type interConn struct {
+	stream *quic.Stream
+	local  net.Addr
+	remote net.Addr
+
+	client bool
+	user   *protocol.MemoryUser
+	// active counts the relays of the client, which is decremented once on
+	// close.
+	active *atomic.Int32
+	once   sync.Once
+}
+
+func (c *interConn) User() *protocol.MemoryUser {
+	return c.user
+}
+
+func (c *interConn) Read(b []byte) (int, error) {
+	return c.stream.Read(b)
+}
+
+func (c *interConn) Write(b []byte) (int, error) {
+	if c.client {
+		c.client = false
+		if _, err := c.stream.Write(append([]byte{Version, CommandConnect}, b...)); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	return c.stream.Write(b)
+}
+
+func (c *interConn) Close() error {
+	if c.active != nil {
+		c.once.Do(func() {
+			c.active.Add(-1)
+		})
+	}
+	c.stream.CancelRead(0)
+	return c.stream.Close()
+}
+
+func (c *interConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *interConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *interConn) SetDeadline(t time.Time) error {
+	return c.stream.SetDeadline(t)
+}
+
+func (c *interConn) SetReadDeadline(t time.Time) error {
+	return c.stream.SetReadDeadline(t)
+}
+
+func (c *interConn) SetWriteDeadline(t time.Time) error {
+	return c.stream.SetWriteDeadline(t)
+}
+
+// InterConn is a UDP association. It reads and writes whole packet
+// commands, whose association ID it sets.
+type InterConn struct {
+	local  net.Addr
+	remote net.Addr
+
+	id     uint16
+	ch     chan []byte
+	time   time.Time
+	mutex  sync.Mutex
+	closed bool
+
+	conn *quic.Conn
+	// stream is whether the packets are relayed in unidirectional streams
+	// instead of datagrams.
+	stream bool
+	close  func()
+	user   *protocol.MemoryUser
+}
+
+func (c *InterConn) User() *protocol.MemoryUser {
+	return c.user
+}
+
+func (c *InterConn) Time() time.Time {
+	c.mutex.Lock()
+	v := c.time
+	c.mutex.Unlock()
+	return v
+}
+
+func (c *InterConn) Update() {
+	c.mutex.Lock()
+	c.time = time.Now()
+	c.mutex.Unlock()
+}
+
+func (c *InterConn) Read(p []byte) (int, error) {
+	b, ok := <-c.ch
+	if !ok {
+		return 0, io.EOF
+	}
+	if len(p) < len(b) {
+		return 0, io.ErrShortBuffer
+	}
+	c.Update()
+	return copy(p, b), nil
+}
+
+// Write writes the packet command. In native mode, it returns
+// *quic.DatagramTooLargeError if the packet must be fragmented.
+func (c *InterConn) Write(p []byte) (int, error) {
+	if c.closed {
+		return 0, io.ErrClosedPipe
+	}
+	binary.BigEndian.PutUint16(p[2:], c.id)
+	var err error
+	if c.stream {
+		err = sendUni(c.conn, p)
+	} else {
+		err = c.conn.SendDatagram(p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	c.Update()
+	return len(p), nil
+}
+
+func (c *InterConn) Close() error {
+	c.close()
+	return nil
+}
+
+func (c *InterConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *InterConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *InterConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *InterConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *InterConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// udpSessionManager demultiplexes the packets of a connection by their
+// association ID.
+type udpSessionManager struct {
+	sync.RWMutex
+
+	conn   *quic.Conn
+	m      map[uint16]*InterConn
+	next   uint16
+	closed bool
+
+	// stream is whether the client relays in unidirectional streams.
+	stream bool
+	active *atomic.Int32
+
+	addConn        internet.ConnHandler
+	udpIdleTimeout time.Duration
+	user           *protocol.MemoryUser
+}
+
+func (m *udpSessionManager) close(udpConn *InterConn) {
+	if !udpConn.closed {
+		udpConn.closed = true
+		close(udpConn.ch)
+		delete(m.m, udpConn.id)
+		if m.active != nil {
+			m.active.Add(-1)
+		}
+	}
+}
+
+func (m *udpSessionManager) clean() {
+	ticker := time.NewTicker(idleCleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.RLock()
+		if m.closed {
+			m.RUnlock()
+			return
+		}
+		now := time.Now()
+		timeoutConn := make([]*InterConn, 0, len(m.m))
+		for _, udpConn := range m.m {
+			if now.Sub(udpConn.Time()) > m.udpIdleTimeout {
+				timeoutConn = append(timeoutConn, udpConn)
+			}
+		}
+		m.RUnlock()
+
+		for _, udpConn := range timeoutConn {
+			m.Lock()
+			m.close(udpConn)
+			m.Unlock()
+		}
+	}
+}
+
+// closeAll closes the associations after the connection is closed.
+func (m *udpSessionManager) closeAll() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.closed = true
+	for _, udpConn := range m.m {
+		m.close(udpConn)
+	}
+}
+
+// udp creates an association of the client, which dissociates on close.
+func (m *udpSessionManager) udp() (*InterConn, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.closed {
+		return nil, errors.New("closed")
+	}
+	if len(m.m) > 0xFFFF {
+		return nil, errors.New("too many associations")
+	}
+	for m.m[m.next] != nil {
+		m.next++
+	}
+
+	udpConn := &InterConn{
+		local:  m.conn.LocalAddr(),
+		remote: m.conn.RemoteAddr(),
+
+		id:     m.next,
+		ch:     make(chan []byte, udpMessageChanSize),
+		time:   time.Now(),
+		conn:   m.conn,
+		stream: m.stream,
+	}
+	udpConn.close = func() {
+		m.Lock()
+		closed := udpConn.closed
+		m.close(udpConn)
+		m.Unlock()
+		if !closed {
+			b := []byte{Version, CommandDissociate, 0, 0}
+			binary.BigEndian.PutUint16(b[2:], udpConn.id)
+			if err := sendUni(m.conn, b); err != nil {
+				errors.LogDebugInner(context.Background(), err, "failed to dissociate")
+			}
+		}
+	}
+	m.m[m.next] = udpConn
+	m.next++
+	if m.active != nil {
+		m.active.Add(1)
+	}
+
+	return udpConn, nil
+}
+
+// feed delivers the packet command to its association. The server creates
+// the association of an unknown ID, which replies the way the packet came.
+func (m *udpSessionManager) feed(d []byte, stream bool) {
+	if len(d) < 4 {
+		return
+	}
+	id := binary.BigEndian.Uint16(d[2:])
+
+	m.RLock()
+	udpConn, ok := m.m[id]
+	if ok {
+		select {
+		case udpConn.ch <- d:
+		default:
+		}
+		m.RUnlock()
+		return
+	}
+	m.RUnlock()
+
+	if m.addConn == nil {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.closed {
+		return
+	}
+	udpConn, ok = m.m[id]
+	if !ok {
+		udpConn = &InterConn{
+			local:  m.conn.LocalAddr(),
+			remote: m.conn.RemoteAddr(),
+
+			id:     id,
+			ch:     make(chan []byte, udpMessageChanSize),
+			time:   time.Now(),
+			conn:   m.conn,
+			stream: stream,
+		}
+		udpConn.close = func() {
+			m.Lock()
+			m.close(udpConn)
+			m.Unlock()
+		}
+		udpConn.user = m.user
+		m.m[id] = udpConn
+		m.addConn(udpConn)
+	}
+
+	select {
+	case udpConn.ch <- d:
+	default:
+	}
+}
+
+// dissociate closes the association of the server.
+func (m *udpSessionManager) dissociate(id uint16) {
+	m.Lock()
+	if udpConn, ok := m.m[id]; ok {
+		m.close(udpConn)
+	}
+	m.Unlock()
+}
@@ -0,0 +1,394 @@
+package tuic
+
+import (
+	"context"
+	go_tls "crypto/tls"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apernet/quic-go"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/net/cnc"
+	"github.com/xtls/xray-core/common/uuid"
+	"github.com/xtls/xray-core/transport/internet"
+	"github.com/xtls/xray-core/transport/internet/finalmask"
+	"github.com/xtls/xray-core/transport/internet/hysteria/congestion"
+	"github.com/xtls/xray-core/transport/internet/hysteria/congestion/bbr"
+	"github.com/xtls/xray-core/transport/internet/stat"
+	"github.com/xtls/xray-core/transport/internet/tls"
+)
+
+type client struct {
+	sync.Mutex
+
+	dest           net.Destination
+	config         *Config
+	tlsConfig      *go_tls.Config
+	socketConfig   *internet.SocketConfig
+	udpmaskManager *finalmask.UdpmaskManager
+	quicParams     *internet.QuicParams
+
+	conn    *quic.Conn
+	tr      *quic.Transport
+	pktConn net.PacketConn
+	udpSM   *udpSessionManager
+	// active counts the relays, during which heartbeats are sent.
+	active *atomic.Int32
+}
+
+func (c *client) status() status {
+	if c.conn == nil {
+		return StatusNull
+	}
+	select {
+	case <-c.conn.Context().Done():
+		return StatusInactive
+	default:
+		return StatusActive
+	}
+}
+
+func (c *client) close() {
+	c.conn.CloseWithError(closeErrCodeOK, "")
+	c.tr.Close()
+	c.pktConn.Close()
+	c.conn = nil
+	c.tr = nil
+	c.pktConn = nil
+	c.udpSM = nil
+	c.active = nil
+}
+
+// authenticate sends the token, which is exported once the handshake
+// completes.
+func (c *client) authenticate(conn *quic.Conn, id uuid.UUID) error {
+	select {
+	case <-conn.HandshakeComplete():
+	case <-conn.Context().Done():
+		return context.Cause(conn.Context())
+	}
+	state := conn.ConnectionState().TLS
+	token, err := state.ExportKeyingMaterial(string(id[:]), []byte(c.config.Password), 32)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 0, 2+16+32)
+	b = append(b, Version, CommandAuthenticate)
+	b = append(b, id[:]...)
+	b = append(b, token...)
+	return sendUni(conn, b)
+}
+
+func (c *client) heartbeat(conn *quic.Conn, active *atomic.Int32) {
+	if c.config.Heartbeat == 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(c.config.Heartbeat) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if active.Load() > 0 {
+				_ = conn.SendDatagram([]byte{Version, CommandHeartbeat})
+			}
+		case <-conn.Context().Done():
+			return
+		}
+	}
+}
+
+func receiveDatagrams(conn *quic.Conn, udpSM *udpSessionManager) {
+	for {
+		d, err := conn.ReceiveDatagram(context.Background())
+		if err != nil {
+			udpSM.closeAll()
+			return
+		}
+		if len(d) < 2 || d[0] != Version || d[1] != CommandPacket {
+			continue
+		}
+		udpSM.feed(d, false)
+	}
+}
+
+func acceptUniStreams(conn *quic.Conn, udpSM *udpSessionManager) {
+	for {
+		stream, err := conn.AcceptUniStream(context.Background())
+		if err != nil {
+			return
+		}
+		go func() {
+			command, err := readCommand(stream)
+			if err != nil || command != CommandPacket {
+				stream.CancelRead(0)
+				return
+			}
+			p, err := readPacket(stream)
+			if err != nil {
+				return
+			}
+			udpSM.feed(p, true)
+		}()
+	}
+}
+
+func (c *client) dial(ctx context.Context) error {
+	status := c.status()
+	if status == StatusActive {
+		return nil
+	}
+	if status == StatusInactive {
+		c.close()
+	}
+
+	id, err := uuid.ParseString(c.config.Uuid)
+	if err != nil {
+		return errors.New("failed to parse ID").Base(err)
+	}
+
+	quicParams := c.quicParams
+	if quicParams == nil {
+		quicParams = &internet.QuicParams{
+			BbrProfile: string(bbr.ProfileStandard),
+		}
+	}
+
+	quicConfig := &quic.Config{
+		InitialStreamReceiveWindow:     quicParams.InitStreamReceiveWindow,
+		MaxStreamReceiveWindow:         quicParams.MaxStreamReceiveWindow,
+		InitialConnectionReceiveWindow: quicParams.InitConnReceiveWindow,
+		MaxConnectionReceiveWindow:     quicParams.MaxConnReceiveWindow,
+		MaxIdleTimeout:                 time.Duration(quicParams.MaxIdleTimeout) * time.Second,
+		KeepAlivePeriod:                time.Duration(quicParams.KeepAlivePeriod) * time.Second,
+		DisablePathMTUDiscovery:        quicParams.DisablePathMtuDiscovery || (runtime.GOOS != "linux" && runtime.GOOS != "windows" && runtime.GOOS != "darwin"),
+		EnableDatagrams:                true,
+		MaxDatagramFrameSize:           MaxDatagramFrameSize,
+		DisablePathManager:             true,
+	}
+	if quicParams.InitStreamReceiveWindow == 0 {
+		quicConfig.InitialStreamReceiveWindow = 8388608
+	}
+	if quicParams.MaxStreamReceiveWindow == 0 {
+		quicConfig.MaxStreamReceiveWindow = 8388608
+	}
+	if quicParams.InitConnReceiveWindow == 0 {
+		quicConfig.InitialConnectionReceiveWindow = 8388608 * 5 / 2
+	}
+	if quicParams.MaxConnReceiveWindow == 0 {
+		quicConfig.MaxConnectionReceiveWindow = 8388608 * 5 / 2
+	}
+	if quicParams.MaxIdleTimeout == 0 {
+		quicConfig.MaxIdleTimeout = 30 * time.Second
+	}
+
+	var pktConn net.PacketConn
+	var udpAddr *net.UDPAddr
+
+	raw, err := internet.DialSystem(ctx, c.dest, c.socketConfig)
+	if err != nil {
+		return errors.New("failed to dial to dest").Base(err)
+	}
+	switch c := raw.(type) {
+	case *internet.PacketConnWrapper:
+		pktConn = c.PacketConn
+		udpAddr = raw.RemoteAddr().(*net.UDPAddr)
+	case *cnc.Connection:
+		pktConn = &internet.FakePacketConn{Conn: c}
+		udpAddr = &net.UDPAddr{IP: c.RemoteAddr().(*net.TCPAddr).IP, Port: c.RemoteAddr().(*net.TCPAddr).Port}
+	default:
+		panic(reflect.TypeOf(c))
+	}
+
+	if c.udpmaskManager != nil {
+		newConn, err := c.udpmaskManager.WrapPacketConnClient(pktConn)
+		if err != nil {
+			pktConn.Close()
+			return errors.New("mask err").Base(err)
+		}
+		pktConn = newConn
+	}
+
+	tr := &quic.Transport{Conn: pktConn}
+
+	var conn *quic.Conn
+	if c.config.ZeroRtt {
+		conn, err = tr.DialEarly(ctx, udpAddr, c.tlsConfig, quicConfig)
+	} else {
+		conn, err = tr.Dial(ctx, udpAddr, c.tlsConfig, quicConfig)
+	}
+	if err != nil {
+		_ = tr.Close()
+		_ = pktConn.Close()
+		return err
+	}
+
+	switch quicParams.Congestion {
+	case "reno":
+	case "", "bbr", "brutal":
+		congestion.UseBBR(conn, bbr.Profile(quicParams.BbrProfile))
+	case "force-brutal":
+		congestion.UseBrutal(conn, quicParams.BrutalUp)
+	default:
+		panic(quicParams.Congestion)
+	}
+
+	active := &atomic.Int32{}
+	udpSM := &udpSessionManager{
+		conn: conn,
+		m:    make(map[uint16]*InterConn),
+
+		stream: c.config.UdpRelayMode == "quic",
+		active: active,
+	}
+
+	// In 0-RTT, the commands are sent before the handshake completes, and
+	// the server holds them until the client authenticates.
+	go func() {
+		if err := c.authenticate(conn, id); err != nil {
+			errors.LogInfoInner(context.Background(), err, "tuic: failed to authenticate")
+			_ = conn.CloseWithError(closeErrCodeAuthFailed, "")
+		}
+	}()
+	if !c.config.ZeroRtt {
+		select {
+		case <-conn.HandshakeComplete():
+		case <-conn.Context().Done():
+		}
+	}
+
+	go c.heartbeat(conn, active)
+	go receiveDatagrams(conn, udpSM)
+	go acceptUniStreams(conn, udpSM)
+
+	c.pktConn = pktConn
+	c.tr = tr
+	c.conn = conn
+	c.udpSM = udpSM
+	c.active = active
+
+	return nil
+}
+
+func (c *client) tcp(ctx context.Context) (stat.Connection, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.conn.OpenStream()
+	if err != nil {
+		return nil, err
+	}
+	c.active.Add(1)
+
+	return &interConn{
+		stream: stream,
+		local:  c.conn.LocalAddr(),
+		remote: c.conn.RemoteAddr(),
+
+		client: true,
+		active: c.active,
+	}, nil
+}
+
+func (c *client) udp(ctx context.Context) (stat.Connection, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.udpSM.udp()
+}
+
+func (c *client) clean() {
+	c.Lock()
+	if c.status() == StatusInactive {
+		c.close()
+	}
+	c.Unlock()
+}
+
+type dialerConf struct {
+	net.Destination
+	*internet.MemoryStreamConfig
+}
+
+type clientManager struct {
+	sync.RWMutex
+	m map[dialerConf]*client
+}
+
+func (m *clientManager) clean() {
+	ticker := time.NewTicker(idleCleanupInterval)
+	for range ticker.C {
+		m.RLock()
+		for _, c := range m.m {
+			c.clean()
+		}
+		m.RUnlock()
+	}
+}
+
+var (
+	manager     *clientManager
+	initmanager sync.Once
+)
+
+func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (stat.Connection, error) {
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig == nil {
+		return nil, errors.New("tls config is nil")
+	}
+
+	datagram := DatagramFromContext(ctx)
+	dest.Network = net.Network_UDP
+
+	initmanager.Do(func() {
+		manager = &clientManager{
+			m: make(map[dialerConf]*client),
+		}
+		go manager.clean()
+	})
+
+	manager.RLock()
+	c := manager.m[dialerConf{dest, streamSettings}]
+	manager.RUnlock()
+
+	if c == nil {
+		manager.Lock()
+		c = manager.m[dialerConf{dest, streamSettings}]
+		if c == nil {
+			c = &client{
+				dest:           dest,
+				config:         streamSettings.ProtocolSettings.(*Config),
+				tlsConfig:      tlsConfig.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("h3")),
+				socketConfig:   streamSettings.SocketSettings,
+				udpmaskManager: streamSettings.UdpmaskManager,
+				quicParams:     streamSettings.QuicParams,
+			}
+			manager.m[dialerConf{dest, streamSettings}] = c
+		}
+		manager.Unlock()
+	}
+
+	if datagram {
+		return c.udp(ctx)
+	}
+	return c.tcp(ctx)
+}
+
+func init() {
+	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
+}
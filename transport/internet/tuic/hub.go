@@ -0,0 +1,361 @@
+package tuic
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/apernet/quic-go"
+	"github.com/xtls/xray-core/common"
+	"github.com/xtls/xray-core/common/errors"
+	"github.com/xtls/xray-core/common/net"
+	"github.com/xtls/xray-core/common/protocol"
+	"github.com/xtls/xray-core/common/uuid"
+	"github.com/xtls/xray-core/proxy/tuic/account"
+	"github.com/xtls/xray-core/transport/internet"
+	"github.com/xtls/xray-core/transport/internet/hysteria/congestion"
+	"github.com/xtls/xray-core/transport/internet/hysteria/congestion/bbr"
+	"github.com/xtls/xray-core/transport/internet/tls"
+)
+
+// serverConn serves the commands of a client, which wait until it
+// authenticates.
+type serverConn struct {
+	validator *account.Validator
+	config    *Config
+	addConn   internet.ConnHandler
+	conn      *quic.Conn
+	udpSM     *udpSessionManager
+
+	authOnce sync.Once
+	authed   chan struct{}
+	user     *protocol.MemoryUser
+}
+
+// waitAuth returns whether the client authenticated before the connection
+// closed.
+func (c *serverConn) waitAuth() bool {
+	select {
+	case <-c.authed:
+		return true
+	case <-c.conn.Context().Done():
+		return false
+	}
+}
+
+func (c *serverConn) authenticate(stream *quic.ReceiveStream) error {
+	var b [16 + 32]byte
+	if _, err := io.ReadFull(stream, b[:]); err != nil {
+		return err
+	}
+	id, err := uuid.ParseBytes(b[:16])
+	if err != nil {
+		return err
+	}
+	user := c.validator.Get(id)
+	if user == nil {
+		return errors.New("unknown user ", id.String())
+	}
+
+	// The token is exported after the handshake, even if the command came in
+	// 0-RTT.
+	select {
+	case <-c.conn.HandshakeComplete():
+	case <-c.conn.Context().Done():
+		return c.conn.Context().Err()
+	}
+	state := c.conn.ConnectionState().TLS
+	token, err := state.ExportKeyingMaterial(string(b[:16]), []byte(user.Account.(*account.MemoryAccount).Password), 32)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(token, b[16:]) != 1 {
+		return errors.New("wrong token of user ", id.String())
+	}
+
+	c.authOnce.Do(func() {
+		c.user = user
+		c.udpSM.user = user
+		close(c.authed)
+	})
+	return nil
+}
+
+func (c *serverConn) handleUniStream(stream *quic.ReceiveStream) {
+	command, err := readCommand(stream)
+	if err != nil {
+		stream.CancelRead(0)
+		return
+	}
+	switch command {
+	case CommandAuthenticate:
+		if err := c.authenticate(stream); err != nil {
+			errors.LogInfoInner(context.Background(), err, "tuic: failed to authenticate ", c.conn.RemoteAddr())
+			_ = c.conn.CloseWithError(closeErrCodeAuthFailed, "")
+		}
+	case CommandPacket:
+		if !c.waitAuth() {
+			return
+		}
+		p, err := readPacket(stream)
+		if err != nil {
+			return
+		}
+		c.udpSM.feed(p, true)
+	case CommandDissociate:
+		if !c.waitAuth() {
+			return
+		}
+		var b [2]byte
+		if _, err := io.ReadFull(stream, b[:]); err != nil {
+			return
+		}
+		c.udpSM.dissociate(binary.BigEndian.Uint16(b[:]))
+	default:
+		_ = c.conn.CloseWithError(closeErrCodeProtocolError, "")
+	}
+}
+
+func (c *serverConn) handleStream(stream *quic.Stream) {
+	if !c.waitAuth() {
+		stream.CancelRead(0)
+		stream.CancelWrite(0)
+		return
+	}
+	command, err := readCommand(stream)
+	if err != nil || command != CommandConnect {
+		stream.CancelRead(0)
+		stream.CancelWrite(0)
+		return
+	}
+	c.addConn(&interConn{
+		stream: stream,
+		local:  c.conn.LocalAddr(),
+		remote: c.conn.RemoteAddr(),
+
+		user: c.user,
+	})
+}
+
+func (c *serverConn) acceptUniStreams() {
+	for {
+		stream, err := c.conn.AcceptUniStream(context.Background())
+		if err != nil {
+			return
+		}
+		go c.handleUniStream(stream)
+	}
+}
+
+func (c *serverConn) receiveDatagrams() {
+	for {
+		d, err := c.conn.ReceiveDatagram(context.Background())
+		if err != nil {
+			return
+		}
+		if len(d) < 2 || d[0] != Version {
+			continue
+		}
+		switch d[1] {
+		case CommandPacket:
+			if !c.waitAuth() {
+				return
+			}
+			c.udpSM.feed(d, false)
+		case CommandHeartbeat:
+		default:
+			_ = c.conn.CloseWithError(closeErrCodeProtocolError, "")
+			return
+		}
+	}
+}
+
+func (c *serverConn) acceptStreams() {
+	for {
+		stream, err := c.conn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		go c.handleStream(stream)
+	}
+}
+
+type Listener struct {
+	validator  *account.Validator
+	config     *Config
+	quicParams *internet.QuicParams
+	addConn    internet.ConnHandler
+
+	pktConn  net.PacketConn
+	tr       *quic.Transport
+	listener *quic.EarlyListener
+}
+
+func (l *Listener) handleClient(conn *quic.Conn) {
+	c := &serverConn{
+		validator: l.validator,
+		config:    l.config,
+		addConn:   l.addConn,
+		conn:      conn,
+		authed:    make(chan struct{}),
+	}
+	c.udpSM = &udpSessionManager{
+		conn: conn,
+		m:    make(map[uint16]*InterConn),
+
+		addConn:        l.addConn,
+		udpIdleTimeout: time.Duration(l.config.UdpIdleTimeout) * time.Second,
+	}
+
+	quicParams := l.quicParams
+	switch quicParams.Congestion {
+	case "reno":
+	case "", "bbr", "brutal":
+		congestion.UseBBR(conn, bbr.Profile(quicParams.BbrProfile))
+	case "force-brutal":
+		congestion.UseBrutal(conn, quicParams.BrutalUp)
+	default:
+		panic(quicParams.Congestion)
+	}
+
+	authTimeout := time.AfterFunc(time.Duration(l.config.AuthTimeout)*time.Second, func() {
+		select {
+		case <-c.authed:
+		default:
+			_ = conn.CloseWithError(closeErrCodeAuthTimeout, "")
+		}
+	})
+	defer authTimeout.Stop()
+
+	go c.udpSM.clean()
+	go c.acceptUniStreams()
+	go c.receiveDatagrams()
+	c.acceptStreams()
+	c.udpSM.closeAll()
+	_ = conn.CloseWithError(closeErrCodeOK, "")
+}
+
+func (l *Listener) keepAccepting() {
+	for {
+		conn, err := l.listener.Accept(context.Background())
+		if err != nil {
+			if err != quic.ErrServerClosed {
+				errors.LogErrorInner(context.Background(), err, "failed to serve tuic")
+			}
+			break
+		}
+		go l.handleClient(conn)
+	}
+}
+
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+func (l *Listener) Close() error {
+	return errors.Combine(l.listener.Close(), l.tr.Close(), l.pktConn.Close())
+}
+
+func Listen(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, handler internet.ConnHandler) (internet.Listener, error) {
+	if address.Family().IsDomain() {
+		return nil, errors.New("address is domain")
+	}
+
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig == nil {
+		return nil, errors.New("tls config is nil")
+	}
+
+	validator := ValidatorFromContext(ctx)
+	if validator == nil {
+		return nil, errors.New("validator is nil")
+	}
+	config := streamSettings.ProtocolSettings.(*Config)
+
+	quicParams := streamSettings.QuicParams
+	if quicParams == nil {
+		quicParams = &internet.QuicParams{
+			BbrProfile: string(bbr.ProfileStandard),
+		}
+	}
+
+	quicConfig := &quic.Config{
+		InitialStreamReceiveWindow:     quicParams.InitStreamReceiveWindow,
+		MaxStreamReceiveWindow:         quicParams.MaxStreamReceiveWindow,
+		InitialConnectionReceiveWindow: quicParams.InitConnReceiveWindow,
+		MaxConnectionReceiveWindow:     quicParams.MaxConnReceiveWindow,
+		MaxIdleTimeout:                 time.Duration(quicParams.MaxIdleTimeout) * time.Second,
+		MaxIncomingStreams:             quicParams.MaxIncomingStreams,
+		MaxIncomingUniStreams:          quicParams.MaxIncomingStreams,
+		DisablePathMTUDiscovery:        quicParams.DisablePathMtuDiscovery || (runtime.GOOS != "linux" && runtime.GOOS != "windows" && runtime.GOOS != "darwin"),
+		EnableDatagrams:                true,
+		MaxDatagramFrameSize:           MaxDatagramFrameSize,
+		Allow0RTT:                      config.ZeroRtt,
+		DisablePathManager:             true,
+	}
+	if quicParams.InitStreamReceiveWindow == 0 {
+		quicConfig.InitialStreamReceiveWindow = 8388608
+	}
+	if quicParams.MaxStreamReceiveWindow == 0 {
+		quicConfig.MaxStreamReceiveWindow = 8388608
+	}
+	if quicParams.InitConnReceiveWindow == 0 {
+		quicConfig.InitialConnectionReceiveWindow = 8388608 * 5 / 2
+	}
+	if quicParams.MaxConnReceiveWindow == 0 {
+		quicConfig.MaxConnectionReceiveWindow = 8388608 * 5 / 2
+	}
+	if quicParams.MaxIdleTimeout == 0 {
+		quicConfig.MaxIdleTimeout = 30 * time.Second
+	}
+	if quicParams.MaxIncomingStreams == 0 {
+		quicConfig.MaxIncomingStreams = 1024
+		quicConfig.MaxIncomingUniStreams = 1024
+	}
+
+	pktConn, err := internet.ListenSystemPacket(context.Background(), &net.UDPAddr{IP: address.IP(), Port: int(port)}, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if streamSettings.UdpmaskManager != nil {
+		newConn, err := streamSettings.UdpmaskManager.WrapPacketConnServer(pktConn)
+		if err != nil {
+			pktConn.Close()
+			return nil, errors.New("mask err").Base(err)
+		}
+		pktConn = newConn
+	}
+
+	tr := &quic.Transport{Conn: pktConn}
+
+	listener, err := tr.ListenEarly(tlsConfig.GetTLSConfig(tls.WithNextProto("h3")), quicConfig)
+	if err != nil {
+		_ = tr.Close()
+		_ = pktConn.Close()
+		return nil, err
+	}
+
+	l := &Listener{
+		validator:  validator,
+		config:     config,
+		quicParams: quicParams,
+		addConn:    handler,
+
+		pktConn:  pktConn,
+		tr:       tr,
+		listener: listener,
+	}
+
+	go l.keepAccepting()
+
+	return l, nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, Listen))
+}